@@ -1,7 +1,13 @@
 package ai
 
 import (
+	"context"
+	"fmt"
+	"math"
 	"math/rand"
+	"runtime"
+	"sort"
+	"strings"
 	"time"
 
 	"tic-tac-toe/internal/game"
@@ -17,12 +23,61 @@ const (
 	INeverLose
 )
 
+// movingDepth limits the search when marks move: those games need not end,
+// and three moves each already see every threat on a 3×3 board
+const movingDepth = 6
+
 // AI represents the AI player
 type AI struct {
 	difficulty   Difficulty
 	player       game.Player
 	opponent     game.Player
 	randomSource *rand.Rand
+	params       Params
+	workers      int          // Goroutines that search root moves in parallel
+	book         *OpeningBook // Varies the perfect AI's openings; nil picks uniformly
+	personality  Personality
+}
+
+// Params controls how the AI turns its evaluation of every move into a choice
+type Params struct {
+	Strength    float64 // Position on the ladder from 0 (weakest) to 1 (perfect)
+	Temperature float64 // Softmax temperature over move scores; 0 always plays a best move
+	Blunder     float64 // Chance of playing a uniformly random move instead
+}
+
+// Difficulties lists the named levels from weakest to strongest
+var Difficulties = []Difficulty{Easy, Normal, Hard, INeverLose}
+
+// levelStrengths are the named levels' strengths as calibrated by
+// Calibrate(CalibrationGames, CalibrationSeed)
+var levelStrengths = map[Difficulty]float64{
+	Easy:       0.25,
+	Normal:     0.4,
+	Hard:       0.6,
+	INeverLose: 1,
+}
+
+// Strength returns where a named level sits on the continuous strength scale
+func (d Difficulty) Strength() float64 {
+	if strength, ok := levelStrengths[d]; ok {
+		return strength
+	}
+	return levelStrengths[Easy]
+}
+
+// ParamsForStrength maps a strength from 0 to 1 onto the mistake model. Move
+// scores are about 100 apart between a win, a draw and a loss, so the
+// temperature sets how often a worse outcome is chosen, while blunders are
+// slips that ignore the evaluation entirely.
+func ParamsForStrength(strength float64) Params {
+	strength = math.Max(0, math.Min(1, strength))
+	weakness := 1 - strength
+	return Params{
+		Strength:    strength,
+		Temperature: 60 * weakness,
+		Blunder:     0.5 * weakness * weakness,
+	}
 }
 
 // New creates a new AI with specified difficulty and player
@@ -37,244 +92,213 @@ func New(difficulty Difficulty, player game.Player) *AI {
 		player:       player,
 		opponent:     opponent,
 		randomSource: rand.New(rand.NewSource(time.Now().UnixNano())),
+		params:       ParamsForStrength(difficulty.Strength()),
+		personality:  Personalities[0],
+		workers:      runtime.GOMAXPROCS(0),
 	}
 }
 
-// GetMove returns the AI's next move
-func (ai *AI) GetMove(g *game.Game) (int, int, error) {
-	availableMoves := g.GetAvailableMoves()
-	if len(availableMoves) == 0 {
-		return -1, -1, nil // No moves available
-	}
-
-	switch ai.difficulty {
-	case Easy:
-		return ai.getEasyMove(g, availableMoves)
-	case Normal:
-		return ai.getNormalMove(g, availableMoves)
-	case Hard:
-		return ai.getHardMove(g, availableMoves)
-	case INeverLose:
-		return ai.getPerfectMove(g, availableMoves)
+// ParseDifficulty converts a difficulty name such as "hard" into a Difficulty
+func ParseDifficulty(name string) (Difficulty, error) {
+	switch strings.ToLower(name) {
+	case "easy":
+		return Easy, nil
+	case "normal":
+		return Normal, nil
+	case "hard":
+		return Hard, nil
+	case "perfect", "i-never-lose":
+		return INeverLose, nil
 	default:
-		return ai.getEasyMove(g, availableMoves)
+		return Easy, fmt.Errorf("unknown difficulty %q (want easy, normal, hard or perfect)", name)
 	}
 }
 
-// getEasyMove - mostly random with occasional basic moves
-func (ai *AI) getEasyMove(g *game.Game, availableMoves []game.Position) (int, int, error) {
-	// 20% chance to make a smart move, 80% random
-	if ai.randomSource.Float32() < 0.2 {
-		// Try to win or block
-		if row, col := ai.findWinningMove(g, ai.player); row != -1 {
-			return row, col, nil
-		}
-		if row, col := ai.findWinningMove(g, ai.opponent); row != -1 {
-			return row, col, nil
-		}
-	}
+// SetSeed makes the AI's random choices reproducible
+func (ai *AI) SetSeed(seed int64) {
+	ai.randomSource = rand.New(rand.NewSource(seed))
+}
 
-	// Random move
-	move := availableMoves[ai.randomSource.Intn(len(availableMoves))]
-	return move.Row, move.Col, nil
+// SetBook lets the AI choose between equally good opening moves by their record
+func (ai *AI) SetBook(book *OpeningBook) {
+	ai.book = book
 }
 
-// getNormalMove - basic strategy: block wins, take center, corners
-func (ai *AI) getNormalMove(g *game.Game, availableMoves []game.Position) (int, int, error) {
-	// 1. Try to win
-	if row, col := ai.findWinningMove(g, ai.player); row != -1 {
-		return row, col, nil
-	}
+// GetMove returns the AI's next move
+func (ai *AI) GetMove(g *game.Game) (int, int, error) {
+	return ai.GetMoveContext(context.Background(), g, 0)
+}
 
-	// 2. Block opponent win
-	if row, col := ai.findWinningMove(g, ai.opponent); row != -1 {
-		return row, col, nil
-	}
+// GetMoveContext returns the AI's next move, giving up with the context's
+// error if it is cancelled first. A search outside the tablebase stops after
+// budget and plays the best move found so far; zero leaves timed games to
+// their clock and untimed games unlimited.
+func (ai *AI) GetMoveContext(ctx context.Context, g *game.Game, budget time.Duration) (int, int, error) {
+	move, err := ai.GetMoveMark(ctx, g, budget)
+	return move.Row, move.Col, err
+}
 
-	// 3. Take center if available
-	if g.IsValidMove(1, 1) {
-		return 1, 1, nil
+// GetMoveMark is GetMoveContext for any rules: the move carries the mark to
+// place. Rule variants and setups are outside the tablebase and searched,
+// and the mistake model then chooses between the searched moves as it does
+// for the tablebase, so the difficulty holds for handicap games too.
+func (ai *AI) GetMoveMark(ctx context.Context, g *game.Game, budget time.Duration) (game.Move, error) {
+	legalMoves := g.LegalMoves()
+	if len(legalMoves) == 0 {
+		return game.Move{Row: -1, Col: -1}, nil // No moves available
+	}
+	if err := ctx.Err(); err != nil {
+		return game.Move{Row: -1, Col: -1}, err
 	}
 
-	// 4. Take corners
-	corners := []game.Position{{0, 0}, {0, 2}, {2, 0}, {2, 2}}
-	for _, corner := range corners {
-		if g.IsValidMove(corner.Row, corner.Col) {
-			return corner.Row, corner.Col, nil
-		}
+	moves, err := ai.scoredMoves(ctx, g, budget)
+	if err != nil {
+		return game.Move{Row: -1, Col: -1}, err
+	}
+	if len(moves) == 0 {
+		// Out of time before the first ply finished
+		return legalMoves[ai.randomSource.Intn(len(legalMoves))], nil
 	}
 
-	// 5. Take any available move
-	move := availableMoves[ai.randomSource.Intn(len(availableMoves))]
-	return move.Row, move.Col, nil
+	if g.Opening.Rule != game.NoSwap && !g.Opening.Decided {
+		// The opponent may take this mark, so leave the position even
+		return ai.balancedMove(moves), nil
+	}
+	return ai.choose(g, legalMoves, moves), nil
 }
 
-// getHardMove - minimax algorithm with limited depth
-func (ai *AI) getHardMove(g *game.Game, availableMoves []game.Position) (int, int, error) {
-	bestMove := game.Position{Row: -1, Col: -1}
-	bestScore := -1000
-
-	for _, move := range availableMoves {
-		// Create a copy of the game for simulation
-		testGame := ai.copyGame(g)
-		testGame.MakeMove(move.Row, move.Col)
+// scoredMove is a legal move with its value for the player to move, on the
+// scale of MoveEvaluation.Score
+type scoredMove struct {
+	move  game.Move
+	score int
+}
 
-		score := ai.minimax(testGame, 4, false) // Look ahead 4 moves
-		if score > bestScore {
-			bestScore = score
-			bestMove = move
+// scoredMoves rates the legal moves best first: from the tablebase when it
+// knows the game, otherwise by a search that stops after budget. It returns
+// no moves if the search ran out of time before the first ply finished.
+func (ai *AI) scoredMoves(ctx context.Context, g *game.Game, budget time.Duration) ([]scoredMove, error) {
+	if evaluations := Analyze(g); len(evaluations) > 0 {
+		moves := make([]scoredMove, len(evaluations))
+		for i, evaluation := range evaluations {
+			move := game.Move{Row: evaluation.Move.Row, Col: evaluation.Move.Col, Mark: g.GetCurrentPlayer()}
+			moves[i] = scoredMove{move: move, score: evaluation.Score}
 		}
+		return moves, nil
 	}
 
-	if bestMove.Row == -1 {
-		// Fallback to normal strategy
-		return ai.getNormalMove(g, availableMoves)
+	// Positions outside the tablebase fall back to a deep search;
+	// timed games limit it to a share of the AI's clock
+	if clock := TimeBudget(g, time.Now()); clock > 0 && (budget == 0 || clock < budget) {
+		budget = clock
+	}
+	searchCtx := ctx
+	if budget > 0 {
+		var cancel context.CancelFunc
+		searchCtx, cancel = context.WithTimeout(ctx, budget)
+		defer cancel()
 	}
 
-	return bestMove.Row, bestMove.Col, nil
+	depth := 10
+	if g.GetRules().Pieces() > 0 {
+		depth = movingDepth
+	}
+	legalMoves, scores, searched, err := ai.scoreMoves(searchCtx, g, depth)
+	if err != nil {
+		return nil, ctx.Err()
+	}
+	moves := make([]scoredMove, len(legalMoves))
+	for i, move := range legalMoves {
+		moves[i] = scoredMove{move: move, score: outcomeScore(scores[i], searched)}
+	}
+	sort.SliceStable(moves, func(i, j int) bool { return moves[i].score > moves[j].score })
+	return moves, nil
 }
 
-// getPerfectMove - perfect minimax, never loses
-func (ai *AI) getPerfectMove(g *game.Game, availableMoves []game.Position) (int, int, error) {
-	bestMove := game.Position{Row: -1, Col: -1}
-	bestScore := -1000
-
-	for _, move := range availableMoves {
-		// Create a copy of the game for simulation
-		testGame := ai.copyGame(g)
-		testGame.MakeMove(move.Row, move.Col)
-
-		score := ai.minimax(testGame, 10, false) // Look ahead deeply
-		if score > bestScore {
-			bestScore = score
-			bestMove = move
-		}
+// choose applies the mistake model to the scored moves: an occasional
+// blunder, otherwise a softmax over the scores that sharpens as strength rises
+func (ai *AI) choose(g *game.Game, legalMoves []game.Move, moves []scoredMove) game.Move {
+	if ai.randomSource.Float64() < ai.params.Blunder {
+		return legalMoves[ai.randomSource.Intn(len(legalMoves))]
 	}
 
-	if bestMove.Row == -1 {
-		// Should never happen, but fallback
-		return ai.getHardMove(g, availableMoves)
+	// The personality reads one board, so it sits out games on several
+	style := make([]float64, len(moves))
+	for i, move := range moves {
+		if len(g.Extra) == 0 {
+			style[i] = ai.personality.Evaluate(g, game.Position{Row: move.move.Row, Col: move.move.Col})
+		}
 	}
 
-	return bestMove.Row, bestMove.Col, nil
-}
-
-// findWinningMove finds a move that would win for the specified player
-func (ai *AI) findWinningMove(g *game.Game, player game.Player) (int, int) {
-	board := g.GetBoard()
-
-	// Check rows
-	for row := 0; row < 3; row++ {
-		count := 0
-		emptyCol := -1
-		for col := 0; col < 3; col++ {
-			if board[row][col] == player {
-				count++
-			} else if board[row][col] == game.Empty {
-				emptyCol = col
+	if ai.params.Temperature <= 0 {
+		// The personality breaks ties between the moves that share the best
+		// value, then the book or chance varies play between what is left
+		bestStyle := style[0]
+		for i, move := range moves {
+			if move.score == moves[0].score && style[i] > bestStyle {
+				bestStyle = style[i]
 			}
 		}
-		if count == 2 && emptyCol != -1 {
-			return row, emptyCol
-		}
-	}
-
-	// Check columns
-	for col := 0; col < 3; col++ {
-		count := 0
-		emptyRow := -1
-		for row := 0; row < 3; row++ {
-			if board[row][col] == player {
-				count++
-			} else if board[row][col] == game.Empty {
-				emptyRow = row
+		var best []scoredMove
+		for i, move := range moves {
+			if move.score == moves[0].score && style[i] == bestStyle {
+				best = append(best, move)
 			}
 		}
-		if count == 2 && emptyRow != -1 {
-			return emptyRow, col
+		if ai.book != nil && g.IsStandard() && len(g.GetMoveHistory()) < BookDepth {
+			positions := make([]game.Position, len(best))
+			for i, move := range best {
+				positions[i] = game.Position{Row: move.move.Row, Col: move.move.Col}
+			}
+			chosen := ai.book.Choose(g, positions, ai.randomSource)
+			for _, move := range best {
+				if move.move.Row == chosen.Row && move.move.Col == chosen.Col {
+					return move.move
+				}
+			}
 		}
+		return best[ai.randomSource.Intn(len(best))].move
 	}
 
-	// Check diagonal (top-left to bottom-right)
-	count := 0
-	emptyRow, emptyCol := -1, -1
-	for i := 0; i < 3; i++ {
-		if board[i][i] == player {
-			count++
-		} else if board[i][i] == game.Empty {
-			emptyRow, emptyCol = i, i
-		}
-	}
-	if count == 2 && emptyRow != -1 {
-		return emptyRow, emptyCol
+	// Scores are relative to the best move so the weights cannot overflow
+	weights := make([]float64, len(moves))
+	total := 0.0
+	for i, move := range moves {
+		score := float64(move.score-moves[0].score) + styleScale*math.Min(style[i], maxStyle)
+		weights[i] = math.Exp(score / ai.params.Temperature)
+		total += weights[i]
 	}
-
-	// Check diagonal (top-right to bottom-left)
-	count = 0
-	emptyRow, emptyCol = -1, -1
-	for i := 0; i < 3; i++ {
-		if board[i][2-i] == player {
-			count++
-		} else if board[i][2-i] == game.Empty {
-			emptyRow, emptyCol = i, 2-i
+	pick := ai.randomSource.Float64() * total
+	for i, weight := range weights {
+		if pick < weight {
+			return moves[i].move
 		}
+		pick -= weight
 	}
-	if count == 2 && emptyRow != -1 {
-		return emptyRow, emptyCol
-	}
-
-	return -1, -1 // No winning move found
+	return moves[len(moves)-1].move
 }
 
-// minimax implements the minimax algorithm
-func (ai *AI) minimax(g *game.Game, depth int, isMaximizing bool) int {
-	status := g.GetStatus()
-
-	// Terminal conditions
-	if status == game.StatusWon {
-		if g.GetWinner() == ai.player {
-			return 10 + depth // Prefer quicker wins
-		}
-		return -10 - depth // Prefer delayed losses
-	}
-	if status == game.StatusDraw {
+// TimeBudget returns how long the player to move may think, spreading the
+// remaining clock over the moves they still have to make
+func TimeBudget(g *game.Game, now time.Time) time.Duration {
+	if g.Clock == nil {
 		return 0
 	}
-	if depth == 0 {
-		return 0 // Neutral when depth limit reached
-	}
 
-	availableMoves := g.GetAvailableMoves()
-	if isMaximizing {
-		maxScore := -1000
-		for _, move := range availableMoves {
-			testGame := ai.copyGame(g)
-			testGame.MakeMove(move.Row, move.Col)
-			score := ai.minimax(testGame, depth-1, false)
-			maxScore = max(maxScore, score)
-		}
-		return maxScore
-	} else {
-		minScore := 1000
-		for _, move := range availableMoves {
-			testGame := ai.copyGame(g)
-			testGame.MakeMove(move.Row, move.Col)
-			score := ai.minimax(testGame, depth-1, true)
-			minScore = min(minScore, score)
-		}
-		return minScore
+	remaining := g.GetRemainingTime(g.GetCurrentPlayer(), now)
+	movesLeft := (len(g.GetAvailableMoves()) + 1) / 2
+	if movesLeft < 1 {
+		movesLeft = 1
 	}
-}
 
-// copyGame creates a deep copy of the game state
-func (ai *AI) copyGame(g *game.Game) *game.Game {
-	newGame := game.New()
-	newGame.Board = g.GetBoard()
-	newGame.CurrentPlayer = g.GetCurrentPlayer()
-	newGame.Status = g.GetStatus()
-	newGame.Winner = g.GetWinner()
-	newGame.Mode = g.GetMode()
-	return newGame
+	budget := remaining/time.Duration(movesLeft) + g.Clock.Control.Increment/2
+	if budget > remaining/2 {
+		budget = remaining / 2
+	}
+	if budget < time.Millisecond {
+		budget = time.Millisecond
+	}
+	return budget
 }
 
 // GetDifficultyName returns the string name of the difficulty
@@ -298,9 +322,58 @@ func (ai *AI) GetPlayer() game.Player {
 	return ai.player
 }
 
-// SetDifficulty updates the AI difficulty
+// SetPlayer changes the AI's player, as when the swap rule exchanges the sides
+func (ai *AI) SetPlayer(player game.Player) {
+	ai.player = player
+	ai.opponent = game.PlayerO
+	if player == game.PlayerO {
+		ai.opponent = game.PlayerX
+	}
+}
+
+// GetDifficulty returns the AI's difficulty
+func (ai *AI) GetDifficulty() Difficulty {
+	return ai.difficulty
+}
+
+// SetDifficulty updates the AI difficulty and resets its strength to the level's
 func (ai *AI) SetDifficulty(difficulty Difficulty) {
 	ai.difficulty = difficulty
+	ai.params = ParamsForStrength(difficulty.Strength())
+}
+
+// SetStrength fine-tunes the AI between the named levels; 0 is the weakest and 1 perfect
+func (ai *AI) SetStrength(strength float64) {
+	ai.params = ParamsForStrength(strength)
+}
+
+// SetPersonality changes the AI's playing style
+func (ai *AI) SetPersonality(personality Personality) {
+	ai.personality = personality
+}
+
+// GetPersonality returns the AI's playing style
+func (ai *AI) GetPersonality() Personality {
+	return ai.personality
+}
+
+// SetWorkers sets how many goroutines search root moves in parallel; less
+// than 1 uses one per CPU
+func (ai *AI) SetWorkers(workers int) {
+	if workers < 1 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	ai.workers = workers
+}
+
+// GetWorkers returns how many goroutines search root moves in parallel
+func (ai *AI) GetWorkers() int {
+	return ai.workers
+}
+
+// GetParams returns the mistake-model parameters the AI plays with
+func (ai *AI) GetParams() Params {
+	return ai.params
 }
 
 // min returns the minimum of two integers
@@ -317,4 +390,4 @@ func max(a, b int) int {
 		return a
 	}
 	return b
-}
\ No newline at end of file
+}
@@ -1,6 +1,9 @@
 package ai_test
 
 import (
+	"context"
+	"time"
+
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
@@ -80,12 +83,31 @@ var _ = Describe("AI", func() {
 			g.MakeMove(1, 1) // O
 			g.MakeMove(0, 1) // X
 
-			// Hard AI should block at (0, 2)
+			// Hard AI should block at (0, 2) all but the odd time
 			aiHard := ai.New(ai.Hard, game.PlayerO)
-			row, col, err := aiHard.GetMove(g)
-			Expect(err).ToNot(HaveOccurred())
-			Expect(row).To(Equal(0))
-			Expect(col).To(Equal(2))
+			aiHard.SetSeed(1)
+			blocks := 0
+			for i := 0; i < 100; i++ {
+				row, col, err := aiHard.GetMove(g)
+				Expect(err).ToNot(HaveOccurred())
+				if row == 0 && col == 2 {
+					blocks++
+				}
+			}
+			Expect(blocks).To(BeNumerically(">=", 80))
+		})
+
+		It("should always block on I Never Lose", func() {
+			g.MakeMove(0, 0) // X
+			g.MakeMove(1, 1) // O
+			g.MakeMove(0, 1) // X
+
+			perfect := ai.New(ai.INeverLose, game.PlayerO)
+			for i := 0; i < 20; i++ {
+				row, col, err := perfect.GetMove(g)
+				Expect(err).ToNot(HaveOccurred())
+				Expect([]int{row, col}).To(Equal([]int{0, 2}))
+			}
 		})
 
 		It("should take winning moves when available", func() {
@@ -112,6 +134,29 @@ var _ = Describe("AI", func() {
 		})
 	})
 
+	Describe("Time budget", func() {
+		It("should not limit untimed games", func() {
+			Expect(ai.TimeBudget(g, time.Now())).To(BeZero())
+		})
+
+		It("should spread the clock over the remaining moves", func() {
+			g.SetTimeControl(game.TimeControl{Name: "test", Initial: 10 * time.Second})
+			budget := ai.TimeBudget(g, time.Now())
+			Expect(budget).To(BeNumerically(">", 0))
+			Expect(budget).To(BeNumerically("<=", 2*time.Second))
+		})
+
+		It("should still find a valid move when nearly out of time", func() {
+			g.SetTimeControl(game.TimeControl{Name: "test", Initial: 20 * time.Millisecond})
+			g.StartClock(time.Now())
+			g.MakeMove(1, 1)
+
+			row, col, err := ai.New(ai.INeverLose, game.PlayerO).GetMove(g)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(g.IsValidMove(row, col)).To(BeTrue())
+		})
+	})
+
 	Describe("SetDifficulty", func() {
 		It("should update difficulty", func() {
 			aiEasy.SetDifficulty(ai.Hard)
@@ -191,4 +236,512 @@ var _ = Describe("AI", func() {
 			Expect(hardWins).To(BeNumerically(">=", easyWins-2))
 		})
 	})
-})
\ No newline at end of file
+
+	Describe("Analyze", func() {
+		It("should find the winning move", func() {
+			position, err := game.ParsePosition("XX./OO./...")
+			Expect(err).ToNot(HaveOccurred())
+
+			evaluations := ai.Analyze(position)
+			Expect(evaluations).To(HaveLen(5))
+			Expect(evaluations[0].Move).To(Equal(game.Position{Row: 0, Col: 2}))
+			Expect(evaluations[0].Outcome).To(Equal(ai.OutcomeWin))
+			Expect(evaluations[1].Outcome).To(Equal(ai.OutcomeDraw))
+			Expect(evaluations[4].Outcome).To(Equal(ai.OutcomeLoss))
+		})
+
+		It("should rate every opening move as a draw or better", func() {
+			for _, evaluation := range ai.Analyze(game.New()) {
+				Expect(evaluation.Outcome).To(Equal(ai.OutcomeDraw))
+			}
+		})
+
+		It("should return nothing for finished games", func() {
+			position, _ := game.ParsePosition("XXX/OO./...")
+			Expect(ai.Analyze(position)).To(BeEmpty())
+		})
+	})
+
+	Describe("Opening book", func() {
+		var book *ai.OpeningBook
+
+		BeforeEach(func() {
+			book = ai.NewOpeningBook()
+		})
+
+		It("should share statistics between symmetric openings", func() {
+			book.Record([]game.Position{{Row: 0, Col: 0}, {Row: 1, Col: 1}}, game.PlayerX)
+			book.Record([]game.Position{{Row: 2, Col: 2}, {Row: 1, Col: 1}}, game.Empty)
+
+			g.MakeMove(0, 2)
+			entry, ok := book.Entry(g)
+			Expect(ok).To(BeTrue())
+			Expect(entry).To(Equal(ai.BookEntry{Games: 2, XWins: 1, Draws: 1}))
+		})
+
+		It("should only keep the first plies", func() {
+			moves := []game.Position{{Row: 0, Col: 0}, {Row: 1, Col: 0}, {Row: 0, Col: 1}, {Row: 1, Col: 1}, {Row: 0, Col: 2}}
+			book.Record(moves, game.PlayerX)
+			Expect(book.Positions).To(HaveLen(ai.BookDepth))
+		})
+
+		It("should favor moves that have scored well", func() {
+			for i := 0; i < 20; i++ {
+				book.Record([]game.Position{{Row: 1, Col: 1}}, game.PlayerX)
+				book.Record([]game.Position{{Row: 0, Col: 0}}, game.PlayerO)
+				book.Record([]game.Position{{Row: 0, Col: 1}}, game.PlayerO)
+			}
+
+			perfect := ai.New(ai.INeverLose, game.PlayerX)
+			perfect.SetSeed(1)
+			perfect.SetBook(book)
+			centers := 0
+			for i := 0; i < 50; i++ {
+				row, col, err := perfect.GetMove(game.New())
+				Expect(err).ToNot(HaveOccurred())
+				if row == 1 && col == 1 {
+					centers++
+				}
+			}
+			Expect(centers).To(BeNumerically(">", 25))
+		})
+
+		It("should vary the perfect AI's openings without a book", func() {
+			perfect := ai.New(ai.INeverLose, game.PlayerX)
+			perfect.SetSeed(1)
+			seen := map[game.Position]bool{}
+			for i := 0; i < 30; i++ {
+				row, col, _ := perfect.GetMove(game.New())
+				seen[game.Position{Row: row, Col: col}] = true
+			}
+			Expect(len(seen)).To(BeNumerically(">", 1))
+		})
+
+		It("should name openings by their shape", func() {
+			Expect(ai.OpeningName([]game.Position{{Row: 1, Col: 1}, {Row: 0, Col: 2}})).To(Equal("X center, O corner"))
+			Expect(ai.OpeningName([]game.Position{{Row: 0, Col: 0}, {Row: 2, Col: 2}})).To(Equal("X corner, O opposite corner"))
+			Expect(ai.OpeningName([]game.Position{{Row: 0, Col: 0}, {Row: 0, Col: 1}})).To(Equal("X corner, O adjacent edge"))
+			Expect(ai.OpeningName([]game.Position{{Row: 0, Col: 1}, {Row: 2, Col: 0}})).To(Equal("X edge, O far corner"))
+		})
+	})
+
+	Describe("Mistake model", func() {
+		It("should map strength onto fewer mistakes", func() {
+			weak := ai.ParamsForStrength(0)
+			strong := ai.ParamsForStrength(0.9)
+			Expect(weak.Temperature).To(BeNumerically(">", strong.Temperature))
+			Expect(weak.Blunder).To(BeNumerically(">", strong.Blunder))
+			Expect(ai.ParamsForStrength(1)).To(Equal(ai.Params{Strength: 1}))
+			Expect(ai.ParamsForStrength(7).Strength).To(Equal(1.0))
+		})
+
+		It("should order the named levels by strength", func() {
+			for i := 1; i < len(ai.Difficulties); i++ {
+				Expect(ai.Difficulties[i].Strength()).To(BeNumerically(">", ai.Difficulties[i-1].Strength()))
+			}
+		})
+
+		It("should let strength override the level", func() {
+			player := ai.New(ai.Easy, game.PlayerO)
+			player.SetStrength(1)
+			Expect(player.GetParams().Blunder).To(BeZero())
+			player.SetDifficulty(ai.Normal)
+			Expect(player.GetParams().Strength).To(Equal(ai.Normal.Strength()))
+		})
+
+		It("should measure a monotonic strength ladder", func() {
+			rungs := ai.MeasureLadder(400, 1)
+			Expect(rungs).To(HaveLen(len(ai.Difficulties)))
+			for i := 1; i < len(rungs); i++ {
+				Expect(rungs[i].Rating()).To(BeNumerically(">", rungs[i-1].Rating()), rungs[i].Name)
+			}
+			Expect(rungs[len(rungs)-1].VsPerfect).To(Equal(0.5)) // Perfect play never loses
+		})
+
+		It("should reproduce the calibrated level strengths", func() {
+			rungs := ai.Calibrate(ai.CalibrationGames, ai.CalibrationSeed)
+			Expect(rungs).To(HaveLen(len(ai.Difficulties)))
+			for i, rung := range rungs {
+				Expect(rung.Difficulty).To(Equal(ai.Difficulties[i]))
+				Expect(rung.Strength).To(Equal(rung.Difficulty.Strength()), rung.Name)
+			}
+		})
+	})
+
+	Describe("Personalities", func() {
+		personality := func(id string) ai.Personality {
+			p, err := ai.PersonalityByID(id)
+			Expect(err).ToNot(HaveOccurred())
+			return p
+		}
+
+		It("should look personalities up by ID", func() {
+			Expect(personality("Aggressive").Name).To(Equal("Blaze"))
+			_, err := ai.PersonalityByID("reckless")
+			Expect(err).To(HaveOccurred())
+			for _, p := range ai.Personalities {
+				Expect(p.Name).ToNot(BeEmpty())
+				Expect(p.Taunt).ToNot(BeEmpty())
+			}
+		})
+
+		It("should make the aggressive AI value forks", func() {
+			position, _ := game.ParsePosition("XO./.O./..X")
+			aggressive := personality("aggressive")
+			fork := aggressive.Evaluate(position, game.Position{Row: 2, Col: 0})
+			single := aggressive.Evaluate(position, game.Position{Row: 1, Col: 0})
+			Expect(fork).To(BeNumerically(">", single))
+		})
+
+		It("should not count threats where completing a line loses", func() {
+			aggressive := personality("aggressive")
+			for _, variant := range []game.Variant{game.Misere, game.Notakto} {
+				position, _ := game.ParsePosition("XO./.O./..X")
+				position.SetRules(game.Rules{Variant: variant})
+				Expect(aggressive.Evaluate(position, game.Position{Row: 2, Col: 0})).To(BeZero(), string(variant))
+			}
+		})
+
+		It("should make the defensive AI value blocks", func() {
+			position, _ := game.ParsePosition("X../.../...")
+			defensive := personality("defensive")
+			Expect(defensive.Evaluate(position, game.Position{Row: 1, Col: 1})).To(BeNumerically(">",
+				defensive.Evaluate(position, game.Position{Row: 1, Col: 2})))
+		})
+
+		It("should make the mirror AI answer through the center", func() {
+			g.MakeMove(0, 1)
+			echo := ai.New(ai.INeverLose, game.PlayerO)
+			echo.SetPersonality(personality("mirror"))
+			for i := 0; i < 10; i++ {
+				row, col, err := echo.GetMove(g)
+				Expect(err).ToNot(HaveOccurred())
+				Expect([]int{row, col}).To(Equal([]int{2, 1}))
+			}
+		})
+
+		It("should make the trickster prefer replies that lose", func() {
+			trickster := personality("trickster")
+			// Only the center holds against a corner opening; half the replies hold against the center
+			Expect(trickster.Evaluate(game.New(), game.Position{Row: 0, Col: 0})).To(BeNumerically(">",
+				trickster.Evaluate(game.New(), game.Position{Row: 1, Col: 1})))
+		})
+
+		It("should never cost a perfect AI a game", func() {
+			random := ai.New(ai.Easy, game.PlayerO)
+			random.SetStrength(0)
+			random.SetSeed(3)
+			for _, p := range ai.Personalities {
+				styled := ai.New(ai.INeverLose, game.PlayerX)
+				styled.SetPersonality(p)
+				styled.SetSeed(3)
+				for i := 0; i < 20; i++ {
+					Expect(ai.PlayGame(styled, random)).ToNot(Equal(game.PlayerO), p.ID)
+					Expect(ai.PlayGame(random, styled)).ToNot(Equal(game.PlayerX), p.ID)
+				}
+			}
+		})
+	})
+
+	Describe("Explain", func() {
+		explain := func(position string, row, col int) ai.Explanation {
+			g, err := game.ParsePosition(position)
+			Expect(err).ToNot(HaveOccurred())
+			return ai.Explain(g, game.Position{Row: row, Col: col})
+		}
+
+		It("should explain a winning move", func() {
+			explanation := explain("XX./OO./...", 0, 2)
+			Expect(explanation.Player).To(Equal(game.PlayerX))
+			Expect(explanation.Outcome).To(Equal(ai.OutcomeWin))
+			Expect(explanation.String()).To(Equal("wins on row 0"))
+		})
+
+		It("should explain a forced block", func() {
+			explanation := explain("XX./.O./...", 0, 2)
+			Expect(explanation.Outcome).To(Equal(ai.OutcomeDraw))
+			Expect(explanation.String()).To(Equal("blocks X's row 0; threatens anti-diagonal; forced: every other move loses in 2"))
+		})
+
+		It("should explain a fork", func() {
+			explanation := explain("XO./.X./..O", 1, 0)
+			Expect(explanation.Reasons[0].Kind).To(Equal(ai.ReasonFork))
+			Expect(explanation.String()).To(Equal("creates a fork on row 1 and column 0"))
+		})
+
+		It("should point out mistakes", func() {
+			explanation := explain("XX./OO./...", 2, 2)
+			Expect(explanation.Outcome).To(Equal(ai.OutcomeLoss))
+			Expect(explanation.String()).To(Equal("mistake: misses a win"))
+		})
+
+		It("should name quiet moves by their cell", func() {
+			Expect(explain(".../.../...", 1, 1).String()).To(Equal("takes center"))
+			Expect(explain(".../.../...", 0, 2).String()).To(Equal("takes a corner"))
+			Expect(explain(".../.../...", 1, 0).String()).To(Equal("takes an edge"))
+		})
+	})
+
+	Describe("Search", func() {
+		positions := []string{"XX./OO./...", "X../.O./..X", "XO./.X./..O", ".../.X./..."}
+
+		It("should find wins and blocks", func() {
+			position, _ := game.ParsePosition("XX./OO./...")
+			move, err := aiEasy.Search(context.Background(), position, 9)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(move).To(Equal(game.Position{Row: 0, Col: 2}))
+
+			position, _ = game.ParsePosition("XX./.O./...")
+			move, err = aiEasy.Search(context.Background(), position, 9)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(move).To(Equal(game.Position{Row: 0, Col: 2}))
+		})
+
+		It("should give the same move with any number of workers", func() {
+			for _, notation := range positions {
+				position, err := game.ParsePosition(notation)
+				Expect(err).ToNot(HaveOccurred())
+
+				aiEasy.SetWorkers(1)
+				expected, err := aiEasy.Search(context.Background(), position, 9)
+				Expect(err).ToNot(HaveOccurred())
+				for _, workers := range []int{2, 3, 8} {
+					aiEasy.SetWorkers(workers)
+					Expect(aiEasy.Search(context.Background(), position, 9)).To(Equal(expected), notation)
+				}
+			}
+		})
+
+		It("should default to one worker per CPU", func() {
+			aiEasy.SetWorkers(0)
+			Expect(aiEasy.GetWorkers()).To(BeNumerically(">=", 1))
+		})
+
+		It("should stop when the context is cancelled", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			_, err := aiEasy.Search(ctx, g, 9)
+			Expect(err).To(MatchError(context.Canceled))
+			_, _, err = aiEasy.GetMoveContext(ctx, g, time.Second)
+			Expect(err).To(MatchError(context.Canceled))
+		})
+
+		It("should keep the deepest result that finished in time", func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+
+			position, _ := game.ParsePosition("XX./OO./...")
+			move, err := aiEasy.Search(ctx, position, 9)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(move).To(Equal(game.Position{Row: 0, Col: 2}))
+		})
+	})
+
+	Describe("Rule variants", func() {
+		variantGame := func(variant game.Variant, moves ...game.Move) *game.Game {
+			g := game.New()
+			g.SetRules(game.Rules{Variant: variant})
+			for _, move := range moves {
+				Expect(g.Play(move)).To(Succeed())
+			}
+			return g
+		}
+
+		var perfect *ai.AI
+		BeforeEach(func() {
+			perfect = ai.New(ai.INeverLose, game.PlayerO)
+			perfect.SetSeed(1)
+		})
+
+		It("should complete a line of the opponent's mark in Wild", func() {
+			position := variantGame(game.Wild,
+				game.Move{Row: 0, Col: 0, Mark: game.PlayerO},
+				game.Move{Row: 0, Col: 1, Mark: game.PlayerO},
+			)
+			move, err := perfect.GetMoveMark(context.Background(), position, 0)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(move).To(Equal(game.Move{Row: 0, Col: 2, Mark: game.PlayerO}))
+		})
+
+		It("should not complete its own line in misère", func() {
+			position := variantGame(game.Misere,
+				game.Move{Row: 0, Col: 0, Mark: game.PlayerX},
+				game.Move{Row: 1, Col: 0, Mark: game.PlayerO},
+				game.Move{Row: 0, Col: 1, Mark: game.PlayerX},
+				game.Move{Row: 1, Col: 1, Mark: game.PlayerO},
+			)
+			row, col, err := perfect.GetMove(position)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(game.Position{Row: row, Col: col}).ToNot(Equal(game.Position{Row: 0, Col: 2}))
+			Expect(position.MakeMove(row, col)).To(Succeed())
+			Expect(position.GetStatus()).To(Equal(game.StatusPlaying))
+		})
+
+		It("should block Order's line as Chaos", func() {
+			// Order wins either way, but only the block holds out longest
+			position := variantGame(game.OrderChaos,
+				game.Move{Row: 0, Col: 0, Mark: game.PlayerX},
+				game.Move{Row: 1, Col: 0, Mark: game.PlayerO},
+				game.Move{Row: 0, Col: 1, Mark: game.PlayerX},
+			)
+			chaos := ai.New(ai.INeverLose, game.PlayerO)
+			move, err := chaos.GetMoveMark(context.Background(), position, 0)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(move).To(Equal(game.Move{Row: 0, Col: 2, Mark: game.PlayerO}))
+		})
+
+		It("should avoid the cell that completes a line in Notakto", func() {
+			position := variantGame(game.Notakto,
+				game.Move{Row: 0, Col: 0, Mark: game.PlayerX},
+				game.Move{Row: 0, Col: 1, Mark: game.PlayerX},
+				game.Move{Row: 1, Col: 0, Mark: game.PlayerX},
+				game.Move{Row: 1, Col: 2, Mark: game.PlayerX},
+			)
+			move, err := perfect.GetMoveMark(context.Background(), position, 0)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(move.Mark).To(Equal(game.PlayerX))
+			Expect(position.Play(move)).To(Succeed())
+			Expect(position.GetStatus()).To(Equal(game.StatusPlaying))
+		})
+
+		It("should play Notakto on the boards still in play", func() {
+			position := game.New()
+			position.SetRules(game.Rules{Variant: game.Notakto, Boards: 2})
+			on := func(board, row, col int) game.Move {
+				return game.Move{Row: row, Col: col, Mark: game.PlayerX, Board: board}
+			}
+			// The first board is out of play, and four of the second's cells complete a line
+			for _, move := range []game.Move{on(0, 0, 0), on(0, 0, 1), on(0, 0, 2), on(1, 0, 0), on(1, 0, 1), on(1, 1, 0), on(1, 1, 2)} {
+				Expect(position.Play(move)).To(Succeed())
+			}
+
+			move, err := perfect.GetMoveMark(context.Background(), position, 0)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(move.Board).To(Equal(1))
+			Expect(position.Play(move)).To(Succeed())
+			Expect(position.GetStatus()).To(Equal(game.StatusPlaying))
+		})
+
+		It("should count repetitions when marks move", func() {
+			x := func(row, col int) game.Move { return game.Move{Row: row, Col: col, Mark: game.PlayerX} }
+			o := func(row, col int) game.Move { return game.Move{Row: row, Col: col, Mark: game.PlayerO} }
+			slide := func(fromRow, fromCol, toRow, toCol int) game.Move {
+				return game.Move{Kind: game.Slide, From: game.Position{Row: fromRow, Col: fromCol}, Row: toRow, Col: toCol}
+			}
+			// O.X / XO. / OX. with X to move for the third time: every other
+			// move loses at once, but sliding back to (2,2) draws by repetition
+			position := variantGame(game.FreeMoving,
+				x(0, 1), o(2, 0), x(1, 0), o(0, 0), x(2, 2), o(1, 1),
+				slide(0, 1, 0, 2), slide(1, 1, 1, 2), slide(2, 2, 0, 1), slide(1, 2, 1, 1),
+				slide(0, 1, 2, 2), slide(1, 1, 1, 2), slide(2, 2, 2, 1), slide(1, 2, 1, 1),
+			)
+			for _, move := range position.LegalMoves() {
+				after := position.Clone()
+				Expect(after.Play(move)).To(Succeed())
+				if after.GetStatus() == game.StatusDraw {
+					Expect(move).To(Equal(game.Move{Kind: game.Slide, From: game.Position{Row: 2, Col: 1}, Row: 2, Col: 2, Mark: game.PlayerX}))
+				}
+			}
+
+			order := ai.New(ai.INeverLose, game.PlayerX)
+			order.SetSeed(1)
+			move, err := order.GetMoveMark(context.Background(), position, 0)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(position.Play(move)).To(Succeed())
+			Expect(position.GetStatus()).To(Equal(game.StatusDraw))
+		})
+
+		It("should make mistakes in variants when weak and not when perfect", func() {
+			position := variantGame(game.Wild,
+				game.Move{Row: 0, Col: 0, Mark: game.PlayerO},
+				game.Move{Row: 0, Col: 1, Mark: game.PlayerO},
+			)
+			win := game.Move{Row: 0, Col: 2, Mark: game.PlayerO}
+			misses := 0
+			for seed := int64(1); seed <= 40; seed++ {
+				weak := ai.New(ai.Easy, game.PlayerX)
+				weak.SetStrength(0)
+				weak.SetSeed(seed)
+				if move, err := weak.GetMoveMark(context.Background(), position, 0); err == nil && move != win {
+					misses++
+				}
+				perfect.SetSeed(seed)
+				Expect(perfect.GetMoveMark(context.Background(), position, 0)).To(Equal(win))
+			}
+			Expect(misses).To(BeNumerically(">", 0))
+		})
+
+		It("should play whole games under every variant", func() {
+			for _, variant := range game.Variants {
+				position := variantGame(variant)
+				for position.GetStatus() == game.StatusPlaying {
+					move, err := aiEasy.GetMoveMark(context.Background(), position, time.Second)
+					Expect(err).ToNot(HaveOccurred(), string(variant))
+					Expect(position.Play(move)).To(Succeed(), string(variant))
+				}
+			}
+		})
+	})
+
+	Describe("Setups", func() {
+		It("should play setups at the AI's strength rather than always perfectly", func() {
+			setup, err := game.LookupSetup("Blocked center")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(g.ApplySetup(setup)).To(Succeed())
+			for _, cell := range [][2]int{{0, 0}, {2, 2}, {0, 1}} {
+				Expect(g.MakeMove(cell[0], cell[1])).To(Succeed())
+			}
+			block := game.Move{Row: 0, Col: 2, Mark: game.PlayerO}
+
+			misses := 0
+			for seed := int64(1); seed <= 40; seed++ {
+				weak := ai.New(ai.Easy, game.PlayerO)
+				weak.SetStrength(0)
+				weak.SetSeed(seed)
+				if move, err := weak.GetMoveMark(context.Background(), g, 0); err == nil && move != block {
+					misses++
+				}
+				perfect := ai.New(ai.INeverLose, game.PlayerO)
+				perfect.SetSeed(seed)
+				Expect(perfect.GetMoveMark(context.Background(), g, 0)).To(Equal(block))
+			}
+			Expect(misses).To(BeNumerically(">", 0))
+		})
+	})
+
+	Describe("Swap rule", func() {
+		It("should keep its side when the first mark wins nothing", func() {
+			chooser := ai.New(ai.INeverLose, game.PlayerO)
+			g.SetSwapRule(game.PieRule)
+			_, err := chooser.DecideSwap(context.Background(), g)
+			Expect(err).To(HaveOccurred())
+
+			Expect(g.MakeMove(1, 1)).To(Succeed())
+			Expect(chooser.DecideSwap(context.Background(), g)).To(Equal(game.KeepSides))
+		})
+
+		It("should take over an opening that wins", func() {
+			setup, err := game.LookupSetup("Handicap X")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(g.ApplySetup(setup)).To(Succeed())
+			g.SetSwapRule(game.PieRule)
+			Expect(g.MakeMove(0, 0)).To(Succeed())
+
+			chooser := ai.New(ai.INeverLose, game.PlayerO)
+			Expect(chooser.DecideSwap(context.Background(), g)).To(Equal(game.SwapSides))
+			chooser.SetPlayer(game.PlayerX)
+			Expect(chooser.GetPlayer()).To(Equal(game.PlayerX))
+		})
+
+		It("should open with a mark the opponent gains nothing by taking", func() {
+			opener := ai.New(ai.INeverLose, game.PlayerX)
+			opener.SetSeed(1)
+			g.SetSwapRule(game.PieRule)
+			row, col, err := opener.GetMove(g)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(g.MakeMove(row, col)).To(Succeed())
+			Expect(ai.New(ai.INeverLose, game.PlayerO).DecideSwap(context.Background(), g)).To(Equal(game.KeepSides))
+		})
+	})
+})
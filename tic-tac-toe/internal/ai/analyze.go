@@ -0,0 +1,58 @@
+package ai
+
+import (
+	"tic-tac-toe/internal/game"
+	"tic-tac-toe/internal/solve"
+)
+
+// Outcome is the result of a move with perfect play from both sides
+type Outcome string
+
+const (
+	OutcomeWin  Outcome = "win"
+	OutcomeDraw Outcome = "draw"
+	OutcomeLoss Outcome = "loss"
+)
+
+// MoveEvaluation is the perfect-play value of one legal move for the player to move
+type MoveEvaluation struct {
+	Move     game.Position `json:"move"`
+	Outcome  Outcome       `json:"outcome"`
+	Score    int           `json:"score"`    // Higher is better; quicker wins score more
+	Distance int           `json:"distance"` // Plies until the game ends with perfect play, this move included
+}
+
+// Analyze rates every legal move from the classic tablebase, best first.
+// Other rule variants and setups are not in the tablebase and get no evaluations.
+func Analyze(g *game.Game) []MoveEvaluation {
+	var evaluations []MoveEvaluation
+	if g.GetStatus() != game.StatusPlaying || !g.IsStandard() {
+		return evaluations
+	}
+
+	moves, err := solve.Classic().Moves(solve.FromGame(g))
+	if err != nil {
+		return evaluations
+	}
+	for _, move := range moves {
+		evaluations = append(evaluations, MoveEvaluation{
+			Move:     game.Position{Row: move.Row, Col: move.Col},
+			Outcome:  Outcome(move.Result.Outcome),
+			Score:    score(move.Result),
+			Distance: move.Result.Distance,
+		})
+	}
+	return evaluations
+}
+
+// score ranks a perfect-play result: quicker wins and slower losses score higher
+func score(result solve.Result) int {
+	switch result.Outcome {
+	case solve.Win:
+		return 100 - result.Distance
+	case solve.Loss:
+		return result.Distance - 100
+	default:
+		return 0
+	}
+}
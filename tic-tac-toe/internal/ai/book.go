@@ -0,0 +1,163 @@
+package ai
+
+import (
+	"math/rand"
+
+	"tic-tac-toe/internal/game"
+	"tic-tac-toe/internal/solve"
+)
+
+// BookDepth is how many opening plies the book keeps statistics for
+const BookDepth = 4
+
+// BookEntry aggregates the results of games that reached a position
+type BookEntry struct {
+	Games int `json:"games"`
+	XWins int `json:"x_wins"`
+	OWins int `json:"o_wins"`
+	Draws int `json:"draws"`
+}
+
+// OpeningBook is a tree of early positions with the results of the games
+// that passed through them. Positions are stored in canonical form, so
+// rotations and reflections of an opening share their statistics.
+type OpeningBook struct {
+	Positions map[string]*BookEntry `json:"positions"`
+}
+
+// NewOpeningBook creates an empty opening book
+func NewOpeningBook() *OpeningBook {
+	return &OpeningBook{Positions: map[string]*BookEntry{}}
+}
+
+// Record adds a finished game's opening to the book; winner is Empty for a draw
+func (b *OpeningBook) Record(moves []game.Position, winner game.Player) {
+	if b.Positions == nil {
+		b.Positions = map[string]*BookEntry{}
+	}
+
+	g := game.New()
+	for i, move := range moves {
+		if i == BookDepth || g.MakeMove(move.Row, move.Col) != nil {
+			return
+		}
+
+		key := bookKey(g)
+		entry := b.Positions[key]
+		if entry == nil {
+			entry = &BookEntry{}
+			b.Positions[key] = entry
+		}
+		entry.Games++
+		switch winner {
+		case game.PlayerX:
+			entry.XWins++
+		case game.PlayerO:
+			entry.OWins++
+		default:
+			entry.Draws++
+		}
+	}
+}
+
+// Entry returns the statistics of the game's current position
+func (b *OpeningBook) Entry(g *game.Game) (BookEntry, bool) {
+	entry, ok := b.Positions[bookKey(g)]
+	if !ok {
+		return BookEntry{}, false
+	}
+	return *entry, true
+}
+
+// Choose picks one of the candidate moves at random, weighted by how well
+// the position it leads to has scored for the player to move. Moves the book
+// has never seen count as an even score, so new lines still get tried.
+func (b *OpeningBook) Choose(g *game.Game, candidates []game.Position, rng *rand.Rand) game.Position {
+	player := g.GetCurrentPlayer()
+	weights := make([]float64, len(candidates))
+	total := 0.0
+	for i, move := range candidates {
+		child := cloneGame(g)
+		child.MakeMove(move.Row, move.Col)
+
+		entry, _ := b.Entry(child)
+		wins := entry.XWins
+		if player == game.PlayerO {
+			wins = entry.OWins
+		}
+		// Laplace smoothing keeps a single loss from ruling a move out
+		weights[i] = (float64(wins) + float64(entry.Draws)/2 + 1) / float64(entry.Games+2)
+		total += weights[i]
+	}
+
+	pick := rng.Float64() * total
+	for i, weight := range weights {
+		if pick < weight {
+			return candidates[i]
+		}
+		pick -= weight
+	}
+	return candidates[len(candidates)-1]
+}
+
+// bookKey identifies a position up to symmetry
+func bookKey(g *game.Game) string {
+	return solve.FromGame(g).Canonical().String()
+}
+
+// cloneGame copies g for the AI to play moves on, history included so that
+// repetitions count. The clock is dropped so searching never flags a side,
+// and the swap rule too, since the search plays colors rather than seats.
+func cloneGame(g *game.Game) *game.Game {
+	clone := g.Clone()
+	clone.Clock = nil
+	clone.Opening = game.Opening{}
+	return clone
+}
+
+// OpeningName describes the first two moves of a game, e.g.
+// "X corner, O center"; symmetric openings get the same name
+func OpeningName(moves []game.Position) string {
+	if len(moves) == 0 {
+		return "No moves"
+	}
+
+	first := moves[0]
+	name := "X " + cellKind(first)
+	if len(moves) == 1 {
+		return name
+	}
+
+	reply := moves[1]
+	switch {
+	case reply == (game.Position{Row: 1, Col: 1}) || first == (game.Position{Row: 1, Col: 1}):
+		return name + ", O " + cellKind(reply)
+	case reply == (game.Position{Row: 2 - first.Row, Col: 2 - first.Col}):
+		return name + ", O opposite " + cellKind(reply)
+	case abs(reply.Row-first.Row) <= 1 && abs(reply.Col-first.Col) <= 1,
+		reply.Row == first.Row, reply.Col == first.Col:
+		return name + ", O adjacent " + cellKind(reply)
+	default:
+		return name + ", O far " + cellKind(reply)
+	}
+}
+
+// cellKind classifies a cell as center, corner or edge
+func cellKind(p game.Position) string {
+	switch {
+	case p.Row == 1 && p.Col == 1:
+		return "center"
+	case p.Row != 1 && p.Col != 1:
+		return "corner"
+	default:
+		return "edge"
+	}
+}
+
+// abs returns the absolute value of an integer
+func abs(a int) int {
+	if a < 0 {
+		return -a
+	}
+	return a
+}
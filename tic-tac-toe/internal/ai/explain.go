@@ -0,0 +1,204 @@
+package ai
+
+import (
+	"fmt"
+	"strings"
+
+	"tic-tac-toe/internal/game"
+)
+
+// ReasonKind is one reason a move was worth playing, or why it was not
+type ReasonKind string
+
+const (
+	ReasonWin     ReasonKind = "win"     // Completes a line
+	ReasonBlock   ReasonKind = "block"   // Fills the gap in the opponent's two-in-a-row
+	ReasonFork    ReasonKind = "fork"    // Makes two threats at once
+	ReasonThreat  ReasonKind = "threat"  // Makes one threat
+	ReasonForced  ReasonKind = "forced"  // Every other move loses
+	ReasonCenter  ReasonKind = "center"  // Quiet move to the center
+	ReasonCorner  ReasonKind = "corner"  // Quiet move to a corner
+	ReasonEdge    ReasonKind = "edge"    // Quiet move to an edge
+	ReasonMistake ReasonKind = "mistake" // A better outcome was available
+)
+
+// Line is a row, column or diagonal of the board
+type Line struct {
+	Kind  string `json:"kind"` // "row", "column", "diagonal" or "anti-diagonal"
+	Index int    `json:"index,omitempty"`
+}
+
+// String names the line the way explanations print it, e.g. "row 0"
+func (l Line) String() string {
+	if l.Kind == "row" || l.Kind == "column" {
+		return fmt.Sprintf("%s %d", l.Kind, l.Index)
+	}
+	return l.Kind
+}
+
+// Reason is one part of an explanation
+type Reason struct {
+	Kind     ReasonKind  `json:"kind"`
+	Lines    []Line      `json:"lines,omitempty"`    // Lines won, blocked or threatened
+	Opponent game.Player `json:"opponent,omitempty"` // Whose lines a block stops
+	Distance int         `json:"distance,omitempty"` // Forced: plies until the quickest loss the move avoids
+	Missed   Outcome     `json:"missed,omitempty"`   // Mistake: the outcome a best move would have kept
+}
+
+// String describes the reason in a few words
+func (r Reason) String() string {
+	switch r.Kind {
+	case ReasonWin:
+		return "wins on " + joinLines(r.Lines)
+	case ReasonBlock:
+		return "blocks " + string(r.Opponent) + "'s " + joinLines(r.Lines)
+	case ReasonFork:
+		return "creates a fork on " + joinLines(r.Lines)
+	case ReasonThreat:
+		return "threatens " + joinLines(r.Lines)
+	case ReasonForced:
+		return fmt.Sprintf("forced: every other move loses in %d", r.Distance)
+	case ReasonCenter:
+		return "takes center"
+	case ReasonCorner:
+		return "takes a corner"
+	case ReasonEdge:
+		return "takes an edge"
+	case ReasonMistake:
+		if r.Missed == OutcomeWin {
+			return "mistake: misses a win"
+		}
+		return "mistake: throws away the draw"
+	default:
+		return string(r.Kind)
+	}
+}
+
+// Explanation says why a move was played, from the rules of the position
+// and its perfect-play value
+type Explanation struct {
+	Move    game.Position `json:"move"`
+	Player  game.Player   `json:"player"`
+	Outcome Outcome       `json:"outcome,omitempty"` // Value of the move with perfect play
+	Reasons []Reason      `json:"reasons"`
+}
+
+// String joins the reasons, e.g. "blocks X's column 2; forced: every other move loses in 2"
+func (e Explanation) String() string {
+	parts := make([]string, len(e.Reasons))
+	for i, reason := range e.Reasons {
+		parts[i] = reason.String()
+	}
+	return strings.Join(parts, "; ")
+}
+
+// Explain describes the move the player to move in g is about to make
+func Explain(g *game.Game, move game.Position) Explanation {
+	player := g.GetCurrentPlayer()
+	opponent := otherSide(player)
+	board := g.GetBoard()
+	explanation := Explanation{Move: move, Player: player}
+
+	// Lines the move completes, blocks or turns into threats
+	var wins, blocks, threats []Line
+	for _, line := range linesThrough(move) {
+		mine, theirs := count(board, line, player), count(board, line, opponent)
+		switch {
+		case mine == 2:
+			wins = append(wins, lineOf(line))
+		case theirs == 2:
+			blocks = append(blocks, lineOf(line))
+		case mine == 1 && theirs == 0:
+			threats = append(threats, lineOf(line))
+		}
+	}
+	if len(wins) > 0 {
+		explanation.Outcome = OutcomeWin
+		explanation.Reasons = []Reason{{Kind: ReasonWin, Lines: wins}}
+		return explanation
+	}
+
+	if len(blocks) > 0 {
+		explanation.Reasons = append(explanation.Reasons, Reason{Kind: ReasonBlock, Lines: blocks, Opponent: opponent})
+	}
+	switch {
+	case len(threats) >= 2:
+		explanation.Reasons = append(explanation.Reasons, Reason{Kind: ReasonFork, Lines: threats})
+	case len(threats) == 1:
+		explanation.Reasons = append(explanation.Reasons, Reason{Kind: ReasonThreat, Lines: threats})
+	}
+
+	// The perfect-play values show forced moves and mistakes
+	evaluations := Analyze(g)
+	for _, evaluation := range evaluations {
+		if evaluation.Move == move {
+			explanation.Outcome = evaluation.Outcome
+		}
+	}
+	if len(evaluations) > 0 && explanation.Outcome != "" {
+		best := evaluations[0].Outcome
+		if explanation.Outcome != best {
+			explanation.Reasons = append(explanation.Reasons, Reason{Kind: ReasonMistake, Missed: best})
+		} else if explanation.Outcome == OutcomeLoss {
+			// Nothing is forced when every move loses
+		} else if forced, distance := onlyNonLosingMove(evaluations, move); forced {
+			explanation.Reasons = append(explanation.Reasons, Reason{Kind: ReasonForced, Distance: distance})
+		}
+	}
+
+	if len(explanation.Reasons) == 0 {
+		kind := ReasonEdge
+		switch cellKind(move) {
+		case "center":
+			kind = ReasonCenter
+		case "corner":
+			kind = ReasonCorner
+		}
+		explanation.Reasons = []Reason{{Kind: kind}}
+	}
+	return explanation
+}
+
+// onlyNonLosingMove reports whether every move but move loses, and how soon
+// the quickest of those losses comes
+func onlyNonLosingMove(evaluations []MoveEvaluation, move game.Position) (bool, int) {
+	distance := 0
+	for _, evaluation := range evaluations {
+		if evaluation.Move == move {
+			continue
+		}
+		if evaluation.Outcome != OutcomeLoss {
+			return false, 0
+		}
+		if distance == 0 || evaluation.Distance < distance {
+			distance = evaluation.Distance
+		}
+	}
+	return distance > 0, distance
+}
+
+// lineOf names the line made of cells
+func lineOf(cells [3]game.Position) Line {
+	switch {
+	case cells[0].Row == cells[2].Row:
+		return Line{Kind: "row", Index: cells[0].Row}
+	case cells[0].Col == cells[2].Col:
+		return Line{Kind: "column", Index: cells[0].Col}
+	case cells[0].Col == 0:
+		return Line{Kind: "diagonal"}
+	default:
+		return Line{Kind: "anti-diagonal"}
+	}
+}
+
+// joinLines lists lines as "row 0", "row 0 and diagonal" or "row 0, column 1 and diagonal"
+func joinLines(lines []Line) string {
+	names := make([]string, len(lines))
+	for i, line := range lines {
+		names[i] = line.String()
+	}
+	if len(names) <= 1 {
+		return strings.Join(names, "")
+	}
+	return strings.Join(names[:len(names)-1], ", ") + " and " + names[len(names)-1]
+}
@@ -0,0 +1,164 @@
+package ai
+
+import (
+	"math"
+
+	"tic-tac-toe/internal/game"
+)
+
+// The named levels' strengths come from Calibrate(CalibrationGames,
+// CalibrationSeed), which the ladder command's --calibrate flag reruns.
+// calibrationStep is how finely it measures the strength scale.
+const (
+	CalibrationGames = 400
+	CalibrationSeed  = 1
+	calibrationStep  = 0.05
+)
+
+// Rung is one level of the strength ladder with its measured results. Scores
+// count 1 per win and 1/2 per draw, averaged over games played as both X and O.
+type Rung struct {
+	Difficulty Difficulty `json:"-"`
+	Name       string     `json:"name"`
+	Strength   float64    `json:"strength"`
+	VsRandom   float64    `json:"vs_random"`  // Score against a player that moves at random
+	VsPerfect  float64    `json:"vs_perfect"` // Score against perfect play; 0.5 means it never lost
+}
+
+// Rating combines both scores into one number on which the ladder must be monotonic
+func (r Rung) Rating() float64 {
+	return (r.VsRandom + r.VsPerfect) / 2
+}
+
+// MeasureLadder plays every named level against a random mover and against
+// perfect play, games times each, and returns the rungs weakest first
+func MeasureLadder(games int, seed int64) []Rung {
+	rungs := make([]Rung, len(Difficulties))
+	for i, difficulty := range Difficulties {
+		player := New(difficulty, game.PlayerX)
+		rungs[i] = measure(player, games, seed+int64(i))
+	}
+	return rungs
+}
+
+// Calibrate finds the strengths of the named levels from a run of the ladder.
+// It measures strengths from 0 to 1 in steps of calibrationStep, games times
+// each, and gives each level below perfect the weakest strength whose rating
+// reaches its even share of the way from strength 0's rating to perfect
+// play's. The rungs it returns are measured at those strengths.
+func Calibrate(games int, seed int64) []Rung {
+	steps := int(math.Round(1 / calibrationStep))
+	measured := make([]Rung, steps+1)
+	for i := range measured {
+		player := New(Easy, game.PlayerX)
+		player.SetStrength(float64(i) / float64(steps))
+		measured[i] = measure(player, games, seed)
+	}
+
+	low, high := measured[0].Rating(), measured[steps].Rating()
+	rungs := make([]Rung, len(Difficulties))
+	step := -1
+	for i, difficulty := range Difficulties {
+		if difficulty == INeverLose {
+			step = steps
+		} else {
+			target := low + (high-low)*float64(i+1)/float64(len(Difficulties))
+			step++
+			for step < steps-1 && measured[step].Rating() < target {
+				step++
+			}
+		}
+		rungs[i] = measured[step]
+		rungs[i].Difficulty = difficulty
+		rungs[i].Name = New(difficulty, game.PlayerX).GetDifficultyName()
+	}
+	return rungs
+}
+
+// measure plays player against a random mover and against perfect play,
+// games times each, with every AI's choices seeded from seed
+func measure(player *AI, games int, seed int64) Rung {
+	player.SetSeed(seed)
+	rung := Rung{
+		Difficulty: player.difficulty,
+		Name:       player.GetDifficultyName(),
+		Strength:   player.params.Strength,
+	}
+
+	random := New(Easy, game.PlayerO)
+	random.SetSeed(seed + 100)
+	random.params = ParamsForStrength(0)
+	random.params.Blunder = 1
+	rung.VsRandom = MatchScore(player, random, games)
+
+	perfect := New(INeverLose, game.PlayerO)
+	perfect.SetSeed(seed + 200)
+	rung.VsPerfect = MatchScore(player, perfect, games)
+	return rung
+}
+
+// MatchScore plays games between a and b, alternating who moves first, and
+// returns a's score per game
+func MatchScore(a, b *AI, games int) float64 {
+	if games <= 0 {
+		return 0
+	}
+
+	score := 0.0
+	for i := 0; i < games; i++ {
+		side := game.PlayerX
+		var winner game.Player
+		if i%2 == 0 {
+			winner = PlayGame(a, b)
+		} else {
+			side = game.PlayerO
+			winner = PlayGame(b, a)
+		}
+
+		switch winner {
+		case game.Empty:
+			score += 0.5
+		case side:
+			score++
+		}
+	}
+	return score / float64(games)
+}
+
+// PlayGame plays one game with x moving first and returns the winner, or
+// Empty for a draw. Both AIs play the side they are given for the game only.
+func PlayGame(x, o *AI) game.Player {
+	xSide, oSide := x.player, o.player
+	x.player, x.opponent = game.PlayerX, game.PlayerO
+	o.player, o.opponent = game.PlayerO, game.PlayerX
+	defer func() {
+		x.player, x.opponent = xSide, otherSide(xSide)
+		o.player, o.opponent = oSide, otherSide(oSide)
+	}()
+
+	g := game.New()
+	g.SetMode(game.PlayerVsAI)
+	for g.GetStatus() == game.StatusPlaying {
+		mover := x
+		if g.GetCurrentPlayer() == game.PlayerO {
+			mover = o
+		}
+		row, col, err := mover.GetMove(g)
+		if err != nil || g.MakeMove(row, col) != nil {
+			break
+		}
+	}
+
+	if g.GetStatus() != game.StatusWon {
+		return game.Empty
+	}
+	return g.GetWinner()
+}
+
+// otherSide returns the opponent of player
+func otherSide(player game.Player) game.Player {
+	if player == game.PlayerX {
+		return game.PlayerO
+	}
+	return game.PlayerX
+}
@@ -0,0 +1,168 @@
+package ai
+
+import (
+	"fmt"
+	"strings"
+
+	"tic-tac-toe/internal/game"
+	"tic-tac-toe/internal/solve"
+)
+
+// Personality gives the AI a playing style through extra evaluation terms.
+// The terms only choose between moves of equal value for a perfect AI, so a
+// style never costs it a game; weaker AIs add them to their move scores.
+type Personality struct {
+	ID      string
+	Name    string
+	Taunt   string
+	Threats float64 // Per line the move leaves one mark short of a win
+	Forks   float64 // When the move makes two or more threats at once
+	Blocks  float64 // Per opponent line the move cuts off
+	Traps   float64 // Share of the opponent's replies that would lose
+	Mirror  float64 // When the move mirrors the opponent's last move through the center
+}
+
+// styleScale converts evaluation terms into move-score points for weaker AIs,
+// and maxStyle caps a move's style so that, at 30 points, it stays well under
+// the 100 points between outcomes
+const (
+	styleScale = 15
+	maxStyle   = 2
+)
+
+// Personalities lists the playing styles in the order settings cycles through them
+var Personalities = []Personality{
+	{
+		ID:    "balanced",
+		Name:  "Ada",
+		Taunt: "Let's have a good game.",
+	},
+	{
+		ID:      "aggressive",
+		Name:    "Blaze",
+		Taunt:   "Two threats, one move. Pick your poison.",
+		Threats: 1,
+		Forks:   3,
+	},
+	{
+		ID:     "defensive",
+		Name:   "Bastion",
+		Taunt:  "Every line you start, I close.",
+		Blocks: 1,
+	},
+	{
+		ID:    "trickster",
+		Name:  "Loki",
+		Taunt: "That square looks safe. Doesn't it?",
+		Traps: 3,
+	},
+	{
+		ID:     "mirror",
+		Name:   "Echo",
+		Taunt:  "Whatever you do, I do too.",
+		Mirror: 2,
+	},
+}
+
+// PersonalityByID looks up one of the Personalities
+func PersonalityByID(id string) (Personality, error) {
+	for _, p := range Personalities {
+		if p.ID == strings.ToLower(id) {
+			return p, nil
+		}
+	}
+
+	ids := make([]string, len(Personalities))
+	for i, p := range Personalities {
+		ids[i] = p.ID
+	}
+	return Personality{}, fmt.Errorf("unknown personality %q (want %s)", id, strings.Join(ids, ", "))
+}
+
+// Evaluate scores how well move fits the personality for the player to move
+func (p Personality) Evaluate(g *game.Game, move game.Position) float64 {
+	player := g.GetCurrentPlayer()
+	opponent := otherSide(player)
+	board := g.GetBoard()
+	score := 0.0
+
+	if p.Blocks != 0 {
+		// Lines through the move the opponent has started and we have not
+		blocked := 0
+		for _, line := range linesThrough(move) {
+			if count(board, line, opponent) > 0 && count(board, line, player) == 0 {
+				blocked++
+			}
+		}
+		score += p.Blocks * float64(blocked)
+	}
+
+	// Where completing a line loses, a line one short is no threat
+	if (p.Threats != 0 || p.Forks != 0) && !g.GetRules().LinesLose() {
+		board[move.Row][move.Col] = player
+		threats := 0
+		for _, line := range linesThrough(move) {
+			if count(board, line, player) == 2 && count(board, line, game.Empty) == 1 {
+				threats++
+			}
+		}
+		board[move.Row][move.Col] = game.Empty
+
+		score += p.Threats * float64(threats)
+		if threats >= 2 {
+			score += p.Forks
+		}
+	}
+
+	if p.Traps != 0 && g.IsStandard() { // Only the classic tablebase knows which replies lose
+		child := cloneGame(g)
+		child.MakeMove(move.Row, move.Col)
+		if replies, err := solve.Classic().Moves(solve.FromGame(child)); err == nil && len(replies) > 0 {
+			losing := 0
+			for _, reply := range replies {
+				if reply.Result.Outcome == solve.Loss {
+					losing++
+				}
+			}
+			score += p.Traps * float64(losing) / float64(len(replies))
+		}
+	}
+
+	if p.Mirror != 0 {
+		history := g.GetMoveHistory()
+		if len(history) > 0 {
+			last := history[len(history)-1]
+			if move == (game.Position{Row: 2 - last.Row, Col: 2 - last.Col}) {
+				score += p.Mirror
+			}
+		}
+	}
+
+	return score
+}
+
+// linesThrough returns the rows, columns and diagonals that contain p
+func linesThrough(p game.Position) [][3]game.Position {
+	lines := [][3]game.Position{
+		{{Row: p.Row, Col: 0}, {Row: p.Row, Col: 1}, {Row: p.Row, Col: 2}},
+		{{Row: 0, Col: p.Col}, {Row: 1, Col: p.Col}, {Row: 2, Col: p.Col}},
+	}
+	if p.Row == p.Col {
+		lines = append(lines, [3]game.Position{{Row: 0, Col: 0}, {Row: 1, Col: 1}, {Row: 2, Col: 2}})
+	}
+	if p.Row+p.Col == 2 {
+		lines = append(lines, [3]game.Position{{Row: 0, Col: 2}, {Row: 1, Col: 1}, {Row: 2, Col: 0}})
+	}
+	return lines
+}
+
+// count returns how many cells of line hold player
+func count(board [3][3]game.Player, line [3]game.Position, player game.Player) int {
+	n := 0
+	for _, p := range line {
+		if board[p.Row][p.Col] == player {
+			n++
+		}
+	}
+	return n
+}
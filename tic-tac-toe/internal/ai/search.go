@@ -0,0 +1,241 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"tic-tac-toe/internal/game"
+)
+
+// errNoMoves is returned when there is nothing to search
+var errNoMoves = errors.New("no moves available")
+
+// Search finds the best move for the player to move by minimax to maxDepth.
+// Root moves are split across the AI's workers; ties go to the earliest move
+// in board order, so the result does not depend on the worker count or on
+// scheduling. A context that can be cancelled makes the search deepen one
+// ply at a time and return the deepest completed result, or the context's
+// error if not even the first ply finished.
+func (ai *AI) Search(ctx context.Context, g *game.Game, maxDepth int) (game.Position, error) {
+	move, err := ai.SearchMove(ctx, g, maxDepth)
+	return game.Position{Row: move.Row, Col: move.Col}, err
+}
+
+// SearchMove is Search for any rules: it also chooses the mark to place,
+// preferring the player's own mark between equally good moves
+func (ai *AI) SearchMove(ctx context.Context, g *game.Game, maxDepth int) (game.Move, error) {
+	moves, scores, _, err := ai.scoreMoves(ctx, g, maxDepth)
+	if err != nil {
+		return game.Move{Row: -1, Col: -1}, err
+	}
+	best := 0
+	for i, score := range scores {
+		if score > scores[best] {
+			best = i
+		}
+	}
+	return moves[best], nil
+}
+
+// scoreMoves scores every legal move by minimax, in the order of LegalMoves,
+// and returns the depth the scores were searched to: maxDepth, or with a
+// context that can be cancelled the deepest ply that finished
+func (ai *AI) scoreMoves(ctx context.Context, g *game.Game, maxDepth int) ([]game.Move, []int, int, error) {
+	moves := g.LegalMoves()
+	if len(moves) == 0 {
+		return nil, nil, 0, errNoMoves
+	}
+
+	s := &search{ctx: ctx, player: g.GetCurrentPlayer(), workers: ai.workers, memo: map[memoKey]int{}}
+	if ctx.Done() == nil {
+		scores, err := s.root(g, moves, maxDepth)
+		return moves, scores, maxDepth, err
+	}
+
+	var bestScores []int
+	searched := 0
+	for depth := 1; depth <= maxDepth; depth++ {
+		scores, err := s.root(g, moves, depth)
+		if err != nil {
+			if depth == 1 {
+				return nil, nil, 0, err
+			}
+			break
+		}
+		bestScores, searched = scores, depth
+	}
+	return moves, bestScores, searched, nil
+}
+
+// search holds the state shared by the workers of one Search
+type search struct {
+	ctx     context.Context
+	player  game.Player // The side the scores favor
+	workers int
+
+	mu   sync.Mutex
+	memo map[memoKey]int // Scores of positions already searched, shared by the workers
+}
+
+// memoKey identifies a position and the depth left to search it. Variants
+// that let players choose marks reach the same board by many move orders.
+// Once marks move, positions that have occurred before may end the game in a
+// draw by repetition, so how often they have occurred is part of the key.
+// Notakto on several boards is keyed by its boards instead.
+type memoKey struct {
+	board       [3][3]game.Player
+	boards      string
+	player      game.Player
+	depth       int
+	repetitions string
+}
+
+// symmetries map each cell, numbered row by row, to its image under each of
+// the board's rotations and reflections
+var symmetries = func() [8][9]int {
+	var maps [8][9]int
+	for i := range maps {
+		for cell := range maps[i] {
+			row, col := cell/3, cell%3
+			if i >= 4 {
+				col = 2 - col
+			}
+			for turn := 0; turn < i%4; turn++ {
+				row, col = col, 2-row
+			}
+			maps[i][cell] = row*3 + col
+		}
+	}
+	return maps
+}()
+
+// boardsKey identifies a Notakto position on several boards. Boards out of
+// play no longer matter, and neither do the order and symmetries of the
+// rest, since every mark is the same.
+func boardsKey(g *game.Game) string {
+	var live []int
+	for i, board := range g.Boards() {
+		if g.BoardDead(i) {
+			continue
+		}
+		best := -1
+		for _, symmetry := range symmetries {
+			mask := 0
+			for cell, image := range symmetry {
+				if board[cell/3][cell%3] != game.Empty {
+					mask |= 1 << image
+				}
+			}
+			if best < 0 || mask < best {
+				best = mask
+			}
+		}
+		live = append(live, best)
+	}
+	sort.Ints(live)
+	return fmt.Sprint(live)
+}
+
+// root scores every root move to depth on the worker pool
+func (s *search) root(g *game.Game, moves []game.Move, depth int) ([]int, error) {
+	scores := make([]int, len(moves))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < min(max(s.workers, 1), len(moves)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				child := cloneGame(g)
+				child.Play(moves[i])
+				scores[i] = s.minimax(child, depth, false)
+			}
+		}()
+	}
+	for i := range moves {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	if err := s.ctx.Err(); err != nil {
+		return nil, err
+	}
+	return scores, nil
+}
+
+// minimax scores a position for s.player; it returns early once the search is cancelled
+func (s *search) minimax(g *game.Game, depth int, isMaximizing bool) int {
+	select {
+	case <-s.ctx.Done():
+		return 0
+	default:
+	}
+
+	// Terminal conditions
+	switch g.GetStatus() {
+	case game.StatusWon:
+		if g.GetWinner() == s.player {
+			return 10 + depth // Prefer quicker wins
+		}
+		return -10 - depth // Prefer delayed losses
+	case game.StatusDraw:
+		return 0
+	}
+	if depth == 0 {
+		return 0 // Neutral when depth limit reached
+	}
+
+	key := memoKey{player: g.GetCurrentPlayer(), depth: depth, repetitions: g.RepetitionKey(depth)}
+	if len(g.Extra) > 0 {
+		key.boards = boardsKey(g)
+	} else {
+		key.board = g.GetBoard()
+	}
+	s.mu.Lock()
+	score, ok := s.memo[key]
+	s.mu.Unlock()
+	if ok {
+		return score
+	}
+
+	bestScore := 1000
+	if isMaximizing {
+		bestScore = -1000
+	}
+	for _, move := range g.LegalMoves() {
+		child := cloneGame(g)
+		child.Play(move)
+		score := s.minimax(child, depth-1, !isMaximizing)
+		if isMaximizing {
+			bestScore = max(bestScore, score)
+		} else {
+			bestScore = min(bestScore, score)
+		}
+	}
+
+	// A cancelled search scores positions as neutral; don't remember those
+	if s.ctx.Err() == nil {
+		s.mu.Lock()
+		s.memo[key] = bestScore
+		s.mu.Unlock()
+	}
+	return bestScore
+}
+
+// outcomeScore puts a score from a search depth plies deep on the scale of
+// MoveEvaluation.Score: a win or a loss counts the plies to it, this move
+// included, and anything else is even
+func outcomeScore(score, depth int) int {
+	switch {
+	case score > 0:
+		return 100 - (depth - (score - 10) + 1)
+	case score < 0:
+		return (depth - (-score - 10) + 1) - 100
+	default:
+		return 0
+	}
+}
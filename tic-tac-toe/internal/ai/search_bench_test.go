@@ -0,0 +1,27 @@
+package ai_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"tic-tac-toe/internal/ai"
+	"tic-tac-toe/internal/game"
+)
+
+// BenchmarkSearch searches the whole game tree from the empty board with
+// different numbers of workers
+func BenchmarkSearch(b *testing.B) {
+	for _, workers := range []int{1, 2, 4, 8} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			player := ai.New(ai.INeverLose, game.PlayerX)
+			player.SetWorkers(workers)
+			g := game.New()
+			for i := 0; i < b.N; i++ {
+				if _, err := player.Search(context.Background(), g, 9); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+
+	"tic-tac-toe/internal/game"
+)
+
+// DecideSwap makes the swap decision due in g: the seat that must decide
+// takes the color with the better value, from the tablebase or else from a
+// search, keeping its side between equals. It always picks a side rather
+// than placing two more marks.
+func (ai *AI) DecideSwap(ctx context.Context, g *game.Game) (game.SwapChoice, error) {
+	seat, _, ok := g.SwapPending()
+	if !ok {
+		return game.KeepSides, fmt.Errorf("no swap decision is due")
+	}
+
+	score, err := ai.positionValue(ctx, g)
+	if err != nil {
+		return game.KeepSides, err
+	}
+	if g.Opening.ColorOf(seat) != g.GetCurrentPlayer() {
+		score = -score
+	}
+	if score < 0 {
+		return game.SwapSides, nil
+	}
+	return game.KeepSides, nil
+}
+
+// positionValue scores the position for the player to move: the best move's
+// perfect-play score when the tablebase knows the game, otherwise a search
+func (ai *AI) positionValue(ctx context.Context, g *game.Game) (int, error) {
+	if evaluations := Analyze(g); len(evaluations) > 0 {
+		return evaluations[0].Score, nil
+	}
+
+	depth := 10
+	if g.GetRules().Pieces() > 0 {
+		depth = movingDepth
+	}
+	s := &search{ctx: ctx, player: g.GetCurrentPlayer(), workers: ai.workers, memo: map[memoKey]int{}}
+	score := s.minimax(cloneGame(g), depth, true)
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	return score, nil
+}
+
+// balancedMove picks one of the moves whose value is closest to even, so
+// that neither side is worth taking over
+func (ai *AI) balancedMove(moves []scoredMove) game.Move {
+	var best []game.Move
+	bestScore := -1
+	for _, move := range moves {
+		score := max(move.score, -move.score)
+		switch {
+		case bestScore < 0 || score < bestScore:
+			best, bestScore = []game.Move{move.move}, score
+		case score == bestScore:
+			best = append(best, move.move)
+		}
+	}
+	return best[ai.randomSource.Intn(len(best))]
+}
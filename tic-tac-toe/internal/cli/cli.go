@@ -0,0 +1,218 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+
+	"tic-tac-toe/internal/persistence"
+)
+
+// Exit codes returned by Run
+const (
+	ExitOK    = 0
+	ExitError = 1 // The command ran but failed
+	ExitUsage = 2 // The command line was invalid
+)
+
+// programName is how the binary refers to itself in help text
+const programName = "tic-tac-toe"
+
+// usageError marks errors caused by a bad command line rather than a failure
+type usageError struct {
+	message string
+}
+
+func (e *usageError) Error() string {
+	return e.message
+}
+
+// usagef builds a usageError
+func usagef(format string, args ...interface{}) error {
+	return &usageError{message: fmt.Sprintf(format, args...)}
+}
+
+// env is what every subcommand runs against
+type env struct {
+	stdin   io.Reader
+	stdout  io.Writer
+	stderr  io.Writer
+	saveDir string
+	logger  *slog.Logger
+}
+
+// persistence opens the save directory chosen on the command line
+func (e *env) persistence() *persistence.Manager {
+	if e.saveDir != "" {
+		return persistence.NewWithDirectory(e.saveDir)
+	}
+	return persistence.New()
+}
+
+// command is one subcommand of the CLI
+type command struct {
+	name    string
+	args    string // Positional argument synopsis for the usage line
+	summary string
+	flags   func(fs *flag.FlagSet) func(e *env, args []string) error
+}
+
+// commands lists the subcommands in the order help shows them
+var commands = []command{
+	playCommand,
+	statsCommand,
+	exportCommand,
+	importCommand,
+	replayCommand,
+	analyzeCommand,
+	solveCommand,
+	ladderCommand,
+	resetDataCommand,
+	configCommand,
+}
+
+// Run parses args (without the program name), runs the chosen subcommand
+// and returns the process exit code. With no subcommand it starts the game.
+func Run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	global := flag.NewFlagSet(programName, flag.ContinueOnError)
+	global.SetOutput(stderr)
+	saveDir := global.String("save-dir", "", "directory for saved games, scores and settings (default ~/.tic-tac-toe)")
+	logLevel := global.String("log-level", "warn", "log verbosity: debug, info, warn or error")
+	global.Usage = func() { printUsage(stderr, global) }
+
+	if err := global.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return ExitOK
+		}
+		return ExitUsage
+	}
+
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(*logLevel)); err != nil {
+		fmt.Fprintf(stderr, "%s: invalid --log-level %q\n", programName, *logLevel)
+		return ExitUsage
+	}
+
+	e := &env{
+		stdin:   stdin,
+		stdout:  stdout,
+		stderr:  stderr,
+		saveDir: *saveDir,
+		logger:  slog.New(slog.NewTextHandler(stderr, &slog.HandlerOptions{Level: level})),
+	}
+
+	rest := global.Args()
+	if len(rest) == 0 {
+		rest = []string{playCommand.name}
+	}
+
+	name := rest[0]
+	if name == "help" {
+		return runHelp(stdout, stderr, global, rest[1:])
+	}
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd.execute(e, rest[1:])
+		}
+	}
+
+	fmt.Fprintf(stderr, "%s: unknown command %q\n\n", programName, name)
+	printUsage(stderr, global)
+	return ExitUsage
+}
+
+// execute parses the subcommand's flags and runs it
+func (c command) execute(e *env, args []string) int {
+	fs := flag.NewFlagSet(c.name, flag.ContinueOnError)
+	fs.SetOutput(e.stderr)
+	run := c.flags(fs)
+	fs.Usage = func() { c.printUsage(e.stderr, fs) }
+
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return ExitOK
+		}
+		return ExitUsage
+	}
+
+	e.logger.Debug("running command", "command", c.name, "args", positional, "save_dir", e.saveDir)
+	if err := run(e, positional); err != nil {
+		fmt.Fprintf(e.stderr, "%s %s: %v\n", programName, c.name, err)
+		var usage *usageError
+		if errors.As(err, &usage) {
+			fmt.Fprintf(e.stderr, "Run '%s help %s' for usage.\n", programName, c.name)
+			return ExitUsage
+		}
+		return ExitError
+	}
+	return ExitOK
+}
+
+// parseInterspersed parses flags that appear before, between or after positional arguments
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// printUsage shows the subcommand's synopsis, summary and flags
+func (c command) printUsage(w io.Writer, fs *flag.FlagSet) {
+	synopsis := strings.TrimSpace(fmt.Sprintf("%s %s [flags] %s", programName, c.name, c.args))
+	fmt.Fprintf(w, "Usage: %s\n\n%s\n", synopsis, c.summary)
+
+	hasFlags := false
+	fs.VisitAll(func(*flag.Flag) { hasFlags = true })
+	if hasFlags {
+		fmt.Fprintf(w, "\nFlags:\n")
+		fs.PrintDefaults()
+	}
+}
+
+// printUsage shows the top-level help
+func printUsage(w io.Writer, global *flag.FlagSet) {
+	fmt.Fprintf(w, "Usage: %s [global flags] <command> [flags] [args]\n\n", programName)
+	fmt.Fprintf(w, "Commands:\n")
+	for _, cmd := range commands {
+		firstLine := strings.SplitN(cmd.summary, "\n", 2)[0]
+		fmt.Fprintf(w, "  %-11s %s\n", cmd.name, firstLine)
+	}
+	fmt.Fprintf(w, "  %-11s %s\n", "help", "Show help for a command")
+	fmt.Fprintf(w, "\nWith no command the interactive game starts.\n\nGlobal flags:\n")
+	global.SetOutput(w)
+	global.PrintDefaults()
+	fmt.Fprintf(w, "\nExit codes: %d success, %d failure, %d invalid usage\n", ExitOK, ExitError, ExitUsage)
+}
+
+// runHelp prints help for the program or one command
+func runHelp(stdout, stderr io.Writer, global *flag.FlagSet, args []string) int {
+	if len(args) == 0 {
+		printUsage(stdout, global)
+		return ExitOK
+	}
+
+	for _, cmd := range commands {
+		if cmd.name == args[0] {
+			fs := flag.NewFlagSet(cmd.name, flag.ContinueOnError)
+			fs.SetOutput(stdout)
+			cmd.flags(fs)
+			cmd.printUsage(stdout, fs)
+			return ExitOK
+		}
+	}
+
+	fmt.Fprintf(stderr, "%s: unknown command %q\n", programName, args[0])
+	return ExitUsage
+}
@@ -0,0 +1,279 @@
+package cli_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"tic-tac-toe/internal/cli"
+	"tic-tac-toe/internal/persistence"
+)
+
+var _ = Describe("CLI", func() {
+	var (
+		saveDir string
+		stdin   *bytes.Buffer
+		stdout  *bytes.Buffer
+		stderr  *bytes.Buffer
+	)
+
+	BeforeEach(func() {
+		saveDir = GinkgoT().TempDir()
+		stdin = &bytes.Buffer{}
+		stdout = &bytes.Buffer{}
+		stderr = &bytes.Buffer{}
+	})
+
+	run := func(args ...string) int {
+		stdout.Reset()
+		stderr.Reset()
+		return cli.Run(append([]string{"--save-dir", saveDir}, args...), stdin, stdout, stderr)
+	}
+
+	Describe("Usage", func() {
+		It("should list the commands in help", func() {
+			Expect(run("help")).To(Equal(cli.ExitOK))
+			for _, name := range []string{"play", "stats", "export", "import", "replay", "analyze", "reset-data", "config"} {
+				Expect(stdout.String()).To(ContainSubstring(name))
+			}
+		})
+
+		It("should show a command's flags", func() {
+			Expect(run("help", "play")).To(Equal(cli.ExitOK))
+			Expect(stdout.String()).To(ContainSubstring("-difficulty"))
+			Expect(stdout.String()).To(ContainSubstring("-seed"))
+		})
+
+		It("should reject unknown commands", func() {
+			Expect(run("juggle")).To(Equal(cli.ExitUsage))
+			Expect(stderr.String()).To(ContainSubstring(`unknown command "juggle"`))
+		})
+
+		It("should reject unknown flags", func() {
+			Expect(run("stats", "--colour")).To(Equal(cli.ExitUsage))
+		})
+
+		It("should reject an invalid log level", func() {
+			Expect(cli.Run([]string{"--log-level", "loud", "stats"}, stdin, stdout, stderr)).To(Equal(cli.ExitUsage))
+		})
+
+		It("should validate play flags before starting the game", func() {
+			Expect(run("play", "--mode", "chess")).To(Equal(cli.ExitUsage))
+			Expect(run("play", "--variant", "qubic")).To(Equal(cli.ExitUsage))
+			Expect(run("play", "--difficulty", "impossible")).To(Equal(cli.ExitUsage))
+			Expect(run("play", "--as", "Z")).To(Equal(cli.ExitUsage))
+		})
+	})
+
+	Describe("play", func() {
+		It("should use the line interface when not attached to a terminal", func() {
+			stdin.WriteString("a1\na2\nb1\nb2\nc1\n")
+			Expect(run("play", "--mode", "pvp")).To(Equal(cli.ExitOK))
+			Expect(stdout.String()).To(HaveSuffix("RESULT winner=X moves=5\n"))
+		})
+
+		It("should play the chosen variant", func() {
+			stdin.WriteString("b2 o\nquit\n")
+			Expect(run("play", "--mode", "pvp", "--variant", "wild")).To(Equal(cli.ExitOK))
+			Expect(stdout.String()).To(ContainSubstring("MOVE player=X cell=b2 mark=O\n"))
+		})
+
+		It("should reject an unknown interface", func() {
+			Expect(run("play", "--ui", "web")).To(Equal(cli.ExitUsage))
+		})
+	})
+
+	Describe("analyze", func() {
+		It("should rate every move of a position", func() {
+			Expect(run("analyze", "XX./OO./...")).To(Equal(cli.ExitOK))
+			Expect(stdout.String()).To(ContainSubstring("To move: X"))
+			Expect(stdout.String()).To(ContainSubstring("Best: (0,2) win"))
+		})
+
+		It("should accept flags after the position", func() {
+			Expect(run("analyze", "XX./OO./...", "--json")).To(Equal(cli.ExitOK))
+			Expect(stdout.String()).To(ContainSubstring(`"outcome": "win"`))
+		})
+
+		It("should report finished games", func() {
+			Expect(run("analyze", "XXX/OO./...")).To(Equal(cli.ExitOK))
+			Expect(stdout.String()).To(ContainSubstring("Game over: X wins"))
+		})
+
+		It("should reject malformed positions", func() {
+			Expect(run("analyze", "XXXX/...")).To(Equal(cli.ExitUsage))
+			Expect(run("analyze")).To(Equal(cli.ExitUsage))
+		})
+	})
+
+	Describe("solve", func() {
+		It("should print the totals of a variant", func() {
+			Expect(run("solve", "generate")).To(Equal(cli.ExitOK))
+			Expect(stdout.String()).To(ContainSubstring("Reachable positions: 5478"))
+			Expect(stdout.String()).To(ContainSubstring("Canonical positions: 765"))
+		})
+
+		It("should save a tablebase and query it", func() {
+			table := filepath.Join(saveDir, "classic.tb")
+			Expect(run("solve", "generate", "--output", table)).To(Equal(cli.ExitOK))
+			Expect(run("solve", "query", "XX./OO./...", "--table", table)).To(Equal(cli.ExitOK))
+			Expect(stdout.String()).To(ContainSubstring("Value: win in 1"))
+			Expect(stdout.String()).To(ContainSubstring("(0,2) win in 1"))
+			Expect(run("solve", "stats", "--table", table, "--json")).To(Equal(cli.ExitOK))
+			Expect(stdout.String()).To(ContainSubstring(`"canonical": 765`))
+		})
+
+		It("should reject unreachable positions and unknown variants", func() {
+			Expect(run("solve", "query", "XXX/XXX/OOO")).To(Equal(cli.ExitError))
+			Expect(run("solve", "generate", "--variant", "9x9")).To(Equal(cli.ExitUsage))
+			Expect(run("solve", "stats")).To(Equal(cli.ExitUsage))
+		})
+	})
+
+	Describe("ladder", func() {
+		It("should measure every level", func() {
+			Expect(run("ladder", "--games", "20")).To(Equal(cli.ExitOK))
+			Expect(stdout.String()).To(ContainSubstring("Easy"))
+			Expect(stdout.String()).To(ContainSubstring("I Never Lose"))
+			Expect(run("ladder", "--games", "0")).To(Equal(cli.ExitUsage))
+		})
+
+		It("should calibrate the levels", func() {
+			Expect(run("ladder", "--calibrate", "--games", "20")).To(Equal(cli.ExitOK))
+			Expect(stdout.String()).To(ContainSubstring("I Never Lose"))
+			Expect(stdout.String()).To(ContainSubstring("1.00"))
+		})
+	})
+
+	Describe("config", func() {
+		It("should set and get settings", func() {
+			Expect(run("config", "set", "difficulty", "hard")).To(Equal(cli.ExitOK))
+			Expect(run("config", "set", "time-control", "blitz")).To(Equal(cli.ExitOK))
+
+			Expect(run("config", "get", "difficulty")).To(Equal(cli.ExitOK))
+			Expect(stdout.String()).To(Equal("hard\n"))
+
+			Expect(run("config", "get")).To(Equal(cli.ExitOK))
+			Expect(stdout.String()).To(ContainSubstring("time-control=blitz\n"))
+			Expect(stdout.String()).To(ContainSubstring("ai-strength=0.6\n"))
+
+			Expect(run("config", "set", "ai-strength", "0.4")).To(Equal(cli.ExitOK))
+			Expect(run("config", "get", "ai-strength")).To(Equal(cli.ExitOK))
+			Expect(stdout.String()).To(Equal("0.4\n"))
+			Expect(run("config", "set", "ai-strength", "2")).To(Equal(cli.ExitUsage))
+
+			Expect(run("config", "set", "personality", "mirror")).To(Equal(cli.ExitOK))
+			Expect(run("config", "get", "personality")).To(Equal(cli.ExitOK))
+			Expect(stdout.String()).To(Equal("mirror\n"))
+			Expect(run("config", "set", "personality", "reckless")).To(Equal(cli.ExitUsage))
+		})
+
+		It("should set and get the game settings", func() {
+			for key, value := range map[string]string{
+				"variant":      "misere",
+				"setup":        "X#./.../..#",
+				"swap":         "pie",
+				"match-format": "bo3",
+				"multi-search": "paranoid",
+			} {
+				Expect(run("config", "set", key, value)).To(Equal(cli.ExitOK), key)
+				Expect(run("config", "get", key)).To(Equal(cli.ExitOK), key)
+				Expect(stdout.String()).To(Equal(value+"\n"), key)
+			}
+			Expect(run("config", "set", "variant", "chess")).To(Equal(cli.ExitUsage))
+			Expect(run("config", "set", "swap", "always")).To(Equal(cli.ExitUsage))
+		})
+
+		It("should reject unknown keys and values", func() {
+			Expect(run("config", "get", "volume")).To(Equal(cli.ExitUsage))
+			Expect(run("config", "set", "gradient", "plaid")).To(Equal(cli.ExitUsage))
+			Expect(run("config", "frobnicate")).To(Equal(cli.ExitUsage))
+		})
+	})
+
+	Describe("archive commands", func() {
+		var exportFile string
+
+		BeforeEach(func() {
+			exportFile = filepath.Join(GinkgoT().TempDir(), "games.json")
+			manager := persistence.NewWithDirectory(saveDir)
+			Expect(manager.ArchiveGame(persistence.ArchivedGame{
+				ID:      "g1",
+				PlayerX: "alice",
+				PlayerO: "bob",
+				Winner:  "X",
+				Moves:   []persistence.Position{{Row: 0, Col: 0}, {Row: 1, Col: 0}, {Row: 0, Col: 1}, {Row: 1, Col: 1}, {Row: 0, Col: 2}},
+			})).To(Succeed())
+		})
+
+		It("should export and re-import games without duplicates", func() {
+			Expect(run("export", "--output", exportFile)).To(Equal(cli.ExitOK))
+
+			otherDir := GinkgoT().TempDir()
+			Expect(cli.Run([]string{"--save-dir", otherDir, "import", exportFile}, stdin, stdout, stderr)).To(Equal(cli.ExitOK))
+			Expect(stdout.String()).To(ContainSubstring("Imported 1 games"))
+
+			archive, err := persistence.NewWithDirectory(otherDir).LoadArchive()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(archive).To(HaveLen(1))
+
+			Expect(run("import", exportFile)).To(Equal(cli.ExitOK))
+			Expect(stdout.String()).To(ContainSubstring("Imported 0 games (1 already archived)"))
+		})
+
+		It("should import from standard input and reject illegal games", func() {
+			stdin.WriteString(`{"id": "bad", "winner": "X", "moves": [{"Row": 0, "Col": 0}, {"Row": 0, "Col": 0}]}`)
+			Expect(run("import", "-")).To(Equal(cli.ExitError))
+			Expect(stderr.String()).To(ContainSubstring("already occupied"))
+		})
+
+		It("should replay a game move by move", func() {
+			Expect(run("replay", "g1")).To(Equal(cli.ExitOK))
+			Expect(strings.Count(stdout.String(), "Move ")).To(Equal(5))
+			Expect(stdout.String()).To(ContainSubstring("Result: X wins"))
+			Expect(stdout.String()).To(ContainSubstring("Move 5: X (0,2) - wins on row 0"))
+		})
+
+		It("should replay without explanations when asked", func() {
+			Expect(run("replay", "--explain=false", "g1")).To(Equal(cli.ExitOK))
+			Expect(stdout.String()).To(ContainSubstring("Move 5: X (0,2)\n"))
+		})
+
+		It("should list archived games without an ID", func() {
+			Expect(run("replay")).To(Equal(cli.ExitOK))
+			Expect(stdout.String()).To(ContainSubstring("g1"))
+			Expect(stdout.String()).To(ContainSubstring("alice vs bob"))
+		})
+
+		It("should fail for unknown games", func() {
+			Expect(run("replay", "nope")).To(Equal(cli.ExitError))
+			Expect(run("export", "--id", "nope")).To(Equal(cli.ExitError))
+		})
+
+		It("should count archived games in stats", func() {
+			Expect(run("stats", "--json")).To(Equal(cli.ExitOK))
+			Expect(stdout.String()).To(ContainSubstring(`"archived_games": 1`))
+		})
+	})
+
+	Describe("reset-data", func() {
+		It("should require confirmation", func() {
+			Expect(run("reset-data")).To(Equal(cli.ExitUsage))
+			_, err := os.Stat(filepath.Join(saveDir, "archive.json"))
+			Expect(os.IsNotExist(err)).To(BeTrue())
+		})
+
+		It("should clear the archive when confirmed", func() {
+			manager := persistence.NewWithDirectory(saveDir)
+			Expect(manager.ArchiveGame(persistence.ArchivedGame{ID: "g1"})).To(Succeed())
+
+			Expect(run("reset-data", "--yes")).To(Equal(cli.ExitOK))
+			archive, _ := manager.LoadArchive()
+			Expect(archive).To(BeEmpty())
+		})
+	})
+})
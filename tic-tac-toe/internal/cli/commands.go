@@ -0,0 +1,934 @@
+package cli
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"tic-tac-toe/internal/ai"
+	"tic-tac-toe/internal/config"
+	"tic-tac-toe/internal/game"
+	"tic-tac-toe/internal/gradient"
+	"tic-tac-toe/internal/lineui"
+	"tic-tac-toe/internal/match"
+	"tic-tac-toe/internal/multi"
+	"tic-tac-toe/internal/persistence"
+	"tic-tac-toe/internal/solve"
+	"tic-tac-toe/internal/ui"
+)
+
+var playCommand = command{
+	name: "play",
+	summary: "Start the interactive game, optionally straight into a match\n\n" +
+		"The line interface reads moves such as b2 from standard input and prints plain-text\n" +
+		"STATUS, MOVE, HINT, UNDO, ERROR and RESULT lines; it has no menu, so menu mode plays pvp.",
+	flags: func(fs *flag.FlagSet) func(e *env, args []string) error {
+		mode := fs.String("mode", "menu", "game to start: menu, pvp or ai")
+		frontEnd := fs.String("ui", "auto", "interface: tui, line, or auto (line when not attached to a terminal)")
+		difficulty := fs.String("difficulty", "", "AI difficulty: easy, normal, hard or perfect (default from settings)")
+		strength := fs.Float64("strength", -1, "AI strength from 0 to 1, overriding the difficulty's (default from settings)")
+		personality := fs.String("personality", "", "AI playing style: "+personalityIDs()+" (default from settings)")
+		variant := fs.String("variant", "", "rule variant: "+variantNames()+" (default from settings)")
+		as := fs.String("as", "X", "side you play against the AI: X or O")
+		seed := fs.Int64("seed", 0, "seed for the AI's random choices (0 picks one)")
+
+		return func(e *env, args []string) error {
+			if len(args) > 0 {
+				return usagef("unexpected argument %q", args[0])
+			}
+
+			opts := ui.Options{SaveDir: e.saveDir, Seed: *seed}
+			switch *mode {
+			case "menu":
+			case "pvp":
+				opts.StartGame, opts.Mode = true, game.PlayerVsPlayer
+			case "ai":
+				opts.StartGame, opts.Mode = true, game.PlayerVsAI
+			default:
+				return usagef("unknown --mode %q (want menu, pvp or ai)", *mode)
+			}
+
+			if *variant != "" {
+				parsed, err := game.ParseVariant(*variant)
+				if err != nil {
+					return usagef("%v", err)
+				}
+				opts.Variant = &parsed
+			}
+
+			if *difficulty != "" {
+				level, err := ai.ParseDifficulty(*difficulty)
+				if err != nil {
+					return usagef("%v", err)
+				}
+				opts.Difficulty = &level
+			}
+			if *strength >= 0 {
+				if *strength > 1 {
+					return usagef("--strength must be between 0 and 1, got %g", *strength)
+				}
+				opts.Strength = strength
+			}
+			if *personality != "" {
+				if _, err := ai.PersonalityByID(*personality); err != nil {
+					return usagef("%v", err)
+				}
+				opts.Personality = *personality
+			}
+
+			switch strings.ToUpper(*as) {
+			case "X":
+				opts.HumanPlayer = game.PlayerX
+			case "O":
+				opts.HumanPlayer = game.PlayerO
+			default:
+				return usagef("unknown --as %q (want X or O)", *as)
+			}
+
+			line := false
+			switch *frontEnd {
+			case "tui":
+			case "line":
+				line = true
+			case "auto":
+				line = !isTerminal(e.stdin) || !isTerminal(e.stdout)
+			default:
+				return usagef("unknown --ui %q (want tui, line or auto)", *frontEnd)
+			}
+
+			e.logger.Info("starting game", "mode", *mode, "variant", *variant, "as", opts.HumanPlayer, "seed", *seed, "line", line)
+			if line {
+				return playLine(e, opts)
+			}
+			return ui.Start(opts)
+		}
+	},
+}
+
+var statsCommand = command{
+	name:    "stats",
+	summary: "Print win/loss statistics",
+	flags: func(fs *flag.FlagSet) func(e *env, args []string) error {
+		asJSON := fs.Bool("json", false, "print the statistics as JSON")
+
+		return func(e *env, args []string) error {
+			if len(args) > 0 {
+				return usagef("unexpected argument %q", args[0])
+			}
+
+			pm := e.persistence()
+			scores, err := pm.LoadScores()
+			if err != nil {
+				return err
+			}
+			archive, err := pm.LoadArchive()
+			if err != nil {
+				return err
+			}
+
+			if *asJSON {
+				return writeJSON(e.stdout, struct {
+					*persistence.Scores
+					ArchivedGames int `json:"archived_games"`
+				}{scores, len(archive)})
+			}
+
+			pvp := scores.PlayerVsPlayer
+			fmt.Fprintf(e.stdout, "Player vs Player: %d games (X %d, O %d, draws %d)\n", pvp.Games, pvp.XWins, pvp.OWins, pvp.Draws)
+			fmt.Fprintf(e.stdout, "Player vs AI:\n")
+			for _, row := range []struct {
+				name  string
+				stats persistence.DifficultyStats
+			}{
+				{"Easy", scores.PlayerVsAI.Easy},
+				{"Normal", scores.PlayerVsAI.Normal},
+				{"Hard", scores.PlayerVsAI.Hard},
+				{"I Never Lose", scores.PlayerVsAI.INeverLose},
+			} {
+				fmt.Fprintf(e.stdout, "  %-12s %d games (you %d, AI %d, draws %d)\n",
+					row.name, row.stats.Games, row.stats.PlayerWins, row.stats.AIWins, row.stats.Draws)
+			}
+			fmt.Fprintf(e.stdout, "Total games: %d\n", scores.TotalGames)
+			fmt.Fprintf(e.stdout, "Archived games: %d\n", len(archive))
+			return nil
+		}
+	},
+}
+
+var exportCommand = command{
+	name:    "export",
+	summary: "Write archived games as JSON",
+	flags: func(fs *flag.FlagSet) func(e *env, args []string) error {
+		output := fs.String("output", "-", "file to write, or - for standard output")
+		id := fs.String("id", "", "export only the game with this ID")
+
+		return func(e *env, args []string) error {
+			if len(args) > 0 {
+				return usagef("unexpected argument %q", args[0])
+			}
+
+			archive, err := e.persistence().LoadArchive()
+			if err != nil {
+				return err
+			}
+			if *id != "" {
+				record, ok := findArchived(archive, *id)
+				if !ok {
+					return fmt.Errorf("no archived game with ID %q", *id)
+				}
+				archive = []persistence.ArchivedGame{record}
+			}
+
+			if *output == "-" {
+				return writeJSON(e.stdout, archive)
+			}
+
+			file, err := os.Create(*output)
+			if err != nil {
+				return err
+			}
+			if err := writeJSON(file, archive); err != nil {
+				file.Close()
+				return err
+			}
+			if err := file.Close(); err != nil {
+				return err
+			}
+			e.logger.Info("exported games", "count", len(archive), "file", *output)
+			return nil
+		}
+	},
+}
+
+var importCommand = command{
+	name:    "import",
+	args:    "<file|->",
+	summary: "Add games from an export file to the archive\n\nEvery game is checked by replaying its moves; nothing is imported if any game is invalid.\nGames whose IDs are already archived are skipped.",
+	flags: func(fs *flag.FlagSet) func(e *env, args []string) error {
+		return func(e *env, args []string) error {
+			if len(args) != 1 {
+				return usagef("expected one file to import")
+			}
+
+			var data []byte
+			var err error
+			if args[0] == "-" {
+				data, err = io.ReadAll(e.stdin)
+			} else {
+				data, err = os.ReadFile(args[0])
+			}
+			if err != nil {
+				return err
+			}
+
+			records, err := decodeArchive(data)
+			if err != nil {
+				return err
+			}
+			for i, record := range records {
+				if record.ID == "" {
+					return fmt.Errorf("game %d has no ID", i+1)
+				}
+				if _, err := record.Replay(); err != nil {
+					return err
+				}
+			}
+
+			added, err := e.persistence().ImportArchive(records)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(e.stdout, "Imported %d games (%d already archived)\n", added, len(records)-added)
+			return nil
+		}
+	},
+}
+
+var replayCommand = command{
+	name:    "replay",
+	args:    "[id]",
+	summary: "Print an archived game move by move, or list archived games without an ID",
+	flags: func(fs *flag.FlagSet) func(e *env, args []string) error {
+		delay := fs.Duration("delay", 0, "pause between moves, e.g. 500ms")
+		explain := fs.Bool("explain", true, "say why each move was played")
+
+		return func(e *env, args []string) error {
+			if len(args) > 1 {
+				return usagef("expected at most one game ID")
+			}
+
+			archive, err := e.persistence().LoadArchive()
+			if err != nil {
+				return err
+			}
+
+			if len(args) == 0 {
+				for _, record := range archive {
+					fmt.Fprintf(e.stdout, "%s  %s  %s vs %s  %s\n", record.ID, record.PlayedAt.Format("2006-01-02 15:04"),
+						record.PlayerX, record.PlayerO, resultText(record.Winner))
+				}
+				return nil
+			}
+
+			record, ok := findArchived(archive, args[0])
+			if !ok {
+				return fmt.Errorf("no archived game with ID %q", args[0])
+			}
+
+			fmt.Fprintf(e.stdout, "Game %s: %s (X) vs %s (O), %s\n", record.ID, record.PlayerX, record.PlayerO,
+				record.PlayedAt.Format("2006-01-02 15:04"))
+			g := game.New()
+			g.SetRules(record.Rules())
+			if !g.GetRules().IsClassic() {
+				fmt.Fprintf(e.stdout, "Rules: %s\n", g.GetRules().Variant.Name())
+				if boards := g.GetRules().BoardCount(); boards > 1 {
+					fmt.Fprintf(e.stdout, "Boards: %d\n", boards)
+				}
+			}
+			if err := g.ApplySetup(record.Setup); err != nil {
+				return err
+			}
+			if record.Setup != nil {
+				fmt.Fprintf(e.stdout, "Setup: %s (%s)\n", record.Setup.Name, record.Setup.Position)
+			}
+			if record.Swap != "" {
+				fmt.Fprintf(e.stdout, "Swap rule: %s\n", game.SwapRule(record.Swap).Name())
+			}
+			if record.Match != nil {
+				fmt.Fprintf(e.stdout, "Match %s, game %d\n", record.Match.ID, record.Match.Game)
+			}
+			for i, move := range record.Moves {
+				player := g.GetCurrentPlayer()
+				why := ""
+				if move.Board > 0 {
+					why = fmt.Sprintf(" on board %d", move.Board+1)
+				}
+				if *explain && g.IsStandard() {
+					why += " - " + ai.Explain(g, game.Position{Row: move.Row, Col: move.Col}).String()
+				}
+				if err := move.Play(g); err != nil {
+					return fmt.Errorf("move %d: %w", i+1, err)
+				}
+				if i > 0 && *delay > 0 {
+					time.Sleep(*delay)
+				}
+				fmt.Fprintf(e.stdout, "\nMove %d: %s (%d,%d)%s\n%s", i+1, player, move.Row, move.Col, why, formatBoard(g))
+			}
+			fmt.Fprintf(e.stdout, "\nResult: %s\n", resultText(record.Winner))
+			return nil
+		}
+	},
+}
+
+var analyzeCommand = command{
+	name: "analyze",
+	args: "<position>",
+	summary: "Solve a position and rate every move with perfect play\n\n" +
+		"Positions are written row by row with '/' between rows and '.' for empty cells,\n" +
+		"e.g. \"XO./.X./..O\". The player to move is worked out from the counts.",
+	flags: func(fs *flag.FlagSet) func(e *env, args []string) error {
+		asJSON := fs.Bool("json", false, "print the analysis as JSON")
+
+		return func(e *env, args []string) error {
+			if len(args) != 1 {
+				return usagef("expected one position")
+			}
+
+			g, err := game.ParsePosition(args[0])
+			if err != nil {
+				return usagef("%v", err)
+			}
+			evaluations := ai.Analyze(g)
+
+			if *asJSON {
+				result := struct {
+					Position string              `json:"position"`
+					ToMove   game.Player         `json:"to_move,omitempty"`
+					Status   string              `json:"status"`
+					Winner   game.Player         `json:"winner,omitempty"`
+					Moves    []ai.MoveEvaluation `json:"moves"`
+				}{Position: g.Notation(), Status: statusText(g), Moves: evaluations}
+				if g.GetStatus() == game.StatusPlaying {
+					result.ToMove = g.GetCurrentPlayer()
+				}
+				if g.GetStatus() == game.StatusWon {
+					result.Winner = g.GetWinner()
+				}
+				return writeJSON(e.stdout, result)
+			}
+
+			fmt.Fprint(e.stdout, formatBoard(g))
+			if g.GetStatus() != game.StatusPlaying {
+				fmt.Fprintf(e.stdout, "Game over: %s\n", resultText(string(g.GetWinner())))
+				return nil
+			}
+
+			fmt.Fprintf(e.stdout, "To move: %s\n", g.GetCurrentPlayer())
+			fmt.Fprintf(e.stdout, "Best: (%d,%d) %s\n", evaluations[0].Move.Row, evaluations[0].Move.Col, evaluations[0].Outcome)
+			fmt.Fprintf(e.stdout, "Moves:\n")
+			for _, evaluation := range evaluations {
+				fmt.Fprintf(e.stdout, "  (%d,%d) %s\n", evaluation.Move.Row, evaluation.Move.Col, evaluation.Outcome)
+			}
+			return nil
+		}
+	},
+}
+
+var solveCommand = command{
+	name: "solve",
+	args: "generate | stats | query <position>",
+	summary: "Solve a board variant with perfect play and query the tablebase\n\n" +
+		"'generate' enumerates every reachable position and prints the totals; --output saves\n" +
+		"the tablebase. 'stats' prints the totals of a saved tablebase. 'query' prints the value\n" +
+		"of a position and each of its moves, e.g. \"XX./OO./...\".",
+	flags: func(fs *flag.FlagSet) func(e *env, args []string) error {
+		variantName := fs.String("variant", "classic", "board variant: "+solveVariantNames())
+		table := fs.String("table", "", "read a tablebase saved by 'solve generate --output'")
+		output := fs.String("output", "", "write the generated tablebase to this file")
+		asJSON := fs.Bool("json", false, "print the result as JSON")
+
+		return func(e *env, args []string) error {
+			if len(args) == 0 {
+				return usagef("expected generate, stats or query")
+			}
+
+			switch args[0] {
+			case "generate":
+				if len(args) != 1 {
+					return usagef("generate takes no arguments")
+				}
+				if *table != "" {
+					return usagef("--table cannot be used with generate")
+				}
+				variant, ok := solve.VariantByName(*variantName)
+				if !ok {
+					return usagef("unknown variant %q (want %s)", *variantName, solveVariantNames())
+				}
+
+				started := time.Now()
+				tb, err := solve.Generate(variant)
+				if err != nil {
+					return err
+				}
+				e.logger.Info("solved variant", "variant", variant.Name, "positions", tb.Len(), "elapsed", time.Since(started))
+				if *output != "" {
+					if err := tb.Save(*output); err != nil {
+						return err
+					}
+					e.logger.Info("saved tablebase", "path", *output)
+				}
+				return printSolveStats(e.stdout, tb.Stats, *asJSON)
+
+			case "stats":
+				if len(args) != 1 {
+					return usagef("stats takes no arguments")
+				}
+				if *table == "" {
+					return usagef("stats needs --table")
+				}
+				tb, err := solve.Load(*table)
+				if err != nil {
+					return err
+				}
+				return printSolveStats(e.stdout, tb.Stats, *asJSON)
+
+			case "query":
+				if len(args) != 2 {
+					return usagef("query takes one position")
+				}
+				tb, err := loadTablebase(e, *table, *variantName)
+				if err != nil {
+					return err
+				}
+				board, err := solve.ParseBoard(tb.Variant(), args[1])
+				if err != nil {
+					return usagef("%v", err)
+				}
+				value, err := tb.Lookup(board)
+				if err != nil {
+					return err
+				}
+				moves, err := tb.Moves(board)
+				if err != nil {
+					return err
+				}
+
+				toMove := "X"
+				if board.ToMove() == solve.O {
+					toMove = "O"
+				}
+				if *asJSON {
+					return writeJSON(e.stdout, struct {
+						Position string             `json:"position"`
+						Variant  string             `json:"variant"`
+						ToMove   string             `json:"to_move"`
+						Value    solve.Result       `json:"value"`
+						Moves    []solve.MoveResult `json:"moves"`
+					}{Position: board.String(), Variant: tb.Variant().Name, ToMove: toMove, Value: value, Moves: moves})
+				}
+
+				fmt.Fprintf(e.stdout, "Position: %s (%s)\n", board, tb.Variant().Name)
+				if len(moves) == 0 {
+					fmt.Fprintf(e.stdout, "Game over\n")
+					return nil
+				}
+				fmt.Fprintf(e.stdout, "To move: %s\n", toMove)
+				fmt.Fprintf(e.stdout, "Value: %s\n", resultSummary(value))
+				fmt.Fprintf(e.stdout, "Moves:\n")
+				for _, move := range moves {
+					fmt.Fprintf(e.stdout, "  (%d,%d) %s\n", move.Row, move.Col, resultSummary(move.Result))
+				}
+				return nil
+
+			default:
+				return usagef("unknown solve action %q (want generate, stats or query)", args[0])
+			}
+		}
+	},
+}
+
+var ladderCommand = command{
+	name: "ladder",
+	summary: "Measure the strength of each AI difficulty\n\n" +
+		"Every level plays a random mover and perfect play, alternating sides, and the scores\n" +
+		"(1 per win, 1/2 per draw) show that the levels are ordered by strength. With --calibrate\n" +
+		"it measures the whole strength scale and places the levels evenly on it instead.",
+	flags: func(fs *flag.FlagSet) func(e *env, args []string) error {
+		games := fs.Int("games", ai.CalibrationGames, "games per level against each opponent")
+		seed := fs.Int64("seed", ai.CalibrationSeed, "seed for the players' random choices")
+		calibrate := fs.Bool("calibrate", false, "find the strength of each level rather than measure it")
+		asJSON := fs.Bool("json", false, "print the ladder as JSON")
+
+		return func(e *env, args []string) error {
+			if len(args) > 0 {
+				return usagef("unexpected argument %q", args[0])
+			}
+			if *games < 1 {
+				return usagef("--games must be at least 1")
+			}
+
+			measure := ai.MeasureLadder
+			if *calibrate {
+				measure = ai.Calibrate
+			}
+			rungs := measure(*games, *seed)
+			if *asJSON {
+				return writeJSON(e.stdout, rungs)
+			}
+
+			fmt.Fprintf(e.stdout, "%-13s %8s %10s %11s %7s\n", "Level", "Strength", "vs random", "vs perfect", "Rating")
+			for _, rung := range rungs {
+				fmt.Fprintf(e.stdout, "%-13s %8.2f %10.3f %11.3f %7.3f\n", rung.Name, rung.Strength, rung.VsRandom, rung.VsPerfect, rung.Rating())
+			}
+			return nil
+		}
+	},
+}
+
+var resetDataCommand = command{
+	name:    "reset-data",
+	summary: "Delete the saved game, scores, settings and game archive\n\nCorrespondence games and your signing identity are kept.",
+	flags: func(fs *flag.FlagSet) func(e *env, args []string) error {
+		yes := fs.Bool("yes", false, "confirm deleting the data")
+
+		return func(e *env, args []string) error {
+			if len(args) > 0 {
+				return usagef("unexpected argument %q", args[0])
+			}
+			if !*yes {
+				return usagef("this deletes all saved data; pass --yes to confirm")
+			}
+
+			pm := e.persistence()
+			if err := pm.ClearAllData(); err != nil {
+				return err
+			}
+			fmt.Fprintf(e.stdout, "Cleared saved data in %s\n", pm.GetSaveDirectory())
+			return nil
+		}
+	},
+}
+
+// playLine runs a game in the plain-text line interface
+func playLine(e *env, opts ui.Options) error {
+	pm := e.persistence()
+	cfg := config.New(pm)
+	if err := cfg.Load(); err != nil {
+		return err
+	}
+
+	lineOpts := lineui.Options{
+		Mode:        opts.Mode,
+		Difficulty:  cfg.GetAIDifficulty(),
+		Strength:    opts.Strength,
+		Personality: cfg.GetAIPersonality(),
+		HumanPlayer: opts.HumanPlayer,
+		Seed:        opts.Seed,
+		Color:       isTerminal(e.stdout) && os.Getenv("NO_COLOR") == "" && os.Getenv("TERM") != "dumb",
+		Persistence: pm,
+		Rules:       cfg.GetRules(),
+	}
+	if opts.Variant != nil {
+		lineOpts.Rules = cfg.RulesFor(*opts.Variant)
+	}
+	if !opts.StartGame {
+		lineOpts.Mode = game.PlayerVsPlayer
+	}
+	if opts.Difficulty != nil {
+		lineOpts.Difficulty = *opts.Difficulty
+	} else if opts.Strength == nil {
+		strength := cfg.GetAIStrength()
+		lineOpts.Strength = &strength
+	}
+	if personality, err := ai.PersonalityByID(opts.Personality); err == nil {
+		lineOpts.Personality = personality
+	}
+	return lineui.Run(e.stdin, e.stdout, lineOpts)
+}
+
+// isTerminal reports whether a standard stream is an interactive terminal
+func isTerminal(stream interface{}) bool {
+	f, ok := stream.(*os.File)
+	return ok && lineui.IsTerminal(f)
+}
+
+// configKeys are the settings that config get/set understand, in display order
+var configKeys = []string{
+	"gradient", "difficulty", "ai-strength", "personality", "animation-speed", "time-control",
+	"variant", "notakto-boards", "setup", "swap", "match-format", "multi-search",
+}
+
+var configCommand = command{
+	name: "config",
+	args: "get [key] | set <key> <value>",
+	summary: "Read or change settings\n\n" +
+		"Keys: " + strings.Join(configKeys, ", ") + ". 'get' without a key prints every setting.",
+	flags: func(fs *flag.FlagSet) func(e *env, args []string) error {
+		return func(e *env, args []string) error {
+			if len(args) == 0 {
+				return usagef("expected get or set")
+			}
+
+			cfg := config.New(e.persistence())
+			if err := cfg.Load(); err != nil {
+				return err
+			}
+
+			switch args[0] {
+			case "get":
+				if len(args) > 2 {
+					return usagef("get takes at most one key")
+				}
+				if len(args) == 1 {
+					for _, key := range configKeys {
+						value, _ := configValue(cfg, key)
+						fmt.Fprintf(e.stdout, "%s=%s\n", key, value)
+					}
+					return nil
+				}
+				value, err := configValue(cfg, args[1])
+				if err != nil {
+					return usagef("%v", err)
+				}
+				fmt.Fprintln(e.stdout, value)
+				return nil
+
+			case "set":
+				if len(args) != 3 {
+					return usagef("set takes a key and a value")
+				}
+				if err := setConfigValue(cfg, args[1], args[2]); err != nil {
+					return err
+				}
+				e.logger.Info("updated setting", "key", args[1], "value", args[2])
+				return nil
+
+			default:
+				return usagef("unknown config action %q (want get or set)", args[0])
+			}
+		}
+	},
+}
+
+// configValue reads one setting as the text config set accepts
+func configValue(cfg *config.Config, key string) (string, error) {
+	switch key {
+	case "gradient":
+		return strings.ToLower(cfg.GetGradientTypeName()), nil
+	case "difficulty":
+		return difficultyName(cfg.GetAIDifficulty()), nil
+	case "ai-strength":
+		return strconv.FormatFloat(cfg.GetAIStrength(), 'f', -1, 64), nil
+	case "personality":
+		return cfg.GetAIPersonality().ID, nil
+	case "animation-speed":
+		return strconv.FormatFloat(cfg.GetAnimationSpeed(), 'f', -1, 64), nil
+	case "time-control":
+		return cfg.GetTimeControl().Name, nil
+	case "variant":
+		return string(cfg.GetVariant()), nil
+	case "notakto-boards":
+		return strconv.Itoa(cfg.GetNotaktoBoards()), nil
+	case "setup":
+		if setup := cfg.GetSetup(); setup != nil {
+			return setup.Name, nil
+		}
+		return "standard", nil
+	case "swap":
+		if rule := cfg.GetSwapRule(); rule != game.NoSwap {
+			return string(rule), nil
+		}
+		return "off", nil
+	case "match-format":
+		return cfg.GetMatchFormat().Key(), nil
+	case "multi-search":
+		return string(cfg.GetMultiSearch()), nil
+	default:
+		return "", fmt.Errorf("unknown key %q (want %s)", key, strings.Join(configKeys, ", "))
+	}
+}
+
+// setConfigValue parses and saves one setting
+func setConfigValue(cfg *config.Config, key, value string) error {
+	switch key {
+	case "gradient":
+		gradientType, err := gradient.ParseType(value)
+		if err != nil {
+			return usagef("%v", err)
+		}
+		return cfg.SetGradientType(gradientType)
+	case "difficulty":
+		difficulty, err := ai.ParseDifficulty(value)
+		if err != nil {
+			return usagef("%v", err)
+		}
+		return cfg.SetAIDifficulty(difficulty)
+	case "ai-strength":
+		strength, err := strconv.ParseFloat(value, 64)
+		if err != nil || strength < 0 || strength > 1 {
+			return usagef("invalid AI strength %q (want a number from 0 to 1)", value)
+		}
+		return cfg.SetAIStrength(strength)
+	case "personality":
+		if _, err := ai.PersonalityByID(value); err != nil {
+			return usagef("%v", err)
+		}
+		return cfg.SetAIPersonality(value)
+	case "animation-speed":
+		speed, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return usagef("invalid animation speed %q", value)
+		}
+		return cfg.SetAnimationSpeed(speed)
+	case "time-control":
+		if _, ok := game.TimeControlByName(value); !ok {
+			return usagef("unknown time control %q", value)
+		}
+		return cfg.SetTimeControl(value)
+	case "variant":
+		if _, err := game.ParseVariant(value); err != nil {
+			return usagef("%v", err)
+		}
+		return cfg.SetVariant(value)
+	case "notakto-boards":
+		boards, err := strconv.Atoi(value)
+		if err != nil || boards < 1 || boards > game.MaxBoards {
+			return usagef("invalid number of Notakto boards %q (want 1 to %d)", value, game.MaxBoards)
+		}
+		return cfg.SetNotaktoBoards(boards)
+	case "setup":
+		if _, err := game.LookupSetup(value); err != nil {
+			return usagef("%v", err)
+		}
+		return cfg.SetSetup(value)
+	case "swap":
+		if _, err := game.ParseSwapRule(value); err != nil {
+			return usagef("%v", err)
+		}
+		return cfg.SetSwapRule(value)
+	case "match-format":
+		if _, err := match.ParseFormat(value); err != nil {
+			return usagef("%v", err)
+		}
+		return cfg.SetMatchFormat(value)
+	case "multi-search":
+		if _, err := multi.ParseStrategy(value); err != nil {
+			return usagef("%v", err)
+		}
+		return cfg.SetMultiSearch(value)
+	default:
+		return usagef("unknown key %q (want %s)", key, strings.Join(configKeys, ", "))
+	}
+}
+
+// variantNames lists the rule variants for help text
+func variantNames() string {
+	names := make([]string, len(game.Variants))
+	for i, v := range game.Variants {
+		names[i] = string(v)
+	}
+	return strings.Join(names, ", ")
+}
+
+// personalityIDs lists the AI personalities for help text
+func personalityIDs() string {
+	ids := make([]string, len(ai.Personalities))
+	for i, p := range ai.Personalities {
+		ids[i] = p.ID
+	}
+	return strings.Join(ids, ", ")
+}
+
+// difficultyName is the command-line name of a difficulty, as accepted by ai.ParseDifficulty
+func difficultyName(difficulty ai.Difficulty) string {
+	switch difficulty {
+	case ai.Normal:
+		return "normal"
+	case ai.Hard:
+		return "hard"
+	case ai.INeverLose:
+		return "perfect"
+	default:
+		return "easy"
+	}
+}
+
+// decodeArchive accepts either an exported array of games or a single game
+func decodeArchive(data []byte) ([]persistence.ArchivedGame, error) {
+	var records []persistence.ArchivedGame
+	if err := json.Unmarshal(data, &records); err == nil {
+		return records, nil
+	}
+
+	var record persistence.ArchivedGame
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, fmt.Errorf("not a game export: %w", err)
+	}
+	return []persistence.ArchivedGame{record}, nil
+}
+
+// findArchived looks up an archived game by ID
+func findArchived(archive []persistence.ArchivedGame, id string) (persistence.ArchivedGame, bool) {
+	for _, record := range archive {
+		if record.ID == id {
+			return record, true
+		}
+	}
+	return persistence.ArchivedGame{}, false
+}
+
+// formatBoard draws the board as plain text, or each board in turn in games
+// on several boards
+func formatBoard(g *game.Game) string {
+	boards := g.Boards()
+	if len(boards) == 1 {
+		return formatGrid(boards[0])
+	}
+	text := ""
+	for i, board := range boards {
+		text += fmt.Sprintf("Board %d\n%s", i+1, formatGrid(board))
+	}
+	return text
+}
+
+// formatGrid draws one board as plain text
+func formatGrid(board [3][3]game.Player) string {
+	rows := make([]string, 3)
+	for row := 0; row < 3; row++ {
+		cells := make([]string, 3)
+		for col := 0; col < 3; col++ {
+			cells[col] = "."
+			if board[row][col] != game.Empty {
+				cells[col] = string(board[row][col])
+			}
+		}
+		rows[row] = " " + strings.Join(cells, " | ") + "\n"
+	}
+	return strings.Join(rows, "---+---+---\n")
+}
+
+// resultText describes a recorded winner
+func resultText(winner string) string {
+	if strings.TrimSpace(winner) == "" {
+		return "draw"
+	}
+	return winner + " wins"
+}
+
+// statusText names a game's status for JSON output
+func statusText(g *game.Game) string {
+	switch g.GetStatus() {
+	case game.StatusWon:
+		return "won"
+	case game.StatusDraw:
+		return "draw"
+	default:
+		return "playing"
+	}
+}
+
+// solveVariantNames lists the variants the solver knows, for help and errors
+func solveVariantNames() string {
+	names := make([]string, len(solve.Variants))
+	for i, v := range solve.Variants {
+		names[i] = v.Name
+	}
+	return strings.Join(names, ", ")
+}
+
+// loadTablebase reads a saved tablebase, or solves the variant in memory
+func loadTablebase(e *env, path, variantName string) (*solve.Tablebase, error) {
+	if path != "" {
+		return solve.Load(path)
+	}
+	if variantName == "classic" {
+		return solve.Classic(), nil
+	}
+
+	variant, ok := solve.VariantByName(variantName)
+	if !ok {
+		return nil, usagef("unknown variant %q (want %s)", variantName, solveVariantNames())
+	}
+	e.logger.Warn("solving variant in memory; save it with 'solve generate --output' to skip this", "variant", variant.Name)
+	return solve.Generate(variant)
+}
+
+// printSolveStats prints the totals of a solved variant
+func printSolveStats(w io.Writer, stats solve.Stats, asJSON bool) error {
+	if asJSON {
+		return writeJSON(w, stats)
+	}
+
+	fmt.Fprintf(w, "Variant: %s (%dx%d, %d in a row)\n", stats.Variant.Name, stats.Variant.Size, stats.Variant.Size, stats.Variant.WinLength)
+	fmt.Fprintf(w, "Reachable positions: %d\n", stats.Reachable)
+	fmt.Fprintf(w, "Canonical positions: %d\n", stats.Canonical)
+	fmt.Fprintf(w, "Finished positions:  X wins %d, O wins %d, draws %d\n", stats.XWins, stats.OWins, stats.Draws)
+	fmt.Fprintf(w, "Canonical by value for the player to move: win %d, draw %d, loss %d\n",
+		stats.ByOutcome[solve.Win], stats.ByOutcome[solve.Draw], stats.ByOutcome[solve.Loss])
+	fmt.Fprintf(w, "Empty board: %s\n", resultSummary(stats.Start))
+	fmt.Fprintf(w, "First moves for X:\n")
+	for _, move := range stats.FirstMove {
+		fmt.Fprintf(w, "  (%d,%d) %s\n", move.Row, move.Col, resultSummary(move.Result))
+	}
+	return nil
+}
+
+// resultSummary describes a perfect-play value, e.g. "win in 3"
+func resultSummary(result solve.Result) string {
+	if result.Outcome == solve.Draw {
+		return "draw"
+	}
+	return fmt.Sprintf("%s in %d", result.Outcome, result.Distance)
+}
+
+// writeJSON writes v as indented JSON
+func writeJSON(w io.Writer, v interface{}) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
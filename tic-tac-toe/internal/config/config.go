@@ -4,7 +4,11 @@ import (
 	"fmt"
 
 	"tic-tac-toe/internal/ai"
+	"tic-tac-toe/internal/game"
 	"tic-tac-toe/internal/gradient"
+	"tic-tac-toe/internal/gravity"
+	"tic-tac-toe/internal/match"
+	"tic-tac-toe/internal/multi"
 	"tic-tac-toe/internal/persistence"
 )
 
@@ -16,6 +20,17 @@ type Config struct {
 	SoundEnabled    bool                  `json:"sound_enabled"`
 	AutoSaveEnabled bool                  `json:"auto_save_enabled"`
 	LastGameMode    int                   `json:"last_game_mode"`
+	TimeControl     string                `json:"time_control"`
+	AIStrength      float64               `json:"ai_strength"`
+	AIPersonality   string                `json:"ai_personality"`
+	Variant         string                `json:"variant"`
+	GravityBoard    string                `json:"gravity_board"`
+	Setup           string                `json:"setup"`
+	SwapRule        string                `json:"swap_rule"`
+	Match           string                `json:"match"`
+	MultiBoard      string                `json:"multi_board"`
+	MultiSearch     string                `json:"multi_search"`
+	NotaktoBoards   int                   `json:"notakto_boards"`
 	persistence     *persistence.Manager
 }
 
@@ -28,6 +43,13 @@ func New(persistenceManager *persistence.Manager) *Config {
 		SoundEnabled:    false,
 		AutoSaveEnabled: true,
 		LastGameMode:    0, // PlayerVsPlayer
+		TimeControl:     "untimed",
+		AIStrength:      ai.Normal.Strength(),
+		AIPersonality:   ai.Personalities[0].ID,
+		Variant:         string(game.Classic),
+		GravityBoard:    gravity.Shapes[0].Name(),
+		MultiBoard:      multi.Shapes[0].Name(),
+		NotaktoBoards:   1,
 		persistence:     persistenceManager,
 	}
 }
@@ -46,13 +68,52 @@ func (c *Config) Load() error {
 	c.SoundEnabled = settings.SoundEnabled
 	c.AutoSaveEnabled = settings.AutoSaveEnabled
 	c.LastGameMode = settings.LastGameMode
+	c.TimeControl = settings.TimeControl
+	c.AIStrength = settings.AIStrength
+	if c.AIStrength < 0 {
+		c.AIStrength = c.AIDifficulty.Strength()
+	}
+	if settings.AIPersonality != "" {
+		c.AIPersonality = settings.AIPersonality
+	}
+	if settings.Variant != "" {
+		c.Variant = settings.Variant
+	}
+	if settings.GravityBoard != "" {
+		c.GravityBoard = settings.GravityBoard
+	}
+	c.Setup = settings.Setup
+	c.SwapRule = settings.SwapRule
+	c.Match = settings.Match
+	if settings.MultiBoard != "" {
+		c.MultiBoard = settings.MultiBoard
+	}
+	c.MultiSearch = settings.MultiSearch
+	if settings.NotaktoBoards > 0 {
+		c.NotaktoBoards = settings.NotaktoBoards
+	}
 
 	return nil
 }
 
 // Save saves configuration to persistence
 func (c *Config) Save() error {
-	return c.persistence.SaveSettings(c.GradientType, c.AIDifficulty, c.AnimationSpeed)
+	return c.persistence.UpdateSettings(func(settings *persistence.Settings) {
+		settings.GradientType = int(c.GradientType)
+		settings.AIDifficulty = int(c.AIDifficulty)
+		settings.AnimationSpeed = c.AnimationSpeed
+		settings.TimeControl = c.TimeControl
+		settings.AIStrength = c.AIStrength
+		settings.AIPersonality = c.AIPersonality
+		settings.Variant = c.Variant
+		settings.GravityBoard = c.GravityBoard
+		settings.Setup = c.Setup
+		settings.SwapRule = c.SwapRule
+		settings.Match = c.Match
+		settings.MultiBoard = c.MultiBoard
+		settings.MultiSearch = c.MultiSearch
+		settings.NotaktoBoards = c.NotaktoBoards
+	})
 }
 
 // GetGradientType returns the current gradient type
@@ -71,12 +132,71 @@ func (c *Config) GetAIDifficulty() ai.Difficulty {
 	return c.AIDifficulty
 }
 
-// SetAIDifficulty sets the AI difficulty and saves immediately
+// SetAIDifficulty sets the AI difficulty, resets the strength to the level's and saves immediately
 func (c *Config) SetAIDifficulty(difficulty ai.Difficulty) error {
 	c.AIDifficulty = difficulty
+	c.AIStrength = difficulty.Strength()
+	return c.Save()
+}
+
+// GetAIStrength returns the AI strength from 0 (weakest) to 1 (perfect)
+func (c *Config) GetAIStrength() float64 {
+	return c.AIStrength
+}
+
+// SetAIStrength sets the AI strength and saves immediately
+func (c *Config) SetAIStrength(strength float64) error {
+	if strength < 0 {
+		strength = 0
+	}
+	if strength > 1 {
+		strength = 1
+	}
+
+	c.AIStrength = strength
+	return c.Save()
+}
+
+// GetAIPersonality returns the AI's playing style
+func (c *Config) GetAIPersonality() ai.Personality {
+	if personality, err := ai.PersonalityByID(c.AIPersonality); err == nil {
+		return personality
+	}
+	return ai.Personalities[0]
+}
+
+// SetAIPersonality sets the AI's playing style by ID and saves immediately
+func (c *Config) SetAIPersonality(id string) error {
+	personality, err := ai.PersonalityByID(id)
+	if err != nil {
+		return err
+	}
+	c.AIPersonality = personality.ID
 	return c.Save()
 }
 
+// NextAIPersonality cycles to the next playing style
+func (c *Config) NextAIPersonality() error {
+	currentIndex := 0
+	for i, personality := range ai.Personalities {
+		if personality.ID == c.AIPersonality {
+			currentIndex = i
+			break
+		}
+	}
+
+	nextIndex := (currentIndex + 1) % len(ai.Personalities)
+	return c.SetAIPersonality(ai.Personalities[nextIndex].ID)
+}
+
+// NewAI creates an AI for player with the configured difficulty, strength and personality
+func (c *Config) NewAI(player game.Player) *ai.AI {
+	aiPlayer := ai.New(c.AIDifficulty, player)
+	aiPlayer.SetStrength(c.AIStrength)
+	aiPlayer.SetPersonality(c.GetAIPersonality())
+	return aiPlayer
+}
+
 // GetAnimationSpeed returns the current animation speed
 func (c *Config) GetAnimationSpeed() float64 {
 	return c.AnimationSpeed
@@ -128,6 +248,280 @@ func (c *Config) SetLastGameMode(mode int) error {
 	return c.Save()
 }
 
+// GetTimeControl returns the time control used for new games
+func (c *Config) GetTimeControl() game.TimeControl {
+	if tc, ok := game.TimeControlByName(c.TimeControl); ok {
+		return tc
+	}
+	return game.TimeControls[0]
+}
+
+// SetTimeControl sets the time control by name and saves immediately
+func (c *Config) SetTimeControl(name string) error {
+	if _, ok := game.TimeControlByName(name); !ok {
+		return fmt.Errorf("unknown time control %q", name)
+	}
+	c.TimeControl = name
+	return c.Save()
+}
+
+// NextTimeControl cycles to the next time control
+func (c *Config) NextTimeControl() error {
+	currentIndex := 0
+	for i, tc := range game.TimeControls {
+		if tc.Name == c.TimeControl {
+			currentIndex = i
+			break
+		}
+	}
+
+	nextIndex := (currentIndex + 1) % len(game.TimeControls)
+	return c.SetTimeControl(game.TimeControls[nextIndex].Name)
+}
+
+// GetVariant returns the rule variant used for new local games
+func (c *Config) GetVariant() game.Variant {
+	if variant, err := game.ParseVariant(c.Variant); err == nil {
+		return variant
+	}
+	return game.Classic
+}
+
+// GetRules returns the rules for new local games
+func (c *Config) GetRules() game.Rules {
+	return c.RulesFor(c.GetVariant())
+}
+
+// RulesFor returns the rules for games of the given variant, with the
+// configured number of Notakto boards
+func (c *Config) RulesFor(variant game.Variant) game.Rules {
+	rules := game.Rules{Variant: variant}
+	if rules.Variant == game.Notakto && c.GetNotaktoBoards() > 1 {
+		rules.Boards = c.GetNotaktoBoards()
+	}
+	return rules
+}
+
+// SetVariant sets the rule variant by name and saves immediately
+func (c *Config) SetVariant(name string) error {
+	variant, err := game.ParseVariant(name)
+	if err != nil {
+		return err
+	}
+	c.Variant = string(variant)
+	return c.Save()
+}
+
+// NextVariant cycles to the next rule variant
+func (c *Config) NextVariant() error {
+	current := c.GetVariant()
+	for i, variant := range game.Variants {
+		if variant == current {
+			return c.SetVariant(string(game.Variants[(i+1)%len(game.Variants)]))
+		}
+	}
+	return c.SetVariant(string(game.Classic))
+}
+
+// GetNotaktoBoards returns how many boards Notakto games are played on
+func (c *Config) GetNotaktoBoards() int {
+	if c.NotaktoBoards < 1 || c.NotaktoBoards > game.MaxBoards {
+		return 1
+	}
+	return c.NotaktoBoards
+}
+
+// SetNotaktoBoards sets how many boards Notakto games are played on and saves immediately
+func (c *Config) SetNotaktoBoards(boards int) error {
+	if boards < 1 || boards > game.MaxBoards {
+		return fmt.Errorf("notakto is played on 1 to %d boards, not %d", game.MaxBoards, boards)
+	}
+	c.NotaktoBoards = boards
+	return c.Save()
+}
+
+// NextNotaktoBoards cycles through the numbers of Notakto boards
+func (c *Config) NextNotaktoBoards() error {
+	return c.SetNotaktoBoards(c.GetNotaktoBoards()%game.MaxBoards + 1)
+}
+
+// GetGravityShape returns the board for gravity games
+func (c *Config) GetGravityShape() gravity.Shape {
+	if shape, err := gravity.ParseShape(c.GravityBoard); err == nil {
+		return shape
+	}
+	return gravity.Shapes[0]
+}
+
+// SetGravityShape sets the gravity board by name, e.g. "7x6x4", and saves immediately
+func (c *Config) SetGravityShape(name string) error {
+	shape, err := gravity.ParseShape(name)
+	if err != nil {
+		return err
+	}
+	c.GravityBoard = shape.Name()
+	return c.Save()
+}
+
+// NextGravityShape cycles through the offered gravity boards
+func (c *Config) NextGravityShape() error {
+	current := c.GetGravityShape()
+	for i, shape := range gravity.Shapes {
+		if shape == current {
+			return c.SetGravityShape(gravity.Shapes[(i+1)%len(gravity.Shapes)].Name())
+		}
+	}
+	return c.SetGravityShape(gravity.Shapes[0].Name())
+}
+
+// GetSetup returns the starting position for new local games, nil for the
+// empty board
+func (c *Config) GetSetup() *game.Setup {
+	setup, err := game.LookupSetup(c.Setup)
+	if err != nil {
+		return nil
+	}
+	return setup
+}
+
+// GetSetupName returns the name of the setup for display
+func (c *Config) GetSetupName() string {
+	if setup := c.GetSetup(); setup != nil {
+		return setup.Name
+	}
+	return "Standard"
+}
+
+// SetSetup sets the setup by name or position, e.g. "Handicap X" or
+// "X#./.../..#", and saves immediately
+func (c *Config) SetSetup(name string) error {
+	setup, err := game.LookupSetup(name)
+	if err != nil {
+		return err
+	}
+	c.Setup = ""
+	if setup != nil {
+		c.Setup = setup.Name
+	}
+	return c.Save()
+}
+
+// GetSwapRule returns the swap rule for new local and gravity games
+func (c *Config) GetSwapRule() game.SwapRule {
+	if rule, err := game.ParseSwapRule(c.SwapRule); err == nil {
+		return rule
+	}
+	return game.NoSwap
+}
+
+// SetSwapRule sets the swap rule by name and saves immediately
+func (c *Config) SetSwapRule(name string) error {
+	rule, err := game.ParseSwapRule(name)
+	if err != nil {
+		return err
+	}
+	c.SwapRule = string(rule)
+	return c.Save()
+}
+
+// NextSwapRule cycles to the next swap rule
+func (c *Config) NextSwapRule() error {
+	current := c.GetSwapRule()
+	for i, rule := range game.SwapRules {
+		if rule == current {
+			return c.SetSwapRule(string(game.SwapRules[(i+1)%len(game.SwapRules)]))
+		}
+	}
+	return c.SetSwapRule(string(game.NoSwap))
+}
+
+// GetMatchFormat returns the match format for new local games and tournaments
+func (c *Config) GetMatchFormat() match.Format {
+	if format, err := match.ParseFormat(c.Match); err == nil {
+		return format
+	}
+	return match.Format{}
+}
+
+// SetMatchFormat sets the match format by name and saves immediately
+func (c *Config) SetMatchFormat(name string) error {
+	format, err := match.ParseFormat(name)
+	if err != nil {
+		return err
+	}
+	c.Match = format.Key()
+	return c.Save()
+}
+
+// NextMatchFormat cycles to the next offered match format
+func (c *Config) NextMatchFormat() error {
+	current := c.GetMatchFormat()
+	for i, format := range match.Formats {
+		if format == current {
+			return c.SetMatchFormat(match.Formats[(i+1)%len(match.Formats)].Key())
+		}
+	}
+	return c.SetMatchFormat(match.Formats[0].Key())
+}
+
+// GetMultiShape returns the players and board for multi-player games
+func (c *Config) GetMultiShape() multi.Shape {
+	if shape, err := multi.ParseShape(c.MultiBoard); err == nil {
+		return shape
+	}
+	return multi.Shapes[0]
+}
+
+// SetMultiShape sets the multi-player game by name, e.g. "3p-6x6x4", and saves immediately
+func (c *Config) SetMultiShape(name string) error {
+	shape, err := multi.ParseShape(name)
+	if err != nil {
+		return err
+	}
+	c.MultiBoard = shape.Name()
+	return c.Save()
+}
+
+// NextMultiShape cycles through the offered multi-player games
+func (c *Config) NextMultiShape() error {
+	current := c.GetMultiShape()
+	for i, shape := range multi.Shapes {
+		if shape == current {
+			return c.SetMultiShape(multi.Shapes[(i+1)%len(multi.Shapes)].Name())
+		}
+	}
+	return c.SetMultiShape(multi.Shapes[0].Name())
+}
+
+// GetMultiSearch returns how the multi-player AI expects the other players to play
+func (c *Config) GetMultiSearch() multi.Strategy {
+	if strategy, err := multi.ParseStrategy(c.MultiSearch); err == nil {
+		return strategy
+	}
+	return multi.MaxN
+}
+
+// SetMultiSearch sets the multi-player AI search by name and saves immediately
+func (c *Config) SetMultiSearch(name string) error {
+	strategy, err := multi.ParseStrategy(name)
+	if err != nil {
+		return err
+	}
+	c.MultiSearch = string(strategy)
+	return c.Save()
+}
+
+// NextMultiSearch cycles to the next multi-player AI search
+func (c *Config) NextMultiSearch() error {
+	current := c.GetMultiSearch()
+	for i, strategy := range multi.Strategies {
+		if strategy == current {
+			return c.SetMultiSearch(string(multi.Strategies[(i+1)%len(multi.Strategies)]))
+		}
+	}
+	return c.SetMultiSearch(string(multi.MaxN))
+}
+
 // NextGradientType cycles to the next gradient type
 func (c *Config) NextGradientType() error {
 	gradientTypes := []gradient.GradientType{
@@ -206,6 +600,17 @@ func (c *Config) ResetToDefaults() error {
 	c.SoundEnabled = false
 	c.AutoSaveEnabled = true
 	c.LastGameMode = 0
+	c.TimeControl = "untimed"
+	c.AIStrength = ai.Normal.Strength()
+	c.AIPersonality = ai.Personalities[0].ID
+	c.Variant = string(game.Classic)
+	c.GravityBoard = gravity.Shapes[0].Name()
+	c.Setup = ""
+	c.SwapRule = string(game.NoSwap)
+	c.Match = match.Formats[0].Key()
+	c.MultiBoard = multi.Shapes[0].Name()
+	c.MultiSearch = string(multi.MaxN)
+	c.NotaktoBoards = 1
 
 	return c.Save()
 }
@@ -216,7 +621,18 @@ func (c *Config) GetSettingsDisplay() string {
 	display += "─────────────────\n"
 	display += "Gradient: " + c.GetGradientTypeName() + "\n"
 	display += "AI Difficulty: " + c.GetAIDifficultyName() + "\n"
+	display += "AI Strength: " + fmt.Sprintf("%.2f", c.AIStrength) + "\n"
+	display += "AI Personality: " + c.GetAIPersonality().Name + " (" + c.GetAIPersonality().ID + ")\n"
 	display += "Animation Speed: " + fmt.Sprintf("%.1fx", c.AnimationSpeed) + "\n"
+	display += "Time Control: " + c.GetTimeControl().String() + "\n"
+	display += "Rules: " + c.GetVariant().Name() + "\n"
+	display += fmt.Sprintf("Notakto Boards: %d\n", c.GetNotaktoBoards())
+	display += "Gravity Board: " + c.GetGravityShape().String() + "\n"
+	display += "Setup: " + c.GetSetupName() + "\n"
+	display += "Swap Rule: " + c.GetSwapRule().Name() + "\n"
+	display += "Match: " + c.GetMatchFormat().String() + "\n"
+	display += "Multi-player: " + c.GetMultiShape().String() + "\n"
+	display += "Multi-player AI: " + c.GetMultiSearch().Name() + "\n"
 	display += "Sound: "
 	if c.SoundEnabled {
 		display += "Enabled"
@@ -247,11 +663,64 @@ func (c *Config) ValidateConfig() error {
 		c.AIDifficulty = ai.Normal
 	}
 
+	// Validate AI strength
+	if c.AIStrength < 0 || c.AIStrength > 1 {
+		c.AIStrength = c.AIDifficulty.Strength()
+	}
+
+	// Validate AI personality
+	if _, err := ai.PersonalityByID(c.AIPersonality); err != nil {
+		c.AIPersonality = ai.Personalities[0].ID
+	}
+
 	// Validate animation speed
 	if c.AnimationSpeed < 0.1 || c.AnimationSpeed > 5.0 {
 		c.AnimationSpeed = 1.0
 	}
 
+	// Validate time control
+	if _, ok := game.TimeControlByName(c.TimeControl); !ok {
+		c.TimeControl = "untimed"
+	}
+
+	// Validate rule variant
+	if _, err := game.ParseVariant(c.Variant); err != nil {
+		c.Variant = string(game.Classic)
+	}
+
+	// Validate Notakto boards
+	if c.NotaktoBoards < 1 || c.NotaktoBoards > game.MaxBoards {
+		c.NotaktoBoards = 1
+	}
+
+	// Validate gravity board
+	if _, err := gravity.ParseShape(c.GravityBoard); err != nil {
+		c.GravityBoard = gravity.Shapes[0].Name()
+	}
+
+	// Validate swap rule
+	if _, err := game.ParseSwapRule(c.SwapRule); err != nil {
+		c.SwapRule = string(game.NoSwap)
+	}
+
+	// Validate match format
+	if _, err := match.ParseFormat(c.Match); err != nil {
+		c.Match = match.Formats[0].Key()
+	}
+
+	// Validate multi-player game and AI search
+	if _, err := multi.ParseShape(c.MultiBoard); err != nil {
+		c.MultiBoard = multi.Shapes[0].Name()
+	}
+	if _, err := multi.ParseStrategy(c.MultiSearch); err != nil {
+		c.MultiSearch = string(multi.MaxN)
+	}
+
+	// Validate setup
+	if _, err := game.LookupSetup(c.Setup); err != nil {
+		c.Setup = ""
+	}
+
 	// Validate game mode
 	if c.LastGameMode < 0 || c.LastGameMode > 1 {
 		c.LastGameMode = 0
@@ -8,7 +8,11 @@ import (
 
 	"tic-tac-toe/internal/ai"
 	"tic-tac-toe/internal/config"
+	"tic-tac-toe/internal/game"
 	"tic-tac-toe/internal/gradient"
+	"tic-tac-toe/internal/gravity"
+	"tic-tac-toe/internal/match"
+	"tic-tac-toe/internal/multi"
 	"tic-tac-toe/internal/persistence"
 )
 
@@ -252,4 +256,257 @@ var _ = Describe("Config", func() {
 			Expect(len(seenDifficulties)).To(BeNumerically(">", 1))
 		})
 	})
-})
\ No newline at end of file
+
+	Describe("Time Control Cycling", func() {
+		It("should default to untimed", func() {
+			cfg.ResetToDefaults()
+			Expect(cfg.GetTimeControl().IsUntimed()).To(BeTrue())
+		})
+
+		It("should set a named time control", func() {
+			Expect(cfg.SetTimeControl("blitz")).To(Succeed())
+			Expect(cfg.GetTimeControl().Name).To(Equal("blitz"))
+		})
+
+		It("should reject unknown time controls", func() {
+			Expect(cfg.SetTimeControl("glacial")).ToNot(Succeed())
+		})
+
+		It("should cycle back to the first time control", func() {
+			Expect(cfg.SetTimeControl("untimed")).To(Succeed())
+			for range game.TimeControls {
+				Expect(cfg.NextTimeControl()).To(Succeed())
+			}
+			Expect(cfg.GetTimeControl().Name).To(Equal("untimed"))
+		})
+	})
+
+	Describe("AI Strength", func() {
+		It("should follow the difficulty until set", func() {
+			Expect(cfg.SetAIDifficulty(ai.Hard)).To(Succeed())
+			Expect(cfg.GetAIStrength()).To(Equal(ai.Hard.Strength()))
+
+			Expect(cfg.SetAIStrength(0.6)).To(Succeed())
+			Expect(cfg.GetAIStrength()).To(Equal(0.6))
+			Expect(cfg.NewAI(game.PlayerO).GetParams().Strength).To(Equal(0.6))
+		})
+
+		It("should clamp strength to the valid range", func() {
+			Expect(cfg.SetAIStrength(3)).To(Succeed())
+			Expect(cfg.GetAIStrength()).To(Equal(1.0))
+			Expect(cfg.SetAIStrength(-1)).To(Succeed())
+			Expect(cfg.GetAIStrength()).To(Equal(0.0))
+		})
+
+		It("should persist strength and derive it for older settings", func() {
+			manager := persistence.NewWithDirectory(tempDir)
+			saved := config.New(manager)
+			Expect(saved.SetAIStrength(0.35)).To(Succeed())
+
+			loaded := config.New(manager)
+			Expect(loaded.Load()).To(Succeed())
+			Expect(loaded.GetAIStrength()).To(Equal(0.35))
+
+			older := config.New(persistence.NewWithDirectory(GinkgoT().TempDir()))
+			Expect(older.Load()).To(Succeed())
+			Expect(older.GetAIStrength()).To(Equal(older.GetAIDifficulty().Strength()))
+		})
+	})
+
+	Describe("AI Personality", func() {
+		It("should set and cycle personalities", func() {
+			Expect(cfg.SetAIPersonality("trickster")).To(Succeed())
+			Expect(cfg.GetAIPersonality().Name).To(Equal("Loki"))
+			Expect(cfg.NewAI(game.PlayerO).GetPersonality().ID).To(Equal("trickster"))
+
+			for range ai.Personalities {
+				Expect(cfg.NextAIPersonality()).To(Succeed())
+			}
+			Expect(cfg.GetAIPersonality().ID).To(Equal("trickster"))
+		})
+
+		It("should reject unknown personalities", func() {
+			Expect(cfg.SetAIPersonality("reckless")).ToNot(Succeed())
+		})
+	})
+
+	Describe("Rule Variant", func() {
+		It("should default to classic and cycle back to it", func() {
+			variants := config.New(persistence.NewWithDirectory(tempDir))
+			Expect(variants.GetVariant()).To(Equal(game.Classic))
+			Expect(variants.GetRules().IsClassic()).To(BeTrue())
+
+			for range game.Variants {
+				Expect(variants.NextVariant()).To(Succeed())
+			}
+			Expect(variants.GetVariant()).To(Equal(game.Classic))
+		})
+
+		It("should persist the chosen variant", func() {
+			manager := persistence.NewWithDirectory(tempDir)
+			Expect(config.New(manager).SetVariant("notakto")).To(Succeed())
+
+			loaded := config.New(manager)
+			Expect(loaded.Load()).To(Succeed())
+			Expect(loaded.GetVariant()).To(Equal(game.Notakto))
+			Expect(loaded.GetSettingsDisplay()).To(ContainSubstring("Rules: Notakto"))
+		})
+
+		It("should reject unknown variants", func() {
+			Expect(cfg.SetVariant("gravity")).ToNot(Succeed())
+		})
+
+		It("should play Notakto on the configured number of boards", func() {
+			manager := persistence.NewWithDirectory(tempDir)
+			saved := config.New(manager)
+			Expect(saved.GetNotaktoBoards()).To(Equal(1))
+			Expect(saved.SetNotaktoBoards(3)).To(Succeed())
+			Expect(saved.GetRules()).To(Equal(game.ClassicRules))
+
+			loaded := config.New(manager)
+			Expect(loaded.Load()).To(Succeed())
+			Expect(loaded.SetVariant("notakto")).To(Succeed())
+			Expect(loaded.GetRules()).To(Equal(game.Rules{Variant: game.Notakto, Boards: 3}))
+			Expect(loaded.GetSettingsDisplay()).To(ContainSubstring("Notakto Boards: 3"))
+
+			Expect(loaded.NextNotaktoBoards()).To(Succeed())
+			Expect(loaded.GetRules()).To(Equal(game.Rules{Variant: game.Notakto}))
+			Expect(loaded.SetNotaktoBoards(game.MaxBoards + 1)).ToNot(Succeed())
+		})
+	})
+
+	Describe("Gravity Board", func() {
+		It("should default to Connect Four and cycle back to it", func() {
+			boards := config.New(persistence.NewWithDirectory(tempDir))
+			Expect(boards.GetGravityShape()).To(Equal(gravity.Shapes[0]))
+
+			for range gravity.Shapes {
+				Expect(boards.NextGravityShape()).To(Succeed())
+			}
+			Expect(boards.GetGravityShape()).To(Equal(gravity.Shapes[0]))
+		})
+
+		It("should persist custom boards and reject ones that do not fit", func() {
+			manager := persistence.NewWithDirectory(tempDir)
+			Expect(config.New(manager).SetGravityShape("9x9x5")).To(Succeed())
+
+			loaded := config.New(manager)
+			Expect(loaded.Load()).To(Succeed())
+			Expect(loaded.GetGravityShape()).To(Equal(gravity.Shape{Cols: 9, Rows: 9, Connect: 5}))
+			Expect(loaded.GetSettingsDisplay()).To(ContainSubstring("Gravity Board: 9×9, 5 in a row"))
+
+			Expect(loaded.SetGravityShape("12x6x4")).ToNot(Succeed())
+			Expect(loaded.GetGravityShape().Name()).To(Equal("9x9x5"))
+		})
+	})
+
+	Describe("Multi-player", func() {
+		It("should default to three players and max-n, cycling back to them", func() {
+			games := config.New(persistence.NewWithDirectory(tempDir))
+			Expect(games.GetMultiShape()).To(Equal(multi.Shapes[0]))
+			Expect(games.GetMultiSearch()).To(Equal(multi.MaxN))
+
+			for range multi.Shapes {
+				Expect(games.NextMultiShape()).To(Succeed())
+			}
+			Expect(games.GetMultiShape()).To(Equal(multi.Shapes[0]))
+			Expect(games.NextMultiSearch()).To(Succeed())
+			Expect(games.GetMultiSearch()).To(Equal(multi.Paranoid))
+		})
+
+		It("should persist the game and AI search and reject games that cannot be played", func() {
+			manager := persistence.NewWithDirectory(tempDir)
+			saved := config.New(manager)
+			Expect(saved.SetMultiShape("4p-9x9x5")).To(Succeed())
+			Expect(saved.SetMultiSearch("paranoid")).To(Succeed())
+
+			loaded := config.New(manager)
+			Expect(loaded.Load()).To(Succeed())
+			Expect(loaded.GetMultiShape()).To(Equal(multi.Shape{Players: 4, Cols: 9, Rows: 9, Connect: 5}))
+			Expect(loaded.GetSettingsDisplay()).To(ContainSubstring("Multi-player: 4 players, 9×9, 5 in a row"))
+			Expect(loaded.GetSettingsDisplay()).To(ContainSubstring("Multi-player AI: Paranoid"))
+
+			Expect(loaded.SetMultiShape("5p-6x6x4")).ToNot(Succeed())
+			Expect(loaded.SetMultiSearch("minimax")).ToNot(Succeed())
+			Expect(loaded.GetMultiShape().Name()).To(Equal("4p-9x9x5"))
+		})
+	})
+
+	Describe("Game Setup", func() {
+		It("should default to the empty board", func() {
+			Expect(cfg.GetSetup()).To(BeNil())
+			Expect(cfg.GetSettingsDisplay()).To(ContainSubstring("Setup: Standard"))
+		})
+
+		It("should persist named and custom setups", func() {
+			manager := persistence.NewWithDirectory(tempDir)
+			Expect(config.New(manager).SetSetup("handicap x")).To(Succeed())
+
+			loaded := config.New(manager)
+			Expect(loaded.Load()).To(Succeed())
+			Expect(loaded.GetSetup().Position).To(Equal(".../.X./..."))
+			Expect(loaded.GetSettingsDisplay()).To(ContainSubstring("Setup: Handicap X"))
+
+			Expect(loaded.SetSetup("x#./.../..#")).To(Succeed())
+			Expect(loaded.GetSetup().Position).To(Equal("X#./.../..#"))
+			Expect(loaded.SetSetup("standard")).To(Succeed())
+			Expect(loaded.GetSetup()).To(BeNil())
+		})
+
+		It("should reject setups that cannot be played", func() {
+			Expect(cfg.SetSetup("XXX/.../...")).ToNot(Succeed())
+			Expect(cfg.SetSetup("Blocked everything")).ToNot(Succeed())
+			Expect(cfg.GetSetup()).To(BeNil())
+		})
+	})
+
+	Describe("Swap Rule", func() {
+		It("should default to off and cycle back to it", func() {
+			rules := config.New(persistence.NewWithDirectory(tempDir))
+			Expect(rules.GetSwapRule()).To(Equal(game.NoSwap))
+			Expect(rules.GetSettingsDisplay()).To(ContainSubstring("Swap Rule: Off"))
+
+			for range game.SwapRules {
+				Expect(rules.NextSwapRule()).To(Succeed())
+			}
+			Expect(rules.GetSwapRule()).To(Equal(game.NoSwap))
+		})
+
+		It("should persist the chosen rule and reject unknown ones", func() {
+			manager := persistence.NewWithDirectory(tempDir)
+			Expect(config.New(manager).SetSwapRule("swap2")).To(Succeed())
+
+			loaded := config.New(manager)
+			Expect(loaded.Load()).To(Succeed())
+			Expect(loaded.GetSwapRule()).To(Equal(game.Swap2))
+			Expect(loaded.GetSettingsDisplay()).To(ContainSubstring("Swap Rule: Swap2"))
+			Expect(loaded.SetSwapRule("swap3")).ToNot(Succeed())
+		})
+	})
+
+	Describe("Match Format", func() {
+		It("should default to a single game and cycle back to it", func() {
+			cfg := config.New(persistence.NewWithDirectory(tempDir))
+			Expect(cfg.GetMatchFormat().IsSingle()).To(BeTrue())
+			Expect(cfg.GetSettingsDisplay()).To(ContainSubstring("Match: Single game"))
+
+			Expect(cfg.NextMatchFormat()).To(Succeed())
+			Expect(cfg.GetMatchFormat()).To(Equal(match.Format{Kind: match.BestOf, N: 3}))
+			for range match.Formats[1:] {
+				Expect(cfg.NextMatchFormat()).To(Succeed())
+			}
+			Expect(cfg.GetMatchFormat().IsSingle()).To(BeTrue())
+		})
+
+		It("should persist the chosen format and reject impossible ones", func() {
+			manager := persistence.NewWithDirectory(tempDir)
+			Expect(config.New(manager).SetMatchFormat("first-to-5")).To(Succeed())
+
+			loaded := config.New(manager)
+			Expect(loaded.Load()).To(Succeed())
+			Expect(loaded.GetSettingsDisplay()).To(ContainSubstring("Match: First to 5"))
+			Expect(loaded.SetMatchFormat("bo4")).ToNot(Succeed())
+			Expect(loaded.GetMatchFormat()).To(Equal(match.Format{Kind: match.FirstTo, N: 5}))
+		})
+	})
+})
@@ -0,0 +1,430 @@
+package correspondence
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"tic-tac-toe/internal/game"
+)
+
+const (
+	gameFileExt = ".ttt.json"
+	seenFile    = "correspondence_seen.json"
+)
+
+// Seat is a player's claim on one side of a correspondence game
+type Seat struct {
+	Name      string            `json:"name"`
+	PublicKey ed25519.PublicKey `json:"public_key"`
+}
+
+// SignedMove is a move signed by the player who made it
+type SignedMove struct {
+	Number    int         `json:"number"`
+	Player    game.Player `json:"player"`
+	Row       int         `json:"row"`
+	Col       int         `json:"col"`
+	PlayedAt  time.Time   `json:"played_at"`
+	Signature []byte      `json:"signature"`
+}
+
+// Record is the shared game file exchanged between players
+type Record struct {
+	ID        string       `json:"id"`
+	CreatedAt time.Time    `json:"created_at"`
+	Nonce     string       `json:"nonce"`
+	PlayerX   Seat         `json:"player_x"`
+	PlayerO   *Seat        `json:"player_o,omitempty"` // Claimed by whoever plays O's first move
+	Moves     []SignedMove `json:"moves"`
+}
+
+// Summary describes a correspondence game for listings
+type Summary struct {
+	ID       string
+	PlayerX  string
+	PlayerO  string
+	Moves    int
+	Status   game.GameStatus
+	Winner   game.Player
+	YourTurn bool
+	Err      error // Set when the file failed verification
+}
+
+// seenState remembers the last verified move of a game to detect rollbacks
+type seenState struct {
+	Moves         int    `json:"moves"`
+	LastSignature []byte `json:"last_signature"`
+}
+
+// Manager plays correspondence games stored in a shared directory
+type Manager struct {
+	dir      string
+	seenPath string
+	identity *Identity
+	seen     map[string]seenState
+}
+
+// New creates a manager for games in dir, keeping local verification state in stateDir
+func New(dir, stateDir string, identity *Identity) (*Manager, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+
+	m := &Manager{
+		dir:      dir,
+		seenPath: filepath.Join(stateDir, seenFile),
+		identity: identity,
+		seen:     make(map[string]seenState),
+	}
+
+	if data, err := os.ReadFile(m.seenPath); err == nil {
+		if err := json.Unmarshal(data, &m.seen); err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", m.seenPath, err)
+		}
+	}
+
+	return m, nil
+}
+
+// GetDirectory returns the shared game directory
+func (m *Manager) GetDirectory() string {
+	return m.dir
+}
+
+// Create starts a new game with the local profile playing X
+func (m *Manager) Create() (*Record, error) {
+	nonce := make([]byte, 8)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	record := &Record{
+		CreatedAt: time.Now().UTC(),
+		Nonce:     hex.EncodeToString(nonce),
+		PlayerX:   Seat{Name: m.identity.Name, PublicKey: m.identity.PublicKey},
+		Moves:     []SignedMove{},
+	}
+	record.ID = record.expectedID()
+
+	if err := m.save(record); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// List verifies and summarizes every game in the shared directory
+func (m *Manager) List() ([]Summary, error) {
+	entries, err := os.ReadDir(m.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", m.dir, err)
+	}
+
+	var summaries []Summary
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), gameFileExt) {
+			continue
+		}
+
+		id := strings.TrimSuffix(entry.Name(), gameFileExt)
+		record, g, err := m.Load(id)
+		if err != nil {
+			summaries = append(summaries, Summary{ID: id, Err: err})
+			continue
+		}
+
+		summary := Summary{
+			ID:       id,
+			PlayerX:  record.PlayerX.Name,
+			Moves:    len(record.Moves),
+			Status:   g.GetStatus(),
+			Winner:   g.GetWinner(),
+			YourTurn: m.isMyTurn(record, g),
+		}
+		if record.PlayerO != nil {
+			summary.PlayerO = record.PlayerO.Name
+		}
+		summaries = append(summaries, summary)
+	}
+
+	// Games waiting on us come first
+	sort.SliceStable(summaries, func(i, j int) bool {
+		if summaries[i].YourTurn != summaries[j].YourTurn {
+			return summaries[i].YourTurn
+		}
+		return summaries[i].ID < summaries[j].ID
+	})
+
+	return summaries, nil
+}
+
+// Load reads a game file, verifies it and replays it into a game
+func (m *Manager) Load(id string) (*Record, *game.Game, error) {
+	data, err := os.ReadFile(m.path(id))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read game %s: %w", id, err)
+	}
+
+	var record Record
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse game %s: %w", id, err)
+	}
+	if record.ID != id {
+		return nil, nil, fmt.Errorf("game file %s contains game %s", id, record.ID)
+	}
+
+	g, err := Verify(&record)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := m.checkSeen(&record); err != nil {
+		return nil, nil, err
+	}
+
+	m.markSeen(&record)
+	return &record, g, nil
+}
+
+// Play signs and writes the local player's move
+func (m *Manager) Play(id string, row, col int) (*game.Game, error) {
+	record, g, err := m.Load(id)
+	if err != nil {
+		return nil, err
+	}
+	if !m.isMyTurn(record, g) {
+		return nil, fmt.Errorf("it is not your turn in game %s", id)
+	}
+
+	player := g.GetCurrentPlayer()
+	if err := g.MakeMove(row, col); err != nil {
+		return nil, err
+	}
+
+	// The first O move claims the open seat
+	if player == game.PlayerO && record.PlayerO == nil {
+		record.PlayerO = &Seat{Name: m.identity.Name, PublicKey: m.identity.PublicKey}
+	}
+
+	move := SignedMove{
+		Number:   len(record.Moves) + 1,
+		Player:   player,
+		Row:      row,
+		Col:      col,
+		PlayedAt: time.Now().UTC(),
+	}
+	move.Signature = ed25519.Sign(m.identity.PrivateKey, record.movePayload(move))
+	record.Moves = append(record.Moves, move)
+
+	if err := m.save(record); err != nil {
+		return nil, err
+	}
+	m.markSeen(record)
+
+	return g, nil
+}
+
+// Export encodes a game as a text blob that can be sent by any channel
+func (m *Manager) Export(id string) (string, error) {
+	record, _, err := m.Load(id)
+	if err != nil {
+		return "", err
+	}
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal game: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(data), nil
+}
+
+// Import verifies a blob from Export and stores it in the shared directory
+func (m *Manager) Import(blob string) (*Record, error) {
+	data, err := base64.StdEncoding.DecodeString(strings.TrimSpace(blob))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode game blob: %w", err)
+	}
+
+	var record Record
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, fmt.Errorf("failed to parse game blob: %w", err)
+	}
+	if _, err := Verify(&record); err != nil {
+		return nil, err
+	}
+	if err := m.checkSeen(&record); err != nil {
+		return nil, err
+	}
+
+	if err := m.save(&record); err != nil {
+		return nil, err
+	}
+	m.markSeen(&record)
+
+	return &record, nil
+}
+
+// Verify checks the game ID, every signature and every move against the rules
+func Verify(record *Record) (*game.Game, error) {
+	if record.ID != record.expectedID() {
+		return nil, fmt.Errorf("game %s: header does not match its ID", record.ID)
+	}
+
+	g := game.New()
+	for i, move := range record.Moves {
+		if move.Number != i+1 {
+			return nil, fmt.Errorf("game %s: move %d is numbered %d", record.ID, i+1, move.Number)
+		}
+		if move.Player != g.GetCurrentPlayer() {
+			return nil, fmt.Errorf("game %s: move %d was played out of turn", record.ID, move.Number)
+		}
+
+		seat := record.seat(move.Player)
+		if seat == nil {
+			return nil, fmt.Errorf("game %s: move %d has no seat for %s", record.ID, move.Number, move.Player)
+		}
+		if !ed25519.Verify(seat.PublicKey, record.movePayload(move), move.Signature) {
+			return nil, fmt.Errorf("game %s: move %d has an invalid signature", record.ID, move.Number)
+		}
+
+		if err := g.MakeMove(move.Row, move.Col); err != nil {
+			return nil, fmt.Errorf("game %s: move %d is illegal: %w", record.ID, move.Number, err)
+		}
+	}
+
+	return g, nil
+}
+
+// expectedID derives the game ID from the creator's key and the creation data
+func (r *Record) expectedID() string {
+	h := sha256.New()
+	h.Write(r.PlayerX.PublicKey)
+	h.Write([]byte(r.PlayerX.Name))
+	h.Write([]byte(r.CreatedAt.UTC().Format(time.RFC3339Nano)))
+	h.Write([]byte(r.Nonce))
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// movePayload is the signed content of a move, chained to the previous
+// signature. It names both seats; O's seat is claimed by the second move, so
+// only moves from then on name it.
+func (r *Record) movePayload(move SignedMove) []byte {
+	var previous []byte
+	if move.Number > 1 && move.Number-2 < len(r.Moves) {
+		previous = r.Moves[move.Number-2].Signature
+	}
+
+	var key []byte
+	if seat := r.seat(move.Player); seat != nil {
+		key = seat.PublicKey
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "%s|%d|%s|%d|%d|%s|", r.ID, move.Number, move.Player, move.Row, move.Col, move.PlayedAt.UTC().Format(time.RFC3339Nano))
+	buf.Write(key)
+	fmt.Fprintf(&buf, "|%q|", r.PlayerX.Name)
+	if r.PlayerO != nil && move.Number > 1 {
+		fmt.Fprintf(&buf, "%q", r.PlayerO.Name)
+	}
+	buf.WriteByte('|')
+	buf.Write(previous)
+	return buf.Bytes()
+}
+
+// seat returns the seat playing player
+func (r *Record) seat(player game.Player) *Seat {
+	if player == game.PlayerX {
+		return &r.PlayerX
+	}
+	return r.PlayerO
+}
+
+// isMyTurn reports whether the local profile should move next
+func (m *Manager) isMyTurn(record *Record, g *game.Game) bool {
+	if g.GetStatus() != game.StatusPlaying {
+		return false
+	}
+
+	mine := m.identity.PublicKey
+	if g.GetCurrentPlayer() == game.PlayerX {
+		return record.PlayerX.PublicKey.Equal(mine)
+	}
+	if record.PlayerO == nil {
+		// Anyone but X may take the open seat
+		return !record.PlayerX.PublicKey.Equal(mine)
+	}
+	return record.PlayerO.PublicKey.Equal(mine)
+}
+
+// checkSeen rejects files that drop or rewrite moves we already verified
+func (m *Manager) checkSeen(record *Record) error {
+	seen, ok := m.seen[record.ID]
+	if !ok || seen.Moves == 0 {
+		return nil
+	}
+	if len(record.Moves) < seen.Moves {
+		return fmt.Errorf("game %s: history was truncated from %d to %d moves", record.ID, seen.Moves, len(record.Moves))
+	}
+	if !bytes.Equal(record.Moves[seen.Moves-1].Signature, seen.LastSignature) {
+		return fmt.Errorf("game %s: history was rewritten", record.ID)
+	}
+	return nil
+}
+
+// markSeen records the latest verified move and saves the local state
+func (m *Manager) markSeen(record *Record) {
+	if len(record.Moves) == 0 {
+		return
+	}
+
+	last := record.Moves[len(record.Moves)-1]
+	if seen, ok := m.seen[record.ID]; ok && seen.Moves == len(record.Moves) {
+		return
+	}
+	m.seen[record.ID] = seenState{Moves: len(record.Moves), LastSignature: last.Signature}
+
+	if data, err := json.MarshalIndent(m.seen, "", "  "); err == nil {
+		os.WriteFile(m.seenPath, data, 0644)
+	}
+}
+
+// save writes the record atomically so readers never see a partial file
+func (m *Manager) save(record *Record) error {
+	data, err := json.MarshalIndent(record, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal game: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(m.dir, ".tmp-"+record.ID+"-*")
+	if err != nil {
+		return fmt.Errorf("failed to write game %s: %w", record.ID, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write game %s: %w", record.ID, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write game %s: %w", record.ID, err)
+	}
+
+	if err := os.Rename(tmp.Name(), m.path(record.ID)); err != nil {
+		return fmt.Errorf("failed to write game %s: %w", record.ID, err)
+	}
+	return nil
+}
+
+// path returns the file holding game id
+func (m *Manager) path(id string) string {
+	return filepath.Join(m.dir, id+gameFileExt)
+}
@@ -0,0 +1,13 @@
+package correspondence_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestCorrespondence(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Correspondence Suite")
+}
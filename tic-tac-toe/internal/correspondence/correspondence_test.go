@@ -0,0 +1,209 @@
+package correspondence_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"tic-tac-toe/internal/correspondence"
+	"tic-tac-toe/internal/game"
+)
+
+var _ = Describe("Correspondence", func() {
+	var (
+		sharedDir  string
+		alice, bob *correspondence.Manager
+	)
+
+	newManager := func(name string) *correspondence.Manager {
+		stateDir := GinkgoT().TempDir()
+		identity, err := correspondence.LoadOrCreateIdentity(filepath.Join(stateDir, "identity.json"), name)
+		Expect(err).ToNot(HaveOccurred())
+		m, err := correspondence.New(sharedDir, stateDir, identity)
+		Expect(err).ToNot(HaveOccurred())
+		return m
+	}
+
+	// editRecord rewrites a shared game file to simulate tampering
+	editRecord := func(id string, edit func(r *correspondence.Record)) {
+		path := filepath.Join(sharedDir, id+".ttt.json")
+		data, err := os.ReadFile(path)
+		Expect(err).ToNot(HaveOccurred())
+		var record correspondence.Record
+		Expect(json.Unmarshal(data, &record)).To(Succeed())
+		edit(&record)
+		data, err = json.Marshal(record)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(os.WriteFile(path, data, 0644)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		sharedDir = GinkgoT().TempDir()
+		alice = newManager("alice")
+		bob = newManager("bob")
+	})
+
+	Describe("LoadOrCreateIdentity", func() {
+		It("should create a private key once and reload it", func() {
+			path := filepath.Join(GinkgoT().TempDir(), "identity.json")
+			first, err := correspondence.LoadOrCreateIdentity(path, "carol")
+			Expect(err).ToNot(HaveOccurred())
+
+			info, err := os.Stat(path)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(info.Mode().Perm()).To(Equal(os.FileMode(0600)))
+
+			second, err := correspondence.LoadOrCreateIdentity(path, "ignored")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(second.Name).To(Equal("carol"))
+			Expect(second.PublicKey).To(Equal(first.PublicKey))
+		})
+	})
+
+	Describe("Playing by file", func() {
+		var id string
+
+		BeforeEach(func() {
+			record, err := alice.Create()
+			Expect(err).ToNot(HaveOccurred())
+			id = record.ID
+		})
+
+		It("should give the creator the first move", func() {
+			aliceGames, err := alice.List()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(aliceGames).To(HaveLen(1))
+			Expect(aliceGames[0].YourTurn).To(BeTrue())
+			Expect(aliceGames[0].PlayerX).To(Equal("alice"))
+
+			bobGames, err := bob.List()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(bobGames[0].YourTurn).To(BeFalse())
+
+			_, err = bob.Play(id, 0, 0)
+			Expect(err).To(MatchError(ContainSubstring("not your turn")))
+		})
+
+		It("should detect the opponent's move and let the joiner claim O", func() {
+			_, err := alice.Play(id, 1, 1)
+			Expect(err).ToNot(HaveOccurred())
+
+			bobGames, err := bob.List()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(bobGames[0].YourTurn).To(BeTrue())
+
+			g, err := bob.Play(id, 0, 0)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(g.GetBoard()[1][1]).To(Equal(game.PlayerX))
+			Expect(g.GetBoard()[0][0]).To(Equal(game.PlayerO))
+
+			record, _, err := alice.Load(id)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(record.PlayerO.Name).To(Equal("bob"))
+
+			// A third profile can no longer move for O
+			carol := newManager("carol")
+			alice.Play(id, 2, 2)
+			_, err = carol.Play(id, 0, 1)
+			Expect(err).To(MatchError(ContainSubstring("not your turn")))
+		})
+
+		It("should reject illegal moves", func() {
+			alice.Play(id, 1, 1)
+			_, err := bob.Play(id, 1, 1)
+			Expect(err).To(MatchError(ContainSubstring("already occupied")))
+		})
+
+		It("should report finished games", func() {
+			moves := [][2]int{{0, 0}, {1, 0}, {0, 1}, {1, 1}, {0, 2}}
+			for i, move := range moves {
+				player := alice
+				if i%2 == 1 {
+					player = bob
+				}
+				_, err := player.Play(id, move[0], move[1])
+				Expect(err).ToNot(HaveOccurred())
+			}
+
+			games, err := bob.List()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(games[0].Status).To(Equal(game.StatusWon))
+			Expect(games[0].Winner).To(Equal(game.PlayerX))
+			Expect(games[0].YourTurn).To(BeFalse())
+		})
+
+		Describe("Tamper detection", func() {
+			BeforeEach(func() {
+				alice.Play(id, 1, 1)
+				bob.Play(id, 0, 0)
+			})
+
+			It("should reject a modified move", func() {
+				editRecord(id, func(r *correspondence.Record) { r.Moves[0].Col = 2 })
+				_, _, err := bob.Load(id)
+				Expect(err).To(MatchError(ContainSubstring("invalid signature")))
+			})
+
+			It("should reject a modified header", func() {
+				editRecord(id, func(r *correspondence.Record) { r.PlayerX.Name = "mallory" })
+				_, _, err := bob.Load(id)
+				Expect(err).To(MatchError(ContainSubstring("does not match its ID")))
+			})
+
+			It("should reject a renamed seat", func() {
+				editRecord(id, func(r *correspondence.Record) { r.PlayerO.Name = "mallory" })
+				_, _, err := alice.Load(id)
+				Expect(err).To(MatchError(ContainSubstring("invalid signature")))
+			})
+
+			It("should reject a replaced seat key", func() {
+				editRecord(id, func(r *correspondence.Record) { r.PlayerO.PublicKey = r.PlayerX.PublicKey })
+				_, _, err := alice.Load(id)
+				Expect(err).To(MatchError(ContainSubstring("invalid signature")))
+			})
+
+			It("should reject history truncated after it was seen", func() {
+				editRecord(id, func(r *correspondence.Record) { r.Moves = r.Moves[:1] })
+				_, _, err := bob.Load(id)
+				Expect(err).To(MatchError(ContainSubstring("truncated")))
+			})
+
+			It("should list tampered games with their error", func() {
+				editRecord(id, func(r *correspondence.Record) { r.Moves[1].Row = 2 })
+				games, err := alice.List()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(games[0].Err).To(HaveOccurred())
+			})
+		})
+
+		Describe("Export and Import", func() {
+			It("should move a game between directories as a blob", func() {
+				alice.Play(id, 1, 1)
+				blob, err := alice.Export(id)
+				Expect(err).ToNot(HaveOccurred())
+
+				otherDir := GinkgoT().TempDir()
+				stateDir := GinkgoT().TempDir()
+				identity, _ := correspondence.LoadOrCreateIdentity(filepath.Join(stateDir, "identity.json"), "dave")
+				dave, err := correspondence.New(otherDir, stateDir, identity)
+				Expect(err).ToNot(HaveOccurred())
+
+				record, err := dave.Import(blob)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(record.ID).To(Equal(id))
+
+				g, err := dave.Play(id, 2, 2)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(g.GetMoveHistory()).To(HaveLen(2))
+			})
+
+			It("should reject corrupted blobs", func() {
+				_, err := bob.Import("not-base64!")
+				Expect(err).To(HaveOccurred())
+			})
+		})
+	})
+})
@@ -0,0 +1,63 @@
+package correspondence
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Identity is the per-profile signing key used for correspondence moves
+type Identity struct {
+	Name       string             `json:"name"`
+	PublicKey  ed25519.PublicKey  `json:"public_key"`
+	PrivateKey ed25519.PrivateKey `json:"private_key"`
+}
+
+// NewIdentity generates a fresh signing key for name
+func NewIdentity(name string) (*Identity, error) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate key: %w", err)
+	}
+	return &Identity{Name: name, PublicKey: publicKey, PrivateKey: privateKey}, nil
+}
+
+// LoadOrCreateIdentity loads the identity at path, creating it on first use
+func LoadOrCreateIdentity(path, name string) (*Identity, error) {
+	data, err := os.ReadFile(path)
+	if err == nil {
+		var identity Identity
+		if err := json.Unmarshal(data, &identity); err != nil {
+			return nil, fmt.Errorf("failed to read identity %s: %w", path, err)
+		}
+		if len(identity.PrivateKey) != ed25519.PrivateKeySize {
+			return nil, fmt.Errorf("identity %s has an invalid key", path)
+		}
+		return &identity, nil
+	}
+	if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read identity %s: %w", path, err)
+	}
+
+	identity, err := NewIdentity(name)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err = json.MarshalIndent(identity, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal identity: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+	// The private key never leaves this profile
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return nil, fmt.Errorf("failed to write identity %s: %w", path, err)
+	}
+
+	return identity, nil
+}
@@ -0,0 +1,94 @@
+package game
+
+import (
+	"strings"
+	"time"
+)
+
+// MakeMoveOn places the mover's mark on the given board, counted from 0,
+// for Notakto on several boards
+func (g *Game) MakeMoveOn(board, row, col int) error {
+	return g.MakeMoveOnAt(board, row, col, time.Now())
+}
+
+// MakeMoveOnAt places the mover's mark on the given board at the given
+// wall-clock time, charging the mover's clock
+func (g *Game) MakeMoveOnAt(board, row, col int, now time.Time) error {
+	return g.place(board, row, col, g.Rules.Marks(g.CurrentPlayer)[0], now)
+}
+
+// Boards returns a copy of every board, the first being Board
+func (g *Game) Boards() [][3][3]Player {
+	return append([][3][3]Player{g.Board}, g.Extra...)
+}
+
+// BoardDead reports whether a board of a game on several boards has a
+// complete line, which takes it out of play
+func (g *Game) BoardDead(board int) bool {
+	if len(g.Extra) == 0 {
+		return false
+	}
+	cells := g.board(board)
+	for _, line := range lines {
+		if lineOwnerOn(cells, line) != Empty {
+			return true
+		}
+	}
+	return false
+}
+
+// LastBoard returns the board the last move was made on
+func (g *Game) LastBoard() int {
+	if len(g.MoveHistory) == 0 {
+		return 0
+	}
+	return g.OnBoard[len(g.MoveHistory)-1]
+}
+
+// BoardsNotation writes every board in the form accepted by ParsePosition,
+// separated by spaces
+func (g *Game) BoardsNotation() string {
+	if len(g.Extra) == 0 {
+		return g.Notation()
+	}
+	notations := []string{g.Notation()}
+	for _, board := range g.Extra {
+		extra := Game{Board: board}
+		notations = append(notations, extra.Notation())
+	}
+	return strings.Join(notations, " ")
+}
+
+// board returns the board with the given index to read or change
+func (g *Game) board(board int) *[3][3]Player {
+	if board == 0 {
+		return &g.Board
+	}
+	return &g.Extra[board-1]
+}
+
+// allBoardsDead reports whether every board has a complete line
+func (g *Game) allBoardsDead() bool {
+	for board := 0; board <= len(g.Extra); board++ {
+		if !g.BoardDead(board) {
+			return false
+		}
+	}
+	return true
+}
+
+// emptyBoards returns n boards with every cell empty, or nil for none
+func emptyBoards(n int) [][3][3]Player {
+	if n <= 0 {
+		return nil
+	}
+	boards := make([][3][3]Player, n)
+	for i := range boards {
+		for row := 0; row < 3; row++ {
+			for col := 0; col < 3; col++ {
+				boards[i][row][col] = Empty
+			}
+		}
+	}
+	return boards
+}
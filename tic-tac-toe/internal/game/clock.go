@@ -0,0 +1,155 @@
+package game
+
+import (
+	"fmt"
+	"time"
+)
+
+// TimeControl describes how much thinking time each player gets
+type TimeControl struct {
+	Name      string        `json:"name"`
+	Initial   time.Duration `json:"initial"`   // Starting time per player; zero means untimed
+	Increment time.Duration `json:"increment"` // Added to the mover's clock after each move (Fischer)
+	Delay     time.Duration `json:"delay"`     // Free thinking time per move before the clock runs (Bronstein/simple delay)
+}
+
+// TimeControls are the selectable time controls, untimed first
+var TimeControls = []TimeControl{
+	{Name: "untimed"},
+	{Name: "bullet", Initial: 15 * time.Second, Increment: 1 * time.Second},
+	{Name: "blitz", Initial: 1 * time.Minute, Increment: 2 * time.Second},
+	{Name: "rapid", Initial: 5 * time.Minute, Increment: 5 * time.Second},
+	{Name: "delay", Initial: 1 * time.Minute, Delay: 3 * time.Second},
+}
+
+// TimeControlByName looks up one of the TimeControls
+func TimeControlByName(name string) (TimeControl, bool) {
+	for _, tc := range TimeControls {
+		if tc.Name == name {
+			return tc, true
+		}
+	}
+	return TimeControl{}, false
+}
+
+// IsUntimed reports whether the time control has no clock
+func (tc TimeControl) IsUntimed() bool {
+	return tc.Initial <= 0
+}
+
+// String describes the time control, e.g. "blitz 1:00+2s"
+func (tc TimeControl) String() string {
+	if tc.IsUntimed() {
+		return tc.Name
+	}
+	desc := fmt.Sprintf("%s %s", tc.Name, FormatClock(tc.Initial))
+	if tc.Increment > 0 {
+		desc += fmt.Sprintf("+%ds", int(tc.Increment.Seconds()))
+	}
+	if tc.Delay > 0 {
+		desc += fmt.Sprintf(" d%ds", int(tc.Delay.Seconds()))
+	}
+	return desc
+}
+
+// Clock is a two-sided chess clock
+type Clock struct {
+	Control   TimeControl              `json:"control"`
+	Remaining map[Player]time.Duration `json:"remaining"`
+	Running   Player                   `json:"running"`    // Whose time is running
+	TurnStart time.Time                `json:"turn_start"` // Zero while the clock is paused
+}
+
+// NewClock creates a paused clock with X to move
+func NewClock(control TimeControl) *Clock {
+	return &Clock{
+		Control: control,
+		Remaining: map[Player]time.Duration{
+			PlayerX: control.Initial,
+			PlayerO: control.Initial,
+		},
+		Running: PlayerX,
+	}
+}
+
+// Start resumes the running player's time if the clock is paused
+func (c *Clock) Start(now time.Time) {
+	if c.TurnStart.IsZero() {
+		c.TurnStart = now
+	}
+}
+
+// Pause stops the clock, charging the running player for the time used so far
+func (c *Clock) Pause(now time.Time) {
+	c.Remaining[c.Running] -= c.used(now)
+	c.TurnStart = time.Time{}
+}
+
+// Press ends the running player's turn; it returns false if their time ran out
+func (c *Clock) Press(now time.Time) bool {
+	c.Remaining[c.Running] -= c.used(now)
+	if c.Remaining[c.Running] <= 0 {
+		c.Remaining[c.Running] = 0
+		c.TurnStart = time.Time{}
+		return false
+	}
+
+	c.Remaining[c.Running] += c.Control.Increment
+	c.Running = otherPlayer(c.Running)
+	c.TurnStart = now
+	return true
+}
+
+// RemainingAt returns a player's remaining time as of now
+func (c *Clock) RemainingAt(player Player, now time.Time) time.Duration {
+	remaining := c.Remaining[player]
+	if player == c.Running {
+		remaining -= c.used(now)
+	}
+	if remaining < 0 {
+		return 0
+	}
+	return remaining
+}
+
+// Copy returns an independent copy of the clock
+func (c *Clock) Copy() *Clock {
+	copied := *c
+	copied.Remaining = make(map[Player]time.Duration, len(c.Remaining))
+	for player, remaining := range c.Remaining {
+		copied.Remaining[player] = remaining
+	}
+	return &copied
+}
+
+// used returns the chargeable time of the current turn after any delay
+func (c *Clock) used(now time.Time) time.Duration {
+	if c.TurnStart.IsZero() {
+		return 0
+	}
+	elapsed := now.Sub(c.TurnStart) - c.Control.Delay
+	if elapsed < 0 {
+		return 0
+	}
+	return elapsed
+}
+
+// FormatClock formats a duration as m:ss, with tenths under ten seconds
+func FormatClock(d time.Duration) string {
+	if d < 0 {
+		d = 0
+	}
+	if d < 10*time.Second {
+		return fmt.Sprintf("0:%04.1f", d.Seconds())
+	}
+	d = d.Round(time.Second)
+	return fmt.Sprintf("%d:%02d", int(d.Minutes()), int(d.Seconds())%60)
+}
+
+// otherPlayer returns the opponent in a two-player game
+func otherPlayer(player Player) Player {
+	if player == PlayerX {
+		return PlayerO
+	}
+	return PlayerX
+}
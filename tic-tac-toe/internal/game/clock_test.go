@@ -0,0 +1,139 @@
+package game_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"tic-tac-toe/internal/game"
+)
+
+var _ = Describe("Clock", func() {
+	var start time.Time
+
+	BeforeEach(func() {
+		start = time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	})
+
+	Describe("TimeControlByName", func() {
+		It("should find the built-in time controls", func() {
+			tc, ok := game.TimeControlByName("blitz")
+			Expect(ok).To(BeTrue())
+			Expect(tc.Initial).To(Equal(time.Minute))
+			Expect(tc.Increment).To(Equal(2 * time.Second))
+		})
+
+		It("should reject unknown names", func() {
+			_, ok := game.TimeControlByName("glacial")
+			Expect(ok).To(BeFalse())
+		})
+
+		It("should treat untimed as having no clock", func() {
+			tc, _ := game.TimeControlByName("untimed")
+			Expect(tc.IsUntimed()).To(BeTrue())
+			Expect(tc.String()).To(Equal("untimed"))
+		})
+	})
+
+	Describe("Press", func() {
+		It("should charge the mover and add the increment", func() {
+			clock := game.NewClock(game.TimeControl{Initial: time.Minute, Increment: 2 * time.Second})
+			clock.Start(start)
+
+			Expect(clock.Press(start.Add(10 * time.Second))).To(BeTrue())
+			Expect(clock.Remaining[game.PlayerX]).To(Equal(52 * time.Second))
+			Expect(clock.Running).To(Equal(game.PlayerO))
+		})
+
+		It("should not charge time within the delay", func() {
+			clock := game.NewClock(game.TimeControl{Initial: time.Minute, Delay: 3 * time.Second})
+			clock.Start(start)
+
+			clock.Press(start.Add(2 * time.Second))
+			Expect(clock.Remaining[game.PlayerX]).To(Equal(time.Minute))
+
+			clock.Press(start.Add(7 * time.Second))
+			Expect(clock.Remaining[game.PlayerO]).To(Equal(58 * time.Second))
+		})
+
+		It("should report a flag when time runs out", func() {
+			clock := game.NewClock(game.TimeControl{Initial: 5 * time.Second})
+			clock.Start(start)
+
+			Expect(clock.Press(start.Add(6 * time.Second))).To(BeFalse())
+			Expect(clock.Remaining[game.PlayerX]).To(Equal(time.Duration(0)))
+		})
+	})
+
+	Describe("Pause", func() {
+		It("should stop the running clock", func() {
+			clock := game.NewClock(game.TimeControl{Initial: time.Minute})
+			clock.Start(start)
+			clock.Pause(start.Add(20 * time.Second))
+
+			Expect(clock.RemainingAt(game.PlayerX, start.Add(time.Hour))).To(Equal(40 * time.Second))
+		})
+	})
+
+	Describe("FormatClock", func() {
+		It("should format minutes and seconds", func() {
+			Expect(game.FormatClock(5*time.Minute + 7*time.Second)).To(Equal("5:07"))
+		})
+
+		It("should show tenths under ten seconds", func() {
+			Expect(game.FormatClock(4500 * time.Millisecond)).To(Equal("0:04.5"))
+		})
+
+		It("should clamp negative durations", func() {
+			Expect(game.FormatClock(-time.Second)).To(Equal("0:00.0"))
+		})
+	})
+
+	Describe("Timed games", func() {
+		var g *game.Game
+
+		BeforeEach(func() {
+			g = game.New()
+			g.SetTimeControl(game.TimeControl{Name: "test", Initial: 10 * time.Second, Increment: time.Second})
+			g.StartClock(start)
+		})
+
+		It("should have no clock when untimed", func() {
+			g.SetTimeControl(game.TimeControl{Name: "untimed"})
+			Expect(g.Clock).To(BeNil())
+			Expect(g.CheckTime(start.Add(time.Hour))).To(BeFalse())
+		})
+
+		It("should charge moves made in time", func() {
+			Expect(g.MakeMoveAt(0, 0, start.Add(4*time.Second))).To(Succeed())
+			Expect(g.GetRemainingTime(game.PlayerX, start.Add(4*time.Second))).To(Equal(7 * time.Second))
+			Expect(g.GetRemainingTime(game.PlayerO, start.Add(6*time.Second))).To(Equal(8 * time.Second))
+		})
+
+		It("should lose on time when moving after the flag", func() {
+			err := g.MakeMoveAt(0, 0, start.Add(11*time.Second))
+			Expect(err).To(HaveOccurred())
+			Expect(g.GetStatus()).To(Equal(game.StatusWon))
+			Expect(g.GetWinner()).To(Equal(game.PlayerO))
+			Expect(g.TimeLoss).To(BeTrue())
+			Expect(g.GetBoard()[0][0]).To(Equal(game.Empty))
+		})
+
+		It("should flag the player to move on CheckTime", func() {
+			Expect(g.CheckTime(start.Add(5 * time.Second))).To(BeFalse())
+			Expect(g.CheckTime(start.Add(10 * time.Second))).To(BeTrue())
+			Expect(g.GetWinner()).To(Equal(game.PlayerO))
+			Expect(g.TimeLoss).To(BeTrue())
+		})
+
+		It("should restore fresh clocks on Reset", func() {
+			g.MakeMoveAt(0, 0, start.Add(4*time.Second))
+			g.Reset()
+
+			Expect(g.Clock).NotTo(BeNil())
+			Expect(g.TimeLoss).To(BeFalse())
+			Expect(g.GetRemainingTime(game.PlayerX, start)).To(Equal(10 * time.Second))
+		})
+	})
+})
@@ -0,0 +1,125 @@
+package game
+
+// EventType names a change to a game that subscribers can react to
+type EventType int
+
+const (
+	// EventMoveMade follows every placed or moved mark
+	EventMoveMade EventType = iota
+	// EventGameWon follows the move that won the game, or a loss on time
+	EventGameWon
+	// EventGameDrawn follows the move that drew the game
+	EventGameDrawn
+	// EventReset follows clearing the board for a new game
+	EventReset
+	// EventUndo follows taking moves back
+	EventUndo
+)
+
+// String names the event type for logs
+func (t EventType) String() string {
+	switch t {
+	case EventMoveMade:
+		return "move made"
+	case EventGameWon:
+		return "game won"
+	case EventGameDrawn:
+		return "game drawn"
+	case EventReset:
+		return "reset"
+	case EventUndo:
+		return "undo"
+	default:
+		return "unknown"
+	}
+}
+
+// Event is published to a game's subscribers once the change it describes
+// is complete, so handlers see the game as it now stands
+type Event struct {
+	Type   EventType
+	Game   *Game  // The game it happened to
+	Player Player // The mover for EventMoveMade, the winner for EventGameWon
+	Move   Move   // The move made, for EventMoveMade
+	Plies  int    // How many moves were taken back, for EventUndo
+	Lines  []Line // The lines that decided it, for EventGameWon
+}
+
+// Handler reacts to a game event
+type Handler func(Event)
+
+// Bus delivers game events to its subscribers in the order they subscribed.
+// The zero value is ready to use; a Bus is not safe for concurrent use.
+type Bus struct {
+	subscriptions []subscription
+	next          int
+}
+
+type subscription struct {
+	id     int
+	handle Handler
+}
+
+// Subscribe registers handler for every event published on the bus and
+// returns a function that removes it again
+func (b *Bus) Subscribe(handler Handler) (unsubscribe func()) {
+	b.next++
+	id := b.next
+	b.subscriptions = append(b.subscriptions, subscription{id: id, handle: handler})
+	return func() {
+		for i, s := range b.subscriptions {
+			if s.id == id {
+				b.subscriptions = append(b.subscriptions[:i], b.subscriptions[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// Publish delivers event to the current subscribers; publishing on a nil
+// bus does nothing
+func (b *Bus) Publish(event Event) {
+	if b == nil {
+		return
+	}
+	// Handlers may subscribe or unsubscribe while the event is delivered
+	for _, s := range append([]subscription(nil), b.subscriptions...) {
+		s.handle(event)
+	}
+}
+
+// SetEvents makes the game publish its events on bus, which several games
+// may share; nil stops publishing
+func (g *Game) SetEvents(bus *Bus) {
+	g.events = bus
+}
+
+// Events returns the bus the game publishes on, or nil if it has none
+func (g *Game) Events() *Bus {
+	return g.events
+}
+
+// Subscribe registers handler for the game's events, giving the game a bus
+// of its own if it has none, and returns a function that removes it again
+func (g *Game) Subscribe(handler Handler) (unsubscribe func()) {
+	if g.events == nil {
+		g.events = &Bus{}
+	}
+	return g.events.Subscribe(handler)
+}
+
+// publish sends an event about the game to its subscribers
+func (g *Game) publish(event Event) {
+	event.Game = g
+	g.events.Publish(event)
+}
+
+// publishResult announces the end of the game, if it has ended
+func (g *Game) publishResult() {
+	switch g.Status {
+	case StatusWon:
+		g.publish(Event{Type: EventGameWon, Player: g.Winner, Lines: g.WinningLines()})
+	case StatusDraw:
+		g.publish(Event{Type: EventGameDrawn})
+	}
+}
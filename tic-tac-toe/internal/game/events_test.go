@@ -0,0 +1,94 @@
+package game_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"tic-tac-toe/internal/game"
+)
+
+var _ = Describe("Game events", func() {
+	var (
+		g      *game.Game
+		events []game.Event
+	)
+
+	BeforeEach(func() {
+		g = game.New()
+		events = nil
+		g.Subscribe(func(event game.Event) {
+			events = append(events, event)
+		})
+	})
+
+	types := func() []game.EventType {
+		var types []game.EventType
+		for _, event := range events {
+			types = append(types, event.Type)
+		}
+		return types
+	}
+
+	It("should publish each move and then the win", func() {
+		for _, p := range []game.Position{{Row: 0, Col: 0}, {Row: 1, Col: 0}, {Row: 0, Col: 1}, {Row: 1, Col: 1}, {Row: 0, Col: 2}} {
+			Expect(g.MakeMove(p.Row, p.Col)).To(Succeed())
+		}
+		Expect(types()).To(Equal([]game.EventType{
+			game.EventMoveMade, game.EventMoveMade, game.EventMoveMade, game.EventMoveMade, game.EventMoveMade, game.EventGameWon,
+		}))
+		Expect(events[1].Player).To(Equal(game.PlayerO))
+		Expect(events[1].Move).To(Equal(game.Move{Row: 1, Col: 0, Mark: game.PlayerO}))
+		Expect(events[5].Player).To(Equal(game.PlayerX))
+		Expect(events[5].Game).To(BeIdenticalTo(g))
+	})
+
+	It("should publish a draw", func() {
+		g.Board = [3][3]game.Player{
+			{game.PlayerX, game.PlayerO, game.PlayerX},
+			{game.PlayerX, game.PlayerO, game.PlayerO},
+			{game.PlayerO, game.PlayerX, game.Empty},
+		}
+		Expect(g.MakeMove(2, 2)).To(Succeed())
+		Expect(types()).To(Equal([]game.EventType{game.EventMoveMade, game.EventGameDrawn}))
+	})
+
+	It("should take moves back and publish the undo", func() {
+		Expect(g.ApplySetup(&game.Setup{Name: "Blocked center", Position: ".../.#./..."})).To(Succeed())
+		for _, p := range []game.Position{{Row: 0, Col: 0}, {Row: 1, Col: 0}, {Row: 0, Col: 1}} {
+			Expect(g.MakeMove(p.Row, p.Col)).To(Succeed())
+		}
+		events = nil
+
+		Expect(g.Undo(2)).To(Succeed())
+		Expect(types()).To(Equal([]game.EventType{game.EventUndo}))
+		Expect(events[0].Plies).To(Equal(2))
+		Expect(g.GetMoveHistory()).To(Equal([]game.Position{{Row: 0, Col: 0}}))
+		Expect(g.GetBoard()[1][1]).To(Equal(game.Blocked))
+		Expect(g.GetCurrentPlayer()).To(Equal(game.PlayerO))
+
+		Expect(g.Undo(2)).ToNot(Succeed())
+		g.SetSwapRule(game.PieRule)
+		Expect(g.Undo(1)).To(MatchError("games with a swap rule cannot be undone"))
+	})
+
+	It("should publish resets and stop after unsubscribing", func() {
+		var resets int
+		unsubscribe := g.Subscribe(func(event game.Event) {
+			if event.Type == game.EventReset {
+				resets++
+			}
+		})
+		g.Reset()
+		unsubscribe()
+		g.Reset()
+		Expect(resets).To(Equal(1))
+		Expect(types()).To(Equal([]game.EventType{game.EventReset, game.EventReset}))
+	})
+
+	It("should keep clones quiet so searches do not reach subscribers", func() {
+		clone := g.Clone()
+		Expect(clone.MakeMove(1, 1)).To(Succeed())
+		Expect(events).To(BeEmpty())
+		Expect(clone.Events()).To(BeNil())
+	})
+})
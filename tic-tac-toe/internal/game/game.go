@@ -1,6 +1,9 @@
 package game
 
-import "fmt"
+import (
+	"fmt"
+	"time"
+)
 
 // Player represents a game player
 type Player string
@@ -17,6 +20,8 @@ type GameMode int
 const (
 	PlayerVsPlayer GameMode = iota
 	PlayerVsAI
+	PlayerVsNetwork
+	PlayerVsCorrespondence
 )
 
 // GameStatus represents the current state of the game
@@ -36,12 +41,23 @@ type Position struct {
 
 // Game represents the tic-tac-toe game state
 type Game struct {
-	Board         [3][3]Player `json:"board"`
-	CurrentPlayer Player       `json:"current_player"`
-	Status        GameStatus   `json:"status"`
-	Winner        Player       `json:"winner"`
-	Mode          GameMode     `json:"mode"`
-	MoveHistory   []Position   `json:"move_history"`
+	Board         [3][3]Player     `json:"board"`
+	CurrentPlayer Player           `json:"current_player"`
+	Status        GameStatus       `json:"status"`
+	Winner        Player           `json:"winner"`
+	Mode          GameMode         `json:"mode"`
+	MoveHistory   []Position       `json:"move_history"`
+	Clock         *Clock           `json:"clock,omitempty"`     // Nil for untimed games
+	TimeLoss      bool             `json:"time_loss,omitempty"` // The loser ran out of time
+	Rules         Rules            `json:"rules"`
+	Marks         []Player         `json:"marks,omitempty"`  // Mark placed by each move, kept only when players choose marks
+	Slides        map[int]Position `json:"slides,omitempty"` // Where each move that moved a mark took it from, by ply
+	Setup         *Setup           `json:"setup,omitempty"`  // The starting position; nil for the empty board
+	Opening       Opening          `json:"opening"`          // The swap rule and the seats' colors
+	Extra         [][3][3]Player   `json:"extra,omitempty"`    // The boards after the first, for Notakto on several boards
+	OnBoard       map[int]int      `json:"on_board,omitempty"` // The board of each move off the first board, by ply
+
+	events *Bus // Where the game's events are published; clones get none
 }
 
 // New creates a new game instance
@@ -60,26 +76,94 @@ func New() *Game {
 		Winner:        Empty,
 		Mode:          PlayerVsPlayer,
 		MoveHistory:   make([]Position, 0),
+		Rules:         ClassicRules,
 	}
 }
 
 // MakeMove attempts to make a move at the specified position
 func (g *Game) MakeMove(row, col int) error {
+	return g.MakeMoveAt(row, col, time.Now())
+}
+
+// MakeMoveAt makes a move at the given wall-clock time, charging the mover's clock
+func (g *Game) MakeMoveAt(row, col int, now time.Time) error {
+	return g.MakeMoveMarkAt(row, col, g.Rules.Marks(g.CurrentPlayer)[0], now)
+}
+
+// MakeMoveMark places the given mark, for rules that let players choose
+func (g *Game) MakeMoveMark(row, col int, mark Player) error {
+	return g.MakeMoveMarkAt(row, col, mark, time.Now())
+}
+
+// MakeMoveMarkAt places mark at the given wall-clock time, charging the mover's clock
+func (g *Game) MakeMoveMarkAt(row, col int, mark Player, now time.Time) error {
+	return g.place(0, row, col, mark, now)
+}
+
+// place puts mark on a cell of the given board, charging the mover's clock
+func (g *Game) place(board, row, col int, mark Player, now time.Time) error {
 	if g.Status != StatusPlaying {
 		return fmt.Errorf("game is not in playing state")
 	}
 
+	if board < 0 || board >= g.Rules.BoardCount() {
+		return fmt.Errorf("invalid board: %d", board+1)
+	}
+
 	if row < 0 || row > 2 || col < 0 || col > 2 {
 		return fmt.Errorf("invalid position: (%d, %d)", row, col)
 	}
 
-	if g.Board[row][col] != Empty {
+	if seat, _, pending := g.SwapPending(); pending {
+		return fmt.Errorf("%s must decide whether to swap sides first", seat)
+	}
+
+	if g.BoardDead(board) {
+		return fmt.Errorf("board %d has a line and is out of play", board+1)
+	}
+
+	cells := g.board(board)
+	if cells[row][col] == Blocked {
+		return fmt.Errorf("position (%d, %d) is blocked", row, col)
+	}
+
+	if cells[row][col] != Empty {
 		return fmt.Errorf("position (%d, %d) is already occupied", row, col)
 	}
 
+	if !g.CanPlace(mark) {
+		return fmt.Errorf("%s cannot place %s under %s rules", g.CurrentPlayer, mark, g.Rules.Variant.Name())
+	}
+
+	if g.MustSlide() {
+		return fmt.Errorf("all %d %s marks are placed; move one instead", g.Rules.Pieces(), g.CurrentPlayer)
+	}
+
+	if g.Clock != nil && !g.Clock.Press(now) {
+		g.loseOnTime()
+		return fmt.Errorf("player %s ran out of time", g.CurrentPlayer)
+	}
+
 	// Make the move
-	g.Board[row][col] = g.CurrentPlayer
+	cells[row][col] = mark
+	if board > 0 {
+		if g.OnBoard == nil {
+			g.OnBoard = map[int]int{}
+		}
+		g.OnBoard[len(g.MoveHistory)] = board
+	}
 	g.MoveHistory = append(g.MoveHistory, Position{Row: row, Col: col})
+	if g.Rules.ChoosesMarks() {
+		g.Marks = append(g.Marks, mark)
+	}
+
+	g.finishMove()
+	return nil
+}
+
+// finishMove decides the game after a move and passes the turn if it goes on
+func (g *Game) finishMove() {
+	ply, mover := len(g.MoveHistory)-1, g.CurrentPlayer
 
 	// Check for win or draw
 	g.checkGameStatus()
@@ -87,9 +171,14 @@ func (g *Game) MakeMove(row, col int) error {
 	// Switch players if game is still playing
 	if g.Status == StatusPlaying {
 		g.switchPlayer()
+		g.checkMovingStatus()
+	}
+	if g.Status != StatusPlaying && g.Clock != nil {
+		g.Clock.TurnStart = time.Time{}
 	}
 
-	return nil
+	g.publish(Event{Type: EventMoveMade, Player: mover, Move: g.MoveAt(ply)})
+	g.publishResult()
 }
 
 // Reset resets the game to initial state
@@ -103,6 +192,120 @@ func (g *Game) Reset() {
 	g.Status = StatusPlaying
 	g.Winner = Empty
 	g.MoveHistory = make([]Position, 0)
+	g.Marks = nil
+	g.Slides = nil
+	g.Extra = emptyBoards(len(g.Extra))
+	g.OnBoard = nil
+	g.Setup = nil
+	g.Opening = Opening{Rule: g.Opening.Rule}
+	g.TimeLoss = false
+	if g.Clock != nil {
+		g.Clock = NewClock(g.Clock.Control)
+	}
+	g.publish(Event{Type: EventReset})
+}
+
+// Undo takes back the last plies moves by replaying the others from the
+// starting position. Timed games and games under a swap rule, whose clocks
+// and decisions cannot be replayed, cannot be undone.
+func (g *Game) Undo(plies int) error {
+	switch {
+	case plies < 1 || plies > len(g.MoveHistory):
+		return fmt.Errorf("cannot take back %d of %d moves", plies, len(g.MoveHistory))
+	case g.Clock != nil:
+		return fmt.Errorf("timed games cannot be undone")
+	case g.Opening.Rule != NoSwap:
+		return fmt.Errorf("games with a swap rule cannot be undone")
+	}
+
+	replay := New()
+	replay.Mode = g.Mode
+	replay.Rules = g.Rules
+	replay.Extra = emptyBoards(len(g.Extra))
+	if err := replay.ApplySetup(g.Setup); err != nil {
+		return err
+	}
+	for ply := 0; ply < len(g.MoveHistory)-plies; ply++ {
+		if err := replay.Play(g.MoveAt(ply)); err != nil {
+			return fmt.Errorf("failed to replay move %d: %w", ply+1, err)
+		}
+	}
+	replay.events = g.events
+	*g = *replay
+	g.publish(Event{Type: EventUndo, Plies: plies})
+	return nil
+}
+
+// Clone returns an independent copy of the game, clock included
+func (g *Game) Clone() *Game {
+	clone := *g
+	clone.events = nil
+	clone.MoveHistory = append([]Position(nil), g.MoveHistory...)
+	clone.Marks = append([]Player(nil), g.Marks...)
+	if g.Slides != nil {
+		clone.Slides = make(map[int]Position, len(g.Slides))
+		for ply, from := range g.Slides {
+			clone.Slides[ply] = from
+		}
+	}
+	clone.Extra = append([][3][3]Player(nil), g.Extra...)
+	if g.OnBoard != nil {
+		clone.OnBoard = make(map[int]int, len(g.OnBoard))
+		for ply, board := range g.OnBoard {
+			clone.OnBoard[ply] = board
+		}
+	}
+	if g.Clock != nil {
+		clone.Clock = g.Clock.Copy()
+	}
+	return &clone
+}
+
+// SetTimeControl sets the clocks for a new game; untimed controls remove the clock
+func (g *Game) SetTimeControl(control TimeControl) {
+	if control.IsUntimed() {
+		g.Clock = nil
+		return
+	}
+	g.Clock = NewClock(control)
+	g.Clock.Running = g.CurrentPlayer
+}
+
+// StartClock starts or resumes the clock of the player to move
+func (g *Game) StartClock(now time.Time) {
+	if g.Clock != nil && g.Status == StatusPlaying {
+		g.Clock.Start(now)
+	}
+}
+
+// CheckTime ends the game if the player to move has run out of time
+func (g *Game) CheckTime(now time.Time) bool {
+	if g.Clock == nil || g.Status != StatusPlaying {
+		return false
+	}
+	if g.Clock.RemainingAt(g.CurrentPlayer, now) > 0 {
+		return false
+	}
+	g.Clock.Pause(now)
+	g.Clock.Remaining[g.CurrentPlayer] = 0
+	g.loseOnTime()
+	return true
+}
+
+// GetRemainingTime returns a player's clock as of now, or zero for untimed games
+func (g *Game) GetRemainingTime(player Player, now time.Time) time.Duration {
+	if g.Clock == nil {
+		return 0
+	}
+	return g.Clock.RemainingAt(player, now)
+}
+
+// loseOnTime awards the game to the opponent of the player to move
+func (g *Game) loseOnTime() {
+	g.Status = StatusWon
+	g.Winner = otherPlayer(g.CurrentPlayer)
+	g.TimeLoss = true
+	g.publishResult()
 }
 
 // GetBoard returns a copy of the current board
@@ -151,6 +354,16 @@ func (g *Game) switchPlayer() {
 
 // checkGameStatus checks if the game has ended (win or draw)
 func (g *Game) checkGameStatus() {
+	if len(g.Extra) > 0 {
+		// Boards without blocked cells cannot fill up without a line
+		g.Status, g.Winner = g.Rules.outcome(g.CurrentPlayer, g.allBoardsDead(), false)
+		return
+	}
+	if !g.Rules.IsClassic() {
+		g.Status, g.Winner = g.Rules.outcome(g.CurrentPlayer, g.checkWinner() != Empty, g.isBoardFull())
+		return
+	}
+
 	// Check for win
 	if winner := g.checkWinner(); winner != Empty {
 		g.Status = StatusWon
@@ -165,40 +378,80 @@ func (g *Game) checkGameStatus() {
 	}
 }
 
+// Line is three cells in a row, column or diagonal of the board
+type Line [3]Position
+
+// lines are the board's rows, columns and diagonals, in that order
+var lines = [8]Line{
+	{{0, 0}, {0, 1}, {0, 2}}, {{1, 0}, {1, 1}, {1, 2}}, {{2, 0}, {2, 1}, {2, 2}},
+	{{0, 0}, {1, 0}, {2, 0}}, {{0, 1}, {1, 1}, {2, 1}}, {{0, 2}, {1, 2}, {2, 2}},
+	{{0, 0}, {1, 1}, {2, 2}}, {{0, 2}, {1, 1}, {2, 0}},
+}
+
+// lineOwner returns the mark filling the whole line, or Empty
+func (g *Game) lineOwner(line Line) Player {
+	return lineOwnerOn(&g.Board, line)
+}
+
+// lineOwnerOn returns the mark filling the whole line of board, or Empty
+func lineOwnerOn(board *[3][3]Player, line Line) Player {
+	first := board[line[0].Row][line[0].Col]
+	if first == Empty || first == Blocked {
+		return Empty
+	}
+	for _, p := range line[1:] {
+		if board[p.Row][p.Col] != first {
+			return Empty
+		}
+	}
+	return first
+}
+
 // checkWinner checks if there's a winner
 func (g *Game) checkWinner() Player {
-	// Check rows
-	for row := 0; row < 3; row++ {
-		if g.Board[row][0] != Empty &&
-			g.Board[row][0] == g.Board[row][1] &&
-			g.Board[row][1] == g.Board[row][2] {
-			return g.Board[row][0]
+	for _, line := range lines {
+		if owner := g.lineOwner(line); owner != Empty {
+			return owner
 		}
 	}
+	return Empty
+}
 
-	// Check columns
-	for col := 0; col < 3; col++ {
-		if g.Board[0][col] != Empty &&
-			g.Board[0][col] == g.Board[1][col] &&
-			g.Board[1][col] == g.Board[2][col] {
-			return g.Board[0][col]
+// WinningLines returns the complete lines that decided a won game, in board
+// order; one move can complete several at once. Under misère rules they are
+// the loser's. On several boards they are on the last move's board,
+// LastBoard. Games won otherwise, such as on time, have none.
+func (g *Game) WinningLines() []Line {
+	if g.Status != StatusWon || g.TimeLoss {
+		return nil
+	}
+	board := g.board(g.LastBoard())
+	var won []Line
+	for _, line := range lines {
+		if lineOwnerOn(board, line) != Empty {
+			won = append(won, line)
 		}
 	}
+	return won
+}
 
-	// Check diagonals
-	if g.Board[0][0] != Empty &&
-		g.Board[0][0] == g.Board[1][1] &&
-		g.Board[1][1] == g.Board[2][2] {
-		return g.Board[0][0]
+// WinningCells returns the cells of all winning lines, in board order
+func (g *Game) WinningCells() []Position {
+	var on [3][3]bool
+	for _, line := range g.WinningLines() {
+		for _, p := range line {
+			on[p.Row][p.Col] = true
+		}
 	}
-
-	if g.Board[0][2] != Empty &&
-		g.Board[0][2] == g.Board[1][1] &&
-		g.Board[1][1] == g.Board[2][0] {
-		return g.Board[0][2]
+	var cells []Position
+	for row := 0; row < 3; row++ {
+		for col := 0; col < 3; col++ {
+			if on[row][col] {
+				cells = append(cells, Position{Row: row, Col: col})
+			}
+		}
 	}
-
-	return Empty
+	return cells
 }
 
 // isBoardFull checks if the board is full
@@ -213,12 +466,17 @@ func (g *Game) isBoardFull() bool {
 	return true
 }
 
-// GetAvailableMoves returns all available positions
+// GetAvailableMoves returns all available positions on the first board
 func (g *Game) GetAvailableMoves() []Position {
+	return emptyCells(&g.Board)
+}
+
+// emptyCells returns the empty cells of board in board order
+func emptyCells(board *[3][3]Player) []Position {
 	var moves []Position
 	for row := 0; row < 3; row++ {
 		for col := 0; col < 3; col++ {
-			if g.Board[row][col] == Empty {
+			if board[row][col] == Empty {
 				moves = append(moves, Position{Row: row, Col: col})
 			}
 		}
@@ -1,6 +1,8 @@
 package game_test
 
 import (
+	"time"
+
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
@@ -130,6 +132,23 @@ var _ = Describe("Game", func() {
 		})
 	})
 
+	Describe("Clone", func() {
+		It("should copy the game without sharing state", func() {
+			g.SetTimeControl(game.TimeControl{Name: "test", Initial: 10 * time.Second})
+			g.MakeMove(0, 0)
+
+			clone := g.Clone()
+			Expect(clone.GetBoard()).To(Equal(g.GetBoard()))
+			Expect(clone.GetMoveHistory()).To(Equal(g.GetMoveHistory()))
+
+			clone.MakeMove(1, 1)
+			clone.Clock.Remaining[game.PlayerX] = 0
+			Expect(g.GetMoveHistory()).To(HaveLen(1))
+			Expect(g.GetBoard()[1][1]).To(Equal(game.Empty))
+			Expect(g.Clock.Remaining[game.PlayerX]).To(Equal(10 * time.Second))
+		})
+	})
+
 	Describe("IsValidMove", func() {
 		It("should return true for valid moves", func() {
 			Expect(g.IsValidMove(0, 0)).To(BeTrue())
@@ -164,4 +183,31 @@ var _ = Describe("Game", func() {
 			Expect(len(moves)).To(Equal(7))
 		})
 	})
+
+	Describe("WinningLines", func() {
+		It("should report the line that won", func() {
+			g, err := game.ParsePosition("XXX/OO./...")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(g.WinningLines()).To(Equal([]game.Line{{{Row: 0, Col: 0}, {Row: 0, Col: 1}, {Row: 0, Col: 2}}}))
+		})
+
+		It("should report every line one move completes", func() {
+			g, err := game.ParsePosition("X.X/OXO/OXO")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(g.MakeMove(0, 1)).To(Succeed())
+
+			Expect(g.WinningLines()).To(HaveLen(2))
+			Expect(g.WinningCells()).To(Equal([]game.Position{
+				{Row: 0, Col: 0}, {Row: 0, Col: 1}, {Row: 0, Col: 2}, {Row: 1, Col: 1}, {Row: 2, Col: 1},
+			}))
+		})
+
+		It("should report no lines for games still playing or drawn", func() {
+			Expect(g.WinningLines()).To(BeEmpty())
+			drawn, err := game.ParsePosition("XOX/XOO/OXX")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(drawn.GetStatus()).To(Equal(game.StatusDraw))
+			Expect(drawn.WinningCells()).To(BeEmpty())
+		})
+	})
 })
\ No newline at end of file
@@ -0,0 +1,251 @@
+package game
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// RepetitionLimit is how many times the same position may occur, with the
+// same player to move, before a game of moving marks is drawn
+const RepetitionLimit = 3
+
+// MustSlide reports whether the player to move has placed all their marks
+// and must move one of them instead
+func (g *Game) MustSlide() bool {
+	pieces := g.Rules.Pieces()
+	return pieces > 0 && g.count(g.CurrentPlayer) >= pieces
+}
+
+// MakeSlide moves one of the current player's marks to an empty cell
+func (g *Game) MakeSlide(from, to Position) error {
+	return g.MakeSlideAt(from, to, time.Now())
+}
+
+// MakeSlideAt moves a mark at the given wall-clock time, charging the mover's clock
+func (g *Game) MakeSlideAt(from, to Position, now time.Time) error {
+	if g.Status != StatusPlaying {
+		return fmt.Errorf("game is not in playing state")
+	}
+
+	if g.Rules.Pieces() == 0 {
+		return fmt.Errorf("marks cannot move under %s rules", g.Rules.Variant.Name())
+	}
+
+	if !g.MustSlide() {
+		return fmt.Errorf("%s must place all %d marks before moving one", g.CurrentPlayer, g.Rules.Pieces())
+	}
+
+	if seat, _, pending := g.SwapPending(); pending {
+		return fmt.Errorf("%s must decide whether to swap sides first", seat)
+	}
+
+	for _, pos := range []Position{from, to} {
+		if pos.Row < 0 || pos.Row > 2 || pos.Col < 0 || pos.Col > 2 {
+			return fmt.Errorf("invalid position: (%d, %d)", pos.Row, pos.Col)
+		}
+	}
+
+	if g.Board[from.Row][from.Col] != g.CurrentPlayer {
+		return fmt.Errorf("no %s mark at (%d, %d)", g.CurrentPlayer, from.Row, from.Col)
+	}
+
+	if g.Board[to.Row][to.Col] != Empty {
+		return fmt.Errorf("position (%d, %d) is already occupied", to.Row, to.Col)
+	}
+
+	if !g.Rules.CanSlide(from, to) {
+		return fmt.Errorf("cannot move from (%d, %d) to (%d, %d) under %s rules",
+			from.Row, from.Col, to.Row, to.Col, g.Rules.Variant.Name())
+	}
+
+	if g.Clock != nil && !g.Clock.Press(now) {
+		g.loseOnTime()
+		return fmt.Errorf("player %s ran out of time", g.CurrentPlayer)
+	}
+
+	g.Board[from.Row][from.Col] = Empty
+	g.Board[to.Row][to.Col] = g.CurrentPlayer
+	if g.Slides == nil {
+		g.Slides = map[int]Position{}
+	}
+	g.Slides[len(g.MoveHistory)] = from
+	g.MoveHistory = append(g.MoveHistory, to)
+
+	g.finishMove()
+	return nil
+}
+
+// MoveAt returns the move made by the given ply of the move history
+func (g *Game) MoveAt(ply int) Move {
+	to := g.MoveHistory[ply]
+	move := Move{Row: to.Row, Col: to.Col, Mark: g.MarkAt(ply), Board: g.OnBoard[ply]}
+	if from, ok := g.Slides[ply]; ok {
+		move.Kind = Slide
+		move.From = from
+	}
+	return move
+}
+
+// legalSlides lists every move of one of the current player's marks to an
+// empty cell the rules allow, in board order
+func (g *Game) legalSlides() []Move {
+	var moves []Move
+	for row := 0; row < 3; row++ {
+		for col := 0; col < 3; col++ {
+			if g.Board[row][col] != g.CurrentPlayer {
+				continue
+			}
+			from := Position{Row: row, Col: col}
+			for _, to := range g.GetAvailableMoves() {
+				if g.Rules.CanSlide(from, to) {
+					moves = append(moves, Move{Kind: Slide, From: from, Row: to.Row, Col: to.Col, Mark: g.CurrentPlayer})
+				}
+			}
+		}
+	}
+	return moves
+}
+
+// checkMovingStatus ends a game of moving marks once the player to move is
+// blocked, which loses, or the position has repeated too often, which draws
+func (g *Game) checkMovingStatus() {
+	if g.Rules.Pieces() == 0 || g.Status != StatusPlaying {
+		return
+	}
+	if g.MustSlide() && len(g.legalSlides()) == 0 {
+		g.Status = StatusWon
+		g.Winner = otherPlayer(g.CurrentPlayer)
+		return
+	}
+	if g.repetitions() >= RepetitionLimit {
+		g.Status = StatusDraw
+	}
+}
+
+// repetitions counts how often the current position, with the same player
+// to move, has occurred
+func (g *Game) repetitions() int {
+	count := 0
+	current := position{board: g.Board, player: g.CurrentPlayer}
+	for _, p := range g.positions() {
+		if p == current {
+			count++
+		}
+	}
+	return count
+}
+
+// RepetitionKey encodes how often each position that could be repeated up
+// to the limit within the next plies has occurred. Games with the same
+// board, player to move and key are drawn by repetition at the same moments
+// over those plies, so a search looking that far may score them alike.
+func (g *Game) RepetitionKey(plies int) string {
+	if g.Rules.Pieces() == 0 {
+		return ""
+	}
+	counts := map[position]int{}
+	for _, p := range g.positions() {
+		counts[p]++
+	}
+
+	current := position{board: g.Board, player: g.CurrentPlayer}
+	var keys []string
+	for p, n := range counts {
+		// Leaving a position and coming back takes at least four plies
+		distance := current.distance(p)
+		if distance < 0 || distance+4*(RepetitionLimit-1-n) > plies {
+			continue
+		}
+		var key strings.Builder
+		for row := range p.board {
+			for _, cell := range p.board[row] {
+				key.WriteString(string(cell))
+			}
+		}
+		fmt.Fprintf(&key, "%s%d", p.player, n)
+		keys = append(keys, key.String())
+	}
+	sort.Strings(keys)
+	return strings.Join(keys, "/")
+}
+
+// position is a board with the player to move, as repetitions count it
+type position struct {
+	board  [3][3]Player
+	player Player
+}
+
+// distance is the fewest plies that could lead from p to other, or -1 if
+// none can: each ply places or moves one of the mover's marks, the players
+// take turns, and marks are never taken off
+func (p position) distance(other position) int {
+	arrive, leave := map[Player]int{}, map[Player]int{}
+	for row := range p.board {
+		for col, cell := range p.board[row] {
+			if target := other.board[row][col]; cell != target {
+				arrive[target]++
+				leave[cell]++
+			}
+		}
+	}
+
+	opponent := otherPlayer(p.player)
+	for _, player := range []Player{p.player, opponent} {
+		if leave[player] > arrive[player] {
+			return -1 // Some of player's marks would have to come off
+		}
+	}
+	for plies := 0; ; plies++ {
+		settled := (plies+1)/2 >= arrive[p.player] && plies/2 >= arrive[opponent]
+		if settled && (plies%2 == 0) == (p.player == other.player) {
+			return plies
+		}
+	}
+}
+
+// positions replays the move history from the starting board and returns
+// the position after each ply
+func (g *Game) positions() []position {
+	var board [3][3]Player
+	for row := range board {
+		for col := range board[row] {
+			board[row][col] = Empty
+		}
+	}
+	if g.Setup != nil {
+		board, _ = parseSetupBoard(g.Setup.Position)
+	}
+
+	positions := make([]position, len(g.MoveHistory))
+	for ply, to := range g.MoveHistory {
+		if from, ok := g.Slides[ply]; ok {
+			board[from.Row][from.Col] = Empty
+		}
+		board[to.Row][to.Col] = g.MarkAt(ply)
+		positions[ply] = position{board: board, player: moverAfter(ply)}
+	}
+	return positions
+}
+
+// moverAfter returns the player to move once the given ply has been made
+func moverAfter(ply int) Player {
+	if ply%2 == 0 {
+		return PlayerO
+	}
+	return PlayerX
+}
+
+// count returns how many of player's marks are on the board
+func (g *Game) count(player Player) int {
+	n := 0
+	for row := 0; row < 3; row++ {
+		for col := 0; col < 3; col++ {
+			if g.Board[row][col] == player {
+				n++
+			}
+		}
+	}
+	return n
+}
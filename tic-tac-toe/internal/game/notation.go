@@ -0,0 +1,84 @@
+package game
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ParsePosition builds a game from a board written row by row, e.g.
+// "XO./.X./..O". Empty cells may be '.', '-' or '_' and the '/' row
+// separators are optional. The player to move is inferred from the counts.
+func ParsePosition(notation string) (*Game, error) {
+	cells := strings.ReplaceAll(strings.TrimSpace(notation), "/", "")
+	if len(cells) != 9 {
+		return nil, fmt.Errorf("position %q must have 9 cells, got %d", notation, len(cells))
+	}
+
+	g := New()
+	counts := map[Player]int{}
+	for i, cell := range strings.ToUpper(cells) {
+		player := Empty
+		switch cell {
+		case 'X':
+			player = PlayerX
+		case 'O':
+			player = PlayerO
+		case '.', '-', '_':
+		default:
+			return nil, fmt.Errorf("position %q has invalid cell %q", notation, cell)
+		}
+		g.Board[i/3][i%3] = player
+		counts[player]++
+	}
+
+	xCount, oCount := counts[PlayerX], counts[PlayerO]
+	if xCount != oCount && xCount != oCount+1 {
+		return nil, fmt.Errorf("position %q has %d X and %d O; X moves first", notation, xCount, oCount)
+	}
+	if g.hasLine(PlayerX) && g.hasLine(PlayerO) {
+		return nil, fmt.Errorf("position %q has winning lines for both players", notation)
+	}
+
+	// Whoever made the last move stays current once the game is over
+	lastMover := PlayerO
+	if xCount > oCount {
+		lastMover = PlayerX
+	}
+
+	g.checkGameStatus()
+	if g.Status == StatusWon && g.Winner != lastMover {
+		return nil, fmt.Errorf("position %q continues after %s has already won", notation, g.Winner)
+	}
+
+	if g.Status == StatusPlaying {
+		g.CurrentPlayer = otherPlayer(lastMover)
+	} else {
+		g.CurrentPlayer = lastMover
+	}
+	return g, nil
+}
+
+// Notation writes the board in the form accepted by ParsePosition
+func (g *Game) Notation() string {
+	rows := make([]string, 3)
+	for row := 0; row < 3; row++ {
+		for col := 0; col < 3; col++ {
+			if g.Board[row][col] == Empty {
+				rows[row] += "."
+			} else {
+				rows[row] += string(g.Board[row][col])
+			}
+		}
+	}
+	return strings.Join(rows, "/")
+}
+
+// hasLine reports whether player holds any complete row, column or diagonal
+func (g *Game) hasLine(player Player) bool {
+	for _, line := range lines {
+		if g.lineOwner(line) == player {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,53 @@
+package game_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"tic-tac-toe/internal/game"
+)
+
+var _ = Describe("Notation", func() {
+	Describe("ParsePosition", func() {
+		It("should infer the player to move", func() {
+			g, err := game.ParsePosition("X../.O./..X")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(g.GetCurrentPlayer()).To(Equal(game.PlayerO))
+			Expect(g.GetStatus()).To(Equal(game.StatusPlaying))
+		})
+
+		It("should accept positions without separators", func() {
+			g, err := game.ParsePosition("x-o______")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(g.GetBoard()[0][2]).To(Equal(game.PlayerO))
+			Expect(g.GetCurrentPlayer()).To(Equal(game.PlayerX))
+		})
+
+		It("should detect finished games", func() {
+			g, err := game.ParsePosition("XXX/OO./...")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(g.GetStatus()).To(Equal(game.StatusWon))
+			Expect(g.GetWinner()).To(Equal(game.PlayerX))
+		})
+
+		It("should reject impossible positions", func() {
+			for _, notation := range []string{"XX./.../...", "O../.../...", "XXX/OOO/...", "XXX/OO./..O", "XO", "XO?/.../..."} {
+				_, err := game.ParsePosition(notation)
+				Expect(err).To(HaveOccurred(), notation)
+			}
+		})
+	})
+
+	Describe("Notation", func() {
+		It("should round-trip through ParsePosition", func() {
+			g := game.New()
+			g.MakeMove(1, 1)
+			g.MakeMove(0, 2)
+			Expect(g.Notation()).To(Equal("..O/.X./..."))
+
+			parsed, err := game.ParsePosition(g.Notation())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(parsed.GetBoard()).To(Equal(g.GetBoard()))
+		})
+	})
+})
@@ -0,0 +1,51 @@
+package game
+
+// Further marks for games of more than two players, who move after X and O
+const (
+	PlayerTriangle Player = "Δ"
+	PlayerSquare   Player = "□"
+)
+
+// Players lists every mark in turn order; a game of n players uses the first n
+var Players = []Player{PlayerX, PlayerO, PlayerTriangle, PlayerSquare}
+
+// Limits on the number of players in a game
+const (
+	MinPlayers = 2
+	MaxPlayers = 4
+)
+
+// Rotation returns the marks of a game of n players in turn order
+func Rotation(n int) []Player {
+	return Players[:n]
+}
+
+// Index returns the player's place in turn order, counted from 0 for X, or
+// -1 for marks that are not players
+func (p Player) Index() int {
+	for i, player := range Players {
+		if player == p {
+			return i
+		}
+	}
+	return -1
+}
+
+// NextPlayer returns who moves after player when n players take turns in rotation
+func NextPlayer(player Player, n int) Player {
+	return Players[(player.Index()+1)%n]
+}
+
+// MoverAt returns who makes the move at index ply when n players take turns
+// in rotation from X
+func MoverAt(ply, n int) Player {
+	return Players[ply%n]
+}
+
+// MoverAt returns who made the move at index ply of the move history; X
+// moves first from any setup, and the two players alternate. Game is always
+// played by two: games of three or four players are multi.Game, which keeps
+// its own count.
+func (g *Game) MoverAt(ply int) Player {
+	return MoverAt(ply, 2)
+}
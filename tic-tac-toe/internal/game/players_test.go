@@ -0,0 +1,28 @@
+package game_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"tic-tac-toe/internal/game"
+)
+
+var _ = Describe("Players", func() {
+	It("should take turns in rotation among the first n marks", func() {
+		Expect(game.Rotation(3)).To(Equal([]game.Player{game.PlayerX, game.PlayerO, game.PlayerTriangle}))
+		Expect(game.NextPlayer(game.PlayerO, 3)).To(Equal(game.PlayerTriangle))
+		Expect(game.NextPlayer(game.PlayerTriangle, 3)).To(Equal(game.PlayerX))
+		Expect(game.NextPlayer(game.PlayerSquare, 4)).To(Equal(game.PlayerX))
+		Expect(game.MoverAt(6, 4)).To(Equal(game.PlayerTriangle))
+		Expect(game.Empty.Index()).To(Equal(-1))
+	})
+
+	It("should attribute the moves of a 3×3 game to X and O in turn", func() {
+		g := game.New()
+		Expect(g.ApplySetup(&game.Setup{Name: "Handicap O", Position: ".../.O./..."})).To(Succeed())
+		Expect(g.MakeMove(0, 0)).To(Succeed())
+		Expect(g.MakeMove(0, 1)).To(Succeed())
+		Expect(g.MoverAt(0)).To(Equal(game.PlayerX))
+		Expect(g.MoverAt(1)).To(Equal(game.PlayerO))
+	})
+})
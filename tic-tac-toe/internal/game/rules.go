@@ -0,0 +1,277 @@
+package game
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Variant names a set of rules
+type Variant string
+
+const (
+	Classic    Variant = "classic"     // Three in a row wins
+	Misere     Variant = "misere"      // Three in a row loses
+	Wild       Variant = "wild"        // Either player may place X or O; completing a line wins
+	OrderChaos Variant = "order-chaos" // X plays Order, who wants a line of either mark; O plays Chaos, who wants a full board
+	Notakto    Variant = "notakto"     // Both players place X on one or more boards; completing the last line loses
+	Achi       Variant = "achi"        // Three marks each, then slide one along a line to a neighboring cell
+	FreeMoving Variant = "free-moving" // Three marks each, then move one to any empty cell
+)
+
+// Variants lists the rule variants in menu order
+var Variants = []Variant{Classic, Misere, Wild, OrderChaos, Notakto, Achi, FreeMoving}
+
+// ParseVariant looks up a variant by name
+func ParseVariant(name string) (Variant, error) {
+	for _, v := range Variants {
+		if string(v) == strings.ToLower(name) {
+			return v, nil
+		}
+	}
+
+	names := make([]string, len(Variants))
+	for i, v := range Variants {
+		names[i] = string(v)
+	}
+	return Classic, fmt.Errorf("unknown variant %q (want %s)", name, strings.Join(names, ", "))
+}
+
+// Name returns the variant's display name
+func (v Variant) Name() string {
+	switch v {
+	case Misere:
+		return "Misère"
+	case Wild:
+		return "Wild"
+	case OrderChaos:
+		return "Order and Chaos"
+	case Notakto:
+		return "Notakto"
+	case Achi:
+		return "Achi"
+	case FreeMoving:
+		return "Free Moving"
+	default:
+		return "Classic"
+	}
+}
+
+// Description explains the variant in one sentence
+func (v Variant) Description() string {
+	switch v {
+	case Misere:
+		return "Avoid three in a row: whoever completes a line loses."
+	case Wild:
+		return "Place an X or an O each turn: whoever completes a line of either wins."
+	case OrderChaos:
+		return "Order (X) wins with any line of three; Chaos (O) wins if the board fills without one."
+	case Notakto:
+		return "Both players place X: whoever completes a line loses. On several boards, a board with a line is out of play and whoever completes the last one loses."
+	case Achi:
+		return "Three marks each; once all are placed, slide one along a line to a neighboring empty cell."
+	case FreeMoving:
+		return "Three marks each; once all are placed, move one to any empty cell."
+	default:
+		return "Three in a row wins."
+	}
+}
+
+// MaxBoards is the most boards Notakto is played on at once
+const MaxBoards = 3
+
+// Rules decides which marks a player may place and how a game ends
+type Rules struct {
+	Variant Variant `json:"variant"`
+	Boards  int     `json:"boards,omitempty"` // Boards Notakto is played on; zero is one
+}
+
+// ClassicRules are the standard rules of tic-tac-toe
+var ClassicRules = Rules{Variant: Classic}
+
+// IsClassic reports whether the rules are standard tic-tac-toe; games saved
+// before rules existed have no variant and are classic too
+func (r Rules) IsClassic() bool {
+	return r.Variant == Classic || r.Variant == ""
+}
+
+// BoardCount returns how many boards a game is played on: up to MaxBoards
+// for Notakto and one for every other variant
+func (r Rules) BoardCount() int {
+	if r.Variant != Notakto || r.Boards < 1 {
+		return 1
+	}
+	return min(r.Boards, MaxBoards)
+}
+
+// LinesLose reports whether completing a line loses rather than wins
+func (r Rules) LinesLose() bool {
+	return r.Variant == Misere || r.Variant == Notakto
+}
+
+// Marks returns the marks player may place, the usual one first
+func (r Rules) Marks(player Player) []Player {
+	switch r.Variant {
+	case Wild, OrderChaos:
+		return []Player{player, otherPlayer(player)}
+	case Notakto:
+		return []Player{PlayerX}
+	default:
+		return []Player{player}
+	}
+}
+
+// ChoosesMarks reports whether players pick which mark to place
+func (r Rules) ChoosesMarks() bool {
+	return len(r.Marks(PlayerX)) > 1
+}
+
+// Pieces returns how many marks each player may have on the board; zero is no limit
+func (r Rules) Pieces() int {
+	switch r.Variant {
+	case Achi, FreeMoving:
+		return 3
+	default:
+		return 0
+	}
+}
+
+// CanSlide reports whether the rules let a mark move from one cell to
+// another, whatever else is on the board. Achi marks move one step along the
+// board's lines: across, down, or diagonally to or from the center.
+func (r Rules) CanSlide(from, to Position) bool {
+	if from == to {
+		return false
+	}
+	switch r.Variant {
+	case FreeMoving:
+		return true
+	case Achi:
+		dr, dc := to.Row-from.Row, to.Col-from.Col
+		if dr < -1 || dr > 1 || dc < -1 || dc > 1 {
+			return false
+		}
+		center := Position{Row: 1, Col: 1}
+		return dr == 0 || dc == 0 || from == center || to == center
+	default:
+		return false
+	}
+}
+
+// SeatName names the side that moves as player, e.g. "Order" or "Player X"
+func (r Rules) SeatName(player Player) string {
+	if r.Variant == OrderChaos {
+		if player == PlayerX {
+			return "Order"
+		}
+		return "Chaos"
+	}
+	return "Player " + string(player)
+}
+
+// outcome decides the game after mover's move, given whether a line is
+// complete and whether the board is full
+func (r Rules) outcome(mover Player, line, full bool) (GameStatus, Player) {
+	switch {
+	case line && r.LinesLose():
+		return StatusWon, otherPlayer(mover)
+	case line && r.Variant == OrderChaos:
+		return StatusWon, PlayerX
+	case line:
+		return StatusWon, mover
+	case full && r.Variant == OrderChaos:
+		return StatusWon, PlayerO
+	case full:
+		return StatusDraw, Empty
+	default:
+		return StatusPlaying, Empty
+	}
+}
+
+// MoveKind tells placing a new mark apart from moving one already on the board
+type MoveKind int
+
+const (
+	Place MoveKind = iota // A new mark on an empty cell
+	Slide                 // A mark moved from From to an empty cell
+)
+
+// Move is a placement together with the mark placed, or a slide of one of
+// the mover's marks from From to (Row, Col)
+type Move struct {
+	Kind  MoveKind
+	From  Position // Only for slides
+	Row   int
+	Col   int
+	Mark  Player
+	Board int // The board played on, counted from 0; only Notakto has more than one
+}
+
+// SetRules sets the rules for a new game
+func (g *Game) SetRules(rules Rules) {
+	if rules.Variant == "" {
+		rules = ClassicRules
+	}
+	g.Rules = rules
+	g.Extra = emptyBoards(rules.BoardCount() - 1)
+	g.OnBoard = nil
+}
+
+// GetRules returns the game's rules
+func (g *Game) GetRules() Rules {
+	return g.Rules
+}
+
+// CanPlace reports whether the player to move may place mark
+func (g *Game) CanPlace(mark Player) bool {
+	for _, allowed := range g.Rules.Marks(g.CurrentPlayer) {
+		if mark == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// LegalMoves lists every empty cell of every board in play with every mark
+// the player to move may place there, the usual mark first, or every slide
+// once the player must move a mark instead
+func (g *Game) LegalMoves() []Move {
+	if g.Status != StatusPlaying {
+		return nil
+	}
+	if g.MustSlide() {
+		return g.legalSlides()
+	}
+	var moves []Move
+	for board := 0; board < g.Rules.BoardCount(); board++ {
+		if g.BoardDead(board) {
+			continue
+		}
+		for _, pos := range emptyCells(g.board(board)) {
+			for _, mark := range g.Rules.Marks(g.CurrentPlayer) {
+				moves = append(moves, Move{Row: pos.Row, Col: pos.Col, Mark: mark, Board: board})
+			}
+		}
+	}
+	return moves
+}
+
+// Play makes a move with its mark
+func (g *Game) Play(move Move) error {
+	if move.Kind == Slide {
+		return g.MakeSlide(move.From, Position{Row: move.Row, Col: move.Col})
+	}
+	return g.place(move.Board, move.Row, move.Col, move.Mark, time.Now())
+}
+
+// MarkAt returns the mark placed by the given ply of the move history
+func (g *Game) MarkAt(ply int) Player {
+	if ply < len(g.Marks) {
+		return g.Marks[ply]
+	}
+	mover := PlayerX
+	if ply%2 == 1 {
+		mover = PlayerO
+	}
+	return g.Rules.Marks(mover)[0]
+}
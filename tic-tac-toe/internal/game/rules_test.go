@@ -0,0 +1,317 @@
+package game_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"tic-tac-toe/internal/game"
+)
+
+var _ = Describe("Rules", func() {
+	var g *game.Game
+
+	newGame := func(variant game.Variant) *game.Game {
+		g := game.New()
+		g.SetRules(game.Rules{Variant: variant})
+		return g
+	}
+
+	play := func(g *game.Game, moves ...game.Move) {
+		for _, move := range moves {
+			Expect(g.Play(move)).To(Succeed())
+		}
+	}
+
+	Describe("ParseVariant", func() {
+		It("should accept every variant by name", func() {
+			for _, variant := range game.Variants {
+				parsed, err := game.ParseVariant(string(variant))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(parsed).To(Equal(variant))
+			}
+		})
+
+		It("should reject unknown variants", func() {
+			_, err := game.ParseVariant("gravity")
+			Expect(err).To(MatchError(ContainSubstring("unknown variant")))
+		})
+	})
+
+	Describe("Classic", func() {
+		It("should be the default and let each player place only their own mark", func() {
+			g = game.New()
+			Expect(g.GetRules().IsClassic()).To(BeTrue())
+			Expect(g.LegalMoves()).To(HaveLen(9))
+			Expect(g.MakeMoveMark(0, 0, game.PlayerO)).To(MatchError(ContainSubstring("cannot place O")))
+			Expect(g.Marks).To(BeEmpty())
+		})
+
+		It("should treat rules missing from old saves as classic", func() {
+			g = game.New()
+			g.Rules = game.Rules{}
+			play(g, game.Move{Row: 0, Col: 0, Mark: game.PlayerX})
+			Expect(g.GetBoard()[0][0]).To(Equal(game.PlayerX))
+		})
+	})
+
+	Describe("Misère", func() {
+		It("should make the player who completes a line lose", func() {
+			g = newGame(game.Misere)
+			play(g,
+				game.Move{Row: 0, Col: 0, Mark: game.PlayerX},
+				game.Move{Row: 1, Col: 0, Mark: game.PlayerO},
+				game.Move{Row: 0, Col: 1, Mark: game.PlayerX},
+				game.Move{Row: 1, Col: 1, Mark: game.PlayerO},
+				game.Move{Row: 2, Col: 2, Mark: game.PlayerX},
+				game.Move{Row: 1, Col: 2, Mark: game.PlayerO},
+			)
+			Expect(g.GetStatus()).To(Equal(game.StatusWon))
+			Expect(g.GetWinner()).To(Equal(game.PlayerX))
+		})
+
+		It("should still draw on a full board without a line", func() {
+			g = newGame(game.Misere)
+			for _, move := range [][2]int{{0, 0}, {0, 1}, {0, 2}, {1, 1}, {1, 0}, {1, 2}, {2, 1}, {2, 0}, {2, 2}} {
+				Expect(g.MakeMove(move[0], move[1])).To(Succeed())
+			}
+			Expect(g.GetStatus()).To(Equal(game.StatusDraw))
+		})
+	})
+
+	Describe("Wild", func() {
+		It("should let either player place either mark and record the choice", func() {
+			g = newGame(game.Wild)
+			Expect(g.GetRules().ChoosesMarks()).To(BeTrue())
+			Expect(g.LegalMoves()).To(HaveLen(18))
+
+			play(g, game.Move{Row: 0, Col: 0, Mark: game.PlayerO})
+			Expect(g.GetBoard()[0][0]).To(Equal(game.PlayerO))
+			Expect(g.GetCurrentPlayer()).To(Equal(game.PlayerO))
+			Expect(g.Marks).To(Equal([]game.Player{game.PlayerO}))
+			Expect(g.MarkAt(0)).To(Equal(game.PlayerO))
+		})
+
+		It("should award the game to whoever completes a line of either mark", func() {
+			g = newGame(game.Wild)
+			play(g,
+				game.Move{Row: 0, Col: 0, Mark: game.PlayerO},
+				game.Move{Row: 0, Col: 1, Mark: game.PlayerO},
+				game.Move{Row: 0, Col: 2, Mark: game.PlayerO},
+			)
+			Expect(g.GetStatus()).To(Equal(game.StatusWon))
+			Expect(g.GetWinner()).To(Equal(game.PlayerX))
+		})
+
+		It("should keep and clear the marks with the game", func() {
+			g = newGame(game.Wild)
+			play(g, game.Move{Row: 1, Col: 1, Mark: game.PlayerO})
+			clone := g.Clone()
+			clone.Marks[0] = game.PlayerX
+			Expect(g.Marks[0]).To(Equal(game.PlayerO))
+
+			g.Reset()
+			Expect(g.Marks).To(BeEmpty())
+			Expect(g.GetRules().Variant).To(Equal(game.Wild))
+		})
+	})
+
+	Describe("Order and Chaos", func() {
+		It("should let Order win from a line Chaos completes", func() {
+			g = newGame(game.OrderChaos)
+			Expect(g.GetRules().SeatName(game.PlayerX)).To(Equal("Order"))
+			Expect(g.GetRules().SeatName(game.PlayerO)).To(Equal("Chaos"))
+			play(g,
+				game.Move{Row: 0, Col: 0, Mark: game.PlayerO},
+				game.Move{Row: 2, Col: 2, Mark: game.PlayerX},
+				game.Move{Row: 0, Col: 1, Mark: game.PlayerO},
+				game.Move{Row: 0, Col: 2, Mark: game.PlayerO},
+			)
+			Expect(g.GetStatus()).To(Equal(game.StatusWon))
+			Expect(g.GetWinner()).To(Equal(game.PlayerX))
+		})
+
+		It("should let Chaos win when the board fills without a line", func() {
+			g = newGame(game.OrderChaos)
+			board := "XOXXOOOXX"
+			for i, cell := range board {
+				Expect(g.MakeMoveMark(i/3, i%3, game.Player(cell))).To(Succeed())
+			}
+			Expect(g.GetStatus()).To(Equal(game.StatusWon))
+			Expect(g.GetWinner()).To(Equal(game.PlayerO))
+		})
+	})
+
+	Describe("Notakto", func() {
+		It("should have both players place X", func() {
+			g = newGame(game.Notakto)
+			Expect(g.MakeMove(0, 0)).To(Succeed())
+			Expect(g.GetCurrentPlayer()).To(Equal(game.PlayerO))
+			Expect(g.MakeMoveMark(1, 1, game.PlayerO)).To(HaveOccurred())
+			Expect(g.MakeMove(1, 1)).To(Succeed())
+			Expect(g.GetBoard()[1][1]).To(Equal(game.PlayerX))
+			Expect(g.MarkAt(1)).To(Equal(game.PlayerX))
+		})
+
+		It("should make the player who completes a line lose", func() {
+			g = newGame(game.Notakto)
+			for _, move := range [][2]int{{0, 0}, {0, 1}, {0, 2}} {
+				Expect(g.MakeMove(move[0], move[1])).To(Succeed())
+			}
+			Expect(g.GetStatus()).To(Equal(game.StatusWon))
+			Expect(g.GetWinner()).To(Equal(game.PlayerO))
+		})
+
+		It("should play on until every board has a line", func() {
+			g = game.New()
+			g.SetRules(game.Rules{Variant: game.Notakto, Boards: 2})
+			Expect(g.Boards()).To(HaveLen(2))
+
+			// X completes a line on the first board, which goes out of play
+			for _, move := range [][2]int{{0, 0}, {0, 1}, {0, 2}} {
+				Expect(g.MakeMove(move[0], move[1])).To(Succeed())
+			}
+			Expect(g.GetStatus()).To(Equal(game.StatusPlaying))
+			Expect(g.BoardDead(0)).To(BeTrue())
+			Expect(g.MakeMove(2, 2)).To(MatchError(ContainSubstring("out of play")))
+			Expect(g.LegalMoves()).To(HaveLen(9))
+			for _, move := range g.LegalMoves() {
+				Expect(move.Board).To(Equal(1))
+			}
+
+			// O completes the last line and loses
+			for _, move := range [][2]int{{1, 0}, {1, 1}, {1, 2}} {
+				Expect(g.MakeMoveOn(1, move[0], move[1])).To(Succeed())
+			}
+			Expect(g.GetStatus()).To(Equal(game.StatusWon))
+			Expect(g.GetWinner()).To(Equal(game.PlayerX))
+			Expect(g.LastBoard()).To(Equal(1))
+			Expect(g.WinningLines()).To(Equal([]game.Line{{{Row: 1, Col: 0}, {Row: 1, Col: 1}, {Row: 1, Col: 2}}}))
+			Expect(g.BoardsNotation()).To(Equal("XXX/.../... .../XXX/..."))
+		})
+
+		It("should replay moves on every board", func() {
+			g = game.New()
+			g.SetRules(game.Rules{Variant: game.Notakto, Boards: 3})
+			Expect(g.MakeMoveOn(2, 1, 1)).To(Succeed())
+			Expect(g.Play(game.Move{Row: 0, Col: 0, Mark: game.PlayerX, Board: 1})).To(Succeed())
+			Expect(g.MoveAt(0).Board).To(Equal(2))
+
+			clone := g.Clone()
+			Expect(clone.MakeMoveOn(1, 2, 2)).To(Succeed())
+			Expect(g.Boards()[1][2][2]).To(Equal(game.Empty))
+
+			Expect(g.Undo(1)).To(Succeed())
+			Expect(g.Boards()[1][0][0]).To(Equal(game.Empty))
+			Expect(g.Boards()[2][1][1]).To(Equal(game.PlayerX))
+			Expect(g.MakeMoveOn(3, 0, 0)).To(MatchError(ContainSubstring("invalid board")))
+
+			g.Reset()
+			Expect(g.Boards()).To(HaveLen(3))
+			Expect(g.Boards()[2][1][1]).To(Equal(game.Empty))
+		})
+
+		It("should keep other variants on one board", func() {
+			Expect(game.Rules{Variant: game.Classic, Boards: 3}.BoardCount()).To(Equal(1))
+			Expect(game.Rules{Variant: game.Notakto}.BoardCount()).To(Equal(1))
+			Expect(game.Rules{Variant: game.Notakto, Boards: 9}.BoardCount()).To(Equal(game.MaxBoards))
+		})
+	})
+
+	Describe("Moving marks", func() {
+		// place makes the placements in turn, X first
+		place := func(g *game.Game, cells ...[2]int) {
+			for _, cell := range cells {
+				Expect(g.MakeMove(cell[0], cell[1])).To(Succeed())
+			}
+		}
+		slide := func(fromRow, fromCol, toRow, toCol int) game.Move {
+			return game.Move{Kind: game.Slide, From: game.Position{Row: fromRow, Col: fromCol}, Row: toRow, Col: toCol}
+		}
+
+		// XXO / OO. / ..X with X to move: O threatens row 1 and the anti-diagonal
+		threats := [][2]int{{0, 0}, {1, 0}, {0, 1}, {0, 2}, {2, 2}, {1, 1}}
+
+		It("should switch from placing to moving once three marks each are down", func() {
+			g = newGame(game.Achi)
+			place(g, threats...)
+			Expect(g.MustSlide()).To(BeTrue())
+			Expect(g.MakeMove(2, 0)).To(MatchError(ContainSubstring("move one instead")))
+			for _, move := range g.LegalMoves() {
+				Expect(move.Kind).To(Equal(game.Slide))
+				Expect(g.GetBoard()[move.From.Row][move.From.Col]).To(Equal(game.PlayerX))
+			}
+		})
+
+		It("should only slide Achi marks along a line to a neighboring cell", func() {
+			g = newGame(game.Achi)
+			place(g, threats...)
+			Expect(g.MakeSlide(game.Position{Row: 0, Col: 0}, game.Position{Row: 2, Col: 0})).To(MatchError(ContainSubstring("cannot move")))
+			Expect(g.MakeSlide(game.Position{Row: 0, Col: 1}, game.Position{Row: 1, Col: 2})).To(MatchError(ContainSubstring("cannot move")))
+			Expect(g.MakeSlide(game.Position{Row: 1, Col: 1}, game.Position{Row: 1, Col: 2})).To(MatchError(ContainSubstring("no X mark")))
+			Expect(game.Rules{Variant: game.Achi}.CanSlide(game.Position{Row: 2, Col: 2}, game.Position{Row: 1, Col: 1})).To(BeTrue())
+
+			play(g, slide(2, 2, 1, 2))
+			Expect(g.MoveAt(6)).To(Equal(game.Move{Kind: game.Slide, From: game.Position{Row: 2, Col: 2}, Row: 1, Col: 2, Mark: game.PlayerX}))
+			Expect(g.MoveAt(5).Kind).To(Equal(game.Place))
+
+			// O completes the anti-diagonal by sliding down from (1,0)
+			play(g, slide(1, 0, 2, 0))
+			Expect(g.GetStatus()).To(Equal(game.StatusWon))
+			Expect(g.GetWinner()).To(Equal(game.PlayerO))
+		})
+
+		It("should move free-moving marks to any empty cell", func() {
+			g = newGame(game.FreeMoving)
+			place(g, threats...)
+			Expect(g.MakeSlide(game.Position{Row: 0, Col: 0}, game.Position{Row: 2, Col: 0})).To(Succeed())
+			Expect(g.GetBoard()[0][0]).To(Equal(game.Empty))
+			Expect(g.GetBoard()[2][0]).To(Equal(game.PlayerX))
+		})
+
+		It("should draw when a position repeats three times", func() {
+			g = newGame(game.Achi)
+			// XO. / O.X / .XO leaves nobody a line
+			place(g, [2]int{0, 0}, [2]int{0, 1}, [2]int{1, 2}, [2]int{1, 0}, [2]int{2, 1}, [2]int{2, 2})
+			for i := 0; i < 2; i++ {
+				Expect(g.GetStatus()).To(Equal(game.StatusPlaying))
+				play(g, slide(2, 1, 2, 0), slide(0, 1, 0, 2), slide(2, 0, 2, 1), slide(0, 2, 0, 1))
+			}
+			Expect(g.GetStatus()).To(Equal(game.StatusDraw))
+		})
+
+		It("should key positions by the repetitions they could still reach", func() {
+			// XO. / O.X / .XO placed in two orders
+			g = newGame(game.Achi)
+			place(g, [2]int{0, 0}, [2]int{0, 1}, [2]int{1, 2}, [2]int{1, 0}, [2]int{2, 1}, [2]int{2, 2})
+			transposed := newGame(game.Achi)
+			place(transposed, [2]int{2, 1}, [2]int{2, 2}, [2]int{0, 0}, [2]int{1, 0}, [2]int{1, 2}, [2]int{0, 1})
+			Expect(g.RepetitionKey(8)).To(Equal(transposed.RepetitionKey(8)))
+
+			// Once the position has occurred twice, one more return draws
+			play(g, slide(2, 1, 2, 0), slide(0, 1, 0, 2), slide(2, 0, 2, 1), slide(0, 2, 0, 1))
+			Expect(g.GetBoard()).To(Equal(transposed.GetBoard()))
+			Expect(g.RepetitionKey(8)).ToNot(Equal(transposed.RepetitionKey(8)))
+			Expect(g.RepetitionKey(3)).ToNot(BeEmpty())
+			Expect(transposed.RepetitionKey(3)).To(BeEmpty())
+			Expect(game.New().RepetitionKey(8)).To(BeEmpty())
+		})
+
+		It("should keep slides apart in clones and forget them on reset", func() {
+			g = newGame(game.FreeMoving)
+			place(g, threats...)
+			play(g, slide(0, 0, 2, 0))
+			clone := g.Clone()
+			clone.Slides[6] = game.Position{Row: 2, Col: 2}
+			Expect(g.Slides[6]).To(Equal(game.Position{Row: 0, Col: 0}))
+
+			g.Reset()
+			Expect(g.Slides).To(BeEmpty())
+			Expect(g.GetRules().Variant).To(Equal(game.FreeMoving))
+		})
+
+		It("should not move marks under rules without a limit", func() {
+			g = game.New()
+			Expect(g.MakeSlide(game.Position{Row: 0, Col: 0}, game.Position{Row: 1, Col: 1})).To(MatchError(ContainSubstring("cannot move")))
+		})
+	})
+})
@@ -0,0 +1,168 @@
+package game
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Blocked fills a cell that nobody can play
+const Blocked Player = "#"
+
+// Setup is a starting position other than the empty board: blocked cells and
+// handicap marks placed before the first move. X still moves first.
+type Setup struct {
+	Name     string `json:"name"`     // Keeps the setup's results apart in the statistics
+	Position string `json:"position"` // The board in position notation, '#' for a blocked cell
+}
+
+// Setups are the named setups offered for new games, in menu order
+var Setups = []Setup{
+	{Name: "Blocked center", Position: ".../.#./..."},
+	{Name: "Blocked corners", Position: "#.#/.../#.#"},
+	{Name: "Handicap X", Position: ".../.X./..."},
+	{Name: "Handicap O", Position: ".../.O./..."},
+}
+
+// RandomObstacles names the setup whose blocked cells are drawn anew for
+// each game; the solver keeps only layouts where neither side can force a
+// win, or under rules that always have a winner, where the player to move
+// cannot. RandomBlocked is how many cells it blocks.
+const (
+	RandomObstacles = "Random obstacles"
+	RandomBlocked   = 2
+)
+
+// ParseSetup reads a setup written in position notation with '#' for
+// blocked cells, e.g. "X#./.../..#". Marks may be placed in any numbers,
+// but no line may be complete and two cells must be left to play.
+func ParseSetup(name, notation string) (*Setup, error) {
+	board, err := parseSetupBoard(notation)
+	if err != nil {
+		return nil, err
+	}
+
+	g := New()
+	g.Board = board
+	if g.checkWinner() != Empty {
+		return nil, fmt.Errorf("setup %q already has a line of three", notation)
+	}
+	if len(g.GetAvailableMoves()) < 2 {
+		return nil, fmt.Errorf("setup %q leaves fewer than two cells to play", notation)
+	}
+	return &Setup{Name: name, Position: g.Notation()}, nil
+}
+
+// LookupSetup finds a named setup, or reads the name as a position in
+// setup notation. An empty name or "standard" is the empty board, which is
+// returned as nil.
+func LookupSetup(name string) (*Setup, error) {
+	if name == "" || strings.EqualFold(name, "standard") {
+		return nil, nil
+	}
+	for _, setup := range Setups {
+		if strings.EqualFold(setup.Name, name) {
+			setup := setup
+			return &setup, nil
+		}
+	}
+	if strings.EqualFold(name, RandomObstacles) {
+		return &Setup{Name: RandomObstacles}, nil
+	}
+
+	setup, err := ParseSetup(name, name)
+	if err != nil {
+		names := []string{"standard"}
+		for _, s := range Setups {
+			names = append(names, s.Name)
+		}
+		names = append(names, RandomObstacles)
+		return nil, fmt.Errorf("unknown setup %q (want %s, or a position like X#./.../..#): %w", name, strings.Join(names, ", "), err)
+	}
+	return setup, nil
+}
+
+// Describe explains the setup in one sentence
+func (s *Setup) Describe() string {
+	if s == nil {
+		return "The empty board."
+	}
+	if s.Position == "" {
+		return fmt.Sprintf("%d cells chosen at random are blocked, in a layout where neither side can force a win, or where the rules always have a winner, the player to move cannot.", RandomBlocked)
+	}
+
+	counts := map[Player]int{}
+	for _, cell := range strings.ReplaceAll(s.Position, "/", "") {
+		counts[Player(cell)]++
+	}
+	var parts []string
+	if n := counts[Blocked]; n > 0 {
+		parts = append(parts, fmt.Sprintf("%d blocked %s", n, plural(n, "cell", "cells")))
+	}
+	for _, player := range []Player{PlayerX, PlayerO} {
+		if n := counts[player]; n > 0 {
+			parts = append(parts, fmt.Sprintf("%s starts with %d %s", player, n, plural(n, "mark", "marks")))
+		}
+	}
+	return strings.Join(parts, "; ") + "."
+}
+
+// ApplySetup puts the setup on the board of a new game; a nil setup leaves
+// the empty board
+func (g *Game) ApplySetup(s *Setup) error {
+	if s == nil {
+		return nil
+	}
+	if len(g.MoveHistory) > 0 {
+		return fmt.Errorf("setup %s must be applied before the first move", s.Name)
+	}
+	if len(g.Extra) > 0 {
+		return fmt.Errorf("setup %s needs a game on one board", s.Name)
+	}
+	board, err := parseSetupBoard(s.Position)
+	if err != nil {
+		return err
+	}
+	g.Board = board
+	g.Setup = s
+	return nil
+}
+
+// parseSetupBoard reads the cells of a position in setup notation
+func parseSetupBoard(notation string) ([3][3]Player, error) {
+	var board [3][3]Player
+	cells := strings.ReplaceAll(strings.TrimSpace(notation), "/", "")
+	if len(cells) != 9 {
+		return board, fmt.Errorf("setup %q must have 9 cells, got %d", notation, len(cells))
+	}
+
+	for i, cell := range strings.ToUpper(cells) {
+		player := Empty
+		switch cell {
+		case 'X':
+			player = PlayerX
+		case 'O':
+			player = PlayerO
+		case '#':
+			player = Blocked
+		case '.', '-', '_':
+		default:
+			return board, fmt.Errorf("setup %q has invalid cell %q", notation, cell)
+		}
+		board[i/3][i%3] = player
+	}
+	return board, nil
+}
+
+// plural picks the singular or plural form for n
+func plural(n int, one, many string) string {
+	if n == 1 {
+		return one
+	}
+	return many
+}
+
+// IsStandard reports whether the game is classic tic-tac-toe from the empty
+// board, the only game the tablebase knows
+func (g *Game) IsStandard() bool {
+	return g.Rules.IsClassic() && g.Setup == nil
+}
@@ -0,0 +1,93 @@
+package game_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"tic-tac-toe/internal/game"
+)
+
+var _ = Describe("Setups", func() {
+	It("should start from blocked cells and handicap marks with X to move", func() {
+		setup, err := game.ParseSetup("Test", "x#./.O./..#")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(setup.Position).To(Equal("X#./.O./..#"))
+		Expect(setup.Describe()).To(Equal("2 blocked cells; X starts with 1 mark; O starts with 1 mark."))
+
+		g := game.New()
+		Expect(g.ApplySetup(setup)).To(Succeed())
+		Expect(g.GetCurrentPlayer()).To(Equal(game.PlayerX))
+		Expect(g.GetAvailableMoves()).To(HaveLen(5))
+		Expect(g.MakeMove(0, 1)).To(MatchError("position (0, 1) is blocked"))
+		Expect(g.MakeMove(0, 2)).To(Succeed())
+		Expect(g.ApplySetup(setup)).ToNot(Succeed())
+		Expect(g.Clone().Setup).To(Equal(setup))
+
+		g.Reset()
+		Expect(g.Setup).To(BeNil())
+		Expect(g.GetAvailableMoves()).To(HaveLen(9))
+	})
+
+	It("should not count blocked cells as a line", func() {
+		setup, err := game.ParseSetup("Wall", "###/.../...")
+		Expect(err).ToNot(HaveOccurred())
+		g := game.New()
+		Expect(g.ApplySetup(setup)).To(Succeed())
+		for _, move := range []game.Position{{Row: 1, Col: 0}, {Row: 2, Col: 0}, {Row: 1, Col: 1}, {Row: 2, Col: 1}} {
+			Expect(g.MakeMove(move.Row, move.Col)).To(Succeed())
+		}
+		Expect(g.GetStatus()).To(Equal(game.StatusPlaying))
+		Expect(g.MakeMove(1, 2)).To(Succeed())
+		Expect(g.GetWinner()).To(Equal(game.PlayerX))
+	})
+
+	It("should reject setups that are finished or cannot be played", func() {
+		_, err := game.ParseSetup("Won", "XXX/.../...")
+		Expect(err).To(MatchError(ContainSubstring("line of three")))
+		_, err = game.ParseSetup("Full", "###/###/##.")
+		Expect(err).To(MatchError(ContainSubstring("fewer than two cells")))
+		_, err = game.ParseSetup("Bad", "#?./.../...")
+		Expect(err).To(MatchError(ContainSubstring("invalid cell")))
+	})
+
+	It("should look setups up by name or position", func() {
+		setup, err := game.LookupSetup("")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(setup).To(BeNil())
+
+		setup, err = game.LookupSetup("blocked center")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(setup.Name).To(Equal("Blocked center"))
+
+		setup, err = game.LookupSetup("#../.../...")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(setup.Name).To(Equal("#../.../..."))
+
+		_, err = game.LookupSetup("sideways")
+		Expect(err).To(MatchError(ContainSubstring("unknown setup")))
+	})
+
+	It("should draw by repetition from the setup's position", func() {
+		setup, err := game.LookupSetup("Blocked center")
+		Expect(err).ToNot(HaveOccurred())
+		g := game.New()
+		g.SetRules(game.Rules{Variant: game.Achi})
+		Expect(g.ApplySetup(setup)).To(Succeed())
+
+		// XO. / O#X / .XO leaves nobody a line
+		for _, move := range []game.Move{{Row: 0, Col: 0}, {Row: 0, Col: 1}, {Row: 1, Col: 2}, {Row: 1, Col: 0}, {Row: 2, Col: 1}, {Row: 2, Col: 2}} {
+			Expect(g.MakeMove(move.Row, move.Col)).To(Succeed())
+		}
+		slide := func(fromRow, fromCol, toRow, toCol int) {
+			Expect(g.MakeSlide(game.Position{Row: fromRow, Col: fromCol}, game.Position{Row: toRow, Col: toCol})).To(Succeed())
+		}
+		for i := 0; i < 2; i++ {
+			Expect(g.GetStatus()).To(Equal(game.StatusPlaying))
+			slide(2, 1, 2, 0)
+			slide(0, 1, 0, 2)
+			slide(2, 0, 2, 1)
+			slide(0, 2, 0, 1)
+		}
+		Expect(g.GetStatus()).To(Equal(game.StatusDraw))
+	})
+})
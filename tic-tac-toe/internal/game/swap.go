@@ -0,0 +1,202 @@
+package game
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SwapRule lets the second player take over the first player's opening, so
+// that opening too strongly gains nothing
+type SwapRule string
+
+const (
+	NoSwap SwapRule = ""
+	// PieRule offers the second player X's first mark
+	PieRule SwapRule = "pie"
+	// Swap2 has the first player place X, O and X; the second player then
+	// takes X, takes O, or places an O and an X and lets the first player choose
+	Swap2 SwapRule = "swap2"
+)
+
+// SwapRules are the swap rules offered in the settings, in cycling order
+var SwapRules = []SwapRule{NoSwap, PieRule, Swap2}
+
+// ParseSwapRule converts a swap rule name such as "pie" into a SwapRule
+func ParseSwapRule(name string) (SwapRule, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", "off", "none":
+		return NoSwap, nil
+	case "pie":
+		return PieRule, nil
+	case "swap2":
+		return Swap2, nil
+	default:
+		return NoSwap, fmt.Errorf("unknown swap rule %q (want off, pie or swap2)", name)
+	}
+}
+
+// Name returns the display name of the swap rule
+func (r SwapRule) Name() string {
+	switch r {
+	case PieRule:
+		return "Pie rule"
+	case Swap2:
+		return "Swap2"
+	default:
+		return "Off"
+	}
+}
+
+// Seat is a place at the board, which keeps its player when the colors swap
+type Seat int
+
+const (
+	// FirstSeat places the first mark
+	FirstSeat Seat = iota
+	SecondSeat
+)
+
+// String names the seat for display
+func (s Seat) String() string {
+	if s == FirstSeat {
+		return "Player 1"
+	}
+	return "Player 2"
+}
+
+// Other returns the other seat
+func (s Seat) Other() Seat {
+	return 1 - s
+}
+
+// SwapChoice is a decision the swap rule offers
+type SwapChoice int
+
+const (
+	// KeepSides leaves each seat on its color
+	KeepSides SwapChoice = iota
+	// SwapSides exchanges the colors of the seats
+	SwapSides
+	// PlaceTwo places an O and an X and passes the choice back (Swap2)
+	PlaceTwo
+)
+
+// String describes the choice for display
+func (c SwapChoice) String() string {
+	switch c {
+	case SwapSides:
+		return "swap sides"
+	case PlaceTwo:
+		return "place two more marks"
+	default:
+		return "keep sides"
+	}
+}
+
+// Opening follows the swap rule through the opening of one game: which color
+// each seat plays and which decision is due. The first seat starts as X.
+type Opening struct {
+	Rule     SwapRule `json:"rule,omitempty"`
+	Swapped  bool     `json:"swapped,omitempty"`  // The first seat plays O
+	Extended bool     `json:"extended,omitempty"` // Swap2: the second seat chose to place two more marks
+	Decided  bool     `json:"decided,omitempty"`  // The sides are settled
+}
+
+// ColorOf returns the mark the seat plays
+func (o Opening) ColorOf(seat Seat) Player {
+	if (seat == FirstSeat) != o.Swapped {
+		return PlayerX
+	}
+	return PlayerO
+}
+
+// SeatOf returns the seat that plays the mark
+func (o Opening) SeatOf(player Player) Seat {
+	if o.ColorOf(FirstSeat) == player {
+		return FirstSeat
+	}
+	return SecondSeat
+}
+
+// Pending returns the seat that must decide before the next mark, once
+// placed marks are on the board, and its choices
+func (o Opening) Pending(placed int) (Seat, []SwapChoice, bool) {
+	if o.Decided {
+		return FirstSeat, nil, false
+	}
+	switch {
+	case o.Rule == PieRule && placed == 1:
+		return SecondSeat, []SwapChoice{KeepSides, SwapSides}, true
+	case o.Rule == Swap2 && placed == 3 && !o.Extended:
+		return SecondSeat, []SwapChoice{KeepSides, SwapSides, PlaceTwo}, true
+	case o.Rule == Swap2 && placed == 5 && o.Extended:
+		return FirstSeat, []SwapChoice{KeepSides, SwapSides}, true
+	}
+	return FirstSeat, nil, false
+}
+
+// Mover returns the seat that places the next mark, which is toMove's seat
+// except while one seat places the Swap2 opening marks of both colors
+func (o Opening) Mover(placed int, toMove Player) Seat {
+	if o.Rule == Swap2 && !o.Decided {
+		switch {
+		case placed < 3:
+			return FirstSeat
+		case o.Extended && placed < 5:
+			return SecondSeat
+		}
+	}
+	return o.SeatOf(toMove)
+}
+
+// Choose makes the pending decision with placed marks on the board
+func (o *Opening) Choose(placed int, choice SwapChoice) error {
+	seat, choices, ok := o.Pending(placed)
+	if !ok {
+		return fmt.Errorf("no swap decision is due")
+	}
+	offered := false
+	for _, c := range choices {
+		offered = offered || c == choice
+	}
+	if !offered {
+		return fmt.Errorf("%s cannot %s now", seat, choice)
+	}
+
+	switch choice {
+	case PlaceTwo:
+		o.Extended = true
+		return nil
+	case SwapSides:
+		o.Swapped = !o.Swapped
+	}
+	o.Decided = true
+	return nil
+}
+
+// SetSwapRule sets the swap rule for the game from its first move
+func (g *Game) SetSwapRule(rule SwapRule) {
+	g.Opening = Opening{Rule: rule}
+}
+
+// SwapPending returns the seat that must make a swap decision before the
+// next move, and its choices
+func (g *Game) SwapPending() (Seat, []SwapChoice, bool) {
+	if g.Status != StatusPlaying {
+		return FirstSeat, nil, false
+	}
+	return g.Opening.Pending(len(g.MoveHistory))
+}
+
+// ChooseSwap makes the pending swap decision
+func (g *Game) ChooseSwap(choice SwapChoice) error {
+	if g.Status != StatusPlaying {
+		return fmt.Errorf("game is not in playing state")
+	}
+	return g.Opening.Choose(len(g.MoveHistory), choice)
+}
+
+// SeatToMove returns the seat that places the next mark
+func (g *Game) SeatToMove() Seat {
+	return g.Opening.Mover(len(g.MoveHistory), g.CurrentPlayer)
+}
@@ -0,0 +1,79 @@
+package game_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"tic-tac-toe/internal/game"
+)
+
+var _ = Describe("Swap rules", func() {
+	It("should parse every swap rule by name", func() {
+		for _, rule := range game.SwapRules {
+			Expect(game.ParseSwapRule(string(rule))).To(Equal(rule))
+		}
+		Expect(game.ParseSwapRule("off")).To(Equal(game.NoSwap))
+		_, err := game.ParseSwapRule("swap3")
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should play without decisions when off", func() {
+		g := game.New()
+		Expect(g.MakeMove(1, 1)).To(Succeed())
+		_, _, pending := g.SwapPending()
+		Expect(pending).To(BeFalse())
+		Expect(g.SeatToMove()).To(Equal(game.SecondSeat))
+		Expect(g.ChooseSwap(game.SwapSides)).ToNot(Succeed())
+	})
+
+	It("should let the second player take X's first mark under the pie rule", func() {
+		g := game.New()
+		g.SetSwapRule(game.PieRule)
+		Expect(g.SeatToMove()).To(Equal(game.FirstSeat))
+		Expect(g.MakeMove(1, 1)).To(Succeed())
+
+		seat, choices, pending := g.SwapPending()
+		Expect(pending).To(BeTrue())
+		Expect(seat).To(Equal(game.SecondSeat))
+		Expect(choices).To(Equal([]game.SwapChoice{game.KeepSides, game.SwapSides}))
+		Expect(g.MakeMove(0, 0)).To(MatchError("Player 2 must decide whether to swap sides first"))
+		Expect(g.ChooseSwap(game.PlaceTwo)).ToNot(Succeed())
+
+		Expect(g.ChooseSwap(game.SwapSides)).To(Succeed())
+		Expect(g.Opening.ColorOf(game.SecondSeat)).To(Equal(game.PlayerX))
+		Expect(g.Opening.SeatOf(game.PlayerO)).To(Equal(game.FirstSeat))
+		Expect(g.SeatToMove()).To(Equal(game.FirstSeat))
+		Expect(g.MakeMove(0, 0)).To(Succeed())
+		_, _, pending = g.SwapPending()
+		Expect(pending).To(BeFalse())
+
+		g.Reset()
+		Expect(g.Opening).To(Equal(game.Opening{Rule: game.PieRule}))
+	})
+
+	It("should let one seat place the Swap2 opening and the other choose or place two more", func() {
+		g := game.New()
+		g.SetSwapRule(game.Swap2)
+		for _, p := range []game.Position{{Row: 0, Col: 0}, {Row: 1, Col: 1}, {Row: 2, Col: 2}} {
+			Expect(g.SeatToMove()).To(Equal(game.FirstSeat))
+			Expect(g.MakeMove(p.Row, p.Col)).To(Succeed())
+		}
+		seat, choices, _ := g.SwapPending()
+		Expect(seat).To(Equal(game.SecondSeat))
+		Expect(choices).To(ContainElement(game.PlaceTwo))
+
+		Expect(g.ChooseSwap(game.PlaceTwo)).To(Succeed())
+		for _, p := range []game.Position{{Row: 0, Col: 2}, {Row: 2, Col: 0}} {
+			Expect(g.SeatToMove()).To(Equal(game.SecondSeat))
+			Expect(g.MakeMove(p.Row, p.Col)).To(Succeed())
+		}
+		seat, choices, _ = g.SwapPending()
+		Expect(seat).To(Equal(game.FirstSeat))
+		Expect(choices).To(Equal([]game.SwapChoice{game.KeepSides, game.SwapSides}))
+
+		Expect(g.ChooseSwap(game.KeepSides)).To(Succeed())
+		Expect(g.Opening.ColorOf(game.FirstSeat)).To(Equal(game.PlayerX))
+		Expect(g.GetCurrentPlayer()).To(Equal(game.PlayerO))
+		Expect(g.SeatToMove()).To(Equal(game.SecondSeat))
+	})
+})
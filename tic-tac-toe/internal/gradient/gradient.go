@@ -1,7 +1,9 @@
 package gradient
 
 import (
+	"fmt"
 	"math"
+	"strings"
 	"time"
 
 	"github.com/charmbracelet/lipgloss"
@@ -164,6 +166,16 @@ func (g *Gradient) Reset() {
 	g.StartTime = time.Now()
 }
 
+// ParseType converts a gradient name such as "blue" into a GradientType
+func ParseType(name string) (GradientType, error) {
+	for gradientType := Rainbow; gradientType <= Violet; gradientType++ {
+		if strings.EqualFold(New(gradientType).GetTypeName(), name) {
+			return gradientType, nil
+		}
+	}
+	return Rainbow, fmt.Errorf("unknown gradient %q", name)
+}
+
 // GetTypeName returns the string name of the gradient type
 func (g *Gradient) GetTypeName() string {
 	switch g.Type {
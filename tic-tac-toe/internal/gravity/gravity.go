@@ -0,0 +1,259 @@
+package gravity
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"tic-tac-toe/internal/game"
+)
+
+// Shape is the size of a gravity board and the run of marks that wins on it
+type Shape struct {
+	Cols    int
+	Rows    int
+	Connect int
+}
+
+// Shapes are the boards offered in the UI; the first is Connect Four
+var Shapes = []Shape{
+	{Cols: 7, Rows: 6, Connect: 4},
+	{Cols: 6, Rows: 5, Connect: 4},
+	{Cols: 8, Rows: 7, Connect: 5},
+	{Cols: 5, Rows: 4, Connect: 3},
+	{Cols: 3, Rows: 3, Connect: 3},
+}
+
+// Limits on board shapes; columns are chosen with the keys 1 to 9
+const (
+	MinSize = 3
+	MaxSize = 9
+)
+
+// Name writes the shape as columns x rows x connect, e.g. "7x6x4"
+func (s Shape) Name() string {
+	return fmt.Sprintf("%dx%dx%d", s.Cols, s.Rows, s.Connect)
+}
+
+// String describes the shape for display, e.g. "7×6, 4 in a row"
+func (s Shape) String() string {
+	return fmt.Sprintf("%d×%d, %d in a row", s.Cols, s.Rows, s.Connect)
+}
+
+// Validate checks that the board fits the number keys and the run fits the board
+func (s Shape) Validate() error {
+	if s.Cols < MinSize || s.Cols > MaxSize || s.Rows < MinSize || s.Rows > MaxSize {
+		return fmt.Errorf("board %dx%d must be between %d and %d on each side", s.Cols, s.Rows, MinSize, MaxSize)
+	}
+	if s.Connect < MinSize || s.Connect > max(s.Cols, s.Rows) {
+		return fmt.Errorf("cannot connect %d on a %dx%d board", s.Connect, s.Cols, s.Rows)
+	}
+	return nil
+}
+
+// ParseShape reads a shape written by Name
+func ParseShape(name string) (Shape, error) {
+	parts := strings.Split(strings.ToLower(name), "x")
+	if len(parts) != 3 {
+		return Shape{}, fmt.Errorf("invalid board %q (want columns x rows x connect, e.g. 7x6x4)", name)
+	}
+	var sizes [3]int
+	for i, part := range parts {
+		size, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil {
+			return Shape{}, fmt.Errorf("invalid board %q: %w", name, err)
+		}
+		sizes[i] = size
+	}
+	shape := Shape{Cols: sizes[0], Rows: sizes[1], Connect: sizes[2]}
+	if err := shape.Validate(); err != nil {
+		return Shape{}, err
+	}
+	return shape, nil
+}
+
+// directions are the four ways a line can run: across, down and both diagonals
+var directions = []game.Position{{Row: 0, Col: 1}, {Row: 1, Col: 0}, {Row: 1, Col: 1}, {Row: 1, Col: -1}}
+
+// Game is a drop-token game: each mark falls to the lowest empty cell of its
+// column. Row 0 is the top of the board.
+type Game struct {
+	Shape         Shape           `json:"shape"`
+	Board         [][]game.Player `json:"board"`
+	CurrentPlayer game.Player     `json:"current_player"`
+	Status        game.GameStatus `json:"status"`
+	Winner        game.Player     `json:"winner"`
+	MoveHistory   []game.Position `json:"move_history"` // Where each mark landed
+	Opening       game.Opening    `json:"opening"`      // The swap rule and the seats' colors
+}
+
+// New creates an empty board of the given shape with X to move
+func New(shape Shape) *Game {
+	g := &Game{Shape: shape}
+	g.Reset()
+	return g
+}
+
+// Reset empties the board for a new game
+func (g *Game) Reset() {
+	g.Board = make([][]game.Player, g.Shape.Rows)
+	for row := range g.Board {
+		g.Board[row] = make([]game.Player, g.Shape.Cols)
+		for col := range g.Board[row] {
+			g.Board[row][col] = game.Empty
+		}
+	}
+	g.CurrentPlayer = game.PlayerX
+	g.Status = game.StatusPlaying
+	g.Winner = game.Empty
+	g.MoveHistory = make([]game.Position, 0)
+	g.Opening = game.Opening{Rule: g.Opening.Rule}
+}
+
+// Clone returns an independent copy of the game
+func (g *Game) Clone() *Game {
+	clone := *g
+	clone.Board = make([][]game.Player, len(g.Board))
+	for row := range g.Board {
+		clone.Board[row] = append([]game.Player(nil), g.Board[row]...)
+	}
+	clone.MoveHistory = append([]game.Position(nil), g.MoveHistory...)
+	return &clone
+}
+
+// At returns the mark in a cell, or Empty off the board
+func (g *Game) At(row, col int) game.Player {
+	if !g.inside(row, col) {
+		return game.Empty
+	}
+	return g.Board[row][col]
+}
+
+// Landing returns the row a mark dropped in col would land in
+func (g *Game) Landing(col int) (int, bool) {
+	if col < 0 || col >= g.Shape.Cols {
+		return 0, false
+	}
+	for row := g.Shape.Rows - 1; row >= 0; row-- {
+		if g.Board[row][col] == game.Empty {
+			return row, true
+		}
+	}
+	return 0, false
+}
+
+// Drop drops the current player's mark into col and returns where it landed
+func (g *Game) Drop(col int) (game.Position, error) {
+	if g.Status != game.StatusPlaying {
+		return game.Position{}, fmt.Errorf("game is not in playing state")
+	}
+	if seat, _, pending := g.SwapPending(); pending {
+		return game.Position{}, fmt.Errorf("%s must decide whether to swap sides first", seat)
+	}
+	if col < 0 || col >= g.Shape.Cols {
+		return game.Position{}, fmt.Errorf("invalid column: %d", col+1)
+	}
+	row, ok := g.Landing(col)
+	if !ok {
+		return game.Position{}, fmt.Errorf("column %d is full", col+1)
+	}
+
+	g.Board[row][col] = g.CurrentPlayer
+	landed := game.Position{Row: row, Col: col}
+	g.MoveHistory = append(g.MoveHistory, landed)
+
+	if len(g.lineThrough(row, col)) > 0 {
+		g.Status = game.StatusWon
+		g.Winner = g.CurrentPlayer
+		return landed, nil
+	}
+	if len(g.MoveHistory) == g.Shape.Cols*g.Shape.Rows {
+		g.Status = game.StatusDraw
+		return landed, nil
+	}
+	g.CurrentPlayer = other(g.CurrentPlayer)
+	return landed, nil
+}
+
+// SetSwapRule sets the swap rule for the game from its first mark
+func (g *Game) SetSwapRule(rule game.SwapRule) {
+	g.Opening = game.Opening{Rule: rule}
+}
+
+// SwapPending returns the seat that must make a swap decision before the
+// next mark, and its choices
+func (g *Game) SwapPending() (game.Seat, []game.SwapChoice, bool) {
+	if g.Status != game.StatusPlaying {
+		return game.FirstSeat, nil, false
+	}
+	return g.Opening.Pending(len(g.MoveHistory))
+}
+
+// ChooseSwap makes the pending swap decision
+func (g *Game) ChooseSwap(choice game.SwapChoice) error {
+	if g.Status != game.StatusPlaying {
+		return fmt.Errorf("game is not in playing state")
+	}
+	return g.Opening.Choose(len(g.MoveHistory), choice)
+}
+
+// SeatToMove returns the seat that drops the next mark
+func (g *Game) SeatToMove() game.Seat {
+	return g.Opening.Mover(len(g.MoveHistory), g.CurrentPlayer)
+}
+
+// WinningLine returns the cells of the line that won the game, if any
+func (g *Game) WinningLine() []game.Position {
+	if g.Status != game.StatusWon || len(g.MoveHistory) == 0 {
+		return nil
+	}
+	last := g.MoveHistory[len(g.MoveHistory)-1]
+	return g.lineThrough(last.Row, last.Col)
+}
+
+// lineThrough returns the longest run of the mark at (row, col) through it
+// if that run is long enough to win
+func (g *Game) lineThrough(row, col int) []game.Position {
+	player := g.Board[row][col]
+	for _, d := range directions {
+		line := []game.Position{{Row: row, Col: col}}
+		for _, sign := range []int{-1, 1} {
+			r, c := row+sign*d.Row, col+sign*d.Col
+			for g.At(r, c) == player {
+				line = append(line, game.Position{Row: r, Col: c})
+				r, c = r+sign*d.Row, c+sign*d.Col
+			}
+		}
+		if len(line) >= g.Shape.Connect {
+			return line
+		}
+	}
+	return nil
+}
+
+// AvailableColumns lists the columns that still have room, left to right
+func (g *Game) AvailableColumns() []int {
+	var cols []int
+	if g.Status != game.StatusPlaying {
+		return cols
+	}
+	for col := 0; col < g.Shape.Cols; col++ {
+		if g.Board[0][col] == game.Empty {
+			cols = append(cols, col)
+		}
+	}
+	return cols
+}
+
+// inside reports whether a cell is on the board
+func (g *Game) inside(row, col int) bool {
+	return row >= 0 && row < g.Shape.Rows && col >= 0 && col < g.Shape.Cols
+}
+
+// other returns the opponent of player
+func other(player game.Player) game.Player {
+	if player == game.PlayerX {
+		return game.PlayerO
+	}
+	return game.PlayerX
+}
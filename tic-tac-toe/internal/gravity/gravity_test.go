@@ -0,0 +1,194 @@
+package gravity_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"tic-tac-toe/internal/game"
+	"tic-tac-toe/internal/gravity"
+)
+
+var _ = Describe("Gravity", func() {
+	connectFour := gravity.Shapes[0]
+
+	// play drops marks into the columns in turn, X first
+	play := func(shape gravity.Shape, cols ...int) *gravity.Game {
+		g := gravity.New(shape)
+		for _, col := range cols {
+			_, err := g.Drop(col)
+			Expect(err).ToNot(HaveOccurred())
+		}
+		return g
+	}
+
+	Describe("Shapes", func() {
+		It("should round-trip shape names", func() {
+			for _, shape := range gravity.Shapes {
+				Expect(shape.Validate()).To(Succeed())
+				Expect(gravity.ParseShape(shape.Name())).To(Equal(shape))
+			}
+			Expect(connectFour.String()).To(Equal("7×6, 4 in a row"))
+		})
+
+		It("should reject boards that do not fit", func() {
+			for _, name := range []string{"7x6", "seven", "10x6x4", "7x2x3", "4x4x5", "7x6x2"} {
+				_, err := gravity.ParseShape(name)
+				Expect(err).To(HaveOccurred(), name)
+			}
+		})
+	})
+
+	Describe("Drop", func() {
+		It("should stack marks from the bottom of the column", func() {
+			g := gravity.New(connectFour)
+			Expect(g.Drop(3)).To(Equal(game.Position{Row: 5, Col: 3}))
+			Expect(g.Drop(3)).To(Equal(game.Position{Row: 4, Col: 3}))
+			Expect(g.At(5, 3)).To(Equal(game.PlayerX))
+			Expect(g.At(4, 3)).To(Equal(game.PlayerO))
+			Expect(g.CurrentPlayer).To(Equal(game.PlayerX))
+		})
+
+		It("should reject full columns and columns off the board", func() {
+			g := play(gravity.Shape{Cols: 3, Rows: 3, Connect: 3}, 0, 0, 0)
+			Expect(g.AvailableColumns()).To(Equal([]int{1, 2}))
+
+			_, err := g.Drop(0)
+			Expect(err).To(MatchError("column 1 is full"))
+			_, err = g.Drop(3)
+			Expect(err).To(MatchError("invalid column: 4"))
+		})
+
+		It("should win with a column of four", func() {
+			g := play(connectFour, 0, 1, 0, 1, 0, 1, 0)
+			Expect(g.Status).To(Equal(game.StatusWon))
+			Expect(g.Winner).To(Equal(game.PlayerX))
+			Expect(g.WinningLine()).To(ConsistOf(
+				game.Position{Row: 2, Col: 0}, game.Position{Row: 3, Col: 0},
+				game.Position{Row: 4, Col: 0}, game.Position{Row: 5, Col: 0},
+			))
+
+			_, err := g.Drop(2)
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("should win along a diagonal", func() {
+			// X climbs from (5,0) to (2,3) while O fills underneath
+			g := play(connectFour, 0, 1, 1, 2, 2, 3, 2, 3, 3, 6, 3)
+			Expect(g.Status).To(Equal(game.StatusWon))
+			Expect(g.Winner).To(Equal(game.PlayerX))
+			Expect(g.WinningLine()).To(HaveLen(4))
+		})
+
+		It("should draw when the board fills without a line", func() {
+			g := play(gravity.Shape{Cols: 3, Rows: 3, Connect: 3}, 0, 1, 2, 1, 0, 2, 1, 0, 2)
+			Expect(g.Status).To(Equal(game.StatusDraw))
+			Expect(g.Winner).To(Equal(game.Empty))
+		})
+
+		It("should keep clones independent", func() {
+			g := play(connectFour, 3)
+			clone := g.Clone()
+			clone.Drop(3)
+			Expect(g.At(4, 3)).To(Equal(game.Empty))
+			Expect(g.MoveHistory).To(HaveLen(1))
+		})
+	})
+
+	Describe("Evaluate", func() {
+		It("should be zero on an empty board and favor the side with open lines", func() {
+			Expect(gravity.New(connectFour).Evaluate(game.PlayerX)).To(BeZero())
+
+			g := play(connectFour, 3, 0, 3, 0)
+			Expect(g.Evaluate(game.PlayerX)).To(BeNumerically(">", 0))
+			Expect(g.Evaluate(game.PlayerO)).To(Equal(-g.Evaluate(game.PlayerX)))
+		})
+	})
+
+	Describe("AI", func() {
+		var player *gravity.AI
+
+		BeforeEach(func() {
+			player = gravity.NewAI(game.PlayerX, 1)
+			player.SetSeed(1)
+		})
+
+		It("should look further ahead when stronger", func() {
+			Expect(gravity.NewAI(game.PlayerO, 0).GetDepth()).To(BeNumerically("<", player.GetDepth()))
+		})
+
+		It("should complete four in a row", func() {
+			g := play(connectFour, 2, 6, 2, 6, 2, 5)
+			Expect(player.Move(context.Background(), g)).To(Equal(2))
+		})
+
+		It("should block the opponent's four", func() {
+			blocker := gravity.NewAI(game.PlayerO, 1)
+			blocker.SetSeed(1)
+			g := play(connectFour, 0, 6, 1, 6, 2)
+			Expect(blocker.Move(context.Background(), g)).To(Equal(3))
+		})
+
+		It("should not drop a mark that lets the opponent win above it", func() {
+			// O threatens to complete row 4 at (4,3); X must not fill (5,3) under it
+			g := play(connectFour, 0, 1, 2, 0, 6, 1, 6, 2)
+			col, err := player.Move(context.Background(), g)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(col).ToNot(Equal(3))
+		})
+
+		It("should stop when the context is cancelled", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			_, err := player.Move(ctx, gravity.New(connectFour))
+			Expect(err).To(MatchError(context.Canceled))
+		})
+
+		It("should open on the edge when the opponent may take its mark", func() {
+			g := gravity.New(connectFour)
+			g.SetSwapRule(game.PieRule)
+			Expect(player.Move(context.Background(), g)).To(BeElementOf(0, 6))
+		})
+
+		It("should take the side the search favors under the pie rule", func() {
+			chooser := gravity.NewAI(game.PlayerO, 1)
+			chooser.SetSeed(1)
+			g := gravity.New(connectFour)
+			g.SetSwapRule(game.PieRule)
+			_, err := chooser.DecideSwap(context.Background(), g)
+			Expect(err).To(HaveOccurred())
+
+			_, err = g.Drop(3)
+			Expect(err).ToNot(HaveOccurred())
+			_, err = g.Drop(4)
+			Expect(err).To(MatchError("Player 2 must decide whether to swap sides first"))
+			Expect(chooser.DecideSwap(context.Background(), g)).To(Equal(game.SwapSides))
+
+			Expect(g.ChooseSwap(game.SwapSides)).To(Succeed())
+			Expect(g.Opening.ColorOf(game.SecondSeat)).To(Equal(game.PlayerX))
+			Expect(g.SeatToMove()).To(Equal(game.FirstSeat))
+			g.Reset()
+			Expect(g.Opening).To(Equal(game.Opening{Rule: game.PieRule}))
+		})
+
+		It("should play whole games on every board", func() {
+			for _, shape := range gravity.Shapes {
+				opponent := gravity.NewAI(game.PlayerO, 0.5)
+				opponent.SetSeed(2)
+				g := gravity.New(shape)
+				for g.Status == game.StatusPlaying {
+					mover := player
+					if g.CurrentPlayer == game.PlayerO {
+						mover = opponent
+					}
+					col, err := mover.Move(context.Background(), g)
+					Expect(err).ToNot(HaveOccurred())
+					_, err = g.Drop(col)
+					Expect(err).ToNot(HaveOccurred())
+				}
+				Expect(g.Status).To(Or(Equal(game.StatusWon), Equal(game.StatusDraw)), shape.Name())
+			}
+		})
+	})
+})
@@ -0,0 +1,283 @@
+package gravity
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+
+	"tic-tac-toe/internal/ai"
+	"tic-tac-toe/internal/game"
+)
+
+// errNoMoves is returned when the board is full or the game is over
+var errNoMoves = errors.New("no moves available")
+
+// winScore outweighs any heuristic score; quicker wins score higher
+const winScore = 1_000_000
+
+// AI plays gravity games. Boards like 7×6 are too big to search to the end,
+// so the AI searches a few moves ahead with alpha-beta pruning and scores the
+// positions it stops at with Evaluate.
+type AI struct {
+	player       game.Player
+	params       ai.Params
+	depth        int // Moves to look ahead
+	randomSource *rand.Rand
+}
+
+// NewAI creates a gravity AI for player at a strength from 0 to 1; stronger
+// AIs look further ahead and blunder less, as in the mistake model of the
+// 3×3 AI
+func NewAI(player game.Player, strength float64) *AI {
+	params := ai.ParamsForStrength(strength)
+	return &AI{
+		player:       player,
+		params:       params,
+		depth:        1 + int(math.Round(params.Strength*6)),
+		randomSource: rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// SetSeed makes the AI's random choices reproducible
+func (a *AI) SetSeed(seed int64) {
+	a.randomSource = rand.New(rand.NewSource(seed))
+}
+
+// SetPlayer changes the side the AI plays, as when the swap rule exchanges the sides
+func (a *AI) SetPlayer(player game.Player) {
+	a.player = player
+}
+
+// GetPlayer returns the side the AI plays
+func (a *AI) GetPlayer() game.Player {
+	return a.player
+}
+
+// GetDepth returns how many moves the AI looks ahead
+func (a *AI) GetDepth() int {
+	return a.depth
+}
+
+// Move chooses the column for the player to move in g. It deepens the search
+// one move at a time, so a cancelled context returns the best column of the
+// deepest search that finished, or the context's error if none did. While a
+// swap rule leaves the sides open, the opponent will choose a side after the
+// mark, so the AI drops the mark that leaves the position most even.
+func (a *AI) Move(ctx context.Context, g *Game) (int, error) {
+	cols := g.AvailableColumns()
+	if len(cols) == 0 {
+		return 0, errNoMoves
+	}
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	if a.randomSource.Float64() < a.params.Blunder {
+		return cols[a.randomSource.Intn(len(cols))], nil
+	}
+
+	cols = a.order(g, cols)
+	if g.Opening.Rule != game.NoSwap && !g.Opening.Decided {
+		return balancedColumn(g.Clone(), cols), nil
+	}
+	col, _, err := a.deepen(ctx, g, cols)
+	return col, err
+}
+
+// DecideSwap makes the swap decision due in g: the seat that must decide
+// takes the color that a forced result found by the search favors, or else
+// the one the board's lines favor, keeping its side between equals. It always
+// picks a side rather than placing two more marks.
+func (a *AI) DecideSwap(ctx context.Context, g *Game) (game.SwapChoice, error) {
+	seat, _, ok := g.SwapPending()
+	if !ok {
+		return game.KeepSides, fmt.Errorf("no swap decision is due")
+	}
+
+	_, score, err := a.deepen(ctx, g, a.order(g, g.AvailableColumns()))
+	if err != nil {
+		return game.KeepSides, err
+	}
+	if score < winScore-a.depth && score > -winScore+a.depth {
+		// Nothing is forced yet: the search would only weigh who moves next
+		score = g.Evaluate(g.CurrentPlayer)
+	}
+	if g.Opening.ColorOf(seat) != g.CurrentPlayer {
+		score = -score
+	}
+	if score < 0 {
+		return game.SwapSides, nil
+	}
+	return game.KeepSides, nil
+}
+
+// order sorts columns central first, with columns as central as each other
+// in random order
+func (a *AI) order(g *Game, cols []int) []int {
+	a.randomSource.Shuffle(len(cols), func(i, j int) { cols[i], cols[j] = cols[j], cols[i] })
+	center := float64(g.Shape.Cols-1) / 2
+	sort.SliceStable(cols, func(i, j int) bool {
+		return math.Abs(float64(cols[i])-center) < math.Abs(float64(cols[j])-center)
+	})
+	return cols
+}
+
+// deepen searches one move deeper at a time up to the AI's depth and returns
+// the best column with its score for the player to move
+func (a *AI) deepen(ctx context.Context, g *Game, cols []int) (int, int, error) {
+	s := &search{ctx: ctx, g: g.Clone(), order: cols}
+	best, bestScore, found := 0, 0, false
+	for depth := 1; depth <= a.depth; depth++ {
+		col, score, ok := s.root(depth)
+		if !ok {
+			break
+		}
+		best, bestScore, found = col, score, true
+		if score >= winScore-depth || score <= -winScore+depth {
+			break // The result is decided; looking deeper changes nothing
+		}
+	}
+	if !found {
+		return 0, 0, ctx.Err()
+	}
+	return best, bestScore, nil
+}
+
+// balancedColumn returns the column whose mark leaves the position scored
+// closest to even, preferring earlier columns in cols between equals
+func balancedColumn(g *Game, cols []int) int {
+	player := g.CurrentPlayer
+	best, bestScore := cols[0], math.MaxInt
+	for _, col := range cols {
+		row, _ := g.Landing(col)
+		g.Board[row][col] = player
+		score := g.Evaluate(player)
+		if len(g.lineThrough(row, col)) > 0 {
+			score = winScore
+		}
+		g.Board[row][col] = game.Empty
+		if score < 0 {
+			score = -score
+		}
+		if score < bestScore {
+			best, bestScore = col, score
+		}
+	}
+	return best
+}
+
+// search is one alpha-beta search, dropping and lifting marks on its own copy of the board
+type search struct {
+	ctx       context.Context
+	g         *Game
+	order     []int // Columns in the order to try them
+	nodes     int
+	cancelled bool
+}
+
+// root searches every column to depth and returns the best with its score,
+// or false if the search was cancelled
+func (s *search) root(depth int) (int, int, bool) {
+	player := s.g.CurrentPlayer
+	best, bestScore := -1, math.MinInt
+	alpha := -winScore - 1
+	for _, col := range s.order {
+		row, ok := s.g.Landing(col)
+		if !ok {
+			continue
+		}
+		score := s.score(row, col, player, depth, alpha, winScore+1, 1)
+		if s.cancelled {
+			return 0, 0, false
+		}
+		if score > bestScore {
+			best, bestScore = col, score
+		}
+		alpha = max(alpha, score)
+	}
+	return best, bestScore, true
+}
+
+// score drops player's mark at (row, col), scores the result for player and
+// lifts the mark again
+func (s *search) score(row, col int, player game.Player, depth, alpha, beta, ply int) int {
+	s.g.Board[row][col] = player
+	defer func() { s.g.Board[row][col] = game.Empty }()
+
+	if len(s.g.lineThrough(row, col)) > 0 {
+		return winScore - ply
+	}
+	if depth <= 1 {
+		return s.g.Evaluate(player)
+	}
+	return -s.negamax(other(player), depth-1, -beta, -alpha, ply+1)
+}
+
+// negamax scores the position for player to move, looking depth moves ahead
+func (s *search) negamax(player game.Player, depth, alpha, beta, ply int) int {
+	s.nodes++
+	if s.nodes%1024 == 0 && s.ctx.Err() != nil {
+		s.cancelled = true
+	}
+	if s.cancelled {
+		return 0
+	}
+
+	best, moved := -winScore-1, false
+	for _, col := range s.order {
+		row, ok := s.g.Landing(col)
+		if !ok {
+			continue
+		}
+		moved = true
+		score := s.score(row, col, player, depth, alpha, beta, ply)
+		best = max(best, score)
+		alpha = max(alpha, score)
+		if alpha >= beta {
+			break
+		}
+	}
+	if !moved {
+		return 0 // The board is full: a draw
+	}
+	return best
+}
+
+// Evaluate scores a position without searching it, from player's side.
+// Every run of Connect cells that only one side holds counts for that side,
+// and counts eight times as much for each extra mark in it, so open lines
+// near completion dominate.
+func (g *Game) Evaluate(player game.Player) int {
+	score := 0
+	for row := 0; row < g.Shape.Rows; row++ {
+		for col := 0; col < g.Shape.Cols; col++ {
+			for _, d := range directions {
+				endRow, endCol := row+(g.Shape.Connect-1)*d.Row, col+(g.Shape.Connect-1)*d.Col
+				if !g.inside(endRow, endCol) {
+					continue
+				}
+				mine, theirs := 0, 0
+				for i := 0; i < g.Shape.Connect; i++ {
+					switch g.Board[row+i*d.Row][col+i*d.Col] {
+					case player:
+						mine++
+					case game.Empty:
+					default:
+						theirs++
+					}
+				}
+				switch {
+				case mine > 0 && theirs == 0:
+					score += 1 << (3 * (mine - 1))
+				case theirs > 0 && mine == 0:
+					score -= 1 << (3 * (theirs - 1))
+				}
+			}
+		}
+	}
+	return score
+}
@@ -33,13 +33,49 @@ const (
 	ActionMenu4
 	ActionMenu5
 	ActionMenu6
+	ActionMenu7
+	ActionMenu8
+	ActionMenu9
 	ActionCycleDifficulty
 	ActionSpeedUp
 	ActionSpeedDown
 	ActionCycleCursor
+	ActionChat
+	ActionEmote1
+	ActionEmote2
+	ActionEmote3
+	ActionEmote4
+	ActionTextChanged
+	ActionTextSubmit
+	ActionTextCancel
+	ActionNewGame
+	ActionCycleTimeControl
+	ActionStrengthUp
+	ActionStrengthDown
+	ActionCyclePersonality
+	ActionUndo
+	ActionToggleMark
+	ActionLayerUp
+	ActionLayerDown
+	ActionCycleBoard
+	ActionCycleSwapRule
+	ActionSwapSides
+	ActionPlaceTwo
+	ActionCycleMatch
+	ActionCycleSearch
 	ActionUnknown
 )
 
+// InputMode determines how key presses are interpreted
+type InputMode int
+
+const (
+	// ModeKeybindings maps every key to a KeybindingAction
+	ModeKeybindings InputMode = iota
+	// ModeText collects typed runes into a text buffer
+	ModeText
+)
+
 // Keybinding represents a key and its action
 type Keybinding struct {
 	Key         string
@@ -52,6 +88,10 @@ type Handler struct {
 	keybindings []Keybinding
 	cursorX     int
 	cursorY     int
+	mode        InputMode
+	text        []rune
+	textLimit   int
+	source      *[2]int // Cell picked as the first step of a two-step move, as x, y
 }
 
 // New creates a new input handler
@@ -84,16 +124,39 @@ func getDefaultKeybindings() []Keybinding {
 		{"4", ActionMenu4, "Menu option 4"},
 		{"5", ActionMenu5, "Menu option 5"},
 		{"6", ActionMenu6, "Menu option 6"},
+		{"7", ActionMenu7, "Menu option 7"},
+		{"8", ActionMenu8, "Menu option 8"},
+		{"9", ActionMenu9, "Menu option 9"},
 		{"d", ActionCycleDifficulty, "Cycle AI difficulty"},
 		{"+", ActionSpeedUp, "Increase animation speed"},
 		{"-", ActionSpeedDown, "Decrease animation speed"},
 		{"c", ActionCycleCursor, "Cycle cursor symbol"},
-		// Note: space, enter, esc handled in special keys section
+		{"/", ActionChat, "Chat (online games)"},
+		{"n", ActionNewGame, "New correspondence game"},
+		{"m", ActionCycleTimeControl, "Cycle time control"},
+		{"]", ActionStrengthUp, "Increase AI strength"},
+		{"[", ActionStrengthDown, "Decrease AI strength"},
+		{"p", ActionCyclePersonality, "Cycle AI personality"},
+		{"u", ActionUndo, "Undo last move"},
+		{"v", ActionToggleMark, "Switch the mark to place (Wild, Order and Chaos)"},
+		{".", ActionLayerUp, "Next layer (3D Qubic)"},
+		{",", ActionLayerDown, "Previous layer (3D Qubic)"},
+		{"b", ActionCycleBoard, "Cycle board size (Gravity, Multi-player) or Notakto boards"},
+		{"o", ActionCycleSwapRule, "Cycle opening swap rule"},
+		{"y", ActionSwapSides, "Swap sides (swap rule)"},
+		{"e", ActionPlaceTwo, "Place two more marks (Swap2)"},
+		{"f", ActionCycleMatch, "Cycle match format"},
+		{"i", ActionCycleSearch, "Cycle AI search (Multi-player)"},
+		// Note: space, enter, esc and F1-F4 emotes handled in special keys section
 	}
 }
 
 // ProcessKeyMsg processes keyboard input and returns the action
 func (h *Handler) ProcessKeyMsg(msg tea.KeyMsg) KeybindingAction {
+	if h.mode == ModeText {
+		return h.processTextKey(msg)
+	}
+
 	// Handle special keys
 	switch msg.Type {
 	case tea.KeyUp:
@@ -110,6 +173,14 @@ func (h *Handler) ProcessKeyMsg(msg tea.KeyMsg) KeybindingAction {
 		return ActionSelect
 	case tea.KeyEsc:
 		return ActionBack
+	case tea.KeyF1:
+		return ActionEmote1
+	case tea.KeyF2:
+		return ActionEmote2
+	case tea.KeyF3:
+		return ActionEmote3
+	case tea.KeyF4:
+		return ActionEmote4
 	}
 	
 	// Handle character keys
@@ -129,6 +200,65 @@ func (h *Handler) ProcessKeyMsg(msg tea.KeyMsg) KeybindingAction {
 	return ActionUnknown
 }
 
+// processTextKey edits the text buffer while in text mode
+func (h *Handler) processTextKey(msg tea.KeyMsg) KeybindingAction {
+	switch msg.Type {
+	case tea.KeyEnter:
+		return ActionTextSubmit
+	case tea.KeyEsc:
+		return ActionTextCancel
+	case tea.KeyBackspace:
+		if len(h.text) > 0 {
+			h.text = h.text[:len(h.text)-1]
+		}
+		return ActionTextChanged
+	case tea.KeySpace:
+		h.appendText([]rune{' '})
+		return ActionTextChanged
+	case tea.KeyRunes:
+		h.appendText(msg.Runes)
+		return ActionTextChanged
+	}
+
+	return ActionUnknown
+}
+
+// appendText adds runes to the text buffer up to the limit
+func (h *Handler) appendText(runes []rune) {
+	for _, r := range runes {
+		if h.textLimit > 0 && len(h.text) >= h.textLimit {
+			return
+		}
+		h.text = append(h.text, r)
+	}
+}
+
+// BeginTextEntry switches to text mode with an empty buffer of at most limit runes (0 for no limit)
+func (h *Handler) BeginTextEntry(limit int) {
+	h.mode = ModeText
+	h.text = nil
+	h.textLimit = limit
+}
+
+// EndTextEntry returns to keybinding mode and returns the typed text
+func (h *Handler) EndTextEntry() string {
+	text := string(h.text)
+	h.mode = ModeKeybindings
+	h.text = nil
+	h.textLimit = 0
+	return text
+}
+
+// GetMode returns the current input mode
+func (h *Handler) GetMode() InputMode {
+	return h.mode
+}
+
+// GetText returns the text typed so far in text mode
+func (h *Handler) GetText() string {
+	return string(h.text)
+}
+
 // ProcessMouseMsg processes mouse input and returns click position
 func (h *Handler) ProcessMouseMsg(msg tea.MouseMsg) *MouseClickMsg {
 	if msg.Type == tea.MouseLeft {
@@ -181,6 +311,27 @@ func (h *Handler) SetCursorPosition(x, y int) {
 	}
 }
 
+// SelectSource picks the cell a two-step move starts from, such as a mark to
+// be moved; positions off the board are ignored
+func (h *Handler) SelectSource(x, y int) {
+	if x >= 0 && x <= 2 && y >= 0 && y <= 2 {
+		h.source = &[2]int{x, y}
+	}
+}
+
+// GetSource returns the cell picked by SelectSource, if any
+func (h *Handler) GetSource() (int, int, bool) {
+	if h.source == nil {
+		return 0, 0, false
+	}
+	return h.source[0], h.source[1], true
+}
+
+// ClearSource forgets the first step of a two-step move
+func (h *Handler) ClearSource() {
+	h.source = nil
+}
+
 // MouseToGamePosition converts mouse coordinates to game board position
 // NOTE: This is a basic implementation. For full dynamic support, the UI model
 // would need to pass current board dimensions to the input handler.
@@ -317,6 +468,12 @@ func GetActionName(action KeybindingAction) string {
 		return "Menu Option 5"
 	case ActionMenu6:
 		return "Menu Option 6"
+	case ActionMenu7:
+		return "Menu Option 7"
+	case ActionMenu8:
+		return "Menu Option 8"
+	case ActionMenu9:
+		return "Menu Option 9"
 	case ActionCycleDifficulty:
 		return "Cycle AI Difficulty"
 	case ActionSpeedUp:
@@ -325,6 +482,52 @@ func GetActionName(action KeybindingAction) string {
 		return "Decrease Speed"
 	case ActionCycleCursor:
 		return "Cycle Cursor"
+	case ActionChat:
+		return "Chat"
+	case ActionEmote1:
+		return "Emote 1"
+	case ActionEmote2:
+		return "Emote 2"
+	case ActionEmote3:
+		return "Emote 3"
+	case ActionEmote4:
+		return "Emote 4"
+	case ActionTextChanged:
+		return "Text Changed"
+	case ActionTextSubmit:
+		return "Submit Text"
+	case ActionTextCancel:
+		return "Cancel Text"
+	case ActionNewGame:
+		return "New Game"
+	case ActionCycleTimeControl:
+		return "Cycle Time Control"
+	case ActionStrengthUp:
+		return "Increase Strength"
+	case ActionStrengthDown:
+		return "Decrease Strength"
+	case ActionCyclePersonality:
+		return "Cycle Personality"
+	case ActionUndo:
+		return "Undo"
+	case ActionToggleMark:
+		return "Toggle Mark"
+	case ActionLayerUp:
+		return "Next Layer"
+	case ActionLayerDown:
+		return "Previous Layer"
+	case ActionCycleBoard:
+		return "Cycle Board"
+	case ActionCycleSwapRule:
+		return "Cycle Swap Rule"
+	case ActionSwapSides:
+		return "Swap Sides"
+	case ActionPlaceTwo:
+		return "Place Two"
+	case ActionCycleMatch:
+		return "Cycle Match"
+	case ActionCycleSearch:
+		return "Cycle Search"
 	default:
 		return "Unknown"
 	}
@@ -235,6 +235,84 @@ var _ = Describe("Input", func() {
 		})
 	})
 
+	Describe("Two-step moves", func() {
+		It("should remember the selected source until cleared", func() {
+			_, _, ok := handler.GetSource()
+			Expect(ok).To(BeFalse())
+
+			handler.SelectSource(2, 0)
+			x, y, ok := handler.GetSource()
+			Expect(ok).To(BeTrue())
+			Expect(x).To(Equal(2))
+			Expect(y).To(Equal(0))
+
+			handler.SelectSource(3, 0)
+			x, _, _ = handler.GetSource()
+			Expect(x).To(Equal(2))
+
+			handler.ClearSource()
+			_, _, ok = handler.GetSource()
+			Expect(ok).To(BeFalse())
+		})
+	})
+
+	Describe("Text entry mode", func() {
+		typeText := func(text string) {
+			for _, r := range text {
+				key := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}}
+				if r == ' ' {
+					key = tea.KeyMsg{Type: tea.KeySpace}
+				}
+				Expect(handler.ProcessKeyMsg(key)).To(Equal(input.ActionTextChanged))
+			}
+		}
+
+		It("should start in keybinding mode", func() {
+			Expect(handler.GetMode()).To(Equal(input.ModeKeybindings))
+		})
+
+		It("should treat keybinding runes as text", func() {
+			handler.BeginTextEntry(0)
+			Expect(handler.GetMode()).To(Equal(input.ModeText))
+
+			typeText("quit now")
+			Expect(handler.GetText()).To(Equal("quit now"))
+		})
+
+		It("should support backspace, submit and cancel", func() {
+			handler.BeginTextEntry(0)
+			typeText("hi!")
+			Expect(handler.ProcessKeyMsg(tea.KeyMsg{Type: tea.KeyBackspace})).To(Equal(input.ActionTextChanged))
+			Expect(handler.GetText()).To(Equal("hi"))
+
+			Expect(handler.ProcessKeyMsg(tea.KeyMsg{Type: tea.KeyEnter})).To(Equal(input.ActionTextSubmit))
+			Expect(handler.ProcessKeyMsg(tea.KeyMsg{Type: tea.KeyEsc})).To(Equal(input.ActionTextCancel))
+		})
+
+		It("should enforce the length limit", func() {
+			handler.BeginTextEntry(3)
+			typeText("hello")
+			Expect(handler.GetText()).To(Equal("hel"))
+		})
+
+		It("should return the text and restore keybindings when ended", func() {
+			handler.BeginTextEntry(0)
+			typeText("gg")
+			Expect(handler.EndTextEntry()).To(Equal("gg"))
+			Expect(handler.GetMode()).To(Equal(input.ModeKeybindings))
+
+			qKey := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'q'}}
+			Expect(handler.ProcessKeyMsg(qKey)).To(Equal(input.ActionQuit))
+		})
+
+		It("should map chat and emote keys in keybinding mode", func() {
+			slash := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'/'}}
+			Expect(handler.ProcessKeyMsg(slash)).To(Equal(input.ActionChat))
+			Expect(handler.ProcessKeyMsg(tea.KeyMsg{Type: tea.KeyF1})).To(Equal(input.ActionEmote1))
+			Expect(handler.ProcessKeyMsg(tea.KeyMsg{Type: tea.KeyF4})).To(Equal(input.ActionEmote4))
+		})
+	})
+
 	Describe("GetActionName", func() {
 		It("should return correct action names", func() {
 			Expect(input.GetActionName(input.ActionMoveUp)).To(Equal("Move Up"))
@@ -0,0 +1,450 @@
+// Package lineui is a plain-text front end that reads one command per line
+// and prints the board without any terminal control sequences, so the game
+// can be played over pipes, in CI and with screen readers.
+package lineui
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"tic-tac-toe/internal/ai"
+	"tic-tac-toe/internal/game"
+	"tic-tac-toe/internal/persistence"
+)
+
+// ANSI colours used for the marks when Color is set
+const (
+	colorX     = "\x1b[1;31m"
+	colorO     = "\x1b[1;34m"
+	colorReset = "\x1b[0m"
+)
+
+// helpText lists the commands the line mode understands
+const helpText = "Moves are a column letter and row number, e.g. b2. Commands: undo, hint, board, help, quit."
+
+// markHelp, slideHelp and boardHelp explain the moves of variants where
+// players choose their mark, move marks already placed or play on several
+// boards
+const (
+	markHelp  = "Add the mark to place after the cell, e.g. b2 o."
+	slideHelp = "Once all three marks are placed, move one by naming both cells, e.g. a1 b2."
+	boardHelp = "Name the board before the cell, e.g. 2 b2; without one the move is on board 1."
+)
+
+// Options configures a line-mode game
+type Options struct {
+	Mode        game.GameMode        // PlayerVsPlayer or PlayerVsAI
+	Difficulty  ai.Difficulty        // AI level in PlayerVsAI games
+	Strength    *float64             // Overrides the level's strength from 0 to 1
+	Personality ai.Personality       // Playing style; the zero value plays without one
+	HumanPlayer game.Player          // The side the human plays against the AI; X by default
+	Seed        int64                // Seeds the AI's random choices; zero picks a random seed
+	Color       bool                 // Colour the marks with ANSI escapes
+	Persistence *persistence.Manager // Records scores and archives the game; nil skips both
+	Rules       game.Rules           // The rule variant; the zero value is classic
+}
+
+// session is one line-mode game in progress
+type session struct {
+	opts Options
+	out  io.Writer
+	game *game.Game
+	ai   *ai.AI
+}
+
+// Run plays one game, reading commands from in and writing to out. It
+// returns when the game ends, the player quits or the input is exhausted,
+// and fails if the AI cannot make its move.
+func Run(in io.Reader, out io.Writer, opts Options) error {
+	if opts.HumanPlayer == "" {
+		opts.HumanPlayer = game.PlayerX
+	}
+
+	s := &session{opts: opts, out: out, game: game.New()}
+	s.game.SetMode(opts.Mode)
+	s.game.SetRules(opts.Rules)
+	if opts.Mode == game.PlayerVsAI {
+		aiSide := game.PlayerO
+		if opts.HumanPlayer == game.PlayerO {
+			aiSide = game.PlayerX
+		}
+		s.ai = ai.New(opts.Difficulty, aiSide)
+		if opts.Strength != nil {
+			s.ai.SetStrength(*opts.Strength)
+		}
+		s.ai.SetPersonality(opts.Personality)
+		if opts.Seed != 0 {
+			s.ai.SetSeed(opts.Seed)
+		}
+		if opts.Persistence != nil {
+			if book, err := opts.Persistence.LoadOpeningBook(); err == nil {
+				s.ai.SetBook(book)
+			}
+		}
+	}
+
+	s.printHelp()
+	if s.isAITurn() {
+		if err := s.playAI(); err != nil {
+			return err
+		}
+	}
+	s.printBoard()
+	s.printStatus()
+
+	scanner := bufio.NewScanner(in)
+	for scanner.Scan() {
+		line := strings.ToLower(strings.TrimSpace(scanner.Text()))
+		if line == "" {
+			continue
+		}
+
+		switch line {
+		case "quit", "exit", "q":
+			fmt.Fprintln(out, "QUIT")
+			return nil
+		case "help", "?":
+			s.printHelp()
+		case "board":
+			s.printBoard()
+			s.printStatus()
+		case "hint":
+			s.hint()
+		case "undo":
+			s.undo()
+		default:
+			if done, err := s.move(line); done || err != nil {
+				return err
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read input: %w", err)
+	}
+
+	fmt.Fprintln(out, "QUIT")
+	return nil
+}
+
+// move plays the human's move and the AI's reply; it reports whether the
+// game ended, and fails when the AI cannot reply
+func (s *session) move(input string) (bool, error) {
+	move, err := s.parseMove(input)
+	if err != nil {
+		s.printError(err.Error())
+		return false, nil
+	}
+
+	player := s.game.GetCurrentPlayer()
+	if err := s.game.Play(move); err != nil {
+		if move.Kind == game.Place && s.game.Boards()[move.Board][move.Row][move.Col] != game.Empty {
+			err = fmt.Errorf("cell %s is already taken", FormatCell(move.Row, move.Col))
+		}
+		s.printError(err.Error())
+		return false, nil
+	}
+	s.printMove(player, move, "")
+
+	if s.game.GetStatus() == game.StatusPlaying && s.isAITurn() {
+		if err := s.playAI(); err != nil {
+			return true, err
+		}
+	}
+
+	s.printBoard()
+	if s.game.GetStatus() != game.StatusPlaying {
+		s.finish()
+		return true, nil
+	}
+	s.printStatus()
+	return false, nil
+}
+
+// parseMove reads a cell such as b2, a cell and a mark such as b2 o under
+// rules where players choose their mark, two cells such as a1 b2 to move a
+// mark, or a board and a cell such as 2 b2 in games on several boards
+func (s *session) parseMove(input string) (game.Move, error) {
+	fields := strings.Fields(input)
+	if len(fields) == 0 || len(fields) > 2 {
+		return game.Move{}, fmt.Errorf("%s is not a command or a move", input)
+	}
+	board := 0
+	if boards := len(s.game.Extra) + 1; boards > 1 && len(fields) == 2 {
+		number, err := strconv.Atoi(fields[0])
+		if err != nil || number < 1 || number > boards {
+			return game.Move{}, fmt.Errorf("%s is not a board 1-%d", fields[0], boards)
+		}
+		board, fields = number-1, fields[1:]
+	}
+	row, col, err := ParseCell(fields[0])
+	if err != nil {
+		return game.Move{}, err
+	}
+	rules := s.game.GetRules()
+	move := game.Move{Row: row, Col: col, Board: board, Mark: rules.Marks(s.game.GetCurrentPlayer())[0]}
+	if len(fields) == 1 {
+		return move, nil
+	}
+
+	if mark := game.Player(strings.ToUpper(fields[1])); mark == game.PlayerX || mark == game.PlayerO {
+		move.Mark = mark
+		return move, nil
+	}
+	toRow, toCol, err := ParseCell(fields[1])
+	if err != nil {
+		return game.Move{}, err
+	}
+	return game.Move{Kind: game.Slide, From: game.Position{Row: row, Col: col}, Row: toRow, Col: toCol}, nil
+}
+
+// playAI makes the AI's move; the session cannot go on without one, since
+// the human would be left to play the AI's side
+func (s *session) playAI() error {
+	move, err := s.ai.GetMoveMark(context.Background(), s.game, 0)
+	if err != nil {
+		return fmt.Errorf("the AI could not find a move: %w", err)
+	}
+	if move.Row < 0 {
+		return fmt.Errorf("the AI could not find a move")
+	}
+	player := s.game.GetCurrentPlayer()
+	if err := s.game.Play(move); err != nil {
+		return fmt.Errorf("the AI could not play %s: %w", FormatCell(move.Row, move.Col), err)
+	}
+	s.printMove(player, move, " by=ai")
+	return nil
+}
+
+// printMove prints the machine-readable line for a move just made, with
+// the mark placed under rules where players choose, the cell a moved mark
+// left and the board in games on several boards
+func (s *session) printMove(player game.Player, move game.Move, suffix string) {
+	line := fmt.Sprintf("MOVE player=%s", player)
+	if move.Kind == game.Slide {
+		line += " from=" + FormatCell(move.From.Row, move.From.Col)
+	}
+	if len(s.game.Extra) > 0 {
+		line += fmt.Sprintf(" board=%d", move.Board+1)
+	}
+	line += " cell=" + FormatCell(move.Row, move.Col)
+	if s.game.GetRules().ChoosesMarks() {
+		line += " mark=" + string(move.Mark)
+	}
+	fmt.Fprintln(s.out, line+suffix)
+}
+
+// isAITurn reports whether the AI is to move
+func (s *session) isAITurn() bool {
+	return s.ai != nil && s.game.GetStatus() == game.StatusPlaying && s.game.GetCurrentPlayer() == s.ai.GetPlayer()
+}
+
+// hint prints the best move with perfect play
+func (s *session) hint() {
+	if !s.game.GetRules().IsClassic() {
+		s.printError("hints are only available under classic rules")
+		return
+	}
+	evaluations := ai.Analyze(s.game)
+	if len(evaluations) == 0 {
+		s.printError("no moves left")
+		return
+	}
+	best := evaluations[0]
+	fmt.Fprintf(s.out, "HINT cell=%s outcome=%s\n", FormatCell(best.Move.Row, best.Move.Col), best.Outcome)
+}
+
+// undo takes back the last move, or the last human move and the AI's reply
+func (s *session) undo() {
+	moves := len(s.game.GetMoveHistory())
+	plies := 1
+	if s.ai != nil && (moves-1)%2 != humanParity(s.opts.HumanPlayer) {
+		plies = 2
+	}
+	if plies > moves {
+		s.printError("nothing to undo")
+		return
+	}
+	if err := s.game.Undo(plies); err != nil {
+		s.printError(err.Error())
+		return
+	}
+
+	fmt.Fprintf(s.out, "UNDO moves=%d\n", moves-plies)
+	s.printBoard()
+	s.printStatus()
+}
+
+// humanParity is the move-count parity at which it is the human's turn
+func humanParity(human game.Player) int {
+	if human == game.PlayerO {
+		return 1
+	}
+	return 0
+}
+
+// finish prints the result and records the game
+func (s *session) finish() {
+	moves := len(s.game.GetMoveHistory())
+	if s.game.GetStatus() == game.StatusWon {
+		fmt.Fprintf(s.out, "RESULT winner=%s moves=%d\n", s.game.GetWinner(), moves)
+	} else {
+		fmt.Fprintf(s.out, "RESULT winner=none moves=%d\n", moves)
+	}
+
+	pm := s.opts.Persistence
+	if pm == nil {
+		return
+	}
+
+	var err error
+	rules := s.game.GetRules()
+	record := persistence.ArchivedGame{
+		Mode:    int(s.opts.Mode),
+		PlayerX: "Player X",
+		PlayerO: "Player O",
+		Winner:  string(s.game.GetWinner()),
+	}
+	if !rules.IsClassic() {
+		// Variant games keep their own record, whoever played them
+		record.Variant = string(rules.Variant)
+		if boards := rules.BoardCount(); boards > 1 {
+			record.Boards = boards
+		}
+		err = pm.UpdateVariantScore(rules.Variant, s.game.GetWinner())
+	} else if s.ai != nil {
+		err = pm.UpdatePlayerVsAIScore(s.opts.Difficulty, s.game.GetWinner(), s.ai.GetPlayer())
+	} else {
+		err = pm.UpdatePlayerVsPlayerScore(s.game.GetWinner())
+	}
+	if s.ai != nil {
+		if s.ai.GetPlayer() == game.PlayerX {
+			record.PlayerX = "AI (" + s.ai.GetDifficultyName() + ")"
+		} else {
+			record.PlayerO = "AI (" + s.ai.GetDifficultyName() + ")"
+		}
+	}
+	if err != nil {
+		s.printError("failed to save score: " + err.Error())
+	}
+
+	for i, move := range s.game.GetMoveHistory() {
+		position := persistence.Position{Row: move.Row, Col: move.Col}
+		if rules.ChoosesMarks() {
+			position.Mark = string(s.game.MarkAt(i))
+		}
+		if from, ok := s.game.Slides[i]; ok {
+			position.From = &persistence.Position{Row: from.Row, Col: from.Col}
+		}
+		position.Board = s.game.OnBoard[i]
+		record.Moves = append(record.Moves, position)
+	}
+	if err := pm.ArchiveGame(record); err != nil {
+		s.printError("failed to archive game: " + err.Error())
+	}
+}
+
+// printBoard draws the board with column letters and row numbers, or each
+// board in turn in games on several boards
+func (s *session) printBoard() {
+	boards := s.game.Boards()
+	if len(boards) == 1 {
+		s.printGrid(boards[0])
+		return
+	}
+	for i, board := range boards {
+		title := fmt.Sprintf("Board %d", i+1)
+		if s.game.BoardDead(i) {
+			title += " (out of play)"
+		}
+		fmt.Fprintln(s.out, title)
+		s.printGrid(board)
+	}
+}
+
+// printGrid draws one board with column letters and row numbers
+func (s *session) printGrid(board [3][3]game.Player) {
+	fmt.Fprintln(s.out, "   a   b   c")
+	for row := 0; row < 3; row++ {
+		cells := make([]string, 3)
+		for col := 0; col < 3; col++ {
+			cells[col] = s.mark(board[row][col])
+		}
+		fmt.Fprintf(s.out, "%d  %s\n", row+1, strings.Join(cells, " | "))
+		if row < 2 {
+			fmt.Fprintln(s.out, "  ---+---+---")
+		}
+	}
+}
+
+// mark renders one cell, coloured when enabled
+func (s *session) mark(player game.Player) string {
+	switch player {
+	case game.PlayerX:
+		if s.opts.Color {
+			return colorX + "X" + colorReset
+		}
+		return "X"
+	case game.PlayerO:
+		if s.opts.Color {
+			return colorO + "O" + colorReset
+		}
+		return "O"
+	default:
+		return "."
+	}
+}
+
+// printHelp prints the commands, and how to make the moves the rules add
+func (s *session) printHelp() {
+	help := helpText
+	rules := s.game.GetRules()
+	if rules.ChoosesMarks() {
+		help += " " + markHelp
+	}
+	if rules.Pieces() > 0 {
+		help += " " + slideHelp
+	}
+	if rules.BoardCount() > 1 {
+		help += " " + boardHelp
+	}
+	fmt.Fprintln(s.out, help)
+}
+
+// printStatus prints the machine-readable turn line
+func (s *session) printStatus() {
+	fmt.Fprintf(s.out, "STATUS turn=%s moves=%d\n", s.game.GetCurrentPlayer(), len(s.game.GetMoveHistory()))
+}
+
+// printError prints a machine-readable error line
+func (s *session) printError(message string) {
+	fmt.Fprintf(s.out, "ERROR message=%q\n", message)
+}
+
+// ParseCell converts a cell name such as "b2" into a row and column
+func ParseCell(cell string) (int, int, error) {
+	cell = strings.ToLower(strings.TrimSpace(cell))
+	if len(cell) != 2 || cell[0] < 'a' || cell[0] > 'c' || cell[1] < '1' || cell[1] > '3' {
+		return -1, -1, fmt.Errorf("%s is not a command or a cell a1-c3", cell)
+	}
+	return int(cell[1] - '1'), int(cell[0] - 'a'), nil
+}
+
+// FormatCell names a cell the way ParseCell reads it
+func FormatCell(row, col int) string {
+	return fmt.Sprintf("%c%d", 'a'+col, row+1)
+}
+
+// IsTerminal reports whether f is an interactive terminal; colour and the
+// full-screen UI are only used when it is
+func IsTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
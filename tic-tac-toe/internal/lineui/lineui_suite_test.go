@@ -0,0 +1,13 @@
+package lineui_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestLineUI(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "LineUI Suite")
+}
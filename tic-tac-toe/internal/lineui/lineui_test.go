@@ -0,0 +1,153 @@
+package lineui_test
+
+import (
+	"bytes"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"tic-tac-toe/internal/ai"
+	"tic-tac-toe/internal/game"
+	"tic-tac-toe/internal/lineui"
+	"tic-tac-toe/internal/persistence"
+)
+
+var _ = Describe("LineUI", func() {
+	play := func(input string, opts lineui.Options) string {
+		out := &bytes.Buffer{}
+		Expect(lineui.Run(strings.NewReader(input), out, opts)).To(Succeed())
+		return out.String()
+	}
+
+	Describe("ParseCell and FormatCell", func() {
+		It("should read column letters and row numbers", func() {
+			row, col, err := lineui.ParseCell("C1")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(row).To(Equal(0))
+			Expect(col).To(Equal(2))
+			Expect(lineui.FormatCell(row, col)).To(Equal("c1"))
+		})
+
+		It("should reject cells off the board", func() {
+			for _, cell := range []string{"d1", "a4", "a", "b22", "11"} {
+				_, _, err := lineui.ParseCell(cell)
+				Expect(err).To(HaveOccurred(), cell)
+			}
+		})
+	})
+
+	Describe("Two-player games", func() {
+		It("should play to a result with status lines", func() {
+			out := play("a1\na2\nb1\nb2\nc1\n", lineui.Options{Mode: game.PlayerVsPlayer})
+			Expect(out).To(ContainSubstring("STATUS turn=X moves=0\n"))
+			Expect(out).To(ContainSubstring("MOVE player=O cell=a2\n"))
+			Expect(out).To(HaveSuffix("RESULT winner=X moves=5\n"))
+		})
+
+		It("should be deterministic and free of escape codes", func() {
+			input := "b2\nhint\na1\nundo\nboard\nquit\n"
+			first := play(input, lineui.Options{Mode: game.PlayerVsPlayer})
+			Expect(play(input, lineui.Options{Mode: game.PlayerVsPlayer})).To(Equal(first))
+			Expect(first).ToNot(ContainSubstring("\x1b"))
+		})
+
+		It("should colour marks only when asked", func() {
+			Expect(play("b2\nquit\n", lineui.Options{Mode: game.PlayerVsPlayer, Color: true})).To(ContainSubstring("\x1b[1;31mX"))
+		})
+
+		It("should report errors and keep going", func() {
+			out := play("b2\nb2\nfly\nquit\n", lineui.Options{Mode: game.PlayerVsPlayer})
+			Expect(out).To(ContainSubstring(`ERROR message="cell b2 is already taken"`))
+			Expect(out).To(ContainSubstring("ERROR message=\"fly is not a command"))
+			Expect(out).To(HaveSuffix("QUIT\n"))
+		})
+
+		It("should undo a single move", func() {
+			out := play("b2\nundo\nundo\n", lineui.Options{Mode: game.PlayerVsPlayer})
+			Expect(out).To(ContainSubstring("UNDO moves=0\n"))
+			Expect(out).To(ContainSubstring(`ERROR message="nothing to undo"`))
+		})
+
+		It("should give a winning hint", func() {
+			out := play("a1\na2\nb1\nb2\nhint\nquit\n", lineui.Options{Mode: game.PlayerVsPlayer})
+			Expect(out).To(ContainSubstring("HINT cell=c1 outcome=win\n"))
+		})
+
+		It("should quit at the end of input", func() {
+			Expect(play("b2\n", lineui.Options{Mode: game.PlayerVsPlayer})).To(HaveSuffix("QUIT\n"))
+		})
+	})
+
+	Describe("Rule variants", func() {
+		It("should place the chosen mark and explain how", func() {
+			out := play("b2 o\nhint\nquit\n", lineui.Options{Mode: game.PlayerVsPlayer, Rules: game.Rules{Variant: game.Wild}})
+			Expect(out).To(ContainSubstring("e.g. b2 o."))
+			Expect(out).To(ContainSubstring("MOVE player=X cell=b2 mark=O\n"))
+			Expect(out).To(ContainSubstring(`ERROR message="hints are only available under classic rules"`))
+		})
+
+		It("should move marks once all are placed", func() {
+			out := play("a1\nb1\nc2\na2\nb3\nc3\nc1\na1 b2\nundo\nquit\n", lineui.Options{Mode: game.PlayerVsPlayer, Rules: game.Rules{Variant: game.Achi}})
+			Expect(out).To(ContainSubstring(`ERROR message="all 3 X marks are placed; move one instead"`))
+			Expect(out).To(ContainSubstring("MOVE player=X from=a1 cell=b2\n"))
+			Expect(out).To(ContainSubstring("UNDO moves=6\n"))
+		})
+
+		It("should let the AI play the variant and record it separately", func() {
+			manager := persistence.NewWithDirectory(GinkgoT().TempDir())
+			out := play("b2\nquit\n", lineui.Options{Mode: game.PlayerVsAI, Difficulty: ai.INeverLose, Rules: game.Rules{Variant: game.Notakto}, Persistence: manager})
+			Expect(out).To(ContainSubstring("MOVE player=O cell="))
+
+			play("a1\nb1\nc1\n", lineui.Options{Mode: game.PlayerVsPlayer, Rules: game.Rules{Variant: game.Notakto}, Persistence: manager})
+			scores, err := manager.LoadScores()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(scores.Variants["notakto"].Games).To(Equal(1))
+			archive, _ := manager.LoadArchive()
+			Expect(archive[0].Variant).To(Equal("notakto"))
+		})
+
+		It("should play Notakto on several boards", func() {
+			manager := persistence.NewWithDirectory(GinkgoT().TempDir())
+			rules := game.Rules{Variant: game.Notakto, Boards: 2}
+			out := play("a1\n1 b1\n1 c1\n1 a2\n2 a1\n2 b1\n2 c1\n", lineui.Options{Mode: game.PlayerVsPlayer, Rules: rules, Persistence: manager})
+			Expect(out).To(ContainSubstring("MOVE player=X board=1 cell=a1\n"))
+			Expect(out).To(ContainSubstring("Board 1 (out of play)\n"))
+			Expect(out).To(ContainSubstring(`ERROR message="board 1 has a line and is out of play"`))
+			Expect(out).To(ContainSubstring("MOVE player=O board=2 cell=a1\n"))
+			Expect(out).To(HaveSuffix("RESULT winner=X moves=6\n"))
+
+			archive, _ := manager.LoadArchive()
+			Expect(archive[0].Boards).To(Equal(2))
+			Expect(archive[0].Moves[3].Board).To(Equal(1))
+		})
+	})
+
+	Describe("Games against the AI", func() {
+		It("should reply to each move and undo both", func() {
+			out := play("b2\nundo\nquit\n", lineui.Options{Mode: game.PlayerVsAI, Difficulty: ai.INeverLose})
+			Expect(out).To(ContainSubstring("MOVE player=O cell="))
+			Expect(out).To(ContainSubstring("by=ai\n"))
+			Expect(out).To(ContainSubstring("UNDO moves=0\n"))
+		})
+
+		It("should let the AI open when the human plays O", func() {
+			out := play("quit\n", lineui.Options{Mode: game.PlayerVsAI, Difficulty: ai.INeverLose, HumanPlayer: game.PlayerO})
+			Expect(out).To(ContainSubstring("MOVE player=X"))
+			Expect(out).To(ContainSubstring("STATUS turn=O moves=1\n"))
+		})
+
+		It("should record finished games", func() {
+			manager := persistence.NewWithDirectory(GinkgoT().TempDir())
+			play("a1\na2\nb1\nb2\nc1\n", lineui.Options{Mode: game.PlayerVsPlayer, Persistence: manager})
+
+			scores, err := manager.LoadScores()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(scores.PlayerVsPlayer.XWins).To(Equal(1))
+
+			archive, _ := manager.LoadArchive()
+			Expect(archive).To(HaveLen(1))
+			Expect(archive[0].Moves).To(HaveLen(5))
+		})
+	})
+})
@@ -0,0 +1,154 @@
+package lobby
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"tic-tac-toe/internal/game"
+	"tic-tac-toe/internal/persistence"
+)
+
+// Client is a connection to a lobby server
+type Client struct {
+	conn   net.Conn
+	writeM sync.Mutex
+	enc    *json.Encoder
+	events chan Message
+	name   string
+	rating int
+}
+
+// Dial connects to the lobby at addr and registers under name
+func Dial(addr, name string, rating int) (*Client, error) {
+	conn, err := net.DialTimeout("tcp", addr, 3*time.Second)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to lobby: %w", err)
+	}
+
+	c := &Client{
+		conn:   conn,
+		enc:    json.NewEncoder(conn),
+		events: make(chan Message, 64),
+	}
+
+	scanner := bufio.NewScanner(conn)
+	if err := c.send(Message{Type: MsgRegister, Name: name, Rating: rating}); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	// The first reply is either a welcome or the reason registration failed
+	conn.SetReadDeadline(time.Now().Add(3 * time.Second))
+	if !scanner.Scan() {
+		conn.Close()
+		return nil, fmt.Errorf("lobby closed the connection during registration")
+	}
+	conn.SetReadDeadline(time.Time{})
+
+	var reply Message
+	if err := json.Unmarshal(scanner.Bytes(), &reply); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to read lobby reply: %w", err)
+	}
+	if reply.Type != MsgWelcome {
+		conn.Close()
+		return nil, fmt.Errorf("registration rejected: %s", reply.Error)
+	}
+
+	c.name = reply.Name
+	c.rating = reply.Rating
+
+	go c.readLoop(scanner)
+	return c, nil
+}
+
+// Name returns the registered user name
+func (c *Client) Name() string {
+	return c.name
+}
+
+// Rating returns the registered rating
+func (c *Client) Rating() int {
+	return c.rating
+}
+
+// Events returns the channel of server messages; it is closed on disconnect
+func (c *Client) Events() <-chan Message {
+	return c.events
+}
+
+// JoinQueue enters the matchmaking queue for a variant and time control
+func (c *Client) JoinQueue(variant, timeControl string) error {
+	return c.send(Message{Type: MsgJoinQueue, Variant: variant, TimeControl: timeControl})
+}
+
+// LeaveQueue leaves any matchmaking queue
+func (c *Client) LeaveQueue() error {
+	return c.send(Message{Type: MsgLeaveQueue})
+}
+
+// RequestLobby asks the server for the current users and games
+func (c *Client) RequestLobby() error {
+	return c.send(Message{Type: MsgListLobby})
+}
+
+// Move submits a move in a lobby game
+func (c *Client) Move(gameID string, row, col int) error {
+	return c.send(Message{Type: MsgMove, GameID: gameID, Move: &persistence.Position{Row: row, Col: col}})
+}
+
+// Play submits a move in a lobby game under any rules, with the mark to
+// place, the cell a moved mark leaves or the board it is on
+func (c *Client) Play(gameID string, move game.Move) error {
+	position := &persistence.Position{Row: move.Row, Col: move.Col, Board: move.Board}
+	if move.Kind == game.Slide {
+		position.From = &persistence.Position{Row: move.From.Row, Col: move.From.Col}
+	} else if move.Mark != "" {
+		position.Mark = string(move.Mark)
+	}
+	return c.send(Message{Type: MsgMove, GameID: gameID, Move: position})
+}
+
+// Chat sends a chat line to the opponent in a lobby game
+func (c *Client) Chat(gameID, text string) error {
+	return c.send(Message{Type: MsgChat, GameID: gameID, Chat: &persistence.ChatMessage{Text: text}})
+}
+
+// Emote sends one of the quick Emotes to the opponent
+func (c *Client) Emote(gameID string, index int) error {
+	if index < 0 || index >= len(Emotes) {
+		return fmt.Errorf("unknown emote %d", index)
+	}
+	return c.send(Message{Type: MsgChat, GameID: gameID, Chat: &persistence.ChatMessage{Text: Emotes[index], Emote: true}})
+}
+
+// Close disconnects from the lobby
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// readLoop forwards server messages to the events channel
+func (c *Client) readLoop(scanner *bufio.Scanner) {
+	defer close(c.events)
+	for scanner.Scan() {
+		var msg Message
+		if err := json.Unmarshal(scanner.Bytes(), &msg); err != nil {
+			continue
+		}
+		c.events <- msg
+	}
+}
+
+// send writes a single message to the server
+func (c *Client) send(msg Message) error {
+	c.writeM.Lock()
+	defer c.writeM.Unlock()
+	if err := c.enc.Encode(msg); err != nil {
+		return fmt.Errorf("failed to send %s: %w", msg.Type, err)
+	}
+	return nil
+}
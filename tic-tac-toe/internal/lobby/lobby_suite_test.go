@@ -0,0 +1,13 @@
+package lobby_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestLobby(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Lobby Suite")
+}
@@ -0,0 +1,370 @@
+package lobby_test
+
+import (
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"tic-tac-toe/internal/ai"
+	"tic-tac-toe/internal/game"
+	"tic-tac-toe/internal/lobby"
+)
+
+// waitFor reads client events until one of the given type arrives
+func waitFor(c *lobby.Client, msgType lobby.MessageType) lobby.Message {
+	timeout := time.After(3 * time.Second)
+	for {
+		select {
+		case msg, ok := <-c.Events():
+			Expect(ok).To(BeTrue(), "connection closed while waiting for %s", msgType)
+			if msg.Type == msgType {
+				return msg
+			}
+		case <-timeout:
+			Fail("timed out waiting for " + string(msgType))
+		}
+	}
+}
+
+var _ = Describe("Lobby", func() {
+	var (
+		server *lobby.Server
+		config lobby.ServerConfig
+	)
+
+	BeforeEach(func() {
+		config = lobby.ServerConfig{
+			PairInterval: 10 * time.Millisecond,
+			RatingWindow: 100,
+			WindowGrowth: 0,
+			AITimeout:    time.Hour,
+			AIDifficulty: ai.INeverLose,
+			ChatBurst:    5,
+			ChatRefill:   time.Second,
+		}
+	})
+
+	JustBeforeEach(func() {
+		server = lobby.NewServer(config)
+		Expect(server.Listen("127.0.0.1:0")).To(Succeed())
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	dial := func(name string, rating int) *lobby.Client {
+		c, err := lobby.Dial(server.Addr(), name, rating)
+		Expect(err).ToNot(HaveOccurred())
+		DeferCleanup(func() { c.Close() })
+		return c
+	}
+
+	It("should close more than once", func() {
+		Expect(server.Close()).To(Succeed())
+		Expect(server.Close()).To(Succeed())
+	})
+
+	Describe("Registration", func() {
+		It("should register clients and list them as online", func() {
+			dial("alice", 1500)
+			dial("bob", 0)
+
+			Eventually(server.Users).Should(HaveLen(2))
+			users := server.Users()
+			Expect(users[0]).To(Equal(lobby.User{Name: "alice", Rating: 1500, Status: lobby.UserIdle}))
+			Expect(users[1].Rating).To(Equal(lobby.DefaultRating))
+		})
+
+		It("should reject duplicate names", func() {
+			dial("alice", 1500)
+			_, err := lobby.Dial(server.Addr(), "alice", 1500)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("already online"))
+		})
+
+		It("should remove users when they disconnect", func() {
+			c := dial("alice", 1500)
+			Eventually(server.Users).Should(HaveLen(1))
+			c.Close()
+			Eventually(server.Users).Should(BeEmpty())
+		})
+	})
+
+	Describe("Matchmaking", func() {
+		It("should pair players of similar rating in the same queue", func() {
+			alice := dial("alice", 1500)
+			bob := dial("bob", 1550)
+
+			Expect(alice.JoinQueue("classic", "untimed")).To(Succeed())
+			waitFor(alice, lobby.MsgQueued)
+			Expect(bob.JoinQueue("classic", "untimed")).To(Succeed())
+
+			alicePairing := waitFor(alice, lobby.MsgPaired).Pairing
+			bobPairing := waitFor(bob, lobby.MsgPaired).Pairing
+
+			Expect(alicePairing.GameID).To(Equal(bobPairing.GameID))
+			Expect(alicePairing.You).To(Equal(game.PlayerX))
+			Expect(bobPairing.You).To(Equal(game.PlayerO))
+			Expect(alicePairing.Opponent).To(Equal("bob"))
+			Expect(bobPairing.OpponentRating).To(Equal(1500))
+
+			Eventually(server.Games).Should(HaveLen(1))
+		})
+
+		It("should not pair players from different queues", func() {
+			alice := dial("alice", 1500)
+			bob := dial("bob", 1500)
+
+			alice.JoinQueue("classic", "untimed")
+			bob.JoinQueue("classic", "blitz")
+			waitFor(alice, lobby.MsgQueued)
+			waitFor(bob, lobby.MsgQueued)
+
+			Consistently(server.Games, 100*time.Millisecond).Should(BeEmpty())
+		})
+
+		It("should prefer the closest rating", func() {
+			alice := dial("alice", 1500)
+			far := dial("far", 1590)
+			near := dial("near", 1510)
+
+			far.JoinQueue("classic", "untimed")
+			waitFor(far, lobby.MsgQueued)
+			near.JoinQueue("classic", "untimed")
+			waitFor(near, lobby.MsgQueued)
+
+			// far and near are within the window of each other, so they pair first
+			Expect(waitFor(far, lobby.MsgPaired).Pairing.Opponent).To(Equal("near"))
+
+			alice.JoinQueue("classic", "untimed")
+			waitFor(alice, lobby.MsgQueued)
+			Consistently(server.Games, 100*time.Millisecond).Should(HaveLen(1))
+		})
+
+		It("should leave players with distant ratings queued", func() {
+			alice := dial("alice", 1000)
+			bob := dial("bob", 2000)
+
+			alice.JoinQueue("classic", "untimed")
+			bob.JoinQueue("classic", "untimed")
+			waitFor(bob, lobby.MsgQueued)
+
+			Consistently(server.Games, 100*time.Millisecond).Should(BeEmpty())
+		})
+
+		Context("when the rating window widens while waiting", func() {
+			BeforeEach(func() {
+				config.WindowGrowth = 10000
+			})
+
+			It("should eventually pair distant ratings", func() {
+				alice := dial("alice", 1000)
+				bob := dial("bob", 2000)
+
+				alice.JoinQueue("classic", "untimed")
+				bob.JoinQueue("classic", "untimed")
+
+				Expect(waitFor(alice, lobby.MsgPaired).Pairing.Opponent).To(Equal("bob"))
+			})
+		})
+
+		Context("when nobody suitable arrives", func() {
+			BeforeEach(func() {
+				config.AITimeout = 50 * time.Millisecond
+			})
+
+			It("should fall back to an AI opponent", func() {
+				alice := dial("alice", 1500)
+				alice.JoinQueue("classic", "untimed")
+
+				pairing := waitFor(alice, lobby.MsgPaired).Pairing
+				Expect(pairing.VsAI).To(BeTrue())
+				Expect(pairing.Opponent).To(ContainSubstring("AI"))
+				Expect(pairing.You).To(Equal(game.PlayerX))
+
+				games := server.Games()
+				Expect(games).To(HaveLen(1))
+				Expect(games[0].VsAI).To(BeTrue())
+			})
+
+			It("should let the AI reply to moves", func() {
+				alice := dial("alice", 1500)
+				alice.JoinQueue("classic", "untimed")
+				pairing := waitFor(alice, lobby.MsgPaired).Pairing
+				waitFor(alice, lobby.MsgState)
+
+				Expect(alice.Move(pairing.GameID, 1, 1)).To(Succeed())
+				// The move comes back at once; the AI replies once it has searched
+				Expect(waitFor(alice, lobby.MsgState).State.MoveHistory).To(HaveLen(1))
+				state := waitFor(alice, lobby.MsgState).State
+				Expect(state.MoveHistory).To(HaveLen(2))
+				Expect(state.CurrentPlayer).To(Equal(string(game.PlayerX)))
+			})
+		})
+
+		It("should drop players who leave the queue", func() {
+			alice := dial("alice", 1500)
+			alice.JoinQueue("classic", "untimed")
+			waitFor(alice, lobby.MsgQueued)
+			Eventually(server.Users).Should(ContainElement(HaveField("Status", lobby.UserQueued)))
+
+			alice.LeaveQueue()
+			Eventually(server.Users).Should(ContainElement(HaveField("Status", lobby.UserIdle)))
+		})
+	})
+
+	Describe("Variants", func() {
+		It("should reject unknown variants", func() {
+			alice := dial("alice", 1500)
+			Expect(alice.JoinQueue("hexapawn", "untimed")).To(Succeed())
+			Expect(waitFor(alice, lobby.MsgError).Error).To(ContainSubstring("unknown variant"))
+		})
+
+		It("should host games under the queued variant's rules", func() {
+			alice := dial("alice", 1500)
+			bob := dial("bob", 1500)
+			alice.JoinQueue("Wild", "untimed")
+			Expect(waitFor(alice, lobby.MsgQueued).Variant).To(Equal("wild"))
+			bob.JoinQueue("wild", "untimed")
+			gameID := waitFor(alice, lobby.MsgPaired).Pairing.GameID
+			Expect(waitFor(alice, lobby.MsgState).State.Variant).To(Equal("wild"))
+			waitFor(bob, lobby.MsgState)
+
+			Expect(alice.Play(gameID, game.Move{Row: 1, Col: 1, Mark: game.PlayerO})).To(Succeed())
+			state := waitFor(bob, lobby.MsgState).State
+			Expect(state.Board[1][1]).To(Equal("O"))
+			Expect(state.CurrentPlayer).To(Equal("O"))
+		})
+	})
+
+	Describe("Time controls", func() {
+		It("should reject unknown time controls", func() {
+			alice := dial("alice", 1500)
+			Expect(alice.JoinQueue("classic", "glacial")).To(Succeed())
+			Expect(waitFor(alice, lobby.MsgError).Error).To(ContainSubstring("unknown time control"))
+		})
+
+		It("should host timed games with a server-side clock", func() {
+			alice := dial("alice", 1500)
+			bob := dial("bob", 1500)
+			alice.JoinQueue("classic", "blitz")
+			waitFor(alice, lobby.MsgQueued)
+			bob.JoinQueue("classic", "blitz")
+
+			state := waitFor(alice, lobby.MsgState).State
+			Expect(state.Clock).ToNot(BeNil())
+			Expect(state.Clock.Control.Name).To(Equal("blitz"))
+			Expect(state.Clock.Remaining[game.PlayerO]).To(Equal(time.Minute))
+		})
+	})
+
+	Describe("Hosted games", func() {
+		var (
+			alice, bob *lobby.Client
+			gameID     string
+		)
+
+		JustBeforeEach(func() {
+			alice = dial("alice", 1500)
+			bob = dial("bob", 1500)
+			alice.JoinQueue("classic", "untimed")
+			waitFor(alice, lobby.MsgQueued)
+			bob.JoinQueue("classic", "untimed")
+			gameID = waitFor(alice, lobby.MsgPaired).Pairing.GameID
+			waitFor(bob, lobby.MsgPaired)
+			waitFor(alice, lobby.MsgState)
+			waitFor(bob, lobby.MsgState)
+		})
+
+		It("should relay moves to both players", func() {
+			Expect(alice.Move(gameID, 0, 0)).To(Succeed())
+
+			state := waitFor(bob, lobby.MsgState).State
+			Expect(state.Board[0][0]).To(Equal("X"))
+			Expect(state.CurrentPlayer).To(Equal("O"))
+		})
+
+		It("should reject moves on boards the game does not have", func() {
+			Expect(alice.Play(gameID, game.Move{Row: 0, Col: 0, Board: 1})).To(Succeed())
+			Expect(waitFor(alice, lobby.MsgError).Error).To(ContainSubstring("invalid board"))
+		})
+
+		It("should reject moves out of turn", func() {
+			Expect(bob.Move(gameID, 0, 0)).To(Succeed())
+			Expect(waitFor(bob, lobby.MsgError).Error).To(ContainSubstring("not your turn"))
+		})
+
+		It("should finish the game and return players to idle", func() {
+			moves := [][2]int{{0, 0}, {1, 0}, {0, 1}, {1, 1}, {0, 2}}
+			for i, move := range moves {
+				mover := alice
+				if i%2 == 1 {
+					mover = bob
+				}
+				Expect(mover.Move(gameID, move[0], move[1])).To(Succeed())
+				waitFor(alice, lobby.MsgState)
+			}
+
+			Eventually(server.Games).Should(BeEmpty())
+			Eventually(server.Users).Should(HaveEach(HaveField("Status", lobby.UserIdle)))
+		})
+
+		It("should relay chat messages and emotes to both players", func() {
+			Expect(alice.Chat(gameID, "  good luck  ")).To(Succeed())
+			line := waitFor(bob, lobby.MsgChat).Chat
+			Expect(line.From).To(Equal("alice"))
+			Expect(line.Text).To(Equal("good luck"))
+			Expect(waitFor(alice, lobby.MsgChat).Chat.Text).To(Equal("good luck"))
+
+			Expect(bob.Emote(gameID, 3)).To(Succeed())
+			emote := waitFor(alice, lobby.MsgChat).Chat
+			Expect(emote.Emote).To(BeTrue())
+			Expect(emote.Text).To(Equal(lobby.Emotes[3]))
+		})
+
+		It("should reject empty and overlong chat messages", func() {
+			Expect(alice.Chat(gameID, "   ")).To(Succeed())
+			Expect(waitFor(alice, lobby.MsgError).Error).To(ContainSubstring("empty"))
+
+			Expect(alice.Chat(gameID, strings.Repeat("a", lobby.MaxChatLength+1))).To(Succeed())
+			Expect(waitFor(alice, lobby.MsgError).Error).To(ContainSubstring("longer than"))
+		})
+
+		It("should reject chat for games the sender is not in", func() {
+			Expect(alice.Chat("g999", "hello")).To(Succeed())
+			Expect(waitFor(alice, lobby.MsgError).Error).To(ContainSubstring("not in that game"))
+		})
+
+		It("should reject unknown emotes on the client", func() {
+			Expect(alice.Emote(gameID, len(lobby.Emotes))).ToNot(Succeed())
+		})
+
+		Context("when a player chats too quickly", func() {
+			BeforeEach(func() {
+				config.ChatBurst = 2
+				config.ChatRefill = time.Hour
+			})
+
+			It("should rate limit the sender", func() {
+				for i := 0; i < 3; i++ {
+					Expect(alice.Chat(gameID, "spam")).To(Succeed())
+				}
+				waitFor(bob, lobby.MsgChat)
+				waitFor(bob, lobby.MsgChat)
+				Expect(waitFor(alice, lobby.MsgError).Error).To(ContainSubstring("too quickly"))
+			})
+		})
+
+		It("should forfeit the game when a player disconnects", func() {
+			bob.Close()
+
+			state := waitFor(alice, lobby.MsgState).State
+			Expect(state.Status).To(Equal(int(game.StatusWon)))
+			Expect(state.Winner).To(Equal(string(game.PlayerX)))
+			Eventually(server.Games).Should(BeEmpty())
+		})
+	})
+})
@@ -0,0 +1,114 @@
+package lobby
+
+import (
+	"tic-tac-toe/internal/game"
+	"tic-tac-toe/internal/persistence"
+)
+
+const (
+	// DefaultAddress is where the lobby listens when no address is given
+	DefaultAddress = "127.0.0.1:7777"
+	// DefaultRating is the rating assigned to players without history
+	DefaultRating = 1200
+	// DefaultVariant is the queue variant used by the TUI
+	DefaultVariant = "classic"
+	// DefaultTimeControl is the queue time control used by the TUI
+	DefaultTimeControl = "untimed"
+	// MaxChatLength is the longest chat message the server accepts, in runes
+	MaxChatLength = 200
+)
+
+// Emotes are the quick messages bound to the emote keys
+var Emotes = []string{
+	"👋 Hi!",
+	"👍 Nice move",
+	"😮 Wow!",
+	"🤝 Good game",
+}
+
+// MessageType identifies a lobby protocol message
+type MessageType string
+
+const (
+	// Client -> server
+	MsgRegister   MessageType = "register"
+	MsgJoinQueue  MessageType = "join_queue"
+	MsgLeaveQueue MessageType = "leave_queue"
+	MsgListLobby  MessageType = "list_lobby"
+	MsgMove       MessageType = "move"
+	MsgChat       MessageType = "chat"
+
+	// Server -> client
+	MsgWelcome MessageType = "welcome"
+	MsgQueued  MessageType = "queued"
+	MsgPaired  MessageType = "paired"
+	MsgLobby   MessageType = "lobby"
+	MsgState   MessageType = "state"
+	MsgError   MessageType = "error"
+)
+
+// UserStatus describes what an online user is doing
+type UserStatus string
+
+const (
+	UserIdle    UserStatus = "idle"
+	UserQueued  UserStatus = "queued"
+	UserPlaying UserStatus = "playing"
+)
+
+// Message is a single newline-delimited JSON frame
+type Message struct {
+	Type        MessageType              `json:"type"`
+	Name        string                   `json:"name,omitempty"`
+	Rating      int                      `json:"rating,omitempty"`
+	Variant     string                   `json:"variant,omitempty"`
+	TimeControl string                   `json:"time_control,omitempty"`
+	GameID      string                   `json:"game_id,omitempty"`
+	Move        *persistence.Position    `json:"move,omitempty"`
+	Pairing     *Pairing                 `json:"pairing,omitempty"`
+	State       *persistence.GameState   `json:"state,omitempty"`
+	Users       []User                   `json:"users,omitempty"`
+	Games       []GameInfo               `json:"games,omitempty"`
+	Chat        *persistence.ChatMessage `json:"chat,omitempty"`
+	Error       string                   `json:"error,omitempty"`
+}
+
+// User is an online lobby member
+type User struct {
+	Name   string     `json:"name"`
+	Rating int        `json:"rating"`
+	Status UserStatus `json:"status"`
+	Queue  string     `json:"queue,omitempty"`
+}
+
+// GameInfo summarizes an active lobby game
+type GameInfo struct {
+	ID          string `json:"id"`
+	PlayerX     string `json:"player_x"`
+	PlayerO     string `json:"player_o"`
+	Variant     string `json:"variant"`
+	TimeControl string `json:"time_control"`
+	VsAI        bool   `json:"vs_ai"`
+}
+
+// Pairing tells a client which game it has been placed in
+type Pairing struct {
+	GameID         string      `json:"game_id"`
+	You            game.Player `json:"you"`
+	Opponent       string      `json:"opponent"`
+	OpponentRating int         `json:"opponent_rating"`
+	VsAI           bool        `json:"vs_ai"`
+	Variant        string      `json:"variant"`
+	TimeControl    string      `json:"time_control"`
+}
+
+// QueueKey identifies a matchmaking queue
+type QueueKey struct {
+	Variant     string
+	TimeControl string
+}
+
+// String returns the display name of the queue
+func (k QueueKey) String() string {
+	return k.Variant + "/" + k.TimeControl
+}
@@ -0,0 +1,763 @@
+package lobby
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"tic-tac-toe/internal/ai"
+	"tic-tac-toe/internal/game"
+	"tic-tac-toe/internal/persistence"
+)
+
+// ServerConfig controls matchmaking behaviour
+type ServerConfig struct {
+	PairInterval time.Duration // How often the matchmaker scans the queues
+	RatingWindow int           // Maximum rating gap for an immediate pairing
+	WindowGrowth int           // Rating points the window widens per second of waiting
+	AITimeout    time.Duration // Wait after which a queued player gets an AI opponent
+	AIDifficulty ai.Difficulty // Difficulty of the fallback AI opponent
+	ChatBurst    int           // Chat messages a player may send back to back
+	ChatRefill   time.Duration // Time to earn one more chat message
+}
+
+// DefaultServerConfig returns the standard matchmaking settings
+func DefaultServerConfig() ServerConfig {
+	return ServerConfig{
+		PairInterval: 500 * time.Millisecond,
+		RatingWindow: 100,
+		WindowGrowth: 20,
+		AITimeout:    30 * time.Second,
+		AIDifficulty: ai.Hard,
+		ChatBurst:    5,
+		ChatRefill:   2 * time.Second,
+	}
+}
+
+const (
+	// aiMoveBudget caps the fallback AI's search, which runs on a copy of the
+	// game outside the server lock
+	aiMoveBudget = time.Second
+	// outboxSize is how many messages may wait for a slow client before the
+	// server drops it
+	outboxSize = 64
+	// writeTimeout is how long a single write to a client may take
+	writeTimeout = 5 * time.Second
+)
+
+// Server is the lobby service that registers users, pairs them and hosts their games
+type Server struct {
+	config   ServerConfig
+	listener net.Listener
+
+	mu         sync.Mutex
+	clients    map[string]*clientConn
+	queues     map[QueueKey][]*queueEntry
+	games      map[string]*match
+	nextGameID int
+
+	done      chan struct{}
+	closeOnce sync.Once
+	closeErr  error
+	wg        sync.WaitGroup
+}
+
+// clientConn is a registered connection on the server side. Messages to it
+// queue in out, and its own goroutine writes them, so a slow peer never
+// holds up the server lock.
+type clientConn struct {
+	conn   net.Conn
+	enc    *json.Encoder
+	out    chan Message
+	quit   chan struct{} // Closed when the connection's reader finishes
+	user   User
+	gameID string
+	chat   chatLimiter
+}
+
+// chatLimiter is a token bucket limiting how fast a client can chat
+type chatLimiter struct {
+	tokens float64
+	last   time.Time
+}
+
+// queueEntry is a user waiting in a matchmaking queue
+type queueEntry struct {
+	client   *clientConn
+	joinedAt time.Time
+}
+
+// match is a game hosted by the server
+type match struct {
+	info    GameInfo
+	game    *game.Game
+	players map[game.Player]*clientConn // nil entry means the AI plays that side
+	ai      *ai.AI
+}
+
+// NewServer creates a lobby server with the given configuration
+func NewServer(config ServerConfig) *Server {
+	return &Server{
+		config:  config,
+		clients: make(map[string]*clientConn),
+		queues:  make(map[QueueKey][]*queueEntry),
+		games:   make(map[string]*match),
+		done:    make(chan struct{}),
+	}
+}
+
+// Listen starts accepting clients on addr and runs the matchmaker
+func (s *Server) Listen(addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+	s.listener = listener
+
+	s.wg.Add(2)
+	go s.acceptLoop()
+	go s.matchmakerLoop()
+
+	return nil
+}
+
+// Addr returns the address the server is listening on
+func (s *Server) Addr() string {
+	if s.listener == nil {
+		return ""
+	}
+	return s.listener.Addr().String()
+}
+
+// Close stops the server and disconnects all clients; closing it again
+// returns the first close's error
+func (s *Server) Close() error {
+	s.closeOnce.Do(func() {
+		close(s.done)
+		if s.listener != nil {
+			s.closeErr = s.listener.Close()
+		}
+
+		s.mu.Lock()
+		for _, c := range s.clients {
+			c.conn.Close()
+		}
+		s.mu.Unlock()
+
+		s.wg.Wait()
+	})
+	return s.closeErr
+}
+
+// Users returns the online users sorted by name
+func (s *Server) Users() []User {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.usersLocked()
+}
+
+// Games returns the active games sorted by ID
+func (s *Server) Games() []GameInfo {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.gamesLocked()
+}
+
+// acceptLoop accepts connections until the listener is closed
+func (s *Server) acceptLoop() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		s.wg.Add(1)
+		go s.handleConn(conn)
+	}
+}
+
+// matchmakerLoop pairs queued players on every tick
+func (s *Server) matchmakerLoop() {
+	defer s.wg.Done()
+	ticker := time.NewTicker(s.config.PairInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.done:
+			return
+		case now := <-ticker.C:
+			s.matchmake(now)
+			s.checkClocks(now)
+		}
+	}
+}
+
+// handleConn reads messages from one client until it disconnects
+func (s *Server) handleConn(conn net.Conn) {
+	defer s.wg.Done()
+	defer conn.Close()
+
+	c := &clientConn{
+		conn: conn,
+		enc:  json.NewEncoder(conn),
+		out:  make(chan Message, outboxSize),
+		quit: make(chan struct{}),
+	}
+	defer close(c.quit)
+	s.wg.Add(1)
+	go s.writeLoop(c)
+	scanner := bufio.NewScanner(conn)
+
+	for scanner.Scan() {
+		var msg Message
+		if err := json.Unmarshal(scanner.Bytes(), &msg); err != nil {
+			c.send(Message{Type: MsgError, Error: "malformed message"})
+			continue
+		}
+
+		if c.user.Name == "" && msg.Type != MsgRegister {
+			c.send(Message{Type: MsgError, Error: "register first"})
+			continue
+		}
+
+		s.handleMessage(c, msg)
+	}
+
+	s.disconnect(c)
+}
+
+// writeLoop writes a client's queued messages until its reader finishes or
+// a write fails
+func (s *Server) writeLoop(c *clientConn) {
+	defer s.wg.Done()
+	for {
+		select {
+		case msg := <-c.out:
+			c.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+			if err := c.enc.Encode(msg); err != nil {
+				c.conn.Close()
+				return
+			}
+		case <-c.quit:
+			return
+		}
+	}
+}
+
+// handleMessage dispatches a single client message
+func (s *Server) handleMessage(c *clientConn, msg Message) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch msg.Type {
+	case MsgRegister:
+		s.register(c, msg)
+	case MsgJoinQueue:
+		s.joinQueue(c, QueueKey{Variant: msg.Variant, TimeControl: msg.TimeControl})
+	case MsgLeaveQueue:
+		s.removeFromQueues(c)
+		c.user.Status = UserIdle
+		c.user.Queue = ""
+		s.broadcastLobbyLocked()
+	case MsgListLobby:
+		c.send(Message{Type: MsgLobby, Users: s.usersLocked(), Games: s.gamesLocked()})
+	case MsgMove:
+		s.playMove(c, msg)
+	case MsgChat:
+		s.chat(c, msg)
+	default:
+		c.send(Message{Type: MsgError, Error: fmt.Sprintf("unknown message type %q", msg.Type)})
+	}
+}
+
+// register adds a named client to the lobby
+func (s *Server) register(c *clientConn, msg Message) {
+	if c.user.Name != "" {
+		c.send(Message{Type: MsgError, Error: "already registered"})
+		return
+	}
+	if msg.Name == "" {
+		c.send(Message{Type: MsgError, Error: "name is required"})
+		return
+	}
+	if _, taken := s.clients[msg.Name]; taken {
+		c.send(Message{Type: MsgError, Error: fmt.Sprintf("name %q is already online", msg.Name)})
+		return
+	}
+
+	rating := msg.Rating
+	if rating <= 0 {
+		rating = DefaultRating
+	}
+
+	c.user = User{Name: msg.Name, Rating: rating, Status: UserIdle}
+	c.chat = chatLimiter{tokens: float64(s.config.ChatBurst), last: time.Now()}
+	s.clients[msg.Name] = c
+	c.send(Message{Type: MsgWelcome, Name: c.user.Name, Rating: c.user.Rating})
+	s.broadcastLobbyLocked()
+}
+
+// joinQueue places the client into the queue for key
+func (s *Server) joinQueue(c *clientConn, key QueueKey) {
+	if c.gameID != "" {
+		c.send(Message{Type: MsgError, Error: "already in a game"})
+		return
+	}
+	if key.Variant == "" {
+		key.Variant = DefaultVariant
+	}
+	if key.TimeControl == "" {
+		key.TimeControl = DefaultTimeControl
+	}
+	variant, err := game.ParseVariant(key.Variant)
+	if err != nil {
+		c.send(Message{Type: MsgError, Error: err.Error()})
+		return
+	}
+	key.Variant = string(variant)
+	if _, ok := game.TimeControlByName(key.TimeControl); !ok {
+		c.send(Message{Type: MsgError, Error: "unknown time control " + key.TimeControl})
+		return
+	}
+
+	s.removeFromQueues(c)
+	s.queues[key] = append(s.queues[key], &queueEntry{client: c, joinedAt: time.Now()})
+	c.user.Status = UserQueued
+	c.user.Queue = key.String()
+
+	c.send(Message{Type: MsgQueued, Variant: key.Variant, TimeControl: key.TimeControl})
+	s.broadcastLobbyLocked()
+}
+
+// removeFromQueues drops the client from every queue
+func (s *Server) removeFromQueues(c *clientConn) {
+	for key, entries := range s.queues {
+		for i, entry := range entries {
+			if entry.client == c {
+				s.queues[key] = append(entries[:i], entries[i+1:]...)
+				break
+			}
+		}
+		if len(s.queues[key]) == 0 {
+			delete(s.queues, key)
+		}
+	}
+}
+
+// disconnect removes a client and forfeits any game in progress
+func (s *Server) disconnect(c *clientConn) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if c.user.Name == "" || s.clients[c.user.Name] != c {
+		return
+	}
+
+	s.removeFromQueues(c)
+	delete(s.clients, c.user.Name)
+
+	if m, ok := s.games[c.gameID]; ok {
+		for player, pc := range m.players {
+			if pc == c {
+				m.game.Status = game.StatusWon
+				m.game.Winner = opponentOf(player)
+			}
+		}
+		s.broadcastStateLocked(m)
+		s.endGameLocked(m)
+	}
+
+	s.broadcastLobbyLocked()
+}
+
+// matchmake pairs queued players of similar rating and falls back to AI opponents
+func (s *Server) matchmake(now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	changed := false
+	for key, entries := range s.queues {
+		sort.SliceStable(entries, func(i, j int) bool {
+			return entries[i].joinedAt.Before(entries[j].joinedAt)
+		})
+
+		paired := make(map[*queueEntry]bool)
+		for i, first := range entries {
+			if paired[first] {
+				continue
+			}
+
+			// Find the closest-rated partner that both windows allow
+			var best *queueEntry
+			bestGap := -1
+			for _, second := range entries[i+1:] {
+				if paired[second] {
+					continue
+				}
+				gap := abs(first.client.user.Rating - second.client.user.Rating)
+				allowed := min(s.window(first, now), s.window(second, now))
+				if gap <= allowed && (best == nil || gap < bestGap) {
+					best = second
+					bestGap = gap
+				}
+			}
+
+			if best != nil {
+				paired[first] = true
+				paired[best] = true
+				s.startGameLocked(key, first.client, best.client)
+				changed = true
+				continue
+			}
+
+			if now.Sub(first.joinedAt) >= s.config.AITimeout {
+				paired[first] = true
+				s.startGameLocked(key, first.client, nil)
+				changed = true
+			}
+		}
+
+		var remaining []*queueEntry
+		for _, entry := range entries {
+			if !paired[entry] {
+				remaining = append(remaining, entry)
+			}
+		}
+		if len(remaining) == 0 {
+			delete(s.queues, key)
+		} else {
+			s.queues[key] = remaining
+		}
+	}
+
+	if changed {
+		s.broadcastLobbyLocked()
+	}
+}
+
+// window returns the rating gap an entry accepts after waiting
+func (s *Server) window(entry *queueEntry, now time.Time) int {
+	waited := now.Sub(entry.joinedAt).Seconds()
+	return s.config.RatingWindow + int(waited*float64(s.config.WindowGrowth))
+}
+
+// startGameLocked creates a game for x and o; a nil o means an AI opponent
+func (s *Server) startGameLocked(key QueueKey, x, o *clientConn) {
+	s.nextGameID++
+	id := fmt.Sprintf("g%d", s.nextGameID)
+
+	g := game.New()
+	g.SetMode(game.PlayerVsNetwork)
+	if variant, err := game.ParseVariant(key.Variant); err == nil {
+		g.SetRules(game.Rules{Variant: variant})
+	}
+	if control, ok := game.TimeControlByName(key.TimeControl); ok {
+		g.SetTimeControl(control)
+	}
+	g.StartClock(time.Now())
+
+	m := &match{
+		info: GameInfo{
+			ID:          id,
+			PlayerX:     x.user.Name,
+			Variant:     key.Variant,
+			TimeControl: key.TimeControl,
+		},
+		game:    g,
+		players: map[game.Player]*clientConn{game.PlayerX: x, game.PlayerO: o},
+	}
+
+	opponentName := ""
+	opponentRating := 0
+	if o == nil {
+		m.ai = ai.New(s.config.AIDifficulty, game.PlayerO)
+		m.info.PlayerO = "AI (" + m.ai.GetDifficultyName() + ")"
+		m.info.VsAI = true
+		opponentName = m.info.PlayerO
+	} else {
+		m.info.PlayerO = o.user.Name
+		opponentName = o.user.Name
+		opponentRating = o.user.Rating
+	}
+	s.games[id] = m
+
+	x.gameID = id
+	x.user.Status = UserPlaying
+	x.user.Queue = ""
+	x.send(Message{Type: MsgPaired, Pairing: &Pairing{
+		GameID:         id,
+		You:            game.PlayerX,
+		Opponent:       opponentName,
+		OpponentRating: opponentRating,
+		VsAI:           m.info.VsAI,
+		Variant:        key.Variant,
+		TimeControl:    key.TimeControl,
+	}})
+
+	if o != nil {
+		o.gameID = id
+		o.user.Status = UserPlaying
+		o.user.Queue = ""
+		o.send(Message{Type: MsgPaired, Pairing: &Pairing{
+			GameID:         id,
+			You:            game.PlayerO,
+			Opponent:       x.user.Name,
+			OpponentRating: x.user.Rating,
+			Variant:        key.Variant,
+			TimeControl:    key.TimeControl,
+		}})
+	}
+
+	s.broadcastStateLocked(m)
+}
+
+// playMove applies a client's move to its game
+func (s *Server) playMove(c *clientConn, msg Message) {
+	m, ok := s.games[msg.GameID]
+	if !ok || c.gameID != msg.GameID {
+		c.send(Message{Type: MsgError, Error: "not in that game"})
+		return
+	}
+	if msg.Move == nil {
+		c.send(Message{Type: MsgError, Error: "move is required"})
+		return
+	}
+	if m.players[m.game.GetCurrentPlayer()] != c {
+		c.send(Message{Type: MsgError, Error: "not your turn"})
+		return
+	}
+
+	if err := playAt(m.game, *msg.Move, time.Now()); err != nil {
+		c.send(Message{Type: MsgError, Error: err.Error()})
+		// A move after the flag fell loses on time
+		if m.game.GetStatus() != game.StatusPlaying {
+			s.broadcastStateLocked(m)
+			s.endGameLocked(m)
+			s.broadcastLobbyLocked()
+		}
+		return
+	}
+
+	s.broadcastStateLocked(m)
+	if m.game.GetStatus() != game.StatusPlaying {
+		s.endGameLocked(m)
+		s.broadcastLobbyLocked()
+		return
+	}
+
+	// Let the fallback AI reply straight away, without holding up the server
+	if m.ai != nil && m.game.GetCurrentPlayer() == m.ai.GetPlayer() {
+		s.wg.Add(1)
+		go s.replyAI(m, m.game.Clone())
+	}
+}
+
+// replyAI searches for the fallback AI's move on a copy of the game, then
+// plays it if the game has not moved on in the meantime. An AI that cannot
+// move forfeits, so the game never waits on it forever.
+func (s *Server) replyAI(m *match, position *game.Game) {
+	defer s.wg.Done()
+	ctx, cancel := context.WithTimeout(context.Background(), aiMoveBudget)
+	defer cancel()
+	move, err := m.ai.GetMoveMark(ctx, position, aiMoveBudget)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// A disconnect, a loss on time or a server shutdown may have ended the game
+	if s.games[m.info.ID] != m || m.game.GetStatus() != game.StatusPlaying ||
+		len(m.game.GetMoveHistory()) != len(position.GetMoveHistory()) {
+		return
+	}
+	if err == nil {
+		err = m.game.Play(move)
+	}
+	if err != nil {
+		if human := m.players[opponentOf(m.ai.GetPlayer())]; human != nil {
+			human.send(Message{Type: MsgError, Error: "the AI could not move and forfeits: " + err.Error()})
+		}
+		m.game.Status = game.StatusWon
+		m.game.Winner = opponentOf(m.ai.GetPlayer())
+	}
+
+	s.broadcastStateLocked(m)
+	if m.game.GetStatus() != game.StatusPlaying {
+		s.endGameLocked(m)
+		s.broadcastLobbyLocked()
+	}
+}
+
+// playAt applies a move from the wire: a slide when it names the cell the
+// mark left, a move on another board when it names one, a chosen mark when
+// it names one, and the mover's own otherwise
+func playAt(g *game.Game, move persistence.Position, now time.Time) error {
+	if move.From != nil {
+		from := game.Position{Row: move.From.Row, Col: move.From.Col}
+		return g.MakeSlideAt(from, game.Position{Row: move.Row, Col: move.Col}, now)
+	}
+	if move.Board > 0 {
+		return g.MakeMoveOnAt(move.Board, move.Row, move.Col, now)
+	}
+	if move.Mark != "" {
+		return g.MakeMoveMarkAt(move.Row, move.Col, game.Player(move.Mark), now)
+	}
+	return g.MakeMoveAt(move.Row, move.Col, now)
+}
+
+// checkClocks ends games whose player to move has run out of time
+func (s *Server) checkClocks(now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	changed := false
+	for _, m := range s.games {
+		if m.game.CheckTime(now) {
+			s.broadcastStateLocked(m)
+			s.endGameLocked(m)
+			changed = true
+		}
+	}
+
+	if changed {
+		s.broadcastLobbyLocked()
+	}
+}
+
+// chat relays a chat message to the players of the sender's game
+func (s *Server) chat(c *clientConn, msg Message) {
+	m, ok := s.games[msg.GameID]
+	if !ok || c.gameID != msg.GameID {
+		c.send(Message{Type: MsgError, Error: "not in that game"})
+		return
+	}
+	if msg.Chat == nil {
+		c.send(Message{Type: MsgError, Error: "chat message is required"})
+		return
+	}
+
+	text := strings.TrimSpace(msg.Chat.Text)
+	if text == "" {
+		c.send(Message{Type: MsgError, Error: "chat message is empty"})
+		return
+	}
+	if utf8.RuneCountInString(text) > MaxChatLength {
+		c.send(Message{Type: MsgError, Error: fmt.Sprintf("chat message is longer than %d characters", MaxChatLength)})
+		return
+	}
+	if !c.chat.allow(time.Now(), s.config.ChatBurst, s.config.ChatRefill) {
+		c.send(Message{Type: MsgError, Error: "you are sending messages too quickly"})
+		return
+	}
+
+	line := persistence.ChatMessage{
+		From:  c.user.Name,
+		Text:  text,
+		Emote: msg.Chat.Emote,
+		Time:  time.Now(),
+	}
+
+	for _, pc := range m.players {
+		if pc != nil {
+			pc.send(Message{Type: MsgChat, GameID: m.info.ID, Chat: &line})
+		}
+	}
+}
+
+// allow spends a token if one is available, refilling by elapsed time
+func (l *chatLimiter) allow(now time.Time, burst int, refill time.Duration) bool {
+	if refill > 0 {
+		l.tokens += float64(now.Sub(l.last)) / float64(refill)
+	}
+	if l.tokens > float64(burst) {
+		l.tokens = float64(burst)
+	}
+	l.last = now
+
+	if l.tokens < 1 {
+		return false
+	}
+	l.tokens--
+	return true
+}
+
+// endGameLocked removes a finished game and returns its players to idle
+func (s *Server) endGameLocked(m *match) {
+	delete(s.games, m.info.ID)
+	for _, pc := range m.players {
+		if pc != nil && pc.gameID == m.info.ID {
+			pc.gameID = ""
+			pc.user.Status = UserIdle
+		}
+	}
+}
+
+// broadcastStateLocked sends the game state to both players
+func (s *Server) broadcastStateLocked(m *match) {
+	state := persistence.NewGameState(m.game)
+	for _, pc := range m.players {
+		if pc != nil {
+			pc.send(Message{Type: MsgState, GameID: m.info.ID, State: state})
+		}
+	}
+}
+
+// broadcastLobbyLocked pushes the roster and game list to every client
+func (s *Server) broadcastLobbyLocked() {
+	msg := Message{Type: MsgLobby, Users: s.usersLocked(), Games: s.gamesLocked()}
+	for _, c := range s.clients {
+		c.send(msg)
+	}
+}
+
+// usersLocked lists online users
+func (s *Server) usersLocked() []User {
+	users := make([]User, 0, len(s.clients))
+	for _, c := range s.clients {
+		users = append(users, c.user)
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].Name < users[j].Name })
+	return users
+}
+
+// gamesLocked lists active games
+func (s *Server) gamesLocked() []GameInfo {
+	games := make([]GameInfo, 0, len(s.games))
+	for _, m := range s.games {
+		games = append(games, m.info)
+	}
+	sort.Slice(games, func(i, j int) bool { return games[i].ID < games[j].ID })
+	return games
+}
+
+// send queues a message for the client without blocking; a client too slow
+// to drain its queue is disconnected
+func (c *clientConn) send(msg Message) {
+	select {
+	case c.out <- msg:
+	default:
+		c.conn.Close()
+	}
+}
+
+// opponentOf returns the other side of a two-player game
+func opponentOf(player game.Player) game.Player {
+	if player == game.PlayerX {
+		return game.PlayerO
+	}
+	return game.PlayerX
+}
+
+// abs returns the absolute value of an integer
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
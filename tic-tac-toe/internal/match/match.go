@@ -0,0 +1,209 @@
+package match
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Kind is how a match is won
+type Kind string
+
+const (
+	// Single is one game and no match
+	Single Kind = ""
+	// BestOf is won by taking the majority of N games
+	BestOf Kind = "best-of"
+	// FirstTo is won by the first player to win N games; draws do not count
+	FirstTo Kind = "first-to"
+)
+
+// Format is the kind of match and its number of games or wins
+type Format struct {
+	Kind Kind `json:"kind,omitempty"`
+	N    int  `json:"n,omitempty"`
+}
+
+// Formats are the match formats offered in the settings, in cycling order
+var Formats = []Format{
+	{},
+	{Kind: BestOf, N: 3},
+	{Kind: BestOf, N: 5},
+	{Kind: FirstTo, N: 3},
+	{Kind: FirstTo, N: 5},
+}
+
+// MaxGames limits N in a format
+const MaxGames = 99
+
+// ParseFormat reads a format written as "single", "bo3" or "ft5"; the long
+// forms "best-of-3" and "first-to-5" are accepted too
+func ParseFormat(name string) (Format, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" || name == "single" {
+		return Format{}, nil
+	}
+
+	var format Format
+	var count string
+	switch {
+	case strings.HasPrefix(name, "best-of-"):
+		format.Kind, count = BestOf, strings.TrimPrefix(name, "best-of-")
+	case strings.HasPrefix(name, "bo"):
+		format.Kind, count = BestOf, strings.TrimPrefix(name, "bo")
+	case strings.HasPrefix(name, "first-to-"):
+		format.Kind, count = FirstTo, strings.TrimPrefix(name, "first-to-")
+	case strings.HasPrefix(name, "ft"):
+		format.Kind, count = FirstTo, strings.TrimPrefix(name, "ft")
+	default:
+		return Format{}, fmt.Errorf("unknown match format %q (want single, boN or ftN)", name)
+	}
+
+	n, err := strconv.Atoi(count)
+	if err != nil || n < 1 || n > MaxGames {
+		return Format{}, fmt.Errorf("match format %q needs a count from 1 to %d", name, MaxGames)
+	}
+	if format.Kind == BestOf && n%2 == 0 {
+		return Format{}, fmt.Errorf("match format %q needs an odd number of games", name)
+	}
+	if format.Kind == BestOf && n == 1 {
+		return Format{}, nil
+	}
+	format.N = n
+	return format, nil
+}
+
+// Key writes the format in the short form read by ParseFormat, e.g. "bo3"
+func (f Format) Key() string {
+	switch f.Kind {
+	case BestOf:
+		return fmt.Sprintf("bo%d", f.N)
+	case FirstTo:
+		return fmt.Sprintf("ft%d", f.N)
+	default:
+		return "single"
+	}
+}
+
+// String describes the format for display, e.g. "Best of 3"
+func (f Format) String() string {
+	switch f.Kind {
+	case BestOf:
+		return fmt.Sprintf("Best of %d", f.N)
+	case FirstTo:
+		return fmt.Sprintf("First to %d", f.N)
+	default:
+		return "Single game"
+	}
+}
+
+// IsSingle reports whether the format is a lone game rather than a match
+func (f Format) IsSingle() bool {
+	return f.Kind == Single
+}
+
+// Draw stands for a drawn game in a match's results
+const Draw = -1
+
+// Match is a series of games between two players, who take turns to move
+// first. Players are numbered 0 and 1; player 0 starts the first game.
+type Match struct {
+	Format   Format    `json:"format"`
+	Players  [2]string `json:"players"`
+	Knockout bool      `json:"knockout,omitempty"` // A level match goes on until one player wins a game
+	Results  []int     `json:"results"`            // The winner of each game, or Draw
+}
+
+// New starts a match between two named players
+func New(format Format, first, second string) *Match {
+	return &Match{Format: format, Players: [2]string{first, second}}
+}
+
+// Starter returns the player who moves first in the next game
+func (m *Match) Starter() int {
+	return len(m.Results) % 2
+}
+
+// Record adds the result of a game: the winning player, or Draw
+func (m *Match) Record(winner int) error {
+	if m.Over() {
+		return fmt.Errorf("the match is already over")
+	}
+	if winner != 0 && winner != 1 && winner != Draw {
+		return fmt.Errorf("invalid winner %d", winner)
+	}
+	m.Results = append(m.Results, winner)
+	return nil
+}
+
+// Score returns the games won by each player
+func (m *Match) Score() [2]int {
+	var score [2]int
+	for _, winner := range m.Results {
+		if winner != Draw {
+			score[winner]++
+		}
+	}
+	return score
+}
+
+// Draws returns how many games were drawn
+func (m *Match) Draws() int {
+	draws := 0
+	for _, winner := range m.Results {
+		if winner == Draw {
+			draws++
+		}
+	}
+	return draws
+}
+
+// Over reports whether the match is decided. A best-of match also ends
+// after its N games, drawn if level unless it is a knockout match.
+func (m *Match) Over() bool {
+	score := m.Score()
+	lead := max(score[0], score[1])
+	level := score[0] == score[1]
+	switch m.Format.Kind {
+	case FirstTo:
+		return lead >= m.Format.N
+	case BestOf:
+		if lead > m.Format.N/2 {
+			return true
+		}
+		return len(m.Results) >= m.Format.N && (!level || !m.Knockout)
+	default:
+		return len(m.Results) >= 1 && (!level || !m.Knockout)
+	}
+}
+
+// Winner returns the player who won the match; it reports false while the
+// match goes on and for a drawn match
+func (m *Match) Winner() (int, bool) {
+	if !m.Over() {
+		return 0, false
+	}
+	score := m.Score()
+	switch {
+	case score[0] > score[1]:
+		return 0, true
+	case score[1] > score[0]:
+		return 1, true
+	default:
+		return 0, false
+	}
+}
+
+// Summary describes the score, e.g. "Alice 2 – 1 Bob (1 draw)"
+func (m *Match) Summary() string {
+	score := m.Score()
+	summary := fmt.Sprintf("%s %d – %d %s", m.Players[0], score[0], score[1], m.Players[1])
+	switch draws := m.Draws(); draws {
+	case 0:
+	case 1:
+		summary += " (1 draw)"
+	default:
+		summary += fmt.Sprintf(" (%d draws)", draws)
+	}
+	return summary
+}
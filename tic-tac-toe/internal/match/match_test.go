@@ -0,0 +1,86 @@
+package match_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"tic-tac-toe/internal/match"
+)
+
+var _ = Describe("Match", func() {
+	Describe("ParseFormat", func() {
+		It("should read every offered format back from its key", func() {
+			for _, format := range match.Formats {
+				Expect(match.ParseFormat(format.Key())).To(Equal(format))
+			}
+			Expect(match.ParseFormat("best-of-7")).To(Equal(match.Format{Kind: match.BestOf, N: 7}))
+			Expect(match.ParseFormat("bo1")).To(Equal(match.Format{}))
+		})
+
+		It("should reject unknown formats and impossible counts", func() {
+			for _, name := range []string{"bo4", "ft0", "ft", "league", "bo101"} {
+				_, err := match.ParseFormat(name)
+				Expect(err).To(HaveOccurred(), name)
+			}
+		})
+	})
+
+	It("should alternate the starter and end a best of 3 on two wins", func() {
+		m := match.New(match.Format{Kind: match.BestOf, N: 3}, "Alice", "Bob")
+		Expect(m.Starter()).To(Equal(0))
+		Expect(m.Record(0)).To(Succeed())
+		Expect(m.Starter()).To(Equal(1))
+		Expect(m.Record(match.Draw)).To(Succeed())
+		Expect(m.Over()).To(BeFalse())
+		Expect(m.Record(0)).To(Succeed())
+
+		Expect(m.Over()).To(BeTrue())
+		winner, won := m.Winner()
+		Expect(won).To(BeTrue())
+		Expect(winner).To(Equal(0))
+		Expect(m.Summary()).To(Equal("Alice 2 – 0 Bob (1 draw)"))
+		Expect(m.Record(1)).ToNot(Succeed())
+	})
+
+	It("should draw a level best-of match after its games unless it is a knockout", func() {
+		m := match.New(match.Format{Kind: match.BestOf, N: 3}, "Alice", "Bob")
+		for _, winner := range []int{0, 1, match.Draw} {
+			Expect(m.Record(winner)).To(Succeed())
+		}
+		Expect(m.Over()).To(BeTrue())
+		_, won := m.Winner()
+		Expect(won).To(BeFalse())
+
+		m.Knockout = true
+		Expect(m.Over()).To(BeFalse())
+		Expect(m.Record(match.Draw)).To(Succeed())
+		Expect(m.Record(1)).To(Succeed())
+		winner, won := m.Winner()
+		Expect(won).To(BeTrue())
+		Expect(winner).To(Equal(1))
+	})
+
+	It("should not count draws towards a first-to match", func() {
+		m := match.New(match.Format{Kind: match.FirstTo, N: 2}, "You", "AI")
+		for _, winner := range []int{match.Draw, match.Draw, 1, match.Draw, 0} {
+			Expect(m.Record(winner)).To(Succeed())
+		}
+		Expect(m.Over()).To(BeFalse())
+		Expect(m.Record(1)).To(Succeed())
+		winner, won := m.Winner()
+		Expect(won).To(BeTrue())
+		Expect(winner).To(Equal(1))
+		Expect(m.Score()).To(Equal([2]int{1, 2}))
+	})
+
+	It("should play a single game unless a knockout needs a decision", func() {
+		m := match.New(match.Format{}, "Alice", "Bob")
+		Expect(m.Record(match.Draw)).To(Succeed())
+		Expect(m.Over()).To(BeTrue())
+
+		m = match.New(match.Format{}, "Alice", "Bob")
+		m.Knockout = true
+		Expect(m.Record(match.Draw)).To(Succeed())
+		Expect(m.Over()).To(BeFalse())
+	})
+})
@@ -0,0 +1,153 @@
+package match
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Limits on the number of players in a tournament
+const (
+	MinPlayers = 2
+	MaxPlayers = 8
+)
+
+// Bye fills the missing side of a first-round pairing, whose other player
+// goes through without playing
+const Bye = ""
+
+// Pairing is one match of a tournament round
+type Pairing struct {
+	Players [2]string `json:"players"`          // Empty until the earlier matches are decided; Bye in the first round
+	Winner  string    `json:"winner,omitempty"` // Empty until the match is decided
+	Result  string    `json:"result,omitempty"` // The match summary, or "bye"
+}
+
+// Tournament is a knockout bracket: the winner of each match goes through
+// to the next round until one champion is left
+type Tournament struct {
+	Format  Format      `json:"format"`
+	Players []string    `json:"players"` // In seeding order
+	Rounds  [][]Pairing `json:"rounds"`
+}
+
+// NewTournament seeds the players into a bracket in the order given, the
+// top seeds kept apart until the late rounds. When the number of players is
+// not a power of two, the top seeds get byes through the first round.
+func NewTournament(format Format, players []string) (*Tournament, error) {
+	if len(players) < MinPlayers || len(players) > MaxPlayers {
+		return nil, fmt.Errorf("a tournament needs %d to %d players, got %d", MinPlayers, MaxPlayers, len(players))
+	}
+	players = append([]string(nil), players...)
+	seen := map[string]bool{}
+	for i, name := range players {
+		name = strings.TrimSpace(name)
+		switch {
+		case name == "":
+			return nil, fmt.Errorf("player %d has no name", i+1)
+		case seen[strings.ToLower(name)]:
+			return nil, fmt.Errorf("player name %q is used twice", name)
+		}
+		seen[strings.ToLower(name)] = true
+		players[i] = name
+	}
+
+	size := 2
+	for size < len(players) {
+		size *= 2
+	}
+	t := &Tournament{Format: format, Players: players}
+	for matches := size / 2; matches >= 1; matches /= 2 {
+		t.Rounds = append(t.Rounds, make([]Pairing, matches))
+	}
+
+	seeds := seedOrder(size)
+	for i := range t.Rounds[0] {
+		pairing := &t.Rounds[0][i]
+		for side, seed := range seeds[2*i : 2*i+2] {
+			if seed <= len(players) {
+				pairing.Players[side] = players[seed-1]
+			}
+		}
+		if pairing.Players[0] == Bye || pairing.Players[1] == Bye {
+			winner := pairing.Players[0] + pairing.Players[1]
+			if err := t.Report(0, i, winner, "bye"); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return t, nil
+}
+
+// seedOrder lists the seeds 1 to size in bracket order, so that pairing
+// neighbours gives 1 v size, and seeds 1 and 2 can only meet in the final
+func seedOrder(size int) []int {
+	order := []int{1, 2}
+	for n := 4; n <= size; n *= 2 {
+		next := make([]int, 0, n)
+		for _, seed := range order {
+			next = append(next, seed, n+1-seed)
+		}
+		order = next
+	}
+	return order
+}
+
+// Next returns the next match to play, round by round in bracket order; it
+// reports false once the champion is decided
+func (t *Tournament) Next() (round, index int, ok bool) {
+	for round, pairings := range t.Rounds {
+		for index, pairing := range pairings {
+			if pairing.Winner == "" && pairing.Players[0] != "" && pairing.Players[1] != "" {
+				return round, index, true
+			}
+		}
+	}
+	return 0, 0, false
+}
+
+// Report records the winner of a match and the result shown in the bracket,
+// and moves the winner on to the next round
+func (t *Tournament) Report(round, index int, winner, result string) error {
+	if round < 0 || round >= len(t.Rounds) || index < 0 || index >= len(t.Rounds[round]) {
+		return fmt.Errorf("no match %d in round %d", index+1, round+1)
+	}
+	pairing := &t.Rounds[round][index]
+	switch {
+	case pairing.Winner != "":
+		return fmt.Errorf("%s is already decided", t.describe(round, index))
+	case winner == "" || (winner != pairing.Players[0] && winner != pairing.Players[1]):
+		return fmt.Errorf("%q does not play in %s", winner, t.describe(round, index))
+	}
+
+	pairing.Winner = winner
+	pairing.Result = result
+	if round+1 < len(t.Rounds) {
+		t.Rounds[round+1][index/2].Players[index%2] = winner
+	}
+	return nil
+}
+
+// describe names a match for errors, e.g. "match 2 of the semifinals"
+func (t *Tournament) describe(round, index int) string {
+	return fmt.Sprintf("match %d of the %s", index+1, strings.ToLower(t.RoundName(round)))
+}
+
+// Champion returns the winner of the final, once it is played
+func (t *Tournament) Champion() (string, bool) {
+	final := t.Rounds[len(t.Rounds)-1][0]
+	return final.Winner, final.Winner != ""
+}
+
+// RoundName names a round counted from the final back, e.g. "Semifinals"
+func (t *Tournament) RoundName(round int) string {
+	switch len(t.Rounds) - 1 - round {
+	case 0:
+		return "Final"
+	case 1:
+		return "Semifinals"
+	case 2:
+		return "Quarterfinals"
+	default:
+		return fmt.Sprintf("Round %d", round+1)
+	}
+}
@@ -0,0 +1,56 @@
+package match_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"tic-tac-toe/internal/match"
+)
+
+var _ = Describe("Tournament", func() {
+	It("should seed four players so the top two can only meet in the final", func() {
+		t, err := match.NewTournament(match.Format{}, []string{"Ann", "Ben", "Cat", "Dan"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(t.Rounds).To(HaveLen(2))
+		Expect(t.Rounds[0][0].Players).To(Equal([2]string{"Ann", "Dan"}))
+		Expect(t.Rounds[0][1].Players).To(Equal([2]string{"Ben", "Cat"}))
+		Expect(t.RoundName(0)).To(Equal("Semifinals"))
+		Expect(t.RoundName(1)).To(Equal("Final"))
+	})
+
+	It("should give the top seeds byes and play down to a champion", func() {
+		t, err := match.NewTournament(match.Format{Kind: match.BestOf, N: 3}, []string{"Ann", "Ben", "Cat", "Dan", "Eve"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(t.RoundName(0)).To(Equal("Quarterfinals"))
+
+		// Only Dan and Eve play in the first round; the others have byes
+		round, index, ok := t.Next()
+		Expect(ok).To(BeTrue())
+		Expect(t.Rounds[round][index].Players).To(Equal([2]string{"Dan", "Eve"}))
+		Expect(t.Rounds[1][0].Players[0]).To(Equal("Ann"))
+		Expect(t.Rounds[0][0].Result).To(Equal("bye"))
+
+		Expect(t.Report(round, index, "Ann", "")).ToNot(Succeed())
+		Expect(t.Report(round, index, "Eve", "Eve 2 – 1 Dan")).To(Succeed())
+		Expect(t.Rounds[1][0].Players).To(Equal([2]string{"Ann", "Eve"}))
+		Expect(t.Report(round, index, "Eve", "")).ToNot(Succeed())
+
+		for {
+			round, index, ok := t.Next()
+			if !ok {
+				break
+			}
+			Expect(t.Report(round, index, t.Rounds[round][index].Players[1], "")).To(Succeed())
+		}
+		champion, decided := t.Champion()
+		Expect(decided).To(BeTrue())
+		Expect(champion).To(Equal("Cat"))
+	})
+
+	It("should reject too few players and unusable names", func() {
+		for _, players := range [][]string{{"Ann"}, {"Ann", " "}, {"Ann", "ann"}} {
+			_, err := match.NewTournament(match.Format{}, players)
+			Expect(err).To(HaveOccurred())
+		}
+	})
+})
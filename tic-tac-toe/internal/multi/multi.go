@@ -0,0 +1,222 @@
+package multi
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"tic-tac-toe/internal/game"
+)
+
+// Shape is the number of players, the size of the board and the run of
+// marks that wins on it
+type Shape struct {
+	Players int
+	Cols    int
+	Rows    int
+	Connect int
+}
+
+// Shapes are the games offered in the UI; the first is the default
+var Shapes = []Shape{
+	{Players: 3, Cols: 6, Rows: 6, Connect: 4},
+	{Players: 4, Cols: 8, Rows: 8, Connect: 4},
+	{Players: 3, Cols: 7, Rows: 7, Connect: 5},
+	{Players: 4, Cols: 6, Rows: 6, Connect: 3},
+	{Players: 2, Cols: 7, Rows: 7, Connect: 5},
+}
+
+// Limits on board sizes
+const (
+	MinSize = 3
+	MaxSize = 9
+)
+
+// Name writes the shape as players, then columns x rows x connect, e.g. "3p-6x6x4"
+func (s Shape) Name() string {
+	return fmt.Sprintf("%dp-%dx%dx%d", s.Players, s.Cols, s.Rows, s.Connect)
+}
+
+// String describes the shape for display, e.g. "3 players, 6×6, 4 in a row"
+func (s Shape) String() string {
+	return fmt.Sprintf("%d players, %d×%d, %d in a row", s.Players, s.Cols, s.Rows, s.Connect)
+}
+
+// Validate checks the number of players and that the run fits the board
+func (s Shape) Validate() error {
+	if s.Players < game.MinPlayers || s.Players > game.MaxPlayers {
+		return fmt.Errorf("%d players cannot play; a game takes %d to %d", s.Players, game.MinPlayers, game.MaxPlayers)
+	}
+	if s.Cols < MinSize || s.Cols > MaxSize || s.Rows < MinSize || s.Rows > MaxSize {
+		return fmt.Errorf("board %dx%d must be between %d and %d on each side", s.Cols, s.Rows, MinSize, MaxSize)
+	}
+	if s.Connect < MinSize || s.Connect > max(s.Cols, s.Rows) {
+		return fmt.Errorf("cannot connect %d on a %dx%d board", s.Connect, s.Cols, s.Rows)
+	}
+	return nil
+}
+
+// ParseShape reads a shape written by Name
+func ParseShape(name string) (Shape, error) {
+	players, board, ok := strings.Cut(strings.ToLower(strings.TrimSpace(name)), "p-")
+	parts := strings.Split(board, "x")
+	if !ok || len(parts) != 3 {
+		return Shape{}, fmt.Errorf("invalid game %q (want players, then columns x rows x connect, e.g. 3p-6x6x4)", name)
+	}
+	var sizes [4]int
+	for i, part := range append([]string{players}, parts...) {
+		size, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil {
+			return Shape{}, fmt.Errorf("invalid game %q: %w", name, err)
+		}
+		sizes[i] = size
+	}
+	shape := Shape{Players: sizes[0], Cols: sizes[1], Rows: sizes[2], Connect: sizes[3]}
+	if err := shape.Validate(); err != nil {
+		return Shape{}, err
+	}
+	return shape, nil
+}
+
+// directions are the four ways a line can run: across, down and both diagonals
+var directions = []game.Position{{Row: 0, Col: 1}, {Row: 1, Col: 0}, {Row: 1, Col: 1}, {Row: 1, Col: -1}}
+
+// Game is a game of two to four players on a larger board, who take turns in
+// rotation from X; the first to get Connect marks in a row wins
+type Game struct {
+	Shape         Shape           `json:"shape"`
+	Board         [][]game.Player `json:"board"`
+	CurrentPlayer game.Player     `json:"current_player"`
+	Status        game.GameStatus `json:"status"`
+	Winner        game.Player     `json:"winner"`
+	MoveHistory   []game.Position `json:"move_history"`
+}
+
+// New creates an empty board of the given shape with X to move
+func New(shape Shape) *Game {
+	g := &Game{Shape: shape}
+	g.Reset()
+	return g
+}
+
+// Reset empties the board for a new game
+func (g *Game) Reset() {
+	g.Board = make([][]game.Player, g.Shape.Rows)
+	for row := range g.Board {
+		g.Board[row] = make([]game.Player, g.Shape.Cols)
+		for col := range g.Board[row] {
+			g.Board[row][col] = game.Empty
+		}
+	}
+	g.CurrentPlayer = game.PlayerX
+	g.Status = game.StatusPlaying
+	g.Winner = game.Empty
+	g.MoveHistory = make([]game.Position, 0)
+}
+
+// Clone returns an independent copy of the game
+func (g *Game) Clone() *Game {
+	clone := *g
+	clone.Board = make([][]game.Player, len(g.Board))
+	for row := range g.Board {
+		clone.Board[row] = append([]game.Player(nil), g.Board[row]...)
+	}
+	clone.MoveHistory = append([]game.Position(nil), g.MoveHistory...)
+	return &clone
+}
+
+// Players returns the marks in play, in turn order
+func (g *Game) Players() []game.Player {
+	return game.Rotation(g.Shape.Players)
+}
+
+// MoverAt returns who made the move at index ply of the move history
+func (g *Game) MoverAt(ply int) game.Player {
+	return game.MoverAt(ply, g.Shape.Players)
+}
+
+// At returns the mark in a cell, or Empty off the board
+func (g *Game) At(row, col int) game.Player {
+	if !g.inside(row, col) {
+		return game.Empty
+	}
+	return g.Board[row][col]
+}
+
+// Place puts the current player's mark in a cell and passes the turn on
+func (g *Game) Place(row, col int) error {
+	if g.Status != game.StatusPlaying {
+		return fmt.Errorf("game is not in playing state")
+	}
+	if !g.inside(row, col) {
+		return fmt.Errorf("invalid position: (%d, %d)", row, col)
+	}
+	if g.Board[row][col] != game.Empty {
+		return fmt.Errorf("position (%d, %d) is already occupied", row, col)
+	}
+
+	g.Board[row][col] = g.CurrentPlayer
+	g.MoveHistory = append(g.MoveHistory, game.Position{Row: row, Col: col})
+
+	if len(g.lineThrough(row, col)) > 0 {
+		g.Status = game.StatusWon
+		g.Winner = g.CurrentPlayer
+		return nil
+	}
+	if len(g.MoveHistory) == g.Shape.Cols*g.Shape.Rows {
+		g.Status = game.StatusDraw
+		return nil
+	}
+	g.CurrentPlayer = game.NextPlayer(g.CurrentPlayer, g.Shape.Players)
+	return nil
+}
+
+// WinningLine returns the cells of the line that won the game, if any
+func (g *Game) WinningLine() []game.Position {
+	if g.Status != game.StatusWon || len(g.MoveHistory) == 0 {
+		return nil
+	}
+	last := g.MoveHistory[len(g.MoveHistory)-1]
+	return g.lineThrough(last.Row, last.Col)
+}
+
+// lineThrough returns the longest run of the mark at (row, col) through it
+// if that run is long enough to win
+func (g *Game) lineThrough(row, col int) []game.Position {
+	player := g.Board[row][col]
+	for _, d := range directions {
+		line := []game.Position{{Row: row, Col: col}}
+		for _, sign := range []int{-1, 1} {
+			r, c := row+sign*d.Row, col+sign*d.Col
+			for g.At(r, c) == player {
+				line = append(line, game.Position{Row: r, Col: c})
+				r, c = r+sign*d.Row, c+sign*d.Col
+			}
+		}
+		if len(line) >= g.Shape.Connect {
+			return line
+		}
+	}
+	return nil
+}
+
+// AvailableMoves lists the empty cells, row by row
+func (g *Game) AvailableMoves() []game.Position {
+	var moves []game.Position
+	if g.Status != game.StatusPlaying {
+		return moves
+	}
+	for row := range g.Board {
+		for col, cell := range g.Board[row] {
+			if cell == game.Empty {
+				moves = append(moves, game.Position{Row: row, Col: col})
+			}
+		}
+	}
+	return moves
+}
+
+// inside reports whether a cell is on the board
+func (g *Game) inside(row, col int) bool {
+	return row >= 0 && row < g.Shape.Rows && col >= 0 && col < g.Shape.Cols
+}
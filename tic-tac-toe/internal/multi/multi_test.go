@@ -0,0 +1,180 @@
+package multi_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"tic-tac-toe/internal/game"
+	"tic-tac-toe/internal/multi"
+)
+
+var _ = Describe("Multi", func() {
+	threePlayers := multi.Shapes[0]
+
+	// play places marks at the cells in turn, X first
+	play := func(shape multi.Shape, cells ...game.Position) *multi.Game {
+		g := multi.New(shape)
+		for _, p := range cells {
+			Expect(g.Place(p.Row, p.Col)).To(Succeed())
+		}
+		return g
+	}
+
+	at := func(row, col int) game.Position {
+		return game.Position{Row: row, Col: col}
+	}
+
+	Describe("Shapes", func() {
+		It("should round-trip shape names", func() {
+			for _, shape := range multi.Shapes {
+				Expect(shape.Validate()).To(Succeed())
+				Expect(multi.ParseShape(shape.Name())).To(Equal(shape))
+			}
+			Expect(threePlayers.String()).To(Equal("3 players, 6×6, 4 in a row"))
+		})
+
+		It("should reject games that cannot be played", func() {
+			for _, name := range []string{"6x6x4", "3p", "5p-6x6x4", "1p-6x6x4", "3p-10x6x4", "3p-6x6x7", "3p-6x6x2", "xp-6x6x4"} {
+				_, err := multi.ParseShape(name)
+				Expect(err).To(HaveOccurred(), name)
+			}
+		})
+	})
+
+	Describe("Place", func() {
+		It("should pass the turn on in rotation", func() {
+			g := play(multi.Shapes[1], at(0, 0), at(0, 1), at(0, 2))
+			Expect(g.Players()).To(Equal([]game.Player{game.PlayerX, game.PlayerO, game.PlayerTriangle, game.PlayerSquare}))
+			Expect(g.At(0, 2)).To(Equal(game.PlayerTriangle))
+			Expect(g.CurrentPlayer).To(Equal(game.PlayerSquare))
+			Expect(g.Place(0, 3)).To(Succeed())
+			Expect(g.CurrentPlayer).To(Equal(game.PlayerX))
+			Expect(g.MoverAt(3)).To(Equal(game.PlayerSquare))
+			Expect(g.MoverAt(4)).To(Equal(game.PlayerX))
+		})
+
+		It("should reject taken cells and cells off the board", func() {
+			g := play(threePlayers, at(2, 2))
+			Expect(g.Place(2, 2)).To(MatchError("position (2, 2) is already occupied"))
+			Expect(g.Place(6, 0)).To(MatchError("invalid position: (6, 0)"))
+		})
+
+		It("should let the third player win with a diagonal", func() {
+			g := play(threePlayers,
+				at(0, 5), at(1, 5), at(0, 0),
+				at(2, 5), at(3, 5), at(1, 1),
+				at(4, 5), at(5, 5), at(2, 2),
+				at(0, 4), at(1, 4), at(3, 3))
+			Expect(g.Status).To(Equal(game.StatusWon))
+			Expect(g.Winner).To(Equal(game.PlayerTriangle))
+			Expect(g.WinningLine()).To(ConsistOf(at(0, 0), at(1, 1), at(2, 2), at(3, 3)))
+			Expect(g.Place(4, 4)).To(MatchError("game is not in playing state"))
+		})
+
+		It("should draw when the board fills without a line", func() {
+			g := multi.New(multi.Shape{Players: 3, Cols: 3, Rows: 3, Connect: 3})
+			for _, p := range []game.Position{
+				at(0, 0), at(0, 1), at(0, 2),
+				at(1, 0), at(1, 1), at(1, 2),
+				at(2, 2), at(2, 0), at(2, 1),
+			} {
+				Expect(g.Place(p.Row, p.Col)).To(Succeed())
+			}
+			Expect(g.Status).To(Equal(game.StatusDraw))
+			Expect(g.Winner).To(Equal(game.Empty))
+		})
+	})
+
+	Describe("Evaluate", func() {
+		It("should be even on an empty board and favor the player with open lines", func() {
+			Expect(multi.New(threePlayers).Evaluate()).To(Equal([]int{0, 0, 0}))
+
+			g := play(threePlayers, at(2, 2), at(5, 0), at(0, 5), at(2, 3))
+			scores := g.Evaluate()
+			Expect(scores[0]).To(BeNumerically(">", 0))
+			Expect(scores[1]).To(BeNumerically("<", 0))
+			Expect(scores[2]).To(BeNumerically("<", 0))
+		})
+	})
+
+	Describe("AI", func() {
+		for _, strategy := range multi.Strategies {
+			strategy := strategy
+
+			Context(strategy.Name(), func() {
+				newAI := func(player game.Player, players int) *multi.AI {
+					a := multi.NewAI(player, players, 1, strategy)
+					a.SetSeed(1)
+					return a
+				}
+
+				It("should complete its line", func() {
+					// X has three in row 2 with both ends open
+					g := play(threePlayers,
+						at(2, 1), at(5, 0), at(5, 5),
+						at(2, 2), at(4, 0), at(4, 5),
+						at(2, 3), at(0, 0), at(0, 5))
+					Expect(newAI(game.PlayerX, threePlayers.Players).Move(context.Background(), g)).To(BeElementOf(at(2, 0), at(2, 4)))
+				})
+
+				It("should block the next player's line", func() {
+					// X has three in column 2 with one end open and moves after Δ
+					g := play(threePlayers,
+						at(0, 2), at(5, 5), at(5, 0),
+						at(1, 2), at(4, 5), at(0, 5),
+						at(2, 2), at(0, 0))
+					Expect(g.CurrentPlayer).To(Equal(game.PlayerTriangle))
+					// Δ must stop X at (3,2); nobody else moves in between
+					Expect(newAI(game.PlayerTriangle, threePlayers.Players).Move(context.Background(), g)).To(Equal(at(3, 2)))
+				})
+
+				It("should play whole games with four players", func() {
+					g := multi.New(multi.Shapes[3])
+					players := map[game.Player]*multi.AI{}
+					for _, mark := range g.Players() {
+						players[mark] = newAI(mark, g.Shape.Players)
+					}
+					for g.Status == game.StatusPlaying {
+						// A budget per move, as in the UI, keeps the deepest searches short
+						ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+						p, err := players[g.CurrentPlayer].Move(ctx, g)
+						cancel()
+						Expect(err).ToNot(HaveOccurred())
+						Expect(g.Place(p.Row, p.Col)).To(Succeed())
+					}
+					Expect(g.Status).To(Or(Equal(game.StatusWon), Equal(game.StatusDraw)))
+				})
+			})
+		}
+
+		It("should search deeper when paranoid and when stronger", func() {
+			Expect(multi.NewAI(game.PlayerO, 3, 1, multi.Paranoid).GetDepth()).To(BeNumerically(">", multi.NewAI(game.PlayerO, 3, 1, multi.MaxN).GetDepth()))
+			Expect(multi.NewAI(game.PlayerO, 3, 0, multi.MaxN).GetDepth()).To(BeNumerically("<", multi.NewAI(game.PlayerO, 3, 1, multi.MaxN).GetDepth()))
+		})
+
+		It("should look ahead to its own next move at full strength", func() {
+			for _, shape := range multi.Shapes {
+				// Its move, one by each other player, then its own again
+				Expect(multi.NewAI(game.PlayerX, shape.Players, 1, multi.MaxN).GetDepth()).To(Equal(shape.Players+1), shape.Name())
+			}
+		})
+
+		It("should read strategies back from their keys", func() {
+			for _, strategy := range multi.Strategies {
+				Expect(multi.ParseStrategy(string(strategy))).To(Equal(strategy))
+			}
+			_, err := multi.ParseStrategy("minimax")
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("should stop when the context is cancelled", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			_, err := multi.NewAI(game.PlayerX, threePlayers.Players, 1, multi.MaxN).Move(ctx, multi.New(threePlayers))
+			Expect(err).To(MatchError(context.Canceled))
+		})
+	})
+})
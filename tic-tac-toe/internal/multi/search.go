@@ -0,0 +1,355 @@
+package multi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+
+	"tic-tac-toe/internal/ai"
+	"tic-tac-toe/internal/game"
+)
+
+// errNoMoves is returned when the board is full or the game is over
+var errNoMoves = errors.New("no moves available")
+
+// winScore outweighs any heuristic score; quicker wins score higher
+const winScore = 1_000_000
+
+// Strategy is what the AI expects of the other players when it searches.
+// With two players both strategies are the same minimax search.
+type Strategy string
+
+const (
+	// MaxN expects every player to make the move best for themselves
+	MaxN Strategy = "max-n"
+	// Paranoid expects all the other players to gang up on the AI, which
+	// allows alpha-beta pruning and so a deeper search
+	Paranoid Strategy = "paranoid"
+)
+
+// Strategies lists the strategies in cycling order
+var Strategies = []Strategy{MaxN, Paranoid}
+
+// ParseStrategy reads a strategy by its key; empty is max-n
+func ParseStrategy(name string) (Strategy, error) {
+	switch Strategy(strings.ToLower(strings.TrimSpace(name))) {
+	case "", MaxN:
+		return MaxN, nil
+	case Paranoid:
+		return Paranoid, nil
+	}
+	return MaxN, fmt.Errorf("unknown AI search %q (want max-n or paranoid)", name)
+}
+
+// Name returns the strategy's display name
+func (s Strategy) Name() string {
+	if s == Paranoid {
+		return "Paranoid"
+	}
+	return "Max-n"
+}
+
+// AI plays one seat of a multi-player game. It searches a few moves ahead,
+// the other players replying in turn, and scores the positions it stops at
+// with Evaluate.
+type AI struct {
+	player       game.Player
+	strategy     Strategy
+	params       ai.Params
+	depth        int // Moves to look ahead, counting every player's
+	randomSource *rand.Rand
+}
+
+// NewAI creates an AI for player in a game of the given number of players
+// at a strength from 0 to 1; stronger AIs look further ahead and blunder
+// less. Only the blunder rate comes from the 3×3 AI's mistake model: there
+// is no softmax over the moves, so between blunders the AI plays the best
+// cell its search finds. At full strength it looks past every other
+// player's reply to its own next move.
+func NewAI(player game.Player, players int, strength float64, strategy Strategy) *AI {
+	params := ai.ParamsForStrength(strength)
+	depth := 1 + int(math.Round(params.Strength*float64(players)))
+	if strategy == Paranoid {
+		depth++ // Pruning pays for one more move
+	}
+	return &AI{
+		player:       player,
+		strategy:     strategy,
+		params:       params,
+		depth:        depth,
+		randomSource: rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// SetSeed makes the AI's random choices reproducible
+func (a *AI) SetSeed(seed int64) {
+	a.randomSource = rand.New(rand.NewSource(seed))
+}
+
+// GetPlayer returns the mark the AI plays
+func (a *AI) GetPlayer() game.Player {
+	return a.player
+}
+
+// GetStrategy returns how the AI expects the other players to play
+func (a *AI) GetStrategy() Strategy {
+	return a.strategy
+}
+
+// GetDepth returns how many moves the AI looks ahead
+func (a *AI) GetDepth() int {
+	return a.depth
+}
+
+// Move chooses the cell for the player to move in g. It deepens the search
+// one move at a time, so a cancelled context returns the best cell of the
+// deepest search that finished, or the context's error if none did.
+func (a *AI) Move(ctx context.Context, g *Game) (game.Position, error) {
+	moves := a.order(g, g.candidates())
+	if len(moves) == 0 {
+		return game.Position{}, errNoMoves
+	}
+	if err := ctx.Err(); err != nil {
+		return game.Position{}, err
+	}
+	if a.randomSource.Float64() < a.params.Blunder {
+		return moves[a.randomSource.Intn(len(moves))], nil
+	}
+
+	s := &search{ctx: ctx, g: g.Clone(), root: g.CurrentPlayer, strategy: a.strategy, order: moves}
+	best, found := game.Position{}, false
+	for depth := 1; depth <= a.depth; depth++ {
+		move, score, ok := s.search(depth)
+		if !ok {
+			break
+		}
+		best, found = move, true
+		if score >= winScore-depth || score <= -winScore+depth {
+			break // The result is decided; looking deeper changes nothing
+		}
+	}
+	if !found {
+		return game.Position{}, ctx.Err()
+	}
+	return best, nil
+}
+
+// order sorts moves central first, with moves as central as each other in
+// random order
+func (a *AI) order(g *Game, moves []game.Position) []game.Position {
+	a.randomSource.Shuffle(len(moves), func(i, j int) { moves[i], moves[j] = moves[j], moves[i] })
+	centerRow, centerCol := float64(g.Shape.Rows-1)/2, float64(g.Shape.Cols-1)/2
+	distance := func(p game.Position) float64 {
+		return math.Max(math.Abs(float64(p.Row)-centerRow), math.Abs(float64(p.Col)-centerCol))
+	}
+	sort.SliceStable(moves, func(i, j int) bool {
+		return distance(moves[i]) < distance(moves[j])
+	})
+	return moves
+}
+
+// candidates lists the empty cells next to a mark, since moves far from the
+// play rarely matter; on an empty board it offers every cell
+func (g *Game) candidates() []game.Position {
+	moves := g.AvailableMoves()
+	var near []game.Position
+	for _, p := range moves {
+		if g.touches(p) {
+			near = append(near, p)
+		}
+	}
+	if len(near) == 0 {
+		return moves
+	}
+	return near
+}
+
+// touches reports whether a cell is next to a mark, diagonals included
+func (g *Game) touches(p game.Position) bool {
+	for dr := -1; dr <= 1; dr++ {
+		for dc := -1; dc <= 1; dc++ {
+			if cell := g.At(p.Row+dr, p.Col+dc); (dr != 0 || dc != 0) && cell != game.Empty {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// search is one search of a position, placing and lifting marks on its own
+// copy of the board
+type search struct {
+	ctx       context.Context
+	g         *Game
+	root      game.Player // The player the search chooses a move for
+	strategy  Strategy
+	order     []game.Position // The root's moves in the order to try them
+	nodes     int
+	cancelled bool
+}
+
+// search looks depth moves ahead and returns the root's best move with its
+// score for the root, or false if the search was cancelled
+func (s *search) search(depth int) (game.Position, int, bool) {
+	best, bestScore := s.order[0], math.MinInt
+	alpha := -winScore - 1
+	for _, p := range s.order {
+		var score int
+		if s.strategy == Paranoid {
+			score = s.paranoidMove(p, s.root, depth, alpha, winScore+1, 1)
+			alpha = max(alpha, score)
+		} else {
+			score = s.maxNMove(p, s.root, depth, 1)[s.root.Index()]
+		}
+		if s.cancelled {
+			return game.Position{}, 0, false
+		}
+		if score > bestScore {
+			best, bestScore = p, score
+		}
+	}
+	return best, bestScore, true
+}
+
+// tick counts a node and notices a cancelled context every so often
+func (s *search) tick() bool {
+	s.nodes++
+	if s.nodes%1024 == 0 && s.ctx.Err() != nil {
+		s.cancelled = true
+	}
+	return s.cancelled
+}
+
+// outcome scores the position after player's mark at p for every player:
+// a win ends the search, and otherwise the heuristic scores it at depth 1
+func (s *search) outcome(p game.Position, player game.Player, depth, ply int) ([]int, bool) {
+	if len(s.g.lineThrough(p.Row, p.Col)) > 0 {
+		scores := make([]int, s.g.Shape.Players)
+		for i := range scores {
+			scores[i] = -(winScore - ply)
+		}
+		scores[player.Index()] = winScore - ply
+		return scores, true
+	}
+	if depth <= 1 {
+		return s.g.Evaluate(), true
+	}
+	return nil, false
+}
+
+// maxNMove places player's mark at p and returns the scores of every player
+// when each of the others then makes the move best for themselves
+func (s *search) maxNMove(p game.Position, player game.Player, depth, ply int) []int {
+	s.g.Board[p.Row][p.Col] = player
+	defer func() { s.g.Board[p.Row][p.Col] = game.Empty }()
+
+	if scores, done := s.outcome(p, player, depth, ply); done {
+		return scores
+	}
+	next := game.NextPlayer(player, s.g.Shape.Players)
+	if s.tick() {
+		return make([]int, s.g.Shape.Players)
+	}
+
+	var best []int
+	for _, reply := range s.g.candidates() {
+		scores := s.maxNMove(reply, next, depth-1, ply+1)
+		if best == nil || scores[next.Index()] > best[next.Index()] {
+			best = scores
+		}
+	}
+	if best == nil {
+		return make([]int, s.g.Shape.Players) // The board is full: a draw
+	}
+	return best
+}
+
+// paranoidMove places player's mark at p and returns the root's score when
+// the root then plays its best and every other player plays against it
+func (s *search) paranoidMove(p game.Position, player game.Player, depth, alpha, beta, ply int) int {
+	s.g.Board[p.Row][p.Col] = player
+	defer func() { s.g.Board[p.Row][p.Col] = game.Empty }()
+
+	if scores, done := s.outcome(p, player, depth, ply); done {
+		return scores[s.root.Index()]
+	}
+	next := game.NextPlayer(player, s.g.Shape.Players)
+	if s.tick() {
+		return 0
+	}
+
+	maximizing := next == s.root
+	best, moved := winScore+1, false
+	if maximizing {
+		best = -winScore - 1
+	}
+	for _, reply := range s.g.candidates() {
+		moved = true
+		score := s.paranoidMove(reply, next, depth-1, alpha, beta, ply+1)
+		if maximizing {
+			best = max(best, score)
+			alpha = max(alpha, score)
+		} else {
+			best = min(best, score)
+			beta = min(beta, score)
+		}
+		if alpha >= beta {
+			break
+		}
+	}
+	if !moved {
+		return 0 // The board is full: a draw
+	}
+	return best
+}
+
+// Evaluate scores a position without searching it, for each player in turn
+// order. Every run of Connect cells that only one player holds counts for
+// that player, and counts eight times as much for each extra mark in it. A
+// player's score is their count less the best count among the others, so
+// the leader's threats weigh on everyone else.
+func (g *Game) Evaluate() []int {
+	counts := make([]int, g.Shape.Players)
+	for row := 0; row < g.Shape.Rows; row++ {
+		for col := 0; col < g.Shape.Cols; col++ {
+			for _, d := range directions {
+				endRow, endCol := row+(g.Shape.Connect-1)*d.Row, col+(g.Shape.Connect-1)*d.Col
+				if !g.inside(endRow, endCol) {
+					continue
+				}
+				owner, marks := game.Empty, 0
+				for i := 0; i < g.Shape.Connect; i++ {
+					cell := g.Board[row+i*d.Row][col+i*d.Col]
+					if cell == game.Empty {
+						continue
+					}
+					if owner != game.Empty && cell != owner {
+						marks = 0
+						break
+					}
+					owner, marks = cell, marks+1
+				}
+				if marks > 0 {
+					counts[owner.Index()] += 1 << (3 * (marks - 1))
+				}
+			}
+		}
+	}
+
+	scores := make([]int, g.Shape.Players)
+	for i := range scores {
+		rival := math.MinInt
+		for j, count := range counts {
+			if j != i {
+				rival = max(rival, count)
+			}
+		}
+		scores[i] = counts[i] - rival
+	}
+	return scores
+}
@@ -5,10 +5,15 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
+	"time"
 
 	"tic-tac-toe/internal/ai"
 	"tic-tac-toe/internal/game"
 	"tic-tac-toe/internal/gradient"
+	"tic-tac-toe/internal/match"
+	"tic-tac-toe/internal/puzzle"
 )
 
 const (
@@ -16,22 +21,76 @@ const (
 	gameStateFile  = "gamestate.json"
 	settingsFile   = "settings.json"
 	scoresFile     = "scores.json"
+	archiveFile    = "archive.json"
+	bookFile       = "opening_book.json"
+	puzzleFile     = "puzzles.json"
+	matchesFile    = "matches.json"
 )
 
 // GameState represents the serializable game state
 type GameState struct {
-	Board         [3][3]string `json:"board"`
-	CurrentPlayer string       `json:"current_player"`
-	Status        int          `json:"status"`
-	Winner        string       `json:"winner"`
-	Mode          int          `json:"mode"`
-	MoveHistory   []Position   `json:"move_history"`
+	Board         [3][3]string  `json:"board"`
+	CurrentPlayer string        `json:"current_player"`
+	Status        int           `json:"status"`
+	Winner        string        `json:"winner"`
+	Mode          int           `json:"mode"`
+	MoveHistory   []Position    `json:"move_history"`
+	Clock         *game.Clock   `json:"clock,omitempty"`
+	TimeLoss      bool          `json:"time_loss,omitempty"`
+	Variant       string        `json:"variant,omitempty"`
+	Boards        int           `json:"boards,omitempty"`  // Boards Notakto is played on, when more than one
+	Marks         []string      `json:"marks,omitempty"`   // Mark placed by each move, for variants where players choose
+	Setup         *game.Setup   `json:"setup,omitempty"`   // The starting position; nil for the empty board
+	Opening       *game.Opening `json:"opening,omitempty"` // Nil without a swap rule
 }
 
 // Position represents a move position
 type Position struct {
-	Row int `json:"row"`
-	Col int `json:"col"`
+	Row   int       `json:"row"`
+	Col   int       `json:"col"`
+	Mark  string    `json:"mark,omitempty"`  // Only for variants where players choose their mark
+	From  *Position `json:"from,omitempty"`  // The cell a moved mark left, for variants where marks move
+	Board int       `json:"board,omitempty"` // The board played on, for Notakto on several boards
+}
+
+// ChatMessage is a chat line or emote sent during a game
+type ChatMessage struct {
+	From  string    `json:"from"`
+	Text  string    `json:"text"`
+	Emote bool      `json:"emote,omitempty"`
+	Time  time.Time `json:"time"`
+}
+
+// ArchivedGame is a finished game kept for replays
+type ArchivedGame struct {
+	ID       string        `json:"id"`
+	PlayedAt time.Time     `json:"played_at"`
+	Mode     int           `json:"mode"`
+	PlayerX  string        `json:"player_x"`
+	PlayerO  string        `json:"player_o"`
+	Winner   string        `json:"winner"`
+	Moves    []Position    `json:"moves"`
+	Chat     []ChatMessage `json:"chat,omitempty"`
+	Variant  string        `json:"variant,omitempty"` // Empty for classic rules
+	Boards   int           `json:"boards,omitempty"`  // Notakto boards, when more than one
+	Setup    *game.Setup   `json:"setup,omitempty"`   // Nil for games from the empty board
+	Swap     string        `json:"swap,omitempty"`    // The swap rule; empty when off
+	Match    *MatchGame    `json:"match,omitempty"`   // Nil for games outside a match
+}
+
+// MatchGame places an archived game within its match
+type MatchGame struct {
+	ID   string `json:"id"`   // The ID of the match record
+	Game int    `json:"game"` // Counted from 1
+}
+
+// MatchRecord is a finished match kept with the archive
+type MatchRecord struct {
+	ID         string      `json:"id"`
+	PlayedAt   time.Time   `json:"played_at"`
+	Mode       int         `json:"mode"`
+	Match      match.Match `json:"match"`
+	Tournament string      `json:"tournament,omitempty"` // The ID shared by the matches of one tournament
 }
 
 // Settings represents application settings
@@ -42,14 +101,46 @@ type Settings struct {
 	SoundEnabled     bool    `json:"sound_enabled"`
 	LastGameMode     int     `json:"last_game_mode"`
 	AutoSaveEnabled  bool    `json:"auto_save_enabled"`
+	TimeControl      string  `json:"time_control,omitempty"`
+	AIStrength       float64 `json:"ai_strength"` // Negative follows the AI difficulty
+	AIPersonality    string  `json:"ai_personality,omitempty"`
+	Variant          string  `json:"variant,omitempty"`
+	GravityBoard     string  `json:"gravity_board,omitempty"`
+	Setup            string  `json:"setup,omitempty"` // Empty for the empty board
+	SwapRule         string  `json:"swap_rule,omitempty"`
+	Match            string  `json:"match,omitempty"` // Empty for single games
+	MultiBoard       string  `json:"multi_board,omitempty"`
+	MultiSearch      string  `json:"multi_search,omitempty"`
+	NotaktoBoards    int     `json:"notakto_boards,omitempty"`
 }
 
 // Scores represents game statistics
 type Scores struct {
-	PlayerVsPlayer PlayerVsPlayerStats `json:"player_vs_player"`
-	PlayerVsAI     PlayerVsAIStats     `json:"player_vs_ai"`
-	TotalGames     int                 `json:"total_games"`
-	LastPlayed     string              `json:"last_played"`
+	PlayerVsPlayer PlayerVsPlayerStats      `json:"player_vs_player"`
+	PlayerVsAI     PlayerVsAIStats          `json:"player_vs_ai"`
+	TotalGames     int                      `json:"total_games"`
+	LastPlayed     string                   `json:"last_played"`
+	Variants       map[string]*VariantStats `json:"variants,omitempty"` // Games under rule variants, by variant
+	Gravity        map[string]*VariantStats `json:"gravity,omitempty"`  // Gravity games, by board
+	Setups         map[string]*VariantStats `json:"setups,omitempty"`   // Games from a setup, by setup name
+	Multi          map[string]*MultiStats   `json:"multi,omitempty"`    // Multi-player games, by players and board
+	Matches        MatchStats               `json:"matches"`
+}
+
+// MatchStats counts finished matches and tournaments
+type MatchStats struct {
+	Played      int            `json:"played"`
+	Drawn       int            `json:"drawn"`
+	Wins        map[string]int `json:"wins,omitempty"` // Matches won, by player name
+	Tournaments int            `json:"tournaments"`
+	Champions   map[string]int `json:"champions,omitempty"` // Tournaments won, by player name
+}
+
+// MultiStats counts multi-player games on one board
+type MultiStats struct {
+	Games int            `json:"games"`
+	Draws int            `json:"draws"`
+	Wins  map[string]int `json:"wins,omitempty"` // Games won, by mark
 }
 
 // PlayerVsPlayerStats represents PvP statistics
@@ -60,6 +151,14 @@ type PlayerVsPlayerStats struct {
 	Games  int `json:"games"`
 }
 
+// VariantStats represents results under one rule variant, in any mode
+type VariantStats struct {
+	XWins int `json:"x_wins"`
+	OWins int `json:"o_wins"`
+	Draws int `json:"draws"`
+	Games int `json:"games"`
+}
+
 // PlayerVsAIStats represents Player vs AI statistics
 type PlayerVsAIStats struct {
 	Easy       DifficultyStats `json:"easy"`
@@ -84,24 +183,48 @@ type Manager struct {
 // New creates a new persistence manager
 func New() *Manager {
 	homeDir, _ := os.UserHomeDir()
-	saveDir := filepath.Join(homeDir, saveDir)
-	
+	return NewWithDirectory(filepath.Join(homeDir, saveDir))
+}
+
+// NewWithDirectory creates a persistence manager that saves under dir
+func NewWithDirectory(dir string) *Manager {
 	// Create save directory if it doesn't exist
-	os.MkdirAll(saveDir, 0755)
+	os.MkdirAll(dir, 0755)
 	
 	return &Manager{
-		saveDirectory: saveDir,
+		saveDirectory: dir,
 	}
 }
 
-// SaveGameState saves the current game state immediately
-func (m *Manager) SaveGameState(g *game.Game) error {
+// NewGameState converts a game into its serializable form
+func NewGameState(g *game.Game) *GameState {
 	gameState := &GameState{
 		CurrentPlayer: string(g.GetCurrentPlayer()),
 		Status:        int(g.GetStatus()),
 		Winner:        string(g.GetWinner()),
 		Mode:          int(g.GetMode()),
 		MoveHistory:   []Position{},
+		TimeLoss:      g.TimeLoss,
+		Setup:         g.Setup,
+	}
+	if rules := g.GetRules(); !rules.IsClassic() {
+		gameState.Variant = string(rules.Variant)
+	}
+	if boards := g.GetRules().BoardCount(); boards > 1 {
+		gameState.Boards = boards
+	}
+	if g.Opening.Rule != game.NoSwap {
+		opening := g.Opening
+		gameState.Opening = &opening
+	}
+	for _, mark := range g.Marks {
+		gameState.Marks = append(gameState.Marks, string(mark))
+	}
+
+	// Store the clock paused so time away from the game is not charged
+	if g.Clock != nil {
+		gameState.Clock = g.Clock.Copy()
+		gameState.Clock.Pause(time.Now())
 	}
 
 	// Convert board
@@ -113,10 +236,79 @@ func (m *Manager) SaveGameState(g *game.Game) error {
 	}
 
 	// Convert move history
-	// Note: We'll need to add a GetMoveHistory method to game.Game
-	// For now, initialize empty array
+	for i, move := range g.GetMoveHistory() {
+		position := Position{Row: move.Row, Col: move.Col}
+		if from, ok := g.Slides[i]; ok {
+			position.From = &Position{Row: from.Row, Col: from.Col}
+		}
+		position.Board = g.OnBoard[i]
+		gameState.MoveHistory = append(gameState.MoveHistory, position)
+	}
+
+	return gameState
+}
+
+// Restore rebuilds a game from the serialized state
+func (s *GameState) Restore() *game.Game {
+	g := game.New()
+
+	// Restore board
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			g.Board[i][j] = game.Player(s.Board[i][j])
+		}
+	}
+
+	g.CurrentPlayer = game.Player(s.CurrentPlayer)
+	g.Status = game.GameStatus(s.Status)
+	g.Winner = game.Player(s.Winner)
+	g.SetMode(game.GameMode(s.Mode))
+	g.TimeLoss = s.TimeLoss
+	g.Setup = s.Setup
+	if s.Opening != nil {
+		g.Opening = *s.Opening
+	}
+	g.SetRules(game.Rules{Variant: game.Variant(s.Variant), Boards: s.Boards})
+	for _, mark := range s.Marks {
+		g.Marks = append(g.Marks, game.Player(mark))
+	}
+	if s.Clock != nil {
+		g.Clock = s.Clock.Copy()
+	}
+
+	for i, move := range s.MoveHistory {
+		g.MoveHistory = append(g.MoveHistory, game.Position{Row: move.Row, Col: move.Col})
+		if move.From != nil {
+			if g.Slides == nil {
+				g.Slides = map[int]game.Position{}
+			}
+			g.Slides[i] = game.Position{Row: move.From.Row, Col: move.From.Col}
+		}
+	}
+	if len(g.Extra) > 0 {
+		g.Extra, g.OnBoard = s.replayBoards(g.GetRules())
+	}
+
+	return g
+}
 
-	return m.saveJSON(gameStateFile, gameState)
+// replayBoards rebuilds the boards after the first, which are not saved, by
+// replaying each move onto its own board; a move that no longer plays ends
+// the replay
+func (s *GameState) replayBoards(rules game.Rules) ([][3][3]game.Player, map[int]int) {
+	replay := game.New()
+	replay.SetRules(rules)
+	for _, move := range s.MoveHistory {
+		if err := move.Play(replay); err != nil {
+			break
+		}
+	}
+	return replay.Extra, replay.OnBoard
+}
+
+// SaveGameState saves the current game state immediately
+func (m *Manager) SaveGameState(g *game.Game) error {
+	return m.saveJSON(gameStateFile, NewGameState(g))
 }
 
 // LoadGameState loads the saved game state
@@ -128,33 +320,28 @@ func (m *Manager) LoadGameState() (*game.Game, error) {
 		return game.New(), nil
 	}
 
-	g := game.New()
-	
-	// Restore board
-	for i := 0; i < 3; i++ {
-		for j := 0; j < 3; j++ {
-			g.Board[i][j] = game.Player(gameState.Board[i][j])
-		}
-	}
-	
-	g.CurrentPlayer = game.Player(gameState.CurrentPlayer)
-	g.Status = game.GameStatus(gameState.Status)
-	g.Winner = game.Player(gameState.Winner)
-	g.SetMode(game.GameMode(gameState.Mode))
-
-	return g, nil
+	return gameState.Restore(), nil
 }
 
 // SaveSettings saves application settings immediately
 func (m *Manager) SaveSettings(gradientType gradient.GradientType, aiDifficulty ai.Difficulty, animationSpeed float64) error {
-	settings := &Settings{
-		GradientType:    int(gradientType),
-		AIDifficulty:    int(aiDifficulty),
-		AnimationSpeed:  animationSpeed,
-		SoundEnabled:    false, // Default for now
-		AutoSaveEnabled: true,  // Always enabled for this app
+	return m.UpdateSettings(func(settings *Settings) {
+		settings.GradientType = int(gradientType)
+		settings.AIDifficulty = int(aiDifficulty)
+		settings.AnimationSpeed = animationSpeed
+	})
+}
+
+// UpdateSettings applies update to the stored settings and saves immediately
+func (m *Manager) UpdateSettings(update func(settings *Settings)) error {
+	settings, err := m.LoadSettings()
+	if err != nil {
+		return err
 	}
 
+	update(settings)
+	settings.AutoSaveEnabled = true // Always enabled for this app
+
 	return m.saveJSON(settingsFile, settings)
 }
 
@@ -166,6 +353,8 @@ func (m *Manager) LoadSettings() (*Settings, error) {
 		AnimationSpeed:  1.0,                   // Default
 		SoundEnabled:    false,
 		AutoSaveEnabled: true,
+		TimeControl:     "untimed",
+		AIStrength:      -1,
 	}
 
 	err := m.loadJSON(settingsFile, settings)
@@ -226,6 +415,102 @@ func (m *Manager) UpdatePlayerVsPlayerScore(winner game.Player) error {
 	return m.SaveScores(scores)
 }
 
+// UpdateVariantScore records a game played under a rule variant and saves immediately
+func (m *Manager) UpdateVariantScore(variant game.Variant, winner game.Player) error {
+	scores, err := m.LoadScores()
+	if err != nil {
+		return err
+	}
+
+	if scores.Variants == nil {
+		scores.Variants = map[string]*VariantStats{}
+	}
+	recordResult(scores.Variants, string(variant), winner)
+	scores.TotalGames++
+
+	return m.SaveScores(scores)
+}
+
+// UpdateGravityScore records a gravity game on the named board, e.g. "7x6x4", and saves immediately
+func (m *Manager) UpdateGravityScore(board string, winner game.Player) error {
+	scores, err := m.LoadScores()
+	if err != nil {
+		return err
+	}
+
+	if scores.Gravity == nil {
+		scores.Gravity = map[string]*VariantStats{}
+	}
+	recordResult(scores.Gravity, board, winner)
+	scores.TotalGames++
+
+	return m.SaveScores(scores)
+}
+
+// UpdateMultiScore records a multi-player game of the named shape, e.g. "3p-6x6x4", and saves immediately
+func (m *Manager) UpdateMultiScore(shape string, winner game.Player) error {
+	scores, err := m.LoadScores()
+	if err != nil {
+		return err
+	}
+
+	if scores.Multi == nil {
+		scores.Multi = map[string]*MultiStats{}
+	}
+	stats := scores.Multi[shape]
+	if stats == nil {
+		stats = &MultiStats{Wins: map[string]int{}}
+		scores.Multi[shape] = stats
+	}
+	if stats.Wins == nil {
+		stats.Wins = map[string]int{}
+	}
+	stats.Games++
+	if winner == game.Empty {
+		stats.Draws++
+	} else {
+		stats.Wins[string(winner)]++
+	}
+	scores.TotalGames++
+
+	return m.SaveScores(scores)
+}
+
+// UpdateSetupScore records a game played from the named setup and saves immediately
+func (m *Manager) UpdateSetupScore(setup string, winner game.Player) error {
+	scores, err := m.LoadScores()
+	if err != nil {
+		return err
+	}
+
+	if scores.Setups == nil {
+		scores.Setups = map[string]*VariantStats{}
+	}
+	recordResult(scores.Setups, setup, winner)
+	scores.TotalGames++
+
+	return m.SaveScores(scores)
+}
+
+// recordResult adds a game to the stats kept under key
+func recordResult(table map[string]*VariantStats, key string, winner game.Player) {
+	stats := table[key]
+	if stats == nil {
+		stats = &VariantStats{}
+		table[key] = stats
+	}
+
+	stats.Games++
+	switch winner {
+	case game.PlayerX:
+		stats.XWins++
+	case game.PlayerO:
+		stats.OWins++
+	case game.Empty:
+		stats.Draws++
+	}
+}
+
 // UpdatePlayerVsAIScore updates Player vs AI statistics and saves immediately
 func (m *Manager) UpdatePlayerVsAIScore(difficulty ai.Difficulty, winner game.Player, aiPlayer game.Player) error {
 	scores, err := m.LoadScores()
@@ -259,9 +544,405 @@ func (m *Manager) UpdatePlayerVsAIScore(difficulty ai.Difficulty, winner game.Pl
 	return m.SaveScores(scores)
 }
 
+// ArchiveGame appends a finished game to the archive and saves immediately
+func (m *Manager) ArchiveGame(record ArchivedGame) error {
+	archive, err := m.LoadArchive()
+	if err != nil {
+		return err
+	}
+
+	if record.PlayedAt.IsZero() {
+		record.PlayedAt = time.Now()
+	}
+	if record.ID == "" {
+		record.ID = fmt.Sprintf("%d", record.PlayedAt.UnixNano())
+	}
+
+	archive = append(archive, record)
+	if err := m.saveJSON(archiveFile, archive); err != nil {
+		return err
+	}
+	return m.updateOpeningBook(record)
+}
+
+// ImportArchive appends games whose IDs are not archived yet and reports how many were added
+func (m *Manager) ImportArchive(records []ArchivedGame) (int, error) {
+	archive, err := m.LoadArchive()
+	if err != nil {
+		return 0, err
+	}
+
+	known := make(map[string]bool, len(archive))
+	for _, record := range archive {
+		known[record.ID] = true
+	}
+
+	added := 0
+	for _, record := range records {
+		if record.ID == "" || known[record.ID] {
+			continue
+		}
+		known[record.ID] = true
+		archive = append(archive, record)
+		added++
+	}
+
+	if added == 0 {
+		return 0, nil
+	}
+	if err := m.saveJSON(archiveFile, archive); err != nil {
+		return 0, err
+	}
+	return added, m.updateOpeningBook(records...)
+}
+
+// Replay plays the archived moves on a fresh board under the game's rules
+// and from its setup, failing on any illegal move
+func (a ArchivedGame) Replay() (*game.Game, error) {
+	g := game.New()
+	g.SetRules(a.Rules())
+	if err := g.ApplySetup(a.Setup); err != nil {
+		return nil, fmt.Errorf("game %s: %w", a.ID, err)
+	}
+	for i, move := range a.Moves {
+		if err := move.Play(g); err != nil {
+			return nil, fmt.Errorf("game %s move %d: %w", a.ID, i+1, err)
+		}
+	}
+	if g.GetStatus() == game.StatusWon && string(g.GetWinner()) != a.Winner {
+		return nil, fmt.Errorf("game %s: moves show %s winning, record says %q", a.ID, g.GetWinner(), a.Winner)
+	}
+	return g, nil
+}
+
+// Rules returns the rules the archived game was played under
+func (a ArchivedGame) Rules() game.Rules {
+	return game.Rules{Variant: game.Variant(a.Variant), Boards: a.Boards}
+}
+
+// IsStandard reports whether the archived game is classic tic-tac-toe from
+// the empty board, with each player keeping their side
+func (a ArchivedGame) IsStandard() bool {
+	return a.Rules().IsClassic() && a.Setup == nil && a.Swap == ""
+}
+
+// Play makes the move in g, with its mark if it has one
+func (p Position) Play(g *game.Game) error {
+	if p.From != nil {
+		return g.MakeSlide(game.Position{Row: p.From.Row, Col: p.From.Col}, game.Position{Row: p.Row, Col: p.Col})
+	}
+	if p.Board > 0 {
+		return g.MakeMoveOn(p.Board, p.Row, p.Col)
+	}
+	if p.Mark == "" {
+		return g.MakeMove(p.Row, p.Col)
+	}
+	return g.MakeMoveMark(p.Row, p.Col, game.Player(p.Mark))
+}
+
+// LoadArchive loads all archived games, oldest first
+func (m *Manager) LoadArchive() ([]ArchivedGame, error) {
+	var archive []ArchivedGame
+	if err := m.loadJSON(archiveFile, &archive); err != nil {
+		// Return an empty archive if no file exists
+		return []ArchivedGame{}, nil
+	}
+	return archive, nil
+}
+
+// RecordMatch adds a finished match to the match archive and the stats,
+// and saves immediately
+func (m *Manager) RecordMatch(record MatchRecord) error {
+	if !record.Match.Over() {
+		return fmt.Errorf("the match is not over yet")
+	}
+	matches, err := m.LoadMatches()
+	if err != nil {
+		return err
+	}
+
+	if record.PlayedAt.IsZero() {
+		record.PlayedAt = time.Now()
+	}
+	if record.ID == "" {
+		record.ID = fmt.Sprintf("%d", record.PlayedAt.UnixNano())
+	}
+	matches = append(matches, record)
+	if err := m.saveJSON(matchesFile, matches); err != nil {
+		return err
+	}
+
+	scores, err := m.LoadScores()
+	if err != nil {
+		return err
+	}
+	scores.Matches.Played++
+	if winner, ok := record.Match.Winner(); ok {
+		if scores.Matches.Wins == nil {
+			scores.Matches.Wins = map[string]int{}
+		}
+		scores.Matches.Wins[record.Match.Players[winner]]++
+	} else {
+		scores.Matches.Drawn++
+	}
+	return m.SaveScores(scores)
+}
+
+// RecordTournament counts a finished tournament and its champion and saves immediately
+func (m *Manager) RecordTournament(champion string) error {
+	scores, err := m.LoadScores()
+	if err != nil {
+		return err
+	}
+
+	scores.Matches.Tournaments++
+	if scores.Matches.Champions == nil {
+		scores.Matches.Champions = map[string]int{}
+	}
+	scores.Matches.Champions[champion]++
+	return m.SaveScores(scores)
+}
+
+// LoadMatches loads the finished matches, oldest first
+func (m *Manager) LoadMatches() ([]MatchRecord, error) {
+	var matches []MatchRecord
+	if err := m.loadJSON(matchesFile, &matches); err != nil {
+		// Start with an empty list if no file exists
+		return []MatchRecord{}, nil
+	}
+	return matches, nil
+}
+
+// OpeningStats is how one opening has gone for the human player
+type OpeningStats struct {
+	Name   string      `json:"name"`
+	Side   game.Player `json:"side"` // The side the human played
+	Games  int         `json:"games"`
+	Wins   int         `json:"wins"`
+	Draws  int         `json:"draws"`
+	Losses int         `json:"losses"`
+}
+
+// WinRate returns the share of games won, from 0 to 1
+func (s OpeningStats) WinRate() float64 {
+	if s.Games == 0 {
+		return 0
+	}
+	return float64(s.Wins) / float64(s.Games)
+}
+
+// LoadOpeningBook loads the opening book, building it from the archive the first time
+func (m *Manager) LoadOpeningBook() (*ai.OpeningBook, error) {
+	book := ai.NewOpeningBook()
+	if err := m.loadJSON(bookFile, book); err == nil {
+		return book, nil
+	}
+
+	archive, err := m.LoadArchive()
+	if err != nil {
+		return nil, err
+	}
+	for _, record := range archive {
+		if record.IsStandard() {
+			book.Record(record.gameMoves(), game.Player(record.Winner))
+		}
+	}
+	if len(archive) > 0 {
+		if err := m.SaveOpeningBook(book); err != nil {
+			return nil, err
+		}
+	}
+	return book, nil
+}
+
+// SaveOpeningBook saves the opening book immediately
+func (m *Manager) SaveOpeningBook(book *ai.OpeningBook) error {
+	return m.saveJSON(bookFile, book)
+}
+
+// updateOpeningBook adds newly archived games to the opening book
+func (m *Manager) updateOpeningBook(records ...ArchivedGame) error {
+	if _, err := os.Stat(filepath.Join(m.saveDirectory, bookFile)); os.IsNotExist(err) {
+		// The first load builds the book from the whole archive, these games included
+		_, err := m.LoadOpeningBook()
+		return err
+	}
+
+	book, err := m.LoadOpeningBook()
+	if err != nil {
+		return err
+	}
+	for _, record := range records {
+		if record.IsStandard() {
+			book.Record(record.gameMoves(), game.Player(record.Winner))
+		}
+	}
+	return m.SaveOpeningBook(book)
+}
+
+// HumanOpenings groups the archived games against the AI by opening, most
+// played first, and reports the human's results in each
+func (m *Manager) HumanOpenings(limit int) ([]OpeningStats, error) {
+	archive, err := m.LoadArchive()
+	if err != nil {
+		return nil, err
+	}
+
+	byOpening := map[string]*OpeningStats{}
+	for _, record := range archive {
+		if record.Mode != int(game.PlayerVsAI) || len(record.Moves) == 0 || !record.IsStandard() {
+			continue
+		}
+
+		human := record.humanSide()
+		name := ai.OpeningName(record.gameMoves())
+		key := string(human) + name
+		stats := byOpening[key]
+		if stats == nil {
+			stats = &OpeningStats{Name: name, Side: human}
+			byOpening[key] = stats
+		}
+
+		stats.Games++
+		switch record.Winner {
+		case string(human):
+			stats.Wins++
+		case "", string(game.Empty):
+			stats.Draws++
+		default:
+			stats.Losses++
+		}
+	}
+
+	openings := make([]OpeningStats, 0, len(byOpening))
+	for _, stats := range byOpening {
+		openings = append(openings, *stats)
+	}
+	sort.Slice(openings, func(i, j int) bool {
+		if openings[i].Games != openings[j].Games {
+			return openings[i].Games > openings[j].Games
+		}
+		if openings[i].Name != openings[j].Name {
+			return openings[i].Name < openings[j].Name
+		}
+		return openings[i].Side < openings[j].Side
+	})
+	if limit > 0 && len(openings) > limit {
+		openings = openings[:limit]
+	}
+	return openings, nil
+}
+
+// PuzzleRecord is how one puzzle has gone
+type PuzzleRecord struct {
+	Attempts   int       `json:"attempts"`
+	Solved     int       `json:"solved"`
+	LastPlayed time.Time `json:"last_played"`
+}
+
+// PuzzleStats tracks puzzle streaks and per-puzzle results
+type PuzzleStats struct {
+	Attempts   int                      `json:"attempts"`
+	Solved     int                      `json:"solved"`
+	Streak     int                      `json:"streak"` // Puzzles solved in a row
+	BestStreak int                      `json:"best_streak"`
+	Puzzles    map[string]*PuzzleRecord `json:"puzzles"`
+}
+
+// IsSolved reports whether the puzzle has ever been solved
+func (s *PuzzleStats) IsSolved(id string) bool {
+	record, ok := s.Puzzles[id]
+	return ok && record.Solved > 0
+}
+
+// LoadPuzzleStats loads puzzle statistics
+func (m *Manager) LoadPuzzleStats() (*PuzzleStats, error) {
+	stats := &PuzzleStats{}
+	if err := m.loadJSON(puzzleFile, stats); err != nil {
+		// Start fresh if no file exists
+		stats = &PuzzleStats{}
+	}
+	if stats.Puzzles == nil {
+		stats.Puzzles = map[string]*PuzzleRecord{}
+	}
+	return stats, nil
+}
+
+// RecordPuzzleAttempt saves the result of one try at a puzzle and returns the updated stats
+func (m *Manager) RecordPuzzleAttempt(id string, solved bool) (*PuzzleStats, error) {
+	stats, err := m.LoadPuzzleStats()
+	if err != nil {
+		return nil, err
+	}
+
+	record := stats.Puzzles[id]
+	if record == nil {
+		record = &PuzzleRecord{}
+		stats.Puzzles[id] = record
+	}
+	record.Attempts++
+	record.LastPlayed = time.Now()
+	stats.Attempts++
+
+	if solved {
+		record.Solved++
+		stats.Solved++
+		stats.Streak++
+		stats.BestStreak = max(stats.BestStreak, stats.Streak)
+	} else {
+		stats.Streak = 0
+	}
+
+	if err := m.saveJSON(puzzleFile, stats); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// MistakePuzzles turns the human's missed wins and draws in archived games
+// against the AI into puzzles, one per position
+func (m *Manager) MistakePuzzles() ([]puzzle.Puzzle, error) {
+	archive, err := m.LoadArchive()
+	if err != nil {
+		return nil, err
+	}
+
+	var puzzles []puzzle.Puzzle
+	seen := map[string]bool{}
+	for _, record := range archive {
+		if record.Mode != int(game.PlayerVsAI) || !record.IsStandard() {
+			continue
+		}
+		for _, p := range puzzle.FromGame(record.gameMoves(), record.humanSide()) {
+			if !seen[p.ID] {
+				seen[p.ID] = true
+				puzzles = append(puzzles, p)
+			}
+		}
+	}
+	return puzzles, nil
+}
+
+// humanSide returns the side the human played in a game against the AI
+func (a ArchivedGame) humanSide() game.Player {
+	if strings.HasPrefix(a.PlayerX, "AI") {
+		return game.PlayerO
+	}
+	return game.PlayerX
+}
+
+// gameMoves converts the archived moves into game positions
+func (a ArchivedGame) gameMoves() []game.Position {
+	moves := make([]game.Position, len(a.Moves))
+	for i, move := range a.Moves {
+		moves[i] = game.Position{Row: move.Row, Col: move.Col}
+	}
+	return moves
+}
+
 // ClearAllData removes all saved data
 func (m *Manager) ClearAllData() error {
-	files := []string{gameStateFile, settingsFile, scoresFile}
+	files := []string{gameStateFile, settingsFile, scoresFile, archiveFile, bookFile, puzzleFile, matchesFile}
 	
 	for _, file := range files {
 		filePath := filepath.Join(m.saveDirectory, file)
@@ -3,6 +3,7 @@ package persistence_test
 import (
 	"os"
 	"path/filepath"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -10,7 +11,9 @@ import (
 	"tic-tac-toe/internal/ai"
 	"tic-tac-toe/internal/game"
 	"tic-tac-toe/internal/gradient"
+	"tic-tac-toe/internal/match"
 	"tic-tac-toe/internal/persistence"
+	"tic-tac-toe/internal/puzzle"
 )
 
 var _ = Describe("Persistence", func() {
@@ -65,6 +68,53 @@ var _ = Describe("Persistence", func() {
 		})
 	})
 
+	Describe("NewGameState and Restore", func() {
+		It("should round-trip the board and move history", func() {
+			g := game.New()
+			g.MakeMove(0, 0)
+			g.MakeMove(2, 1)
+
+			restored := persistence.NewGameState(g).Restore()
+			Expect(restored.GetBoard()).To(Equal(g.GetBoard()))
+			Expect(restored.GetCurrentPlayer()).To(Equal(game.PlayerX))
+			Expect(restored.GetMoveHistory()).To(Equal([]game.Position{{Row: 0, Col: 0}, {Row: 2, Col: 1}}))
+		})
+
+		It("should save the clock paused and keep the remaining time", func() {
+			start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+			g := game.New()
+			g.SetTimeControl(game.TimeControl{Name: "test", Initial: time.Minute})
+			g.StartClock(start)
+			g.MakeMoveAt(1, 1, start.Add(5*time.Second))
+
+			restored := persistence.NewGameState(g).Restore()
+			Expect(restored.Clock).ToNot(BeNil())
+			Expect(restored.Clock.TurnStart.IsZero()).To(BeTrue())
+			Expect(restored.GetRemainingTime(game.PlayerX, time.Now())).To(Equal(55 * time.Second))
+		})
+
+		It("should keep every board of a Notakto game on several boards", func() {
+			g := game.New()
+			g.SetRules(game.Rules{Variant: game.Notakto, Boards: 3})
+			Expect(g.MakeMoveOn(0, 1, 1)).To(Succeed())
+			Expect(g.MakeMoveOn(1, 0, 0)).To(Succeed())
+			Expect(g.MakeMoveOn(2, 2, 2)).To(Succeed())
+
+			Expect(manager.SaveGameState(g)).To(Succeed())
+			restored, err := manager.LoadGameState()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(restored.GetRules().BoardCount()).To(Equal(3))
+			Expect(restored.Boards()).To(Equal(g.Boards()))
+			for ply := range g.GetMoveHistory() {
+				Expect(restored.MoveAt(ply)).To(Equal(g.MoveAt(ply)))
+			}
+
+			// Play goes on where it left off
+			Expect(restored.MakeMoveOn(1, 0, 1)).To(Succeed())
+			Expect(restored.Boards()[1][0][1]).To(Equal(game.PlayerX))
+		})
+	})
+
 	Describe("SaveSettings and LoadSettings", func() {
 		It("should save and load settings", func() {
 			err := manager.SaveSettings(gradient.Red, ai.Hard, 2.0)
@@ -147,6 +197,344 @@ var _ = Describe("Persistence", func() {
 		})
 	})
 
+	Describe("ArchiveGame and LoadArchive", func() {
+		It("should return an empty archive when no file exists", func() {
+			archive, err := manager.LoadArchive()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(archive).To(BeEmpty())
+		})
+
+		It("should append games with their chat", func() {
+			err := manager.ArchiveGame(persistence.ArchivedGame{
+				PlayerX: "alice",
+				PlayerO: "bob",
+				Winner:  "X",
+				Moves:   []persistence.Position{{Row: 1, Col: 1}},
+				Chat:    []persistence.ChatMessage{{From: "bob", Text: "gg"}},
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(manager.ArchiveGame(persistence.ArchivedGame{ID: "second"})).To(Succeed())
+
+			archive, err := manager.LoadArchive()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(archive).To(HaveLen(2))
+			Expect(archive[0].ID).ToNot(BeEmpty())
+			Expect(archive[0].PlayedAt.IsZero()).To(BeFalse())
+			Expect(archive[0].Chat).To(HaveLen(1))
+			Expect(archive[0].Chat[0].Text).To(Equal("gg"))
+			Expect(archive[1].ID).To(Equal("second"))
+		})
+
+		It("should import only games that are not archived yet", func() {
+			Expect(manager.ArchiveGame(persistence.ArchivedGame{ID: "first"})).To(Succeed())
+
+			added, err := manager.ImportArchive([]persistence.ArchivedGame{{ID: "first"}, {ID: "second"}, {ID: "second"}})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(added).To(Equal(1))
+
+			archive, _ := manager.LoadArchive()
+			Expect(archive).To(HaveLen(2))
+		})
+
+		It("should replay legal games and reject inconsistent ones", func() {
+			record := persistence.ArchivedGame{
+				ID:     "g1",
+				Winner: "X",
+				Moves:  []persistence.Position{{Row: 0, Col: 0}, {Row: 1, Col: 0}, {Row: 0, Col: 1}, {Row: 1, Col: 1}, {Row: 0, Col: 2}},
+			}
+			g, err := record.Replay()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(g.GetWinner()).To(Equal(game.PlayerX))
+
+			record.Winner = "O"
+			_, err = record.Replay()
+			Expect(err).To(HaveOccurred())
+
+			record.Moves = append(record.Moves, persistence.Position{Row: 2, Col: 2})
+			_, err = record.Replay()
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("Rule variants", func() {
+		It("should round-trip the rules and chosen marks of a saved game", func() {
+			g := game.New()
+			g.SetRules(game.Rules{Variant: game.Wild})
+			Expect(g.MakeMoveMark(1, 1, game.PlayerO)).To(Succeed())
+
+			restored := persistence.NewGameState(g).Restore()
+			Expect(restored.GetRules().Variant).To(Equal(game.Wild))
+			Expect(restored.Marks).To(Equal([]game.Player{game.PlayerO}))
+			Expect(game.New().GetRules().IsClassic()).To(BeTrue())
+		})
+
+		It("should replay archived games under their variant", func() {
+			record := persistence.ArchivedGame{
+				ID:      "w1",
+				Variant: string(game.Wild),
+				Winner:  "X",
+				Moves:   []persistence.Position{{Row: 0, Col: 0, Mark: "O"}, {Row: 0, Col: 1, Mark: "O"}, {Row: 0, Col: 2, Mark: "O"}},
+			}
+			g, err := record.Replay()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(g.GetWinner()).To(Equal(game.PlayerX))
+
+			record.Variant = ""
+			_, err = record.Replay()
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("should save and replay moved marks", func() {
+			g := game.New()
+			g.SetRules(game.Rules{Variant: game.FreeMoving})
+			for _, p := range []game.Position{{Row: 0, Col: 0}, {Row: 1, Col: 1}, {Row: 0, Col: 2}, {Row: 0, Col: 1}, {Row: 2, Col: 1}, {Row: 1, Col: 0}} {
+				Expect(g.MakeMove(p.Row, p.Col)).To(Succeed())
+			}
+			Expect(g.MakeSlide(game.Position{Row: 2, Col: 1}, game.Position{Row: 2, Col: 2})).To(Succeed())
+
+			state := persistence.NewGameState(g)
+			Expect(state.MoveHistory[6].From).To(Equal(&persistence.Position{Row: 2, Col: 1}))
+			restored := state.Restore()
+			Expect(restored.MoveAt(6)).To(Equal(g.MoveAt(6)))
+			Expect(restored.MustSlide()).To(BeTrue())
+
+			record := persistence.ArchivedGame{ID: "m1", Variant: string(game.FreeMoving), Moves: state.MoveHistory}
+			replayed, err := record.Replay()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(replayed.GetBoard()).To(Equal(g.GetBoard()))
+		})
+
+		It("should keep variant results apart from the classic stats", func() {
+			Expect(manager.UpdateVariantScore(game.Misere, game.PlayerO)).To(Succeed())
+			Expect(manager.UpdateVariantScore(game.Misere, game.Empty)).To(Succeed())
+
+			scores, err := manager.LoadScores()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(*scores.Variants["misere"]).To(Equal(persistence.VariantStats{OWins: 1, Draws: 1, Games: 2}))
+			Expect(scores.PlayerVsPlayer.Games).To(Equal(0))
+			Expect(scores.TotalGames).To(Equal(2))
+		})
+
+		It("should keep gravity results by board", func() {
+			Expect(manager.UpdateGravityScore("7x6x4", game.PlayerX)).To(Succeed())
+			Expect(manager.UpdateGravityScore("5x4x3", game.PlayerO)).To(Succeed())
+
+			scores, err := manager.LoadScores()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(*scores.Gravity["7x6x4"]).To(Equal(persistence.VariantStats{XWins: 1, Games: 1}))
+			Expect(*scores.Gravity["5x4x3"]).To(Equal(persistence.VariantStats{OWins: 1, Games: 1}))
+			Expect(scores.Variants).To(BeEmpty())
+			Expect(scores.TotalGames).To(Equal(2))
+		})
+
+		It("should keep multi-player results by game and winning mark", func() {
+			Expect(manager.UpdateMultiScore("3p-6x6x4", game.PlayerTriangle)).To(Succeed())
+			Expect(manager.UpdateMultiScore("3p-6x6x4", game.Empty)).To(Succeed())
+			Expect(manager.UpdateMultiScore("4p-8x8x4", game.PlayerSquare)).To(Succeed())
+
+			scores, err := manager.LoadScores()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(*scores.Multi["3p-6x6x4"]).To(Equal(persistence.MultiStats{Games: 2, Draws: 1, Wins: map[string]int{"Δ": 1}}))
+			Expect(scores.Multi["4p-8x8x4"].Wins).To(Equal(map[string]int{"□": 1}))
+			Expect(scores.TotalGames).To(Equal(3))
+		})
+
+		It("should leave variant games out of the opening book and puzzles", func() {
+			moves := []persistence.Position{{Row: 0, Col: 0}, {Row: 1, Col: 1}}
+			Expect(manager.ArchiveGame(persistence.ArchivedGame{ID: "n", Mode: int(game.PlayerVsAI), PlayerX: "Player X", PlayerO: "AI (Hard)", Variant: string(game.Notakto), Winner: "X", Moves: moves})).To(Succeed())
+
+			book, err := manager.LoadOpeningBook()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(book.Positions).To(BeEmpty())
+			openings, err := manager.HumanOpenings(0)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(openings).To(BeEmpty())
+		})
+	})
+
+	Describe("Setups", func() {
+		var blocked *game.Setup
+
+		BeforeEach(func() {
+			var err error
+			blocked, err = game.LookupSetup("Blocked center")
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("should round-trip the setup of a saved game", func() {
+			g := game.New()
+			Expect(g.ApplySetup(blocked)).To(Succeed())
+			Expect(g.MakeMove(0, 0)).To(Succeed())
+
+			restored := persistence.NewGameState(g).Restore()
+			Expect(restored.Setup).To(Equal(blocked))
+			Expect(restored.GetBoard()[1][1]).To(Equal(game.Blocked))
+			Expect(restored.IsStandard()).To(BeFalse())
+		})
+
+		It("should replay archived games from their setup", func() {
+			record := persistence.ArchivedGame{
+				ID:     "s1",
+				Setup:  blocked,
+				Winner: "X",
+				Moves:  []persistence.Position{{Row: 0, Col: 0}, {Row: 0, Col: 1}, {Row: 1, Col: 0}, {Row: 0, Col: 2}, {Row: 2, Col: 0}},
+			}
+			g, err := record.Replay()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(g.GetWinner()).To(Equal(game.PlayerX))
+
+			record.Moves = []persistence.Position{{Row: 1, Col: 1}}
+			record.Winner = ""
+			_, err = record.Replay()
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("should keep setup results apart and out of the opening book", func() {
+			Expect(manager.UpdateSetupScore("Blocked center", game.PlayerX)).To(Succeed())
+			moves := []persistence.Position{{Row: 0, Col: 0}, {Row: 0, Col: 1}}
+			Expect(manager.ArchiveGame(persistence.ArchivedGame{ID: "s", Mode: int(game.PlayerVsAI), PlayerX: "Player X", PlayerO: "AI (Hard)", Setup: blocked, Winner: "X", Moves: moves})).To(Succeed())
+
+			scores, err := manager.LoadScores()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(*scores.Setups["Blocked center"]).To(Equal(persistence.VariantStats{XWins: 1, Games: 1}))
+			Expect(scores.PlayerVsPlayer.Games).To(Equal(0))
+			Expect(scores.Variants).To(BeEmpty())
+
+			book, err := manager.LoadOpeningBook()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(book.Positions).To(BeEmpty())
+			openings, err := manager.HumanOpenings(0)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(openings).To(BeEmpty())
+		})
+	})
+
+	Describe("Opening book", func() {
+		corner := []persistence.Position{{Row: 0, Col: 0}, {Row: 1, Col: 1}}
+
+		It("should build the book from the archive", func() {
+			Expect(manager.ArchiveGame(persistence.ArchivedGame{ID: "a", Winner: "X", Moves: corner})).To(Succeed())
+			Expect(manager.ArchiveGame(persistence.ArchivedGame{ID: "b", Winner: "O", Moves: corner})).To(Succeed())
+
+			book, err := manager.LoadOpeningBook()
+			Expect(err).ToNot(HaveOccurred())
+			g := game.New()
+			g.MakeMove(2, 0)
+			entry, ok := book.Entry(g)
+			Expect(ok).To(BeTrue())
+			Expect(entry).To(Equal(ai.BookEntry{Games: 2, XWins: 1, OWins: 1}))
+		})
+
+		It("should add imported games to the book", func() {
+			_, err := manager.ImportArchive([]persistence.ArchivedGame{{ID: "a", Winner: " ", Moves: corner}})
+			Expect(err).ToNot(HaveOccurred())
+
+			book, err := manager.LoadOpeningBook()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(book.Positions).To(HaveLen(2))
+		})
+
+		It("should report the human's results per opening", func() {
+			games := []persistence.ArchivedGame{
+				{Mode: int(game.PlayerVsAI), PlayerX: "Player X", PlayerO: "AI (Hard)", Winner: "X", Moves: corner},
+				{Mode: int(game.PlayerVsAI), PlayerX: "Player X", PlayerO: "AI (Hard)", Winner: " ", Moves: []persistence.Position{{Row: 2, Col: 2}, {Row: 1, Col: 1}}},
+				{Mode: int(game.PlayerVsAI), PlayerX: "AI (Easy)", PlayerO: "Player O", Winner: "X", Moves: []persistence.Position{{Row: 1, Col: 1}, {Row: 0, Col: 0}}},
+				{Mode: int(game.PlayerVsPlayer), Winner: "X", Moves: corner},
+			}
+			for _, record := range games {
+				Expect(manager.ArchiveGame(record)).To(Succeed())
+			}
+
+			openings, err := manager.HumanOpenings(0)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(openings).To(HaveLen(2))
+			Expect(openings[0]).To(Equal(persistence.OpeningStats{Name: "X corner, O center", Side: game.PlayerX, Games: 2, Wins: 1, Draws: 1}))
+			Expect(openings[0].WinRate()).To(Equal(0.5))
+			Expect(openings[1].Side).To(Equal(game.PlayerO))
+			Expect(openings[1].Losses).To(Equal(1))
+		})
+	})
+
+	Describe("Puzzles", func() {
+		It("should track streaks and per-puzzle results", func() {
+			stats, err := manager.LoadPuzzleStats()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(stats.Attempts).To(Equal(0))
+
+			_, err = manager.RecordPuzzleAttempt("a", true)
+			Expect(err).ToNot(HaveOccurred())
+			_, err = manager.RecordPuzzleAttempt("b", true)
+			Expect(err).ToNot(HaveOccurred())
+			_, err = manager.RecordPuzzleAttempt("c", false)
+			Expect(err).ToNot(HaveOccurred())
+
+			stats, err = manager.LoadPuzzleStats()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(stats.Attempts).To(Equal(3))
+			Expect(stats.Solved).To(Equal(2))
+			Expect(stats.Streak).To(Equal(0))
+			Expect(stats.BestStreak).To(Equal(2))
+			Expect(stats.IsSolved("a")).To(BeTrue())
+			Expect(stats.IsSolved("c")).To(BeFalse())
+			Expect(stats.Puzzles["c"].Attempts).To(Equal(1))
+		})
+
+		It("should make puzzles from the human's archived mistakes", func() {
+			// The human plays O, answers a corner with an edge and loses to a fork
+			lost := []persistence.Position{{Row: 0, Col: 0}, {Row: 0, Col: 1}, {Row: 1, Col: 1}, {Row: 2, Col: 2}, {Row: 2, Col: 0}, {Row: 1, Col: 0}, {Row: 0, Col: 2}}
+			Expect(manager.ArchiveGame(persistence.ArchivedGame{ID: "a", Mode: int(game.PlayerVsAI), PlayerX: "AI (Hard)", PlayerO: "Player O", Winner: "X", Moves: lost})).To(Succeed())
+			Expect(manager.ArchiveGame(persistence.ArchivedGame{ID: "b", Mode: int(game.PlayerVsPlayer), Winner: "X", Moves: lost})).To(Succeed())
+
+			puzzles, err := manager.MistakePuzzles()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(puzzles).ToNot(BeEmpty())
+			for _, p := range puzzles {
+				Expect(p.Player).To(Equal(game.PlayerO))
+				Expect(p.Source).To(Equal(puzzle.SourceMistake))
+			}
+		})
+	})
+
+	Describe("Matches", func() {
+		It("should archive finished matches and count them in the stats", func() {
+			bestOf3 := match.New(match.Format{Kind: match.BestOf, N: 3}, "Ann", "Ben")
+			Expect(manager.RecordMatch(persistence.MatchRecord{Match: *bestOf3})).ToNot(Succeed())
+			Expect(bestOf3.Record(1)).To(Succeed())
+			Expect(bestOf3.Record(1)).To(Succeed())
+			Expect(manager.RecordMatch(persistence.MatchRecord{ID: "m1", Match: *bestOf3})).To(Succeed())
+
+			drawn := match.New(match.Format{}, "Ann", "Ben")
+			Expect(drawn.Record(match.Draw)).To(Succeed())
+			Expect(manager.RecordMatch(persistence.MatchRecord{Match: *drawn, Tournament: "t1"})).To(Succeed())
+			Expect(manager.RecordTournament("Ben")).To(Succeed())
+
+			matches, err := manager.LoadMatches()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(matches).To(HaveLen(2))
+			Expect(matches[0].ID).To(Equal("m1"))
+			Expect(matches[0].Match.Summary()).To(Equal("Ann 0 – 2 Ben"))
+			Expect(matches[1].Tournament).To(Equal("t1"))
+
+			scores, err := manager.LoadScores()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(scores.Matches.Played).To(Equal(2))
+			Expect(scores.Matches.Drawn).To(Equal(1))
+			Expect(scores.Matches.Wins).To(Equal(map[string]int{"Ben": 1}))
+			Expect(scores.Matches.Tournaments).To(Equal(1))
+			Expect(scores.Matches.Champions).To(Equal(map[string]int{"Ben": 1}))
+		})
+
+		It("should keep the match of an archived game", func() {
+			Expect(manager.ArchiveGame(persistence.ArchivedGame{
+				Mode:  int(game.PlayerVsPlayer),
+				Match: &persistence.MatchGame{ID: "m1", Game: 2},
+			})).To(Succeed())
+			archive, err := manager.LoadArchive()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(archive[0].Match).To(Equal(&persistence.MatchGame{ID: "m1", Game: 2}))
+		})
+	})
+
 	Describe("ClearAllData", func() {
 		It("should remove all save files", func() {
 			// Create some data first
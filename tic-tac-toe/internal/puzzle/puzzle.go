@@ -0,0 +1,289 @@
+package puzzle
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"sync"
+
+	"tic-tac-toe/internal/ai"
+	"tic-tac-toe/internal/game"
+	"tic-tac-toe/internal/solve"
+)
+
+// Theme is the tactic a puzzle trains
+type Theme string
+
+const (
+	ThemeWin       Theme = "win"        // Complete a line
+	ThemeFork      Theme = "fork"       // Make two threats at once
+	ThemeForcedWin Theme = "forced-win" // Win in N with threats the opponent must answer
+	ThemeBlock     Theme = "block"      // Stop the opponent's two-in-a-row
+	ThemeBlockFork Theme = "block-fork" // Stop a fork before it is made
+)
+
+// Source says where a puzzle came from
+type Source string
+
+const (
+	SourceSolver  Source = "solver"  // Found by searching every position
+	SourceMistake Source = "mistake" // A position from an archived game where the human went wrong
+)
+
+// MinRating and MaxRating bound puzzle difficulty
+const (
+	MinRating = 1
+	MaxRating = 5
+)
+
+// Puzzle is a position where exactly one move wins, or exactly one move
+// saves the draw
+type Puzzle struct {
+	ID       string        `json:"id"` // Canonical position, shared by rotations and reflections
+	Position string        `json:"position"`
+	Player   game.Player   `json:"player"`
+	Solution game.Position `json:"solution"`
+	Outcome  ai.Outcome    `json:"outcome"`  // What the solution achieves: win or draw
+	Distance int           `json:"distance"` // Win: plies until it; draw: plies until the quickest loss avoided
+	Theme    Theme         `json:"theme"`
+	Rating   int           `json:"rating"` // From MinRating to MaxRating
+	Source   Source        `json:"source"`
+}
+
+// Game returns a game set up at the puzzle's position
+func (p Puzzle) Game() (*game.Game, error) {
+	g, err := game.ParsePosition(p.Position)
+	if err != nil {
+		return nil, fmt.Errorf("puzzle %s: %w", p.ID, err)
+	}
+	return g, nil
+}
+
+// Solves reports whether move is the puzzle's solution
+func (p Puzzle) Solves(move game.Position) bool {
+	return move == p.Solution
+}
+
+// Goal describes the task, e.g. "X to move and win in 2"
+func (p Puzzle) Goal() string {
+	if p.Outcome == ai.OutcomeDraw {
+		return fmt.Sprintf("%s to move and save the draw", p.Player)
+	}
+	if moves := (p.Distance + 1) / 2; moves > 1 {
+		return fmt.Sprintf("%s to move and win in %d", p.Player, moves)
+	}
+	return fmt.Sprintf("%s to move and win", p.Player)
+}
+
+// At returns the puzzle in g's position, if it has one. The side to move
+// must have at least three choices and exactly one move that keeps the
+// best outcome, which must be a win or a draw.
+func At(g *game.Game) (Puzzle, bool) {
+	evaluations := ai.Analyze(g)
+	if len(evaluations) < 3 {
+		return Puzzle{}, false
+	}
+	best := evaluations[0]
+	if best.Outcome == ai.OutcomeLoss || evaluations[1].Outcome == best.Outcome {
+		return Puzzle{}, false
+	}
+
+	board := solve.FromGame(g)
+	p := Puzzle{
+		ID:       board.Canonical().String(),
+		Position: board.String(),
+		Player:   g.GetCurrentPlayer(),
+		Solution: best.Move,
+		Outcome:  best.Outcome,
+		Distance: best.Distance,
+		Source:   SourceSolver,
+	}
+	if best.Outcome == ai.OutcomeDraw {
+		// The quickest loss among the other moves is what the solution saves
+		p.Distance = evaluations[len(evaluations)-1].Distance
+		for _, evaluation := range evaluations[1:] {
+			p.Distance = min(p.Distance, evaluation.Distance)
+		}
+	}
+
+	p.Theme = theme(ai.Explain(g, best.Move), p)
+	p.Rating = rating(p, len(evaluations))
+	return p, true
+}
+
+// theme picks the tactic the solution shows
+func theme(explanation ai.Explanation, p Puzzle) Theme {
+	has := func(kind ai.ReasonKind) bool {
+		for _, reason := range explanation.Reasons {
+			if reason.Kind == kind {
+				return true
+			}
+		}
+		return false
+	}
+
+	switch {
+	case p.Outcome == ai.OutcomeDraw && has(ai.ReasonBlock):
+		return ThemeBlock
+	case p.Outcome == ai.OutcomeDraw:
+		return ThemeBlockFork
+	case p.Distance == 1:
+		return ThemeWin
+	case has(ai.ReasonFork):
+		return ThemeFork
+	default:
+		return ThemeForcedWin
+	}
+}
+
+// rating grades a puzzle by its tactic, with one more point for positions
+// with many moves to choose from
+func rating(p Puzzle, choices int) int {
+	r := MinRating
+	switch p.Theme {
+	case ThemeFork:
+		r = 3
+	case ThemeBlockFork:
+		r = 3
+	case ThemeForcedWin:
+		r = 2 + (p.Distance-1)/2
+	}
+	if choices >= 6 {
+		r++
+	}
+	return max(MinRating, min(r, MaxRating))
+}
+
+var (
+	generated     []Puzzle
+	generatedOnce sync.Once
+)
+
+// Generate returns a puzzle for every reachable position that has one, one
+// per set of symmetric positions, easiest first. The list is built on first
+// use and shared.
+func Generate() []Puzzle {
+	generatedOnce.Do(func() {
+		seen := map[string]bool{}
+		var walk func(g *game.Game)
+		walk = func(g *game.Game) {
+			key := solve.FromGame(g).Canonical().String()
+			if seen[key] || g.GetStatus() != game.StatusPlaying {
+				return
+			}
+			seen[key] = true
+
+			if p, ok := At(g); ok {
+				// Show the position the way the tablebase stores it
+				canonical, err := game.ParsePosition(p.ID)
+				if err == nil {
+					if p, ok = At(canonical); ok {
+						generated = append(generated, p)
+					}
+				}
+			}
+			for row := 0; row < 3; row++ {
+				for col := 0; col < 3; col++ {
+					child := copyGame(g)
+					if child.MakeMove(row, col) == nil {
+						walk(child)
+					}
+				}
+			}
+		}
+		walk(game.New())
+
+		sort.Slice(generated, func(i, j int) bool {
+			if generated[i].Rating != generated[j].Rating {
+				return generated[i].Rating < generated[j].Rating
+			}
+			return generated[i].ID < generated[j].ID
+		})
+	})
+	return generated
+}
+
+// FromGame finds the puzzles in a finished game where side had a unique
+// best move and played something else
+func FromGame(moves []game.Position, side game.Player) []Puzzle {
+	var puzzles []Puzzle
+	g := game.New()
+	for _, move := range moves {
+		if g.GetCurrentPlayer() == side {
+			if p, ok := At(g); ok && !p.Solves(move) {
+				p.Source = SourceMistake
+				puzzles = append(puzzles, p)
+			}
+		}
+		if g.MakeMove(move.Row, move.Col) != nil {
+			break
+		}
+	}
+	return puzzles
+}
+
+// TargetRating is the difficulty to serve during a streak: one step harder
+// for every two puzzles solved in a row
+func TargetRating(streak int) int {
+	return min(MinRating+streak/2, MaxRating)
+}
+
+// Choose picks the next puzzle. Unsolved puzzles from the player's own
+// mistakes come first, then unsolved puzzles closest to the streak's target
+// rating; once everything is solved any puzzle near the target may return.
+func Choose(puzzles []Puzzle, streak int, solved func(id string) bool, rng *rand.Rand) (Puzzle, bool) {
+	if len(puzzles) == 0 {
+		return Puzzle{}, false
+	}
+
+	var mistakes, unsolved []Puzzle
+	for _, p := range puzzles {
+		if solved(p.ID) {
+			continue
+		}
+		if p.Source == SourceMistake {
+			mistakes = append(mistakes, p)
+		}
+		unsolved = append(unsolved, p)
+	}
+
+	pool := puzzles
+	switch {
+	case len(mistakes) > 0:
+		return mistakes[rng.Intn(len(mistakes))], true
+	case len(unsolved) > 0:
+		pool = unsolved
+	}
+
+	// Narrow the pool to the ratings nearest the target
+	target := TargetRating(streak)
+	nearest := MaxRating
+	for _, p := range pool {
+		nearest = min(nearest, abs(p.Rating-target))
+	}
+	var candidates []Puzzle
+	for _, p := range pool {
+		if abs(p.Rating-target) == nearest {
+			candidates = append(candidates, p)
+		}
+	}
+	return candidates[rng.Intn(len(candidates))], true
+}
+
+// copyGame copies the board and turn of g onto a fresh game
+func copyGame(g *game.Game) *game.Game {
+	clone := game.New()
+	clone.Board = g.GetBoard()
+	clone.CurrentPlayer = g.GetCurrentPlayer()
+	clone.Status = g.GetStatus()
+	clone.Winner = g.GetWinner()
+	return clone
+}
+
+// abs returns the absolute value of an integer
+func abs(a int) int {
+	if a < 0 {
+		return -a
+	}
+	return a
+}
@@ -0,0 +1,13 @@
+package puzzle_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestPuzzle(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Puzzle Suite")
+}
@@ -0,0 +1,138 @@
+package puzzle_test
+
+import (
+	"math/rand"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"tic-tac-toe/internal/ai"
+	"tic-tac-toe/internal/game"
+	"tic-tac-toe/internal/puzzle"
+)
+
+var _ = Describe("Puzzle", func() {
+	position := func(notation string) *game.Game {
+		g, err := game.ParsePosition(notation)
+		Expect(err).ToNot(HaveOccurred())
+		return g
+	}
+
+	Describe("At", func() {
+		It("should find a fork", func() {
+			p, ok := puzzle.At(position(".XO/O../X.."))
+			Expect(ok).To(BeTrue())
+			Expect(p.Player).To(Equal(game.PlayerX))
+			Expect(p.Outcome).To(Equal(ai.OutcomeWin))
+			Expect(p.Theme).To(Equal(puzzle.ThemeFork))
+			Expect(p.Solves(game.Position{Row: 2, Col: 1})).To(BeTrue())
+			Expect(p.Goal()).To(Equal("X to move and win in 2"))
+		})
+
+		It("should find a block that saves the draw", func() {
+			p, ok := puzzle.At(position("XX./.O./..."))
+			Expect(ok).To(BeTrue())
+			Expect(p.Theme).To(Equal(puzzle.ThemeBlock))
+			Expect(p.Solution).To(Equal(game.Position{Row: 0, Col: 2}))
+			Expect(p.Distance).To(Equal(2))
+			Expect(p.Goal()).To(Equal("O to move and save the draw"))
+		})
+
+		It("should skip positions with several good moves", func() {
+			_, ok := puzzle.At(game.New())
+			Expect(ok).To(BeFalse())
+		})
+
+		It("should skip lost positions", func() {
+			// O cannot stop both of X's threats
+			_, ok := puzzle.At(position("XX./XO./..O"))
+			Expect(ok).To(BeFalse())
+		})
+	})
+
+	Describe("Generate", func() {
+		It("should produce unique, solvable puzzles easiest first", func() {
+			puzzles := puzzle.Generate()
+			Expect(len(puzzles)).To(BeNumerically(">", 100))
+
+			ids := map[string]bool{}
+			themes := map[puzzle.Theme]bool{}
+			for i, p := range puzzles {
+				Expect(ids).ToNot(HaveKey(p.ID))
+				ids[p.ID] = true
+				themes[p.Theme] = true
+				Expect(p.Rating).To(BeNumerically(">=", puzzle.MinRating))
+				Expect(p.Rating).To(BeNumerically("<=", puzzle.MaxRating))
+				if i > 0 {
+					Expect(p.Rating).To(BeNumerically(">=", puzzles[i-1].Rating))
+				}
+
+				g, err := p.Game()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(g.MakeMove(p.Solution.Row, p.Solution.Col)).To(Succeed())
+			}
+			for _, theme := range []puzzle.Theme{puzzle.ThemeWin, puzzle.ThemeFork, puzzle.ThemeForcedWin, puzzle.ThemeBlock, puzzle.ThemeBlockFork} {
+				Expect(themes).To(HaveKey(theme))
+			}
+		})
+	})
+
+	Describe("FromGame", func() {
+		It("should turn the player's mistakes into puzzles", func() {
+			// O answers a corner with an edge and X wins with a fork
+			moves := []game.Position{{Row: 0, Col: 0}, {Row: 0, Col: 1}, {Row: 1, Col: 1}, {Row: 2, Col: 2}, {Row: 2, Col: 0}}
+			Expect(puzzle.FromGame(moves, game.PlayerX)).To(BeEmpty())
+
+			puzzles := puzzle.FromGame(moves, game.PlayerO)
+			Expect(puzzles).ToNot(BeEmpty())
+			for _, p := range puzzles {
+				Expect(p.Source).To(Equal(puzzle.SourceMistake))
+				Expect(p.Player).To(Equal(game.PlayerO))
+			}
+		})
+	})
+
+	Describe("Choose", func() {
+		var (
+			puzzles []puzzle.Puzzle
+			rng     *rand.Rand
+			none    = func(string) bool { return false }
+		)
+
+		BeforeEach(func() {
+			puzzles = puzzle.Generate()
+			rng = rand.New(rand.NewSource(1))
+		})
+
+		It("should raise the difficulty with the streak", func() {
+			Expect(puzzle.TargetRating(0)).To(Equal(puzzle.MinRating))
+			Expect(puzzle.TargetRating(100)).To(Equal(puzzle.MaxRating))
+
+			easy, ok := puzzle.Choose(puzzles, 0, none, rng)
+			Expect(ok).To(BeTrue())
+			Expect(easy.Rating).To(Equal(1))
+
+			hard, _ := puzzle.Choose(puzzles, 8, none, rng)
+			Expect(hard.Rating).To(Equal(5))
+		})
+
+		It("should serve the player's own mistakes first", func() {
+			mistake := puzzles[len(puzzles)-1]
+			mistake.Source = puzzle.SourceMistake
+			p, _ := puzzle.Choose(append([]puzzle.Puzzle{mistake}, puzzles...), 0, none, rng)
+			Expect(p).To(Equal(mistake))
+		})
+
+		It("should skip solved puzzles while others remain", func() {
+			first := puzzles[0]
+			solved := func(id string) bool { return id != first.ID }
+			p, _ := puzzle.Choose(puzzles, 8, solved, rng)
+			Expect(p.ID).To(Equal(first.ID))
+		})
+
+		It("should report an empty list", func() {
+			_, ok := puzzle.Choose(nil, 0, none, rng)
+			Expect(ok).To(BeFalse())
+		})
+	})
+})
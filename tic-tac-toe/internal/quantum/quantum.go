@@ -0,0 +1,311 @@
+package quantum
+
+import (
+	"fmt"
+
+	"tic-tac-toe/internal/game"
+)
+
+// Mark is a move of quantum tic-tac-toe: a spooky mark in two cells at once
+// until a measurement collapses it into one of them
+type Mark struct {
+	Player game.Player      `json:"player"`
+	Turn   int              `json:"turn"` // The subscript: the move number, from 1
+	Cells  [2]game.Position `json:"cells"`
+}
+
+// String writes the mark with its subscript, e.g. "X3"
+func (mk Mark) String() string {
+	return fmt.Sprintf("%s%d", mk.Player, mk.Turn)
+}
+
+// other returns the cell of the pair that is not cell
+func (mk Mark) other(cell game.Position) game.Position {
+	if mk.Cells[0] == cell {
+		return mk.Cells[1]
+	}
+	return mk.Cells[0]
+}
+
+// holds reports whether the mark is in cell
+func (mk Mark) holds(cell game.Position) bool {
+	return mk.Cells[0] == cell || mk.Cells[1] == cell
+}
+
+// Game is a game of Allan Goff's quantum tic-tac-toe. Spooky marks entangle
+// the cells they share; when the entanglements close a cycle, the player who
+// did not close it chooses where the last mark collapses, and every mark
+// entangled with it collapses in turn.
+type Game struct {
+	Marks         []Mark                  `json:"marks"`
+	Collapsed     map[int]int             `json:"collapsed"` // Marks turned classical, by index, with the cell index they landed in
+	CurrentPlayer game.Player             `json:"current_player"`
+	Status        game.GameStatus         `json:"status"`
+	Winner        game.Player             `json:"winner"`
+	Points        map[game.Player]float64 `json:"points,omitempty"` // Once a line is made: 1 for the first line, ½ for a simultaneous later one
+	Pending       int                     `json:"pending"`          // Index of the mark that closed a cycle and awaits measurement; -1 when none
+}
+
+// New creates an empty board with X to move
+func New() *Game {
+	g := &Game{}
+	g.Reset()
+	return g
+}
+
+// Reset empties the board for a new game
+func (g *Game) Reset() {
+	g.Marks = nil
+	g.Collapsed = map[int]int{}
+	g.CurrentPlayer = game.PlayerX
+	g.Status = game.StatusPlaying
+	g.Winner = game.Empty
+	g.Points = nil
+	g.Pending = -1
+}
+
+// Clone returns an independent copy of the game
+func (g *Game) Clone() *Game {
+	clone := *g
+	clone.Marks = append([]Mark(nil), g.Marks...)
+	clone.Collapsed = make(map[int]int, len(g.Collapsed))
+	for mark, cell := range g.Collapsed {
+		clone.Collapsed[mark] = cell
+	}
+	if g.Points != nil {
+		clone.Points = map[game.Player]float64{}
+		for player, points := range g.Points {
+			clone.Points[player] = points
+		}
+	}
+	return &clone
+}
+
+// Classical returns the mark that collapsed into cell, if any
+func (g *Game) Classical(cell game.Position) (Mark, bool) {
+	for mark, at := range g.Collapsed {
+		if at == index(cell) {
+			return g.Marks[mark], true
+		}
+	}
+	return Mark{}, false
+}
+
+// Spooky lists the marks still in superposition in cell, oldest first
+func (g *Game) Spooky(cell game.Position) []Mark {
+	var marks []Mark
+	for i, mark := range g.Marks {
+		if _, done := g.Collapsed[i]; !done && mark.holds(cell) {
+			marks = append(marks, mark)
+		}
+	}
+	return marks
+}
+
+// Measurement returns the mark whose cycle must be collapsed before the
+// next move; the current player chooses its cell
+func (g *Game) Measurement() (Mark, bool) {
+	if g.Pending < 0 {
+		return Mark{}, false
+	}
+	return g.Marks[g.Pending], true
+}
+
+// FreeCells lists the cells without a classical mark, in board order
+func (g *Game) FreeCells() []game.Position {
+	var cells []game.Position
+	for i := 0; i < 9; i++ {
+		if !g.isClassical(i) {
+			cells = append(cells, position(i))
+		}
+	}
+	return cells
+}
+
+// Place puts a spooky mark of the current player in cells a and b. When
+// only one cell is left, the last move is classical and a and b are that
+// cell. A mark that closes a cycle leaves a measurement to the opponent.
+func (g *Game) Place(a, b game.Position) error {
+	if g.Status != game.StatusPlaying {
+		return fmt.Errorf("game is not in playing state")
+	}
+	if g.Pending >= 0 {
+		return fmt.Errorf("the cycle through %s must be measured first", g.Marks[g.Pending])
+	}
+	for _, cell := range []game.Position{a, b} {
+		if cell.Row < 0 || cell.Row > 2 || cell.Col < 0 || cell.Col > 2 {
+			return fmt.Errorf("invalid position: (%d, %d)", cell.Row, cell.Col)
+		}
+		if g.isClassical(index(cell)) {
+			return fmt.Errorf("position (%d, %d) holds a classical mark", cell.Row, cell.Col)
+		}
+	}
+
+	free := g.FreeCells()
+	if a == b && len(free) > 1 {
+		return fmt.Errorf("a spooky mark needs two different cells")
+	}
+
+	mark := Mark{Player: g.CurrentPlayer, Turn: len(g.Marks) + 1, Cells: [2]game.Position{a, b}}
+	cycle := a != b && g.connected(a, b)
+	g.Marks = append(g.Marks, mark)
+	g.CurrentPlayer = other(g.CurrentPlayer)
+
+	switch {
+	case a == b:
+		// The last free cell is filled classically
+		g.collapse(len(g.Marks)-1, a)
+		g.checkStatus()
+	case cycle:
+		g.Pending = len(g.Marks) - 1
+	}
+	return nil
+}
+
+// Collapse measures the pending cycle by putting its last mark in cell,
+// which must be one of the mark's two cells. Every mark entangled with it
+// collapses as a result, and the game ends if that completes a line.
+func (g *Game) Collapse(cell game.Position) error {
+	mark, ok := g.Measurement()
+	if !ok {
+		return fmt.Errorf("no measurement is pending")
+	}
+	if !mark.holds(cell) {
+		return fmt.Errorf("mark %s cannot collapse into (%d, %d)", mark, cell.Row, cell.Col)
+	}
+
+	g.collapse(g.Pending, cell)
+	g.Pending = -1
+	g.checkStatus()
+	return nil
+}
+
+// collapse makes a mark classical in cell, then collapses each other spooky
+// mark in that cell into its other cell
+func (g *Game) collapse(mark int, cell game.Position) {
+	g.Collapsed[mark] = index(cell)
+	for i, other := range g.Marks {
+		if _, done := g.Collapsed[i]; done || !other.holds(cell) {
+			continue
+		}
+		g.collapse(i, other.other(cell))
+	}
+}
+
+// connected reports whether the spooky marks link cells a and b, so that
+// a mark between them would close a cycle
+func (g *Game) connected(a, b game.Position) bool {
+	var parent [9]int
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		for parent[i] != i {
+			parent[i] = parent[parent[i]]
+			i = parent[i]
+		}
+		return i
+	}
+	for i, mark := range g.Marks {
+		if _, done := g.Collapsed[i]; done {
+			continue
+		}
+		parent[find(index(mark.Cells[0]))] = find(index(mark.Cells[1]))
+	}
+	return find(index(a)) == find(index(b))
+}
+
+// checkStatus scores the lines of classical marks. A player with a line
+// scores 1; when both players complete lines in the same measurement, the
+// line whose newest mark has the lower subscript scores 1 and the other ½.
+// A full board without a line is a draw.
+func (g *Game) checkStatus() {
+	first := map[game.Player]int{} // Lowest newest subscript of each player's lines
+	for _, line := range g.Lines() {
+		player, newest := line[0].Player, 0
+		for _, mark := range line {
+			newest = max(newest, mark.Turn)
+		}
+		if best, ok := first[player]; !ok || newest < best {
+			first[player] = newest
+		}
+	}
+
+	x, xOK := first[game.PlayerX]
+	o, oOK := first[game.PlayerO]
+	switch {
+	case xOK && oOK && x < o:
+		g.win(game.PlayerX, 0.5)
+	case xOK && oOK:
+		g.win(game.PlayerO, 0.5)
+	case xOK:
+		g.win(game.PlayerX, 0)
+	case oOK:
+		g.win(game.PlayerO, 0)
+	case len(g.FreeCells()) == 0:
+		g.Status = game.StatusDraw
+	}
+}
+
+// win ends the game with a point for winner and loser's share for the other player
+func (g *Game) win(winner game.Player, loser float64) {
+	g.Status = game.StatusWon
+	g.Winner = winner
+	g.Points = map[game.Player]float64{winner: 1}
+	if loser > 0 {
+		g.Points[other(winner)] = loser
+	}
+}
+
+// lines are the eight rows, columns and diagonals of the board
+var lines = [8][3]int{
+	{0, 1, 2}, {3, 4, 5}, {6, 7, 8},
+	{0, 3, 6}, {1, 4, 7}, {2, 5, 8},
+	{0, 4, 8}, {2, 4, 6},
+}
+
+// Lines returns every line of three classical marks of one player
+func (g *Game) Lines() [][3]Mark {
+	var cells [9]*Mark
+	for mark, cell := range g.Collapsed {
+		cells[cell] = &g.Marks[mark]
+	}
+
+	var found [][3]Mark
+	for _, line := range lines {
+		a, b, c := cells[line[0]], cells[line[1]], cells[line[2]]
+		if a != nil && b != nil && c != nil && a.Player == b.Player && b.Player == c.Player {
+			found = append(found, [3]Mark{*a, *b, *c})
+		}
+	}
+	return found
+}
+
+// isClassical reports whether a mark has collapsed into the cell with index i
+func (g *Game) isClassical(i int) bool {
+	for _, cell := range g.Collapsed {
+		if cell == i {
+			return true
+		}
+	}
+	return false
+}
+
+// index numbers the cells 0 to 8 in board order
+func index(cell game.Position) int {
+	return cell.Row*3 + cell.Col
+}
+
+// position returns the cell numbered i by index
+func position(i int) game.Position {
+	return game.Position{Row: i / 3, Col: i % 3}
+}
+
+// other returns the opponent of player
+func other(player game.Player) game.Player {
+	if player == game.PlayerX {
+		return game.PlayerO
+	}
+	return game.PlayerX
+}
@@ -0,0 +1,93 @@
+package quantum_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"tic-tac-toe/internal/game"
+	"tic-tac-toe/internal/quantum"
+)
+
+var _ = Describe("Quantum", func() {
+	// cell returns the position numbered i in board order
+	cell := func(i int) game.Position {
+		return game.Position{Row: i / 3, Col: i % 3}
+	}
+
+	// play places spooky marks in turn, X first, each in a pair of cells
+	play := func(pairs ...[2]int) *quantum.Game {
+		g := quantum.New()
+		for _, pair := range pairs {
+			Expect(g.Place(cell(pair[0]), cell(pair[1]))).To(Succeed())
+		}
+		return g
+	}
+
+	It("should put each spooky mark in two different free cells", func() {
+		g := play([2]int{0, 4})
+		Expect(g.Spooky(cell(0))).To(HaveLen(1))
+		Expect(g.Spooky(cell(4))[0].String()).To(Equal("X1"))
+		Expect(g.CurrentPlayer).To(Equal(game.PlayerO))
+
+		Expect(g.Place(cell(2), cell(2))).To(MatchError(ContainSubstring("two different cells")))
+		Expect(g.Place(cell(2), game.Position{Row: 3, Col: 0})).ToNot(Succeed())
+		_, pending := g.Measurement()
+		Expect(pending).To(BeFalse())
+	})
+
+	It("should let the opponent measure a cycle and collapse every entangled mark", func() {
+		g := play([2]int{0, 1}, [2]int{1, 2}, [2]int{2, 0})
+		mark, pending := g.Measurement()
+		Expect(pending).To(BeTrue())
+		Expect(mark.String()).To(Equal("X3"))
+		Expect(g.CurrentPlayer).To(Equal(game.PlayerO))
+
+		Expect(g.Place(cell(4), cell(5))).To(MatchError(ContainSubstring("must be measured first")))
+		Expect(g.Collapse(cell(1))).To(MatchError(ContainSubstring("cannot collapse into (0, 1)")))
+
+		Expect(g.Collapse(cell(0))).To(Succeed())
+		for i, want := range []string{"X3", "X1", "O2"} {
+			classical, ok := g.Classical(cell(i))
+			Expect(ok).To(BeTrue())
+			Expect(classical.String()).To(Equal(want))
+			Expect(g.Spooky(cell(i))).To(BeEmpty())
+		}
+		Expect(g.FreeCells()).To(HaveLen(6))
+		Expect(g.Place(cell(0), cell(4))).To(MatchError(ContainSubstring("holds a classical mark")))
+	})
+
+	It("should score simultaneous lines by their highest subscript", func() {
+		g := play([2]int{0, 3}, [2]int{3, 1}, [2]int{1, 4}, [2]int{4, 2}, [2]int{2, 5}, [2]int{5, 0})
+		Expect(g.CurrentPlayer).To(Equal(game.PlayerX))
+		Expect(g.Collapse(cell(5))).To(Succeed())
+
+		Expect(g.Lines()).To(HaveLen(2))
+		Expect(g.Status).To(Equal(game.StatusWon))
+		Expect(g.Winner).To(Equal(game.PlayerX))
+		Expect(g.Points).To(Equal(map[game.Player]float64{game.PlayerX: 1, game.PlayerO: 0.5}))
+	})
+
+	It("should fill the last free cell classically", func() {
+		// Each pair of cells takes an X and an O mark, which close a cycle
+		// that X measures by putting the O mark in the second cell
+		g := quantum.New()
+		for _, pair := range [][2]int{{0, 1}, {2, 4}, {3, 5}, {7, 6}} {
+			Expect(g.Place(cell(pair[0]), cell(pair[1]))).To(Succeed())
+			Expect(g.Place(cell(pair[1]), cell(pair[0]))).To(Succeed())
+			Expect(g.Collapse(cell(pair[1]))).To(Succeed())
+			Expect(g.Status).To(Equal(game.StatusPlaying))
+		}
+		Expect(g.FreeCells()).To(Equal([]game.Position{cell(8)}))
+
+		Expect(g.Place(cell(8), cell(8))).To(Succeed())
+		classical, ok := g.Classical(cell(8))
+		Expect(ok).To(BeTrue())
+		Expect(classical.String()).To(Equal("X9"))
+		Expect(g.Status).To(Equal(game.StatusDraw))
+		Expect(g.Points).To(BeNil())
+
+		clone := g.Clone()
+		clone.Collapsed[0] = 4
+		Expect(g.Collapsed[0]).ToNot(Equal(4))
+	})
+})
@@ -0,0 +1,211 @@
+package qubic
+
+import (
+	"fmt"
+
+	"tic-tac-toe/internal/game"
+)
+
+// Size is the length of each side of the cube
+const Size = 4
+
+// Point is a cell of the cube
+type Point struct {
+	Layer int
+	Row   int
+	Col   int
+}
+
+// String writes the point as (layer,row,col)
+func (p Point) String() string {
+	return fmt.Sprintf("(%d,%d,%d)", p.Layer, p.Row, p.Col)
+}
+
+// Line is four cells in a row
+type Line [Size]Point
+
+// Lines are the 76 winning lines of the cube: 48 along rows, columns and
+// pillars, 24 diagonals within planes and 4 space diagonals through the center
+var Lines = buildLines()
+
+// linesThrough lists, for each cell, the indexes of the lines through it
+var linesThrough = buildLinesThrough()
+
+// buildLines walks every direction from every cell that starts a full line
+func buildLines() []Line {
+	var lines []Line
+	for dl := -1; dl <= 1; dl++ {
+		for dr := -1; dr <= 1; dr++ {
+			for dc := -1; dc <= 1; dc++ {
+				// Count each line once, from its lowest end
+				if dl < 0 || (dl == 0 && dr < 0) || (dl == 0 && dr == 0 && dc <= 0) {
+					continue
+				}
+				for l := 0; l < Size; l++ {
+					for r := 0; r < Size; r++ {
+						for c := 0; c < Size; c++ {
+							start := Point{l, r, c}
+							end := Point{l + 3*dl, r + 3*dr, c + 3*dc}
+							before := Point{l - dl, r - dr, c - dc}
+							if !inside(end) || inside(before) {
+								continue
+							}
+							var line Line
+							for i := range line {
+								line[i] = Point{start.Layer + i*dl, start.Row + i*dr, start.Col + i*dc}
+							}
+							lines = append(lines, line)
+						}
+					}
+				}
+			}
+		}
+	}
+	return lines
+}
+
+// buildLinesThrough indexes Lines by cell
+func buildLinesThrough() [Size][Size][Size][]int {
+	var through [Size][Size][Size][]int
+	for i, line := range Lines {
+		for _, p := range line {
+			through[p.Layer][p.Row][p.Col] = append(through[p.Layer][p.Row][p.Col], i)
+		}
+	}
+	return through
+}
+
+// inside reports whether p is on the cube
+func inside(p Point) bool {
+	return p.Layer >= 0 && p.Layer < Size && p.Row >= 0 && p.Row < Size && p.Col >= 0 && p.Col < Size
+}
+
+// Game is a game of Qubic: four in a row anywhere in the cube wins
+type Game struct {
+	Board         [Size][Size][Size]game.Player `json:"board"`
+	CurrentPlayer game.Player                   `json:"current_player"`
+	Status        game.GameStatus               `json:"status"`
+	Winner        game.Player                   `json:"winner"`
+	MoveHistory   []Point                       `json:"move_history"`
+}
+
+// New creates an empty cube with X to move
+func New() *Game {
+	g := &Game{}
+	g.Reset()
+	return g
+}
+
+// Reset empties the cube for a new game
+func (g *Game) Reset() {
+	for l := 0; l < Size; l++ {
+		for r := 0; r < Size; r++ {
+			for c := 0; c < Size; c++ {
+				g.Board[l][r][c] = game.Empty
+			}
+		}
+	}
+	g.CurrentPlayer = game.PlayerX
+	g.Status = game.StatusPlaying
+	g.Winner = game.Empty
+	g.MoveHistory = make([]Point, 0)
+}
+
+// Clone returns an independent copy of the game
+func (g *Game) Clone() *Game {
+	clone := *g
+	clone.MoveHistory = append([]Point(nil), g.MoveHistory...)
+	return &clone
+}
+
+// At returns the mark in cell p
+func (g *Game) At(p Point) game.Player {
+	return g.Board[p.Layer][p.Row][p.Col]
+}
+
+// MakeMove places the current player's mark at p
+func (g *Game) MakeMove(p Point) error {
+	if g.Status != game.StatusPlaying {
+		return fmt.Errorf("game is not in playing state")
+	}
+	if !inside(p) {
+		return fmt.Errorf("invalid position: %s", p)
+	}
+	if g.At(p) != game.Empty {
+		return fmt.Errorf("position %s is already occupied", p)
+	}
+
+	g.Board[p.Layer][p.Row][p.Col] = g.CurrentPlayer
+	g.MoveHistory = append(g.MoveHistory, p)
+
+	if _, ok := g.lineThrough(p, g.CurrentPlayer); ok {
+		g.Status = game.StatusWon
+		g.Winner = g.CurrentPlayer
+		return nil
+	}
+	if len(g.MoveHistory) == Size*Size*Size {
+		g.Status = game.StatusDraw
+		return nil
+	}
+	g.CurrentPlayer = other(g.CurrentPlayer)
+	return nil
+}
+
+// WinningLine returns the line that won the game, if any
+func (g *Game) WinningLine() (Line, bool) {
+	if g.Status != game.StatusWon || len(g.MoveHistory) == 0 {
+		return Line{}, false
+	}
+	index, ok := g.lineThrough(g.MoveHistory[len(g.MoveHistory)-1], g.Winner)
+	if !ok {
+		return Line{}, false
+	}
+	return Lines[index], true
+}
+
+// lineThrough finds a line through p held entirely by player
+func (g *Game) lineThrough(p Point, player game.Player) (int, bool) {
+	for _, index := range linesThrough[p.Layer][p.Row][p.Col] {
+		if g.count(Lines[index], player) == Size {
+			return index, true
+		}
+	}
+	return 0, false
+}
+
+// count returns how many cells of line player holds
+func (g *Game) count(line Line, player game.Player) int {
+	n := 0
+	for _, p := range line {
+		if g.At(p) == player {
+			n++
+		}
+	}
+	return n
+}
+
+// AvailableMoves lists the empty cells, layer by layer
+func (g *Game) AvailableMoves() []Point {
+	var moves []Point
+	if g.Status != game.StatusPlaying {
+		return moves
+	}
+	for l := 0; l < Size; l++ {
+		for r := 0; r < Size; r++ {
+			for c := 0; c < Size; c++ {
+				if g.Board[l][r][c] == game.Empty {
+					moves = append(moves, Point{l, r, c})
+				}
+			}
+		}
+	}
+	return moves
+}
+
+// other returns the opponent of player
+func other(player game.Player) game.Player {
+	if player == game.PlayerX {
+		return game.PlayerO
+	}
+	return game.PlayerX
+}
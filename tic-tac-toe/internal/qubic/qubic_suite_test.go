@@ -0,0 +1,13 @@
+package qubic_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestQubic(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Qubic Suite")
+}
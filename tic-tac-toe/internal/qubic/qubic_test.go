@@ -0,0 +1,149 @@
+package qubic_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"tic-tac-toe/internal/game"
+	"tic-tac-toe/internal/qubic"
+)
+
+var _ = Describe("Qubic", func() {
+	// play makes the moves in turn, X first
+	play := func(moves ...qubic.Point) *qubic.Game {
+		g := qubic.New()
+		for _, p := range moves {
+			Expect(g.MakeMove(p)).To(Succeed())
+		}
+		return g
+	}
+
+	Describe("Lines", func() {
+		It("should have all 76 winning lines", func() {
+			Expect(qubic.Lines).To(HaveLen(76))
+
+			spaceDiagonals := 0
+			for _, line := range qubic.Lines {
+				first, last := line[0], line[3]
+				if first.Layer != last.Layer && first.Row != last.Row && first.Col != last.Col {
+					spaceDiagonals++
+				}
+			}
+			Expect(spaceDiagonals).To(Equal(4))
+		})
+
+		It("should put seven lines through corners and inner cells and four through the rest", func() {
+			through := map[qubic.Point]int{}
+			for _, line := range qubic.Lines {
+				for _, p := range line {
+					through[p]++
+				}
+			}
+			Expect(through[qubic.Point{Layer: 0, Row: 0, Col: 0}]).To(Equal(7))
+			Expect(through[qubic.Point{Layer: 1, Row: 1, Col: 2}]).To(Equal(7))
+			Expect(through[qubic.Point{Layer: 0, Row: 0, Col: 1}]).To(Equal(4))
+		})
+	})
+
+	Describe("MakeMove", func() {
+		It("should alternate players and reject bad moves", func() {
+			g := play(qubic.Point{Layer: 1, Row: 2, Col: 3})
+			Expect(g.At(qubic.Point{Layer: 1, Row: 2, Col: 3})).To(Equal(game.PlayerX))
+			Expect(g.CurrentPlayer).To(Equal(game.PlayerO))
+			Expect(g.AvailableMoves()).To(HaveLen(63))
+
+			Expect(g.MakeMove(qubic.Point{Layer: 1, Row: 2, Col: 3})).To(MatchError(ContainSubstring("already occupied")))
+			Expect(g.MakeMove(qubic.Point{Layer: 4, Row: 0, Col: 0})).To(MatchError(ContainSubstring("invalid position")))
+		})
+
+		It("should win along a space diagonal", func() {
+			g := play(
+				qubic.Point{Layer: 0, Row: 0, Col: 0}, qubic.Point{Layer: 0, Row: 0, Col: 1},
+				qubic.Point{Layer: 1, Row: 1, Col: 1}, qubic.Point{Layer: 0, Row: 0, Col: 2},
+				qubic.Point{Layer: 2, Row: 2, Col: 2}, qubic.Point{Layer: 0, Row: 1, Col: 0},
+				qubic.Point{Layer: 3, Row: 3, Col: 3},
+			)
+			Expect(g.Status).To(Equal(game.StatusWon))
+			Expect(g.Winner).To(Equal(game.PlayerX))
+			line, ok := g.WinningLine()
+			Expect(ok).To(BeTrue())
+			Expect(line[0]).To(Equal(qubic.Point{Layer: 0, Row: 0, Col: 0}))
+			Expect(line[3]).To(Equal(qubic.Point{Layer: 3, Row: 3, Col: 3}))
+			Expect(g.MakeMove(qubic.Point{Layer: 1, Row: 0, Col: 0})).To(HaveOccurred())
+		})
+
+		It("should keep clones independent", func() {
+			g := play(qubic.Point{Layer: 0, Row: 0, Col: 0})
+			clone := g.Clone()
+			Expect(clone.MakeMove(qubic.Point{Layer: 3, Row: 3, Col: 3})).To(Succeed())
+			Expect(g.MoveHistory).To(HaveLen(1))
+			Expect(g.At(qubic.Point{Layer: 3, Row: 3, Col: 3})).To(Equal(game.Empty))
+		})
+	})
+
+	Describe("AI", func() {
+		var player *qubic.AI
+
+		BeforeEach(func() {
+			player = qubic.NewAI(game.PlayerX, 1)
+			player.SetSeed(1)
+		})
+
+		It("should look further ahead when stronger", func() {
+			Expect(qubic.NewAI(game.PlayerO, 0).GetThreatDepth()).To(BeNumerically("<", player.GetThreatDepth()))
+		})
+
+		It("should complete four in a row", func() {
+			g := play(
+				qubic.Point{Layer: 2, Row: 0, Col: 0}, qubic.Point{Layer: 0, Row: 3, Col: 3},
+				qubic.Point{Layer: 2, Row: 1, Col: 1}, qubic.Point{Layer: 1, Row: 3, Col: 3},
+				qubic.Point{Layer: 2, Row: 2, Col: 2}, qubic.Point{Layer: 3, Row: 0, Col: 3},
+			)
+			Expect(player.Move(context.Background(), g)).To(Equal(qubic.Point{Layer: 2, Row: 3, Col: 3}))
+		})
+
+		It("should block the opponent's four", func() {
+			g := play(
+				qubic.Point{Layer: 0, Row: 0, Col: 0}, qubic.Point{Layer: 3, Row: 0, Col: 1},
+				qubic.Point{Layer: 1, Row: 2, Col: 0}, qubic.Point{Layer: 3, Row: 1, Col: 1},
+				qubic.Point{Layer: 2, Row: 0, Col: 3}, qubic.Point{Layer: 3, Row: 2, Col: 1},
+			)
+			Expect(player.Move(context.Background(), g)).To(Equal(qubic.Point{Layer: 3, Row: 3, Col: 1}))
+		})
+
+		It("should make two threats at once", func() {
+			g := play(
+				qubic.Point{Layer: 0, Row: 0, Col: 1}, qubic.Point{Layer: 3, Row: 3, Col: 3},
+				qubic.Point{Layer: 0, Row: 0, Col: 2}, qubic.Point{Layer: 3, Row: 0, Col: 0},
+				qubic.Point{Layer: 0, Row: 1, Col: 0}, qubic.Point{Layer: 2, Row: 1, Col: 3},
+				qubic.Point{Layer: 0, Row: 2, Col: 0}, qubic.Point{Layer: 1, Row: 3, Col: 1},
+			)
+			Expect(player.Move(context.Background(), g)).To(Equal(qubic.Point{Layer: 0, Row: 0, Col: 0}))
+		})
+
+		It("should stop when the context is cancelled", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			_, err := player.Move(ctx, qubic.New())
+			Expect(err).To(MatchError(context.Canceled))
+		})
+
+		It("should play whole games", func() {
+			opponent := qubic.NewAI(game.PlayerO, 0.5)
+			opponent.SetSeed(2)
+			g := qubic.New()
+			for g.Status == game.StatusPlaying {
+				mover := player
+				if g.CurrentPlayer == game.PlayerO {
+					mover = opponent
+				}
+				p, err := mover.Move(context.Background(), g)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(g.MakeMove(p)).To(Succeed())
+			}
+			Expect(g.Status).To(Or(Equal(game.StatusWon), Equal(game.StatusDraw)))
+		})
+	})
+})
@@ -0,0 +1,252 @@
+package qubic
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+
+	"tic-tac-toe/internal/ai"
+	"tic-tac-toe/internal/game"
+)
+
+// errNoMoves is returned when the cube is full or the game is over
+var errNoMoves = errors.New("no moves available")
+
+// defenseCandidates caps how many of the best-looking moves are checked
+// against the opponent's forcing lines
+const defenseCandidates = 12
+
+// AI plays Qubic. Plain minimax cannot see far in a cube with 64 cells, so
+// the AI searches threats instead: it looks for a sequence of three-in-a-rows
+// the opponent must answer that ends in two threats at once, makes sure the
+// opponent has no such sequence after its move, and otherwise takes the cell
+// on the most open lines.
+type AI struct {
+	player       game.Player
+	params       ai.Params
+	threatDepth  int // Forcing moves to look ahead for a win
+	randomSource *rand.Rand
+}
+
+// NewAI creates a Qubic AI for player at a strength from 0 to 1; stronger
+// AIs look further ahead and blunder less, as in the mistake model of the
+// 3×3 AI
+func NewAI(player game.Player, strength float64) *AI {
+	params := ai.ParamsForStrength(strength)
+	return &AI{
+		player:       player,
+		params:       params,
+		threatDepth:  1 + int(math.Round(params.Strength*7)),
+		randomSource: rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// SetSeed makes the AI's random choices reproducible
+func (a *AI) SetSeed(seed int64) {
+	a.randomSource = rand.New(rand.NewSource(seed))
+}
+
+// GetPlayer returns the side the AI plays
+func (a *AI) GetPlayer() game.Player {
+	return a.player
+}
+
+// GetThreatDepth returns how many forcing moves the AI looks ahead
+func (a *AI) GetThreatDepth() int {
+	return a.threatDepth
+}
+
+// Move chooses the AI's move for the player to move in g, giving up with the
+// context's error if it is cancelled first
+func (a *AI) Move(ctx context.Context, g *Game) (Point, error) {
+	moves := g.AvailableMoves()
+	if len(moves) == 0 {
+		return Point{}, errNoMoves
+	}
+	if err := ctx.Err(); err != nil {
+		return Point{}, err
+	}
+
+	if a.randomSource.Float64() < a.params.Blunder {
+		return moves[a.randomSource.Intn(len(moves))], nil
+	}
+
+	player := g.CurrentPlayer
+	opponent := other(player)
+	if wins := g.completing(player); len(wins) > 0 {
+		return wins[0], nil
+	}
+	if losses := g.completing(opponent); len(losses) > 0 {
+		return losses[0], nil
+	}
+
+	s := &threatSearch{ctx: ctx, failed: map[boardKey]int{}}
+	if p, ok := s.forcedWin(g, a.threatDepth); ok {
+		return p, nil
+	}
+
+	// Try the most promising moves until one leaves the opponent no forcing win
+	candidates := a.rank(g, moves)
+	defender := &threatSearch{ctx: ctx, failed: map[boardKey]int{}}
+	for _, p := range candidates[:min(defenseCandidates, len(candidates))] {
+		child := g.Clone()
+		child.MakeMove(p)
+		if _, lost := defender.forcedWin(child, a.threatDepth); !lost {
+			return p, nil
+		}
+		if err := ctx.Err(); err != nil {
+			break
+		}
+	}
+	return candidates[0], nil
+}
+
+// rank orders moves by heuristic value, best first; ties are shuffled so
+// the AI does not always open in the same corner
+func (a *AI) rank(g *Game, moves []Point) []Point {
+	ranked := append([]Point(nil), moves...)
+	a.randomSource.Shuffle(len(ranked), func(i, j int) { ranked[i], ranked[j] = ranked[j], ranked[i] })
+	scores := make(map[Point]int, len(ranked))
+	for _, p := range ranked {
+		scores[p] = g.Evaluate(p, g.CurrentPlayer)
+	}
+	sort.SliceStable(ranked, func(i, j int) bool { return scores[ranked[i]] > scores[ranked[j]] })
+	return ranked
+}
+
+// Evaluate scores an empty cell for player: lines through it that only
+// player holds are worth more the fuller they are, and lines only the
+// opponent holds are worth blocking
+func (g *Game) Evaluate(p Point, player game.Player) int {
+	attack := [Size]int{1, 4, 32, 1000}
+	defend := [Size]int{1, 3, 24, 500}
+	score := 0
+	for _, index := range linesThrough[p.Layer][p.Row][p.Col] {
+		mine, theirs := g.count(Lines[index], player), g.count(Lines[index], other(player))
+		switch {
+		case theirs == 0:
+			score += attack[mine]
+		case mine == 0:
+			score += defend[theirs]
+		}
+	}
+	return score
+}
+
+// completing lists the empty cells that would give player four in a row
+func (g *Game) completing(player game.Player) []Point {
+	var cells []Point
+	seen := map[Point]bool{}
+	for _, line := range Lines {
+		if g.count(line, player) != Size-1 {
+			continue
+		}
+		for _, p := range line {
+			if g.At(p) == game.Empty && !seen[p] {
+				seen[p] = true
+				cells = append(cells, p)
+			}
+		}
+	}
+	return cells
+}
+
+// threatening lists the empty cells that would give player three in a line
+// whose last cell is still empty, in board order
+func (g *Game) threatening(player game.Player) []Point {
+	var cells []Point
+	seen := map[Point]bool{}
+	for _, line := range Lines {
+		if g.count(line, player) != Size-2 || g.count(line, other(player)) != 0 {
+			continue
+		}
+		for _, p := range line {
+			if g.At(p) == game.Empty && !seen[p] {
+				seen[p] = true
+				cells = append(cells, p)
+			}
+		}
+	}
+	sort.Slice(cells, func(i, j int) bool { return index(cells[i]) < index(cells[j]) })
+	return cells
+}
+
+// index numbers the cells layer by layer
+func index(p Point) int {
+	return (p.Layer*Size+p.Row)*Size + p.Col
+}
+
+// boardKey identifies a position for the threat search's memo
+type boardKey [Size * Size * Size]byte
+
+// key packs the board into a boardKey
+func (g *Game) key() boardKey {
+	var k boardKey
+	for l := 0; l < Size; l++ {
+		for r := 0; r < Size; r++ {
+			for c := 0; c < Size; c++ {
+				k[index(Point{l, r, c})] = g.Board[l][r][c][0]
+			}
+		}
+	}
+	return k
+}
+
+// threatSearch looks for a win made only of moves the opponent must answer
+type threatSearch struct {
+	ctx    context.Context
+	failed map[boardKey]int // Positions with no forced win, by the deepest search that showed it
+}
+
+// forcedWin finds a move that starts a winning sequence of threats for the
+// player to move, at most depth threats long. The opponent's answers are
+// forced, so only their blocks are searched; lines where a block makes a
+// threat of its own are given up, which keeps the search sound.
+func (s *threatSearch) forcedWin(g *Game, depth int) (Point, bool) {
+	player := g.CurrentPlayer
+	opponent := other(player)
+	if g.Status != game.StatusPlaying || s.ctx.Err() != nil {
+		return Point{}, false
+	}
+	if wins := g.completing(player); len(wins) > 0 {
+		return wins[0], true
+	}
+	if depth == 0 || len(g.completing(opponent)) > 0 {
+		// Out of depth, or the opponent's own threat must be blocked first
+		return Point{}, false
+	}
+
+	key := g.key()
+	if failed, ok := s.failed[key]; ok && failed >= depth {
+		return Point{}, false
+	}
+
+	for _, p := range g.threatening(player) {
+		child := g.Clone()
+		child.MakeMove(p)
+		threats := child.completing(player)
+		if len(threats) >= 2 {
+			return p, true // The opponent can block only one
+		}
+		if len(threats) == 0 {
+			continue
+		}
+
+		// The opponent must block; a block that threatens back breaks the sequence
+		child.MakeMove(threats[0])
+		if child.Status != game.StatusPlaying || len(child.completing(opponent)) > 0 {
+			continue
+		}
+		if _, ok := s.forcedWin(child, depth-1); ok {
+			return p, true
+		}
+	}
+
+	if s.ctx.Err() == nil {
+		s.failed[key] = depth
+	}
+	return Point{}, false
+}
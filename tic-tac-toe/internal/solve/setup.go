@@ -0,0 +1,139 @@
+package solve
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+
+	"tic-tac-toe/internal/game"
+)
+
+// maxObstacleTries bounds the layouts RandomObstacles draws before giving up
+const maxObstacleTries = 100
+
+// SolveGame finds the perfect-play result for the player to move by
+// searching g to the end. Unlike a Tablebase it handles any position a game
+// can start from, blocked cells and handicap marks included, under any rules
+// where each move fills a cell.
+func SolveGame(g *game.Game) (Result, error) {
+	if pieces := g.GetRules().Pieces(); pieces > 0 {
+		return Result{}, fmt.Errorf("cannot solve %s games: marks move once all %d are placed", g.GetRules().Variant.Name(), pieces)
+	}
+	position := g.Clone()
+	position.Clock = nil
+	return (&gameSolver{memo: map[string]Result{}}).solve(position), nil
+}
+
+// gameSolver searches games move by move, remembering each position's result
+type gameSolver struct {
+	memo map[string]Result
+}
+
+// solve returns the result of g for the player to move
+func (s *gameSolver) solve(g *game.Game) Result {
+	key := g.BoardsNotation() + string(g.GetCurrentPlayer())
+	if result, ok := s.memo[key]; ok {
+		return result
+	}
+
+	mover := g.GetCurrentPlayer()
+	best, found := Result{Outcome: Draw}, false
+	for _, move := range g.LegalMoves() {
+		child := g.Clone()
+		if err := child.Play(move); err != nil {
+			continue
+		}
+
+		var result Result
+		switch child.GetStatus() {
+		case game.StatusWon:
+			result = Result{Outcome: Loss, Distance: 1}
+			if child.GetWinner() == mover {
+				result.Outcome = Win
+			}
+		case game.StatusDraw:
+			result = Result{Outcome: Draw, Distance: 1}
+		default:
+			reply := s.solve(child)
+			result = Result{Outcome: flip(reply.Outcome), Distance: reply.Distance + 1}
+		}
+		if !found || rank(result) > rank(best) {
+			best, found = result, true
+		}
+	}
+
+	s.memo[key] = best
+	return best
+}
+
+// flip turns an outcome for one player into the outcome for the other
+func flip(outcome Outcome) Outcome {
+	switch outcome {
+	case Win:
+		return Loss
+	case Loss:
+		return Win
+	default:
+		return Draw
+	}
+}
+
+// rank orders results for the player they belong to: quicker wins and
+// slower losses rank higher
+func rank(result Result) int {
+	switch result.Outcome {
+	case Win:
+		return 100 - result.Distance
+	case Loss:
+		return result.Distance - 100
+	default:
+		return 0
+	}
+}
+
+// RandomObstacles blocks game.RandomBlocked cells drawn by r, drawing again
+// until SolveGame finds the layout fair for the rules.
+// Games whose marks move cannot be solved, so they are judged by classic rules.
+func RandomObstacles(r *rand.Rand, rules game.Rules) (*game.Setup, error) {
+	if rules.Pieces() > 0 {
+		rules = game.ClassicRules
+	}
+	for try := 0; try < maxObstacleTries; try++ {
+		cells := []byte(strings.Repeat(".", 9))
+		for _, i := range r.Perm(9)[:game.RandomBlocked] {
+			cells[i] = '#'
+		}
+		setup, err := game.ParseSetup(game.RandomObstacles, string(cells))
+		if err != nil {
+			return nil, err
+		}
+
+		g := game.New()
+		g.SetRules(rules)
+		if err := g.ApplySetup(setup); err != nil {
+			return nil, err
+		}
+		result, err := SolveGame(g)
+		if err != nil {
+			return nil, err
+		}
+		if fair(rules, result) {
+			return setup, nil
+		}
+	}
+	return nil, fmt.Errorf("no fair layout of %d blocked cells in %d tries", game.RandomBlocked, maxObstacleTries)
+}
+
+// fair reports whether a layout's result for the player to move leaves the
+// game open. Usually that is a draw, but with game.RandomBlocked cells
+// blocked every Order and Chaos or Notakto game has a winner, so there the
+// player to move, who already has the first move, must not be able to force
+// a win.
+func fair(rules game.Rules, result Result) bool {
+	switch rules.Variant {
+	case game.OrderChaos, game.Notakto:
+		return result.Outcome != Win
+	default:
+		return result.Outcome == Draw
+	}
+}
@@ -0,0 +1,404 @@
+// Package solve enumerates every reachable position of a board variant,
+// solves it with perfect play and stores the results in a tablebase.
+package solve
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"tic-tac-toe/internal/game"
+)
+
+// Variant is a square board on which WinLength marks in a row win
+type Variant struct {
+	Name      string `json:"name"`
+	Size      int    `json:"size"`
+	WinLength int    `json:"win_length"`
+}
+
+// Variants are the boards the solver knows by name
+var Variants = []Variant{
+	{Name: "classic", Size: 3, WinLength: 3},
+	{Name: "4x4", Size: 4, WinLength: 4},
+}
+
+// VariantByName looks up one of the Variants
+func VariantByName(name string) (Variant, bool) {
+	for _, v := range Variants {
+		if v.Name == name {
+			return v, true
+		}
+	}
+	return Variant{}, false
+}
+
+// validate checks that positions of the variant fit in a 32-bit key
+func (v Variant) validate() error {
+	if v.Size < 3 || v.Size > 4 {
+		return fmt.Errorf("variant %s: board size must be 3 or 4, got %d", v.Name, v.Size)
+	}
+	if v.WinLength < 3 || v.WinLength > v.Size {
+		return fmt.Errorf("variant %s: win length must be between 3 and %d, got %d", v.Name, v.Size, v.WinLength)
+	}
+	return nil
+}
+
+// Cell is the content of one square
+type Cell uint8
+
+const (
+	Empty Cell = iota
+	X
+	O
+)
+
+// Outcome is a game-theoretic value for the player to move
+type Outcome string
+
+const (
+	Win  Outcome = "win"
+	Draw Outcome = "draw"
+	Loss Outcome = "loss"
+)
+
+// Result is a position's value and how many plies remain with perfect play;
+// the winner plays for the quickest win and the loser for the longest loss
+type Result struct {
+	Outcome  Outcome `json:"outcome"`
+	Distance int     `json:"distance"`
+}
+
+// Board is a position of a variant, cells listed row by row
+type Board struct {
+	Variant Variant
+	Cells   []Cell
+}
+
+// NewBoard returns an empty board of the variant
+func NewBoard(v Variant) Board {
+	return Board{Variant: v, Cells: make([]Cell, v.Size*v.Size)}
+}
+
+// FromGame converts a classic game into a board
+func FromGame(g *game.Game) Board {
+	classic, _ := VariantByName("classic")
+	b := NewBoard(classic)
+	board := g.GetBoard()
+	for row := 0; row < 3; row++ {
+		for col := 0; col < 3; col++ {
+			switch board[row][col] {
+			case game.PlayerX:
+				b.Cells[row*3+col] = X
+			case game.PlayerO:
+				b.Cells[row*3+col] = O
+			}
+		}
+	}
+	return b
+}
+
+// ParseBoard reads a board written row by row, e.g. "XO./.X./..O". Empty
+// cells may be '.', '-' or '_' and the '/' row separators are optional.
+func ParseBoard(v Variant, notation string) (Board, error) {
+	cells := strings.ReplaceAll(strings.TrimSpace(notation), "/", "")
+	b := NewBoard(v)
+	if len(cells) != len(b.Cells) {
+		return Board{}, fmt.Errorf("position %q must have %d cells for %s, got %d", notation, len(b.Cells), v.Name, len(cells))
+	}
+
+	for i, cell := range strings.ToUpper(cells) {
+		switch cell {
+		case 'X':
+			b.Cells[i] = X
+		case 'O':
+			b.Cells[i] = O
+		case '.', '-', '_':
+		default:
+			return Board{}, fmt.Errorf("position %q has invalid cell %q", notation, cell)
+		}
+	}
+	return b, nil
+}
+
+// String writes the board in the form ParseBoard reads
+func (b Board) String() string {
+	rows := make([]string, b.Variant.Size)
+	for i, cell := range b.Cells {
+		rows[i/b.Variant.Size] += string(".XO"[cell])
+	}
+	return strings.Join(rows, "/")
+}
+
+// Canonical returns the image of the board under rotation and reflection
+// that the tablebase stores, so symmetric positions compare equal
+func (b Board) Canonical() Board {
+	g := newGeometry(b.Variant)
+	key := g.canonical(b.Cells)
+	canonical := NewBoard(b.Variant)
+	for i := range canonical.Cells {
+		canonical.Cells[i] = Cell(key / g.pow3[i] % 3)
+	}
+	return canonical
+}
+
+// ToMove returns the player to move; X moves first
+func (b Board) ToMove() Cell {
+	placed := 0
+	for _, cell := range b.Cells {
+		if cell != Empty {
+			placed++
+		}
+	}
+	if placed%2 == 0 {
+		return X
+	}
+	return O
+}
+
+// FirstMove is the perfect-play value of an opening move for X
+type FirstMove struct {
+	Row    int    `json:"row"`
+	Col    int    `json:"col"`
+	Result Result `json:"result"`
+}
+
+// Stats summarises a solved variant
+type Stats struct {
+	Variant   Variant         `json:"variant"`
+	Reachable int             `json:"reachable"`  // Positions reachable from the empty board, including it
+	Canonical int             `json:"canonical"`  // Reachable positions distinct under rotation and reflection
+	XWins     int             `json:"x_wins"`     // Reachable finished positions won by X
+	OWins     int             `json:"o_wins"`     // Reachable finished positions won by O
+	Draws     int             `json:"draws"`      // Reachable finished positions with a full board and no winner
+	ByOutcome map[Outcome]int `json:"by_outcome"` // Canonical positions by value for the player to move
+	Start     Result          `json:"start"`      // Value of the empty board for X
+	FirstMove []FirstMove     `json:"first_moves"`
+}
+
+// Packed results keep the outcome in the top two bits and the distance in
+// the rest; zero means "not solved yet"
+const (
+	packedWin  = 1
+	packedDraw = 2
+	packedLoss = 3
+)
+
+func pack(outcome uint8, distance int) uint8 {
+	return outcome<<6 | uint8(distance)
+}
+
+func unpack(packed uint8) Result {
+	distance := int(packed & 0x3f)
+	switch packed >> 6 {
+	case packedWin:
+		return Result{Outcome: Win, Distance: distance}
+	case packedLoss:
+		return Result{Outcome: Loss, Distance: distance}
+	default:
+		return Result{Outcome: Draw, Distance: distance}
+	}
+}
+
+// parent turns a child's packed value into the value of the move leading to it
+func parent(child uint8) uint8 {
+	distance := int(child&0x3f) + 1
+	switch child >> 6 {
+	case packedWin:
+		return pack(packedLoss, distance)
+	case packedLoss:
+		return pack(packedWin, distance)
+	default:
+		return pack(packedDraw, distance)
+	}
+}
+
+// better reports whether packed value a is preferable to b for the player to move
+func better(a, b uint8) bool {
+	rank := func(packed uint8) int {
+		distance := int(packed & 0x3f)
+		switch packed >> 6 {
+		case packedWin:
+			return 1000 - distance
+		case packedDraw:
+			return 0
+		default:
+			return -1000 + distance
+		}
+	}
+	return rank(a) > rank(b)
+}
+
+// geometry holds the precomputed tables for one variant
+type geometry struct {
+	variant  Variant
+	cells    int
+	pow3     []uint32
+	symmetry [][]int // symmetry[s][i] is the cell whose content lands on i under transform s
+}
+
+func newGeometry(v Variant) *geometry {
+	n := v.Size
+	g := &geometry{variant: v, cells: n * n, pow3: make([]uint32, n*n+1)}
+	g.pow3[0] = 1
+	for i := 1; i <= g.cells; i++ {
+		g.pow3[i] = g.pow3[i-1] * 3
+	}
+
+	transforms := []func(r, c int) (int, int){
+		func(r, c int) (int, int) { return r, c },
+		func(r, c int) (int, int) { return c, n - 1 - r },
+		func(r, c int) (int, int) { return n - 1 - r, n - 1 - c },
+		func(r, c int) (int, int) { return n - 1 - c, r },
+		func(r, c int) (int, int) { return r, n - 1 - c },
+		func(r, c int) (int, int) { return n - 1 - r, c },
+		func(r, c int) (int, int) { return c, r },
+		func(r, c int) (int, int) { return n - 1 - c, n - 1 - r },
+	}
+	for _, transform := range transforms {
+		perm := make([]int, g.cells)
+		for i := range perm {
+			r, c := transform(i/n, i%n)
+			perm[i] = r*n + c
+		}
+		g.symmetry = append(g.symmetry, perm)
+	}
+	return g
+}
+
+// canonical returns the smallest key among the board's symmetric images
+func (g *geometry) canonical(cells []Cell) uint32 {
+	best := ^uint32(0)
+	for _, perm := range g.symmetry {
+		var key uint32
+		for i, from := range perm {
+			key += uint32(cells[from]) * g.pow3[i]
+		}
+		if key < best {
+			best = key
+		}
+	}
+	return best
+}
+
+// wins reports whether the mark at cell completes a line
+func (g *geometry) wins(cells []Cell, cell int) bool {
+	n, need := g.variant.Size, g.variant.WinLength
+	player := cells[cell]
+	row, col := cell/n, cell%n
+	for _, dir := range [][2]int{{0, 1}, {1, 0}, {1, 1}, {1, -1}} {
+		count := 1
+		for _, sign := range []int{1, -1} {
+			r, c := row+sign*dir[0], col+sign*dir[1]
+			for r >= 0 && r < n && c >= 0 && c < n && cells[r*n+c] == player {
+				count++
+				r, c = r+sign*dir[0], c+sign*dir[1]
+			}
+		}
+		if count >= need {
+			return true
+		}
+	}
+	return false
+}
+
+// solver enumerates positions depth-first, memoising every packed value
+type solver struct {
+	*geometry
+	memo    []uint8
+	cells   []Cell
+	stats   Stats
+	entries map[uint32]uint8 // Canonical key to packed value
+}
+
+// solve returns the packed value of the current position for the player to move
+func (s *solver) solve(key uint32, placed, last int) uint8 {
+	if packed := s.memo[key]; packed != 0 {
+		return packed
+	}
+
+	var result uint8
+	switch {
+	case last >= 0 && s.wins(s.cells, last):
+		// The previous player just completed a line
+		result = pack(packedLoss, 0)
+		if s.cells[last] == X {
+			s.stats.XWins++
+		} else {
+			s.stats.OWins++
+		}
+	case placed == s.geometry.cells:
+		result = pack(packedDraw, 0)
+		s.stats.Draws++
+	default:
+		toMove := X
+		if placed%2 == 1 {
+			toMove = O
+		}
+		for i, cell := range s.cells {
+			if cell != Empty {
+				continue
+			}
+			s.cells[i] = toMove
+			value := parent(s.solve(key+uint32(toMove)*s.pow3[i], placed+1, i))
+			s.cells[i] = Empty
+			if result == 0 || better(value, result) {
+				result = value
+			}
+		}
+	}
+
+	s.memo[key] = result
+	s.stats.Reachable++
+	if canonical := s.canonical(s.cells); canonical == key {
+		s.entries[key] = result
+		s.stats.Canonical++
+		s.stats.ByOutcome[unpack(result).Outcome]++
+	}
+	return result
+}
+
+// Generate solves every reachable position of the variant
+func Generate(v Variant) (*Tablebase, error) {
+	if err := v.validate(); err != nil {
+		return nil, err
+	}
+
+	geometry := newGeometry(v)
+	s := &solver{
+		geometry: geometry,
+		memo:     make([]uint8, geometry.pow3[geometry.cells]),
+		cells:    make([]Cell, geometry.cells),
+		stats:    Stats{Variant: v, ByOutcome: map[Outcome]int{}},
+		entries:  map[uint32]uint8{},
+	}
+	s.stats.Start = unpack(s.solve(0, 0, -1))
+
+	// First moves are listed once per symmetry class, X's view
+	seen := map[uint32]bool{}
+	for i := range s.cells {
+		s.cells[i] = X
+		canonical := s.canonical(s.cells)
+		if !seen[canonical] {
+			seen[canonical] = true
+			s.stats.FirstMove = append(s.stats.FirstMove, FirstMove{
+				Row:    i / v.Size,
+				Col:    i % v.Size,
+				Result: unpack(parent(s.memo[uint32(X)*geometry.pow3[i]])),
+			})
+		}
+		s.cells[i] = Empty
+	}
+
+	t := &Tablebase{Stats: s.stats, geometry: geometry}
+	t.keys = make([]uint32, 0, len(s.entries))
+	for key := range s.entries {
+		t.keys = append(t.keys, key)
+	}
+	sort.Slice(t.keys, func(i, j int) bool { return t.keys[i] < t.keys[j] })
+	t.values = make([]uint8, len(t.keys))
+	for i, key := range t.keys {
+		t.values[i] = s.entries[key]
+	}
+	return t, nil
+}
@@ -0,0 +1,13 @@
+package solve_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestSolve(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Solve Suite")
+}
@@ -0,0 +1,220 @@
+package solve_test
+
+import (
+	"bytes"
+	"math/rand"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"tic-tac-toe/internal/game"
+	"tic-tac-toe/internal/solve"
+)
+
+var _ = Describe("Solve", func() {
+	var classic solve.Variant
+
+	BeforeEach(func() {
+		var ok bool
+		classic, ok = solve.VariantByName("classic")
+		Expect(ok).To(BeTrue())
+	})
+
+	parse := func(notation string) solve.Board {
+		b, err := solve.ParseBoard(classic, notation)
+		Expect(err).ToNot(HaveOccurred())
+		return b
+	}
+
+	Describe("Generate", func() {
+		It("should count the classic positions", func() {
+			stats := solve.Classic().Stats
+			Expect(stats.Reachable).To(Equal(5478))
+			Expect(stats.Canonical).To(Equal(765))
+			Expect(stats.XWins).To(Equal(626))
+			Expect(stats.OWins).To(Equal(316))
+			Expect(stats.Draws).To(Equal(16))
+			Expect(solve.Classic().Len()).To(Equal(765))
+		})
+
+		It("should find that the classic game is a draw", func() {
+			stats := solve.Classic().Stats
+			Expect(stats.Start).To(Equal(solve.Result{Outcome: solve.Draw, Distance: 9}))
+			Expect(stats.FirstMove).To(HaveLen(3)) // Corner, edge and center
+			for _, move := range stats.FirstMove {
+				Expect(move.Result.Outcome).To(Equal(solve.Draw))
+			}
+		})
+
+		It("should reject boards that do not fit the tablebase", func() {
+			_, err := solve.Generate(solve.Variant{Name: "5x5", Size: 5, WinLength: 4})
+			Expect(err).To(HaveOccurred())
+			_, err = solve.Generate(solve.Variant{Name: "short", Size: 3, WinLength: 2})
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("Lookup", func() {
+		It("should value a position for the player to move", func() {
+			result, err := solve.Classic().Lookup(parse("XX./OO./..."))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result).To(Equal(solve.Result{Outcome: solve.Win, Distance: 1}))
+
+			result, err = solve.Classic().Lookup(parse("XXX/OO./..."))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result).To(Equal(solve.Result{Outcome: solve.Loss, Distance: 0}))
+		})
+
+		It("should find symmetric positions", func() {
+			a, err := solve.Classic().Lookup(parse("X../.O./..."))
+			Expect(err).ToNot(HaveOccurred())
+			b, err := solve.Classic().Lookup(parse("..X/.O./..."))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(a).To(Equal(b))
+		})
+
+		It("should reject unreachable positions", func() {
+			_, err := solve.Classic().Lookup(parse("XXX/XXX/OOO"))
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("should reject positions of another variant", func() {
+			v, _ := solve.VariantByName("4x4")
+			_, err := solve.Classic().Lookup(solve.NewBoard(v))
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("Moves", func() {
+		It("should list the best move first", func() {
+			moves, err := solve.Classic().Moves(parse("XX./OO./..."))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(moves).To(HaveLen(5))
+			Expect(moves[0]).To(Equal(solve.MoveResult{Row: 0, Col: 2, Result: solve.Result{Outcome: solve.Win, Distance: 1}}))
+			Expect(moves[len(moves)-1].Result.Outcome).To(Equal(solve.Loss))
+		})
+
+		It("should have no moves in finished positions", func() {
+			moves, err := solve.Classic().Moves(parse("XXX/OO./..."))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(moves).To(BeEmpty())
+		})
+	})
+
+	Describe("Board", func() {
+		It("should round-trip through its notation", func() {
+			b := parse("x-o/_x_/..O")
+			Expect(b.String()).To(Equal("X.O/.X./..O"))
+			Expect(b.ToMove()).To(Equal(solve.X))
+		})
+
+		It("should map symmetric boards to one canonical form", func() {
+			Expect(parse("X../.O./...").Canonical()).To(Equal(parse("..X/.O./...").Canonical()))
+			Expect(parse(".../.O./X..").Canonical()).To(Equal(parse("..X/.O./...").Canonical()))
+			Expect(parse("X../.../...").Canonical()).ToNot(Equal(parse(".X./.../...").Canonical()))
+		})
+
+		It("should reject malformed notation", func() {
+			_, err := solve.ParseBoard(classic, "XX./...")
+			Expect(err).To(HaveOccurred())
+			_, err = solve.ParseBoard(classic, "XX?/.../...")
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("should convert a game", func() {
+			g := game.New()
+			g.MakeMove(1, 1)
+			g.MakeMove(0, 2)
+			Expect(solve.FromGame(g).String()).To(Equal("..O/.X./..."))
+		})
+	})
+
+	Describe("Tablebase files", func() {
+		It("should round-trip through Write and Read", func() {
+			var buf bytes.Buffer
+			Expect(solve.Classic().Write(&buf)).To(Succeed())
+
+			loaded, err := solve.Read(&buf)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(loaded.Stats).To(Equal(solve.Classic().Stats))
+			Expect(loaded.Len()).To(Equal(solve.Classic().Len()))
+
+			result, err := loaded.Lookup(parse("XX./OO./..."))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.Outcome).To(Equal(solve.Win))
+		})
+
+		It("should save and load from disk", func() {
+			path := filepath.Join(GinkgoT().TempDir(), "tables", "classic.tb")
+			Expect(solve.Classic().Save(path)).To(Succeed())
+			loaded, err := solve.Load(path)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(loaded.Variant()).To(Equal(classic))
+		})
+
+		It("should reject files that are not tablebases", func() {
+			_, err := solve.Read(bytes.NewBufferString("not a tablebase"))
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("SolveGame", func() {
+		setupGame := func(name string, rules game.Rules) *game.Game {
+			setup, err := game.LookupSetup(name)
+			Expect(err).ToNot(HaveOccurred())
+			g := game.New()
+			g.SetRules(rules)
+			Expect(g.ApplySetup(setup)).To(Succeed())
+			return g
+		}
+
+		It("should agree with the tablebase on the empty board", func() {
+			result, err := solve.SolveGame(game.New())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result).To(Equal(solve.Result{Outcome: solve.Draw, Distance: 9}))
+		})
+
+		It("should solve setups the tablebase cannot reach", func() {
+			result, err := solve.SolveGame(setupGame("Handicap X", game.ClassicRules))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.Outcome).To(Equal(solve.Win))
+
+			_, err = solve.SolveGame(setupGame("Blocked center", game.Rules{Variant: game.Achi}))
+			Expect(err).To(MatchError(ContainSubstring("marks move")))
+		})
+
+		It("should only draw fair random obstacles", func() {
+			for seed := int64(1); seed <= 5; seed++ {
+				setup, err := solve.RandomObstacles(rand.New(rand.NewSource(seed)), game.ClassicRules)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(setup.Name).To(Equal(game.RandomObstacles))
+				Expect(strings.Count(setup.Position, "#")).To(Equal(game.RandomBlocked))
+
+				g := game.New()
+				Expect(g.ApplySetup(setup)).To(Succeed())
+				result, err := solve.SolveGame(g)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(result.Outcome).To(Equal(solve.Draw))
+			}
+		})
+
+		It("should leave the first move no forced win under rules without draws", func() {
+			for _, variant := range []game.Variant{game.OrderChaos, game.Notakto} {
+				rules := game.Rules{Variant: variant}
+				for seed := int64(1); seed <= 5; seed++ {
+					setup, err := solve.RandomObstacles(rand.New(rand.NewSource(seed)), rules)
+					Expect(err).ToNot(HaveOccurred())
+
+					g := game.New()
+					g.SetRules(rules)
+					Expect(g.ApplySetup(setup)).To(Succeed())
+					result, err := solve.SolveGame(g)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(result.Outcome).To(Equal(solve.Loss), "%s, seed %d", variant, seed)
+				}
+			}
+		})
+	})
+})
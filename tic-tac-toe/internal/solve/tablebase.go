@@ -0,0 +1,264 @@
+package solve
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// tablebaseMagic starts every tablebase file, followed by a format version
+const (
+	tablebaseMagic   = "TTTB"
+	tablebaseVersion = 1
+)
+
+// Tablebase holds the solved value of every canonical reachable position.
+// On disk it is gzip-compressed: a header with the JSON statistics, then the
+// sorted canonical keys as varint deltas, each followed by its packed value.
+type Tablebase struct {
+	Stats    Stats
+	geometry *geometry
+	keys     []uint32
+	values   []uint8
+}
+
+// MoveResult is the value of one legal move for the player making it
+type MoveResult struct {
+	Row    int    `json:"row"`
+	Col    int    `json:"col"`
+	Result Result `json:"result"`
+}
+
+var (
+	classicOnce sync.Once
+	classic     *Tablebase
+)
+
+// Classic returns the tablebase for the classic board, solving it on first use
+func Classic() *Tablebase {
+	classicOnce.Do(func() {
+		v, _ := VariantByName("classic")
+		classic, _ = Generate(v)
+	})
+	return classic
+}
+
+// Variant returns the board variant the tablebase solves
+func (t *Tablebase) Variant() Variant {
+	return t.geometry.variant
+}
+
+// Len returns how many positions the tablebase stores
+func (t *Tablebase) Len() int {
+	return len(t.keys)
+}
+
+// Lookup returns the value of a position for the player to move
+func (t *Tablebase) Lookup(b Board) (Result, error) {
+	packed, err := t.lookup(b.Cells, b.Variant)
+	if err != nil {
+		return Result{}, err
+	}
+	return unpack(packed), nil
+}
+
+// Moves rates every legal move of a position, best first. Finished
+// positions have no moves.
+func (t *Tablebase) Moves(b Board) ([]MoveResult, error) {
+	packed, err := t.lookup(b.Cells, b.Variant)
+	if err != nil {
+		return nil, err
+	}
+	if packed&0x3f == 0 {
+		return nil, nil
+	}
+
+	cells := append([]Cell(nil), b.Cells...)
+	toMove := b.ToMove()
+	type candidate struct {
+		move   MoveResult
+		packed uint8
+	}
+	var candidates []candidate
+	for i, cell := range cells {
+		if cell != Empty {
+			continue
+		}
+		cells[i] = toMove
+		child, err := t.lookup(cells, b.Variant)
+		cells[i] = Empty
+		if err != nil {
+			return nil, err
+		}
+		value := parent(child)
+		candidates = append(candidates, candidate{
+			move:   MoveResult{Row: i / b.Variant.Size, Col: i % b.Variant.Size, Result: unpack(value)},
+			packed: value,
+		})
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return better(candidates[i].packed, candidates[j].packed)
+	})
+	moves := make([]MoveResult, len(candidates))
+	for i, c := range candidates {
+		moves[i] = c.move
+	}
+	return moves, nil
+}
+
+// lookup finds the packed value of cells by their canonical key
+func (t *Tablebase) lookup(cells []Cell, v Variant) (uint8, error) {
+	if v != t.geometry.variant {
+		return 0, fmt.Errorf("position is for %s, tablebase is for %s", v.Name, t.geometry.variant.Name)
+	}
+	if len(cells) != t.geometry.cells {
+		return 0, fmt.Errorf("position has %d cells, want %d", len(cells), t.geometry.cells)
+	}
+
+	key := t.geometry.canonical(cells)
+	i := sort.Search(len(t.keys), func(i int) bool { return t.keys[i] >= key })
+	if i == len(t.keys) || t.keys[i] != key {
+		return 0, fmt.Errorf("position %s cannot be reached in a legal game", Board{Variant: v, Cells: cells})
+	}
+	return t.values[i], nil
+}
+
+// Save writes the tablebase to path
+func (t *Tablebase) Save(path string) error {
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create %s: %w", dir, err)
+		}
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", path, err)
+	}
+	if err := t.Write(file); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// Write encodes the tablebase to w
+func (t *Tablebase) Write(w io.Writer) error {
+	stats, err := json.Marshal(t.Stats)
+	if err != nil {
+		return fmt.Errorf("failed to marshal statistics: %w", err)
+	}
+
+	zw := gzip.NewWriter(w)
+	out := bufio.NewWriter(zw)
+	buf := make([]byte, binary.MaxVarintLen64)
+	writeUvarint := func(v uint64) {
+		out.Write(buf[:binary.PutUvarint(buf, v)])
+	}
+
+	out.WriteString(tablebaseMagic)
+	out.WriteByte(tablebaseVersion)
+	writeUvarint(uint64(len(stats)))
+	out.Write(stats)
+	writeUvarint(uint64(len(t.keys)))
+
+	previous := uint32(0)
+	for i, key := range t.keys {
+		writeUvarint(uint64(key - previous))
+		out.WriteByte(t.values[i])
+		previous = key
+	}
+
+	if err := out.Flush(); err != nil {
+		return fmt.Errorf("failed to write tablebase: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("failed to write tablebase: %w", err)
+	}
+	return nil
+}
+
+// Load reads a tablebase written by Save
+func Load(path string) (*Tablebase, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer file.Close()
+
+	t, err := Read(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return t, nil
+}
+
+// Read decodes a tablebase written by Write
+func Read(r io.Reader) (*Tablebase, error) {
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("not a tablebase: %w", err)
+	}
+	defer zr.Close()
+	in := bufio.NewReader(zr)
+
+	header := make([]byte, len(tablebaseMagic)+1)
+	if _, err := io.ReadFull(in, header); err != nil || string(header[:len(tablebaseMagic)]) != tablebaseMagic {
+		return nil, fmt.Errorf("not a tablebase")
+	}
+	if header[len(tablebaseMagic)] != tablebaseVersion {
+		return nil, fmt.Errorf("unsupported tablebase version %d", header[len(tablebaseMagic)])
+	}
+
+	statsLen, err := binary.ReadUvarint(in)
+	if err != nil {
+		return nil, fmt.Errorf("truncated tablebase: %w", err)
+	}
+	stats := make([]byte, statsLen)
+	if _, err := io.ReadFull(in, stats); err != nil {
+		return nil, fmt.Errorf("truncated tablebase: %w", err)
+	}
+
+	t := &Tablebase{}
+	if err := json.Unmarshal(stats, &t.Stats); err != nil {
+		return nil, fmt.Errorf("invalid tablebase statistics: %w", err)
+	}
+	if err := t.Stats.Variant.validate(); err != nil {
+		return nil, err
+	}
+	t.geometry = newGeometry(t.Stats.Variant)
+
+	count, err := binary.ReadUvarint(in)
+	if err != nil {
+		return nil, fmt.Errorf("truncated tablebase: %w", err)
+	}
+	if count > uint64(t.geometry.pow3[t.geometry.cells]) {
+		return nil, fmt.Errorf("tablebase claims %d positions", count)
+	}
+
+	t.keys = make([]uint32, count)
+	t.values = make([]uint8, count)
+	previous := uint32(0)
+	for i := range t.keys {
+		delta, err := binary.ReadUvarint(in)
+		if err != nil {
+			return nil, fmt.Errorf("truncated tablebase: %w", err)
+		}
+		value, err := in.ReadByte()
+		if err != nil {
+			return nil, fmt.Errorf("truncated tablebase: %w", err)
+		}
+		previous += uint32(delta)
+		t.keys[i] = previous
+		t.values[i] = value
+	}
+	return t, nil
+}
@@ -0,0 +1,98 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"tic-tac-toe/internal/game"
+	"tic-tac-toe/internal/input"
+)
+
+// compactBoardWidth is the width of a board drawn by renderCompactBoard:
+// three cells of three, the padding and the border
+const compactBoardWidth = 3*3 + 2 + 2
+
+// multiBoard reports whether the game is played on several boards
+func (m *Model) multiBoard() bool {
+	return len(m.game.Extra) > 0
+}
+
+// renderBoards draws every board of a game on several boards side by side,
+// boards with a line faded out
+func (m *Model) renderBoards() string {
+	boards := m.game.Boards()
+	rendered := make([]string, 0, len(boards))
+	for i, board := range boards {
+		label := fmt.Sprintf("Board %d", i+1)
+		if m.game.BoardDead(i) {
+			label += " ✗"
+		}
+		rendered = append(rendered, lipgloss.JoinVertical(lipgloss.Center,
+			m.renderCompactBoard(i, board),
+			lipgloss.NewStyle().Faint(m.game.BoardDead(i)).Render(label)))
+		if i < len(boards)-1 {
+			rendered = append(rendered, "  ")
+		}
+	}
+	return lipgloss.NewStyle().
+		MarginTop(1).
+		MarginBottom(1).
+		Render(lipgloss.JoinHorizontal(lipgloss.Top, rendered...))
+}
+
+// renderCompactBoard draws one board of a game on several boards with a
+// cell per three columns, showing the cursor only on its own board
+func (m *Model) renderCompactBoard(index int, board [3][3]game.Player) string {
+	dead := m.game.BoardDead(index)
+	rows := make([]string, 0, 3)
+	for row := 0; row < 3; row++ {
+		var line strings.Builder
+		for col := 0; col < 3; col++ {
+			cell := " " + renderMark(board[row][col]) + " "
+			switch {
+			case m.onWinLine(index, row, col):
+				cell = m.renderWinningCell(" " + string(board[row][col]) + " ")
+			case index == m.cursorBoard && row == m.cursorPosition[0] && col == m.cursorPosition[1]:
+				cell = "[" + renderMark(board[row][col]) + "]"
+			case dead:
+				cell = lipgloss.NewStyle().Faint(true).Render(cell)
+			}
+			line.WriteString(cell)
+		}
+		rows = append(rows, line.String())
+	}
+
+	border := lipgloss.Color("39")
+	if dead {
+		border = lipgloss.Color("240")
+	}
+	return lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(border).
+		Padding(0, 1).
+		Render(strings.Join(rows, "\n"))
+}
+
+// crossBoard moves the cursor onto the neighboring board when it is pushed
+// off the left or right edge of its own, reporting whether it did
+func (m *Model) crossBoard(action input.KeybindingAction) bool {
+	if !m.multiBoard() {
+		return false
+	}
+	row, col := m.cursorPosition[0], m.cursorPosition[1]
+	switch {
+	case action == input.ActionMoveRight && col == 2 && m.cursorBoard < len(m.game.Extra):
+		m.cursorBoard++
+		col = 0
+	case action == input.ActionMoveLeft && col == 0 && m.cursorBoard > 0:
+		m.cursorBoard--
+		col = 2
+	default:
+		return false
+	}
+	m.inputHandler.SetCursorPosition(col, row)
+	m.cursorPosition = [2]int{row, col}
+	return true
+}
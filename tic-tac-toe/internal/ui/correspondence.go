@@ -0,0 +1,219 @@
+package ui
+
+import (
+	"fmt"
+	"path/filepath"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"tic-tac-toe/internal/audio"
+	"tic-tac-toe/internal/correspondence"
+	"tic-tac-toe/internal/game"
+	"tic-tac-toe/internal/input"
+)
+
+// correspondenceManager returns the correspondence manager, creating it on first use
+func (m *Model) correspondenceManager() (*correspondence.Manager, error) {
+	if m.corrManager != nil {
+		return m.corrManager, nil
+	}
+
+	saveDir := m.persistManager.GetSaveDirectory()
+	identity, err := correspondence.LoadOrCreateIdentity(filepath.Join(saveDir, "identity.json"), lobbyPlayerName())
+	if err != nil {
+		return nil, err
+	}
+
+	manager, err := correspondence.New(filepath.Join(saveDir, "correspondence"), saveDir, identity)
+	if err != nil {
+		return nil, err
+	}
+
+	m.corrManager = manager
+	return manager, nil
+}
+
+// refreshCorrespondence reloads the list of shared games
+func (m *Model) refreshCorrespondence() {
+	manager, err := m.correspondenceManager()
+	if err != nil {
+		m.errorMessage = "Correspondence unavailable: " + err.Error()
+		return
+	}
+
+	games, err := manager.List()
+	if err != nil {
+		m.errorMessage = err.Error()
+		return
+	}
+
+	m.corrGames = games
+	if m.corrSelected >= len(games) {
+		m.corrSelected = max(len(games)-1, 0)
+	}
+}
+
+// pollCorrespondence picks up moves written by the opponent since the last tick
+func (m *Model) pollCorrespondence() {
+	switch {
+	case m.state == StateCorrespondence:
+		m.refreshCorrespondence()
+	case m.state == StateGame && m.game.GetMode() == game.PlayerVsCorrespondence:
+		_, g, err := m.corrManager.Load(m.corrGameID)
+		if err != nil {
+			m.errorMessage = err.Error()
+			return
+		}
+		if len(g.GetMoveHistory()) != len(m.game.GetMoveHistory()) {
+			m.showCorrespondenceGame(g)
+			m.audioManager.PlaySound(audio.SoundMove)
+		}
+	}
+}
+
+// showCorrespondenceGame makes g the game on screen
+func (m *Model) showCorrespondenceGame(g *game.Game) {
+	m.game = g
+	m.game.SetMode(game.PlayerVsCorrespondence)
+
+	if m.game.GetStatus() != game.StatusPlaying {
+		m.state = StateGameOver
+	}
+}
+
+// openCorrespondenceGame loads and displays a shared game
+func (m *Model) openCorrespondenceGame(id string) {
+	_, g, err := m.corrManager.Load(id)
+	if err != nil {
+		m.errorMessage = err.Error()
+		return
+	}
+
+	m.corrGameID = id
+	m.state = StateGame
+	m.cursorPosition = [2]int{1, 1}
+	m.showCorrespondenceGame(g)
+}
+
+// playCorrespondenceMove signs and writes the local player's move
+func (m *Model) playCorrespondenceMove(row, col int) tea.Cmd {
+	g, err := m.corrManager.Play(m.corrGameID, row, col)
+	if err != nil {
+		m.errorMessage = err.Error()
+		m.audioManager.PlaySound(audio.SoundError)
+		return nil
+	}
+
+	m.audioManager.PlaySound(audio.SoundMove)
+	m.showCorrespondenceGame(g)
+	if m.state == StateGame {
+		m.statusMessage = "Move sent, waiting for your opponent"
+	}
+	return nil
+}
+
+// handleCorrespondenceInput handles keys on the correspondence list
+func (m *Model) handleCorrespondenceInput(action input.KeybindingAction) tea.Cmd {
+	switch action {
+	case input.ActionMoveUp:
+		if m.corrSelected > 0 {
+			m.corrSelected--
+		}
+	case input.ActionMoveDown:
+		if m.corrSelected < len(m.corrGames)-1 {
+			m.corrSelected++
+		}
+	case input.ActionSelect:
+		if len(m.corrGames) == 0 {
+			return nil
+		}
+		selected := m.corrGames[m.corrSelected]
+		if selected.Err != nil {
+			m.errorMessage = selected.Err.Error()
+			return nil
+		}
+		m.openCorrespondenceGame(selected.ID)
+	case input.ActionNewGame:
+		manager, err := m.correspondenceManager()
+		if err != nil {
+			m.errorMessage = err.Error()
+			return nil
+		}
+		record, err := manager.Create()
+		if err != nil {
+			m.errorMessage = "Failed to create game: " + err.Error()
+			return nil
+		}
+		m.openCorrespondenceGame(record.ID)
+	case input.ActionReset:
+		m.refreshCorrespondence()
+	case input.ActionBack:
+		m.state = StateMainMenu
+		m.cursorPosition = [2]int{1, 0}
+	}
+	return nil
+}
+
+// renderCorrespondenceScreen lists shared games with whose turn it is
+func (m *Model) renderCorrespondenceScreen() string {
+	content := m.gradientManager.ApplyToText("📮 CORRESPONDENCE GAMES") + "\n\n"
+
+	if m.corrManager != nil {
+		content += "Shared folder: " + m.corrManager.GetDirectory() + "\n\n"
+	}
+
+	if len(m.corrGames) == 0 {
+		content += "No games yet. Press 'n' to start one.\n"
+	}
+	for i, summary := range m.corrGames {
+		var line string
+		switch {
+		case summary.Err != nil:
+			line = fmt.Sprintf("%s  ⚠ %s", summary.ID, summary.Err.Error())
+		default:
+			opponent := summary.PlayerO
+			if opponent == "" {
+				opponent = "(open seat)"
+			}
+			line = fmt.Sprintf("%s  %s vs %s  %d moves  %s", summary.ID, summary.PlayerX, opponent, summary.Moves, correspondenceStatus(summary))
+		}
+
+		if i == m.corrSelected {
+			content += m.gradientManager.ApplyToText("▶ "+line) + "\n"
+		} else {
+			content += "  " + line + "\n"
+		}
+	}
+
+	if m.statusMessage != "" {
+		content += "\n" + m.statusMessage + "\n"
+	}
+	if m.errorMessage != "" {
+		content += "\n" + lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Render(m.errorMessage) + "\n"
+	}
+
+	content += "\n" + lipgloss.NewStyle().Faint(true).Render("↑↓ Select • Enter Open • n New game • r Refresh • esc Back")
+
+	style := lipgloss.NewStyle().
+		Align(lipgloss.Center).
+		Width(m.width).
+		Height(m.height).
+		Padding(2)
+
+	return style.Render(content)
+}
+
+// correspondenceStatus describes a listed game's state
+func correspondenceStatus(summary correspondence.Summary) string {
+	switch summary.Status {
+	case game.StatusWon:
+		return string(summary.Winner) + " won"
+	case game.StatusDraw:
+		return "draw"
+	}
+	if summary.YourTurn {
+		return "★ your turn"
+	}
+	return "waiting"
+}
@@ -0,0 +1,78 @@
+package ui
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+
+	"tic-tac-toe/internal/audio"
+	"tic-tac-toe/internal/game"
+)
+
+// subscribeGameEvents registers the model's reactions to the events of the
+// local game. Each concern subscribes on its own, so new ones plug in here
+// rather than in the move handlers.
+func (m *Model) subscribeGameEvents() {
+	m.events.Subscribe(m.playGameSound)
+	m.events.Subscribe(m.finishGame)
+	// After finishGame, which archives the game as the match's next one
+	m.events.Subscribe(m.recordMatchGame)
+	m.events.Subscribe(m.clearGameState)
+	m.events.Subscribe(m.queueSave)
+}
+
+// watchGame makes the local game publish on the model's bus; games shown
+// from the lobby or correspondence replace it without one
+func (m *Model) watchGame() {
+	m.game.SetEvents(m.events)
+}
+
+// playGameSound plays the sound for a move or the end of the game
+func (m *Model) playGameSound(event game.Event) {
+	switch event.Type {
+	case game.EventMoveMade:
+		m.audioManager.PlaySound(audio.SoundMove)
+	case game.EventGameWon:
+		m.audioManager.PlaySound(audio.SoundWin)
+	case game.EventGameDrawn:
+		m.audioManager.PlaySound(audio.SoundDraw)
+	}
+}
+
+// finishGame records the score of a finished game, archives it and shows
+// the winning line, then the game-over screen
+func (m *Model) finishGame(event game.Event) {
+	if event.Type != game.EventGameWon && event.Type != game.EventGameDrawn {
+		return
+	}
+	m.recordGameScore()
+	if m.state == StateGame {
+		m.startWinAnimation(event.Game.LastBoard(), event.Lines)
+	}
+}
+
+// clearGameState forgets the picked mark, the chosen mark and the AI's
+// explanation when the board is reset or moves are taken back
+func (m *Model) clearGameState(event game.Event) {
+	if event.Type != game.EventReset && event.Type != game.EventUndo {
+		return
+	}
+	m.markChoice = game.Empty
+	m.inputHandler.ClearSource()
+	m.aiExplanation = nil
+	m.winLine = nil
+}
+
+// queueSave saves the game once the current message has been handled
+func (m *Model) queueSave(game.Event) {
+	m.saveQueued = true
+}
+
+// saveQueuedGame returns the save queued by game events, if any
+func (m *Model) saveQueuedGame() tea.Cmd {
+	if !m.saveQueued {
+		return nil
+	}
+	m.saveQueued = false
+	return func() tea.Msg {
+		return gameUpdateMsg{saveRequired: true}
+	}
+}
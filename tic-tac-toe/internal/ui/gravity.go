@@ -0,0 +1,370 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"tic-tac-toe/internal/audio"
+	"tic-tac-toe/internal/game"
+	"tic-tac-toe/internal/gravity"
+	"tic-tac-toe/internal/input"
+)
+
+// gravityMoveMsg carries the result of a background gravity AI search
+type gravityMoveMsg struct {
+	search int // The search that produced it; older searches were cancelled
+	col    int
+	swap   *game.SwapChoice // The AI's swap decision, made instead of a drop
+	err    error
+}
+
+// dropAnimation is a mark falling down its column, one row per animation tick
+type dropAnimation struct {
+	landed game.Position // The cell the mark ends in
+	player game.Player
+	row    int // The row the mark is drawn in now
+}
+
+// dropTickDivisor speeds up the animation tick while a mark is falling
+const dropTickDivisor = 10
+
+// gravityCellWidth is the width of a board cell, its left border included
+const gravityCellWidth = 4
+
+// gravityAISeat is the AI's seat in gravity games: it starts as O
+const gravityAISeat = game.SecondSeat
+
+// startGravity opens a new gravity game on the configured board against the
+// AI, which plays O at the configured strength unless the swap rule
+// exchanges the sides
+func (m *Model) startGravity() {
+	m.cancelAIMove()
+	shape := m.config.GetGravityShape()
+	m.gravityGame = gravity.New(shape)
+	m.gravityGame.SetSwapRule(m.config.GetSwapRule())
+	m.gravityAI = gravity.NewAI(game.PlayerO, m.config.GetAIStrength())
+	if m.seed != 0 {
+		m.gravityAI.SetSeed(m.seed)
+	}
+	m.gravityCursor = shape.Cols / 2
+	m.gravityDrop = nil
+	m.winLine = nil
+	m.state = StateGravity
+}
+
+// handleGravityInput handles keys in a gravity game; only the column is
+// chosen, by the number keys or by moving the cursor along the top
+func (m *Model) handleGravityInput(action input.KeybindingAction) tea.Cmd {
+	if action >= input.ActionMenu1 && action <= input.ActionMenu9 {
+		col := int(action - input.ActionMenu1)
+		if col >= m.gravityGame.Shape.Cols {
+			return nil
+		}
+		m.gravityCursor = col
+		return m.dropGravityMark()
+	}
+	if seat, _, pending := m.gravityGame.SwapPending(); pending && seat != gravityAISeat {
+		if choice, ok := swapChoiceFor(action); ok {
+			return m.chooseGravitySwap(choice)
+		}
+	}
+
+	switch action {
+	case input.ActionMoveLeft:
+		m.gravityCursor = max(m.gravityCursor-1, 0)
+	case input.ActionMoveRight:
+		m.gravityCursor = min(m.gravityCursor+1, m.gravityGame.Shape.Cols-1)
+	case input.ActionSelect, input.ActionMoveDown:
+		if m.gravityGame.Status != game.StatusPlaying {
+			m.startGravity()
+			return nil
+		}
+		return m.dropGravityMark()
+	case input.ActionCycleBoard:
+		if err := m.config.NextGravityShape(); err != nil {
+			m.errorMessage = "Failed to change board: " + err.Error()
+			return nil
+		}
+		m.startGravity()
+		m.statusMessage = "Board changed to " + m.gravityGame.Shape.String()
+	case input.ActionReset:
+		m.startGravity()
+	case input.ActionBack:
+		m.cancelAIMove()
+		m.gravityDrop = nil
+		m.state = StateMainMenu
+		m.cursorPosition = [2]int{1, 0}
+	}
+	return nil
+}
+
+// dropGravityMark drops the human's mark into the cursor's column; the AI
+// replies once the mark has landed
+func (m *Model) dropGravityMark() tea.Cmd {
+	if m.gravityDrop != nil {
+		m.statusMessage = "Wait for the mark to land"
+		return nil
+	}
+	if m.aiThinking() {
+		m.statusMessage = "Wait for the AI to move"
+		return nil
+	}
+	if m.gravityAIToMove() {
+		return nil
+	}
+	m.startDrop(m.gravityCursor)
+	return nil
+}
+
+// startDrop drops the current player's mark into col and starts it falling
+func (m *Model) startDrop(col int) {
+	player := m.gravityGame.CurrentPlayer
+	landed, err := m.gravityGame.Drop(col)
+	if err != nil {
+		m.errorMessage = err.Error()
+		m.audioManager.PlaySound(audio.SoundError)
+		return
+	}
+	m.gravityDrop = &dropAnimation{landed: landed, player: player}
+}
+
+// advanceDrop moves the falling mark down a row on each animation tick. When
+// it lands the game is scored if it is over, or the AI starts thinking if it
+// is the AI's turn.
+func (m *Model) advanceDrop() tea.Cmd {
+	drop := m.gravityDrop
+	if drop == nil || m.state != StateGravity {
+		return nil
+	}
+	if drop.row < drop.landed.Row {
+		drop.row++
+		return nil
+	}
+
+	m.gravityDrop = nil
+	m.audioManager.PlaySound(audio.SoundMove)
+	switch {
+	case m.gravityGame.Status != game.StatusPlaying:
+		m.finishGravityGame()
+	case m.gravityAIToMove():
+		return m.startGravityAIMove()
+	}
+	return nil
+}
+
+// gravityAIToMove reports whether the AI drops the next mark or makes the
+// pending swap decision
+func (m *Model) gravityAIToMove() bool {
+	g := m.gravityGame
+	if g.Status != game.StatusPlaying {
+		return false
+	}
+	seat, _, pending := g.SwapPending()
+	if !pending {
+		seat = g.SeatToMove()
+	}
+	return seat == gravityAISeat
+}
+
+// chooseGravitySwap makes the pending swap decision, gives the AI the color
+// of its seat, and starts the AI if the next mark is its own
+func (m *Model) chooseGravitySwap(choice game.SwapChoice) tea.Cmd {
+	seat, _, _ := m.gravityGame.SwapPending()
+	if err := m.gravityGame.ChooseSwap(choice); err != nil {
+		m.errorMessage = err.Error()
+		m.audioManager.PlaySound(audio.SoundError)
+		return nil
+	}
+	m.gravityAI.SetPlayer(m.gravityGame.Opening.ColorOf(gravityAISeat))
+
+	name := "You"
+	if seat == gravityAISeat {
+		name = "The AI"
+	}
+	m.statusMessage = fmt.Sprintf("%s chose to %s", name, choice)
+	if m.gravityAIToMove() {
+		return m.startGravityAIMove()
+	}
+	return nil
+}
+
+// startGravityAIMove searches for the AI's column on a copy of the board in a tea.Cmd
+func (m *Model) startGravityAIMove() tea.Cmd {
+	m.cancelAIMove()
+	ctx, cancel := context.WithTimeout(context.Background(), aiMoveBudget)
+	m.aiCancel = cancel
+	m.aiSearch++
+	m.aiThinkingSince = time.Now()
+
+	search, player, position := m.aiSearch, m.gravityAI, m.gravityGame.Clone()
+	if _, _, pending := position.SwapPending(); pending {
+		return func() tea.Msg {
+			choice, err := player.DecideSwap(ctx, position)
+			return gravityMoveMsg{search: search, swap: &choice, err: err}
+		}
+	}
+	return func() tea.Msg {
+		col, err := player.Move(ctx, position)
+		return gravityMoveMsg{search: search, col: col, err: err}
+	}
+}
+
+// applyGravityMove drops the AI's mark or makes its swap decision, ignoring
+// results of searches cancelled by a new game or by leaving
+func (m *Model) applyGravityMove(msg gravityMoveMsg) tea.Cmd {
+	if msg.search != m.aiSearch || !m.aiThinking() {
+		return nil
+	}
+	m.cancelAIMove()
+	if m.gravityGame == nil || m.gravityGame.Status != game.StatusPlaying {
+		return nil
+	}
+	if msg.err != nil {
+		m.errorMessage = "AI move failed: " + msg.err.Error()
+		return nil
+	}
+	if msg.swap != nil {
+		return m.chooseGravitySwap(*msg.swap)
+	}
+	m.startDrop(msg.col)
+	if m.gravityDrop != nil {
+		m.statusMessage = fmt.Sprintf("AI dropped in column %d", msg.col+1)
+	}
+	return nil
+}
+
+// finishGravityGame plays the result, pulses the winning line and records
+// the result under the board's name
+func (m *Model) finishGravityGame() {
+	if m.gravityGame.Status == game.StatusWon {
+		m.audioManager.PlaySound(audio.SoundWin)
+		m.pulseWinLine()
+	} else {
+		m.audioManager.PlaySound(audio.SoundDraw)
+	}
+	// The board's record counts the human as X, whichever side they ended up on
+	winner := m.gravityGame.Winner
+	if m.gravityGame.Opening.Swapped && winner != game.Empty {
+		winner = opponentOf(winner)
+	}
+	if err := m.persistManager.UpdateGravityScore(m.gravityGame.Shape.Name(), winner); err != nil {
+		m.errorMessage = "Failed to save score: " + err.Error()
+	}
+}
+
+// gravityBoardWidth is the width of the rendered board in cells of the terminal
+func (m *Model) gravityBoardWidth() int {
+	return m.gravityGame.Shape.Cols*gravityCellWidth + 1
+}
+
+// gravityColumnAt maps a mouse click to a column of the board, which is
+// centered below one line of padding and the row of column numbers
+func (m *Model) gravityColumnAt(x, y int) (int, bool) {
+	left := (m.width - m.gravityBoardWidth()) / 2
+	if x < left || y < 1 || y > m.gravityGame.Shape.Rows+2 {
+		return 0, false
+	}
+	col := (x - left) / gravityCellWidth
+	return col, col < m.gravityGame.Shape.Cols
+}
+
+// renderGravityScreen draws the board with the falling mark and the game status below
+func (m *Model) renderGravityScreen() string {
+	g := m.gravityGame
+	shape := g.Shape
+	drop := m.gravityDrop
+	winning := map[game.Position]bool{}
+	if drop == nil {
+		// The winning line lights up once the last mark has landed
+		for _, p := range g.WinningLine() {
+			winning[p] = true
+		}
+	}
+
+	header := ""
+	for col := 0; col < shape.Cols; col++ {
+		label := fmt.Sprintf("  %d ", col+1)
+		if col == m.gravityCursor && g.Status == game.StatusPlaying {
+			label = m.gradientManager.ApplyToText("  ▼ ")
+		}
+		header += label
+	}
+	lines := []string{header + " "}
+
+	for row := 0; row < shape.Rows; row++ {
+		line := ""
+		for col := 0; col < shape.Cols; col++ {
+			p := game.Position{Row: row, Col: col}
+			mark := g.At(row, col)
+			if drop != nil {
+				switch {
+				case col == drop.landed.Col && row == drop.row:
+					mark = drop.player
+				case p == drop.landed:
+					mark = game.Empty
+				}
+			}
+			symbol := string(mark)
+			if mark == game.Empty {
+				symbol = "·"
+			}
+			cell := " " + symbol + " "
+			switch {
+			case winning[p]:
+				cell = m.renderLineCell(cell)
+			case mark != game.Empty:
+				cell = lipgloss.NewStyle().Bold(true).Render(cell)
+			}
+			line += "│" + cell
+		}
+		lines = append(lines, line+"│")
+	}
+	lines = append(lines, "└"+strings.Repeat("───┴", shape.Cols-1)+"───┘")
+	board := strings.Join(lines, "\n")
+
+	panel := m.gradientManager.ApplyToText("🔻 GRAVITY") + " — " + shape.String() + "\n"
+	switch {
+	case drop != nil:
+		panel += "\n"
+	case g.Status == game.StatusWon && g.Winner == m.gravityAI.GetPlayer():
+		panel += m.gradientManager.ApplyToText("The AI wins!") + "\n"
+	case g.Status == game.StatusWon:
+		panel += m.gradientManager.ApplyToText("You win!") + "\n"
+	case g.Status == game.StatusDraw:
+		panel += m.gradientManager.ApplyToText("Draw!") + "\n"
+	case m.aiThinking():
+		elapsed := time.Since(m.aiThinkingSince).Seconds()
+		panel += m.gradientManager.ApplyToText(fmt.Sprintf("🤔 Thinking… %.1fs", elapsed)) + "\n"
+	default:
+		panel += fmt.Sprintf("You play %s • Column %d • Move %d\n", g.Opening.ColorOf(gravityAISeat.Other()), m.gravityCursor+1, len(g.MoveHistory)+1)
+	}
+	panel += fmt.Sprintf("AI looks %d moves ahead\n", m.gravityAI.GetDepth())
+	aiSeat := gravityAISeat
+	panel += m.renderSeats(g, g.Opening, g.Status == game.StatusPlaying && drop == nil, &aiSeat)
+
+	if m.statusMessage != "" {
+		panel += "\n" + m.statusMessage + "\n"
+	}
+	if m.errorMessage != "" {
+		panel += "\n" + lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Render(m.errorMessage) + "\n"
+	}
+
+	help := fmt.Sprintf("←→ Column • 1-%d or Enter Drop • b Board • r New game • esc Back", shape.Cols)
+	if g.Status != game.StatusPlaying {
+		help = "Enter/r New game • b Board • esc Back"
+	}
+	panel += "\n" + lipgloss.NewStyle().Faint(true).Render(help)
+
+	style := lipgloss.NewStyle().
+		Align(lipgloss.Center).
+		PaddingTop(1).
+		Width(m.width).
+		Height(m.height)
+
+	return style.Render(lipgloss.JoinVertical(lipgloss.Center, board, "", panel))
+}
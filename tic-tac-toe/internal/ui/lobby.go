@@ -0,0 +1,355 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"tic-tac-toe/internal/audio"
+	"tic-tac-toe/internal/game"
+	"tic-tac-toe/internal/input"
+	"tic-tac-toe/internal/lobby"
+	"tic-tac-toe/internal/persistence"
+)
+
+// chatPaneLines is how many recent chat messages the game screen shows
+const chatPaneLines = 8
+
+// lobbyEventMsg carries a message received from the lobby server
+type lobbyEventMsg struct {
+	message lobby.Message
+	closed  bool
+}
+
+// lobbyPlayerName returns the name used to register with the lobby
+func lobbyPlayerName() string {
+	if name := os.Getenv("USER"); name != "" {
+		return name
+	}
+	return "player"
+}
+
+// connectLobby connects to the lobby, hosting one in-process if none is running
+func (m *Model) connectLobby() tea.Cmd {
+	if m.lobbyClient != nil {
+		return m.requestLobby()
+	}
+
+	client, err := lobby.Dial(lobby.DefaultAddress, lobbyPlayerName(), lobby.DefaultRating)
+	if err != nil && m.lobbyServer == nil {
+		// Nobody is hosting yet, so this instance becomes the lobby server
+		server := lobby.NewServer(lobby.DefaultServerConfig())
+		if listenErr := server.Listen(lobby.DefaultAddress); listenErr == nil {
+			m.lobbyServer = server
+			m.statusMessage = "Hosting lobby on " + server.Addr()
+			client, err = lobby.Dial(lobby.DefaultAddress, lobbyPlayerName(), lobby.DefaultRating)
+		}
+	}
+	if err != nil {
+		// The name may already be online from another local instance
+		name := fmt.Sprintf("%s-%d", lobbyPlayerName(), os.Getpid())
+		client, err = lobby.Dial(lobby.DefaultAddress, name, lobby.DefaultRating)
+	}
+	if err != nil {
+		m.errorMessage = "Lobby unavailable: " + err.Error()
+		return nil
+	}
+
+	m.lobbyClient = client
+	return tea.Batch(m.listenLobby(), m.requestLobby())
+}
+
+// requestLobby asks the server for a fresh roster
+func (m *Model) requestLobby() tea.Cmd {
+	if m.lobbyClient == nil {
+		return nil
+	}
+	if err := m.lobbyClient.RequestLobby(); err != nil {
+		m.errorMessage = err.Error()
+	}
+	return nil
+}
+
+// listenLobby waits for the next lobby message
+func (m *Model) listenLobby() tea.Cmd {
+	client := m.lobbyClient
+	return func() tea.Msg {
+		msg, ok := <-client.Events()
+		if !ok {
+			return lobbyEventMsg{closed: true}
+		}
+		return lobbyEventMsg{message: msg}
+	}
+}
+
+// handleLobbyEvent applies a lobby message to the model
+func (m *Model) handleLobbyEvent(msg lobbyEventMsg) tea.Cmd {
+	if msg.closed {
+		m.lobbyClient = nil
+		m.lobbyQueued = false
+		m.errorMessage = "Disconnected from lobby"
+		return nil
+	}
+
+	event := msg.message
+	switch event.Type {
+	case lobby.MsgLobby:
+		m.lobbyUsers = event.Users
+		m.lobbyGames = event.Games
+	case lobby.MsgQueued:
+		m.lobbyQueued = true
+		m.statusMessage = "Searching for an opponent in " + event.Variant + "/" + event.TimeControl + "..."
+	case lobby.MsgPaired:
+		m.lobbyQueued = false
+		m.netGame = event.Pairing
+		m.chatLog = nil
+		// Online games follow the server's state rather than local events
+		m.game.SetEvents(nil)
+		m.game.Reset()
+		m.game.SetMode(game.PlayerVsNetwork)
+		m.game.SetRules(game.ClassicRules) // The server plays classic rules
+		m.game.SetSwapRule(game.NoSwap)
+		m.state = StateGame
+		m.cursorPosition = [2]int{1, 1}
+		m.statusMessage = "Paired with " + event.Pairing.Opponent
+	case lobby.MsgState:
+		m.applyNetworkState(event)
+	case lobby.MsgChat:
+		if m.netGame != nil && event.GameID == m.netGame.GameID && event.Chat != nil {
+			m.chatLog = append(m.chatLog, *event.Chat)
+		}
+	case lobby.MsgError:
+		m.errorMessage = event.Error
+	}
+
+	return m.listenLobby()
+}
+
+// applyNetworkState replaces the local game with the server's authoritative state
+func (m *Model) applyNetworkState(event lobby.Message) {
+	if m.netGame == nil || event.GameID != m.netGame.GameID || event.State == nil {
+		return
+	}
+
+	moves := len(m.game.GetMoveHistory())
+	m.game = event.State.Restore()
+	m.game.SetMode(game.PlayerVsNetwork)
+	// The server pauses the clock it sends; run it locally for display
+	m.game.StartClock(time.Now())
+
+	if len(m.game.GetMoveHistory()) > moves {
+		m.audioManager.PlaySound(audio.SoundMove)
+	}
+
+	if m.game.GetStatus() != game.StatusPlaying {
+		if m.inputHandler.GetMode() == input.ModeText {
+			m.inputHandler.EndTextEntry()
+		}
+		m.archiveNetworkGame()
+
+		if m.game.GetStatus() == game.StatusWon {
+			m.audioManager.PlaySound(audio.SoundWin)
+		} else {
+			m.audioManager.PlaySound(audio.SoundDraw)
+		}
+		m.state = StateGameOver
+	}
+}
+
+// archiveNetworkGame stores the finished online game and its conversation
+func (m *Model) archiveNetworkGame() {
+	playerX, playerO := m.lobbyClient.Name(), m.netGame.Opponent
+	if m.netGame.You == game.PlayerO {
+		playerX, playerO = playerO, playerX
+	}
+
+	m.archiveGame(persistence.ArchivedGame{
+		ID:      m.netGame.GameID,
+		Mode:    int(game.PlayerVsNetwork),
+		PlayerX: playerX,
+		PlayerO: playerO,
+		Chat:    m.chatLog,
+	})
+}
+
+// openChat starts typing a chat message in an online game
+func (m *Model) openChat() {
+	if m.game.GetMode() != game.PlayerVsNetwork || m.netGame == nil {
+		m.statusMessage = "Chat is only available in online games"
+		return
+	}
+	m.inputHandler.BeginTextEntry(lobby.MaxChatLength)
+}
+
+// handleChatInput handles keys while the chat box is open
+func (m *Model) handleChatInput(action input.KeybindingAction) tea.Cmd {
+	switch action {
+	case input.ActionTextSubmit:
+		text := strings.TrimSpace(m.inputHandler.EndTextEntry())
+		if text == "" || m.lobbyClient == nil || m.netGame == nil {
+			return nil
+		}
+		if err := m.lobbyClient.Chat(m.netGame.GameID, text); err != nil {
+			m.errorMessage = err.Error()
+		}
+	case input.ActionTextCancel:
+		m.inputHandler.EndTextEntry()
+	}
+	return nil
+}
+
+// sendEmote sends one of the quick emotes
+func (m *Model) sendEmote(index int) {
+	if m.game.GetMode() != game.PlayerVsNetwork || m.lobbyClient == nil || m.netGame == nil {
+		m.statusMessage = "Emotes are only available in online games"
+		return
+	}
+	if err := m.lobbyClient.Emote(m.netGame.GameID, index); err != nil {
+		m.errorMessage = err.Error()
+	}
+}
+
+// renderChatPane shows recent chat messages and the chat input line
+func (m *Model) renderChatPane() string {
+	pane := "CHAT\n"
+	pane += "────\n"
+
+	start := 0
+	if len(m.chatLog) > chatPaneLines {
+		start = len(m.chatLog) - chatPaneLines
+	}
+	if len(m.chatLog) == 0 {
+		pane += lipgloss.NewStyle().Faint(true).Render("No messages yet") + "\n"
+	}
+	for _, line := range m.chatLog[start:] {
+		if line.Emote {
+			pane += fmt.Sprintf("* %s %s\n", line.From, line.Text)
+		} else {
+			pane += fmt.Sprintf("%s: %s\n", line.From, line.Text)
+		}
+	}
+
+	if m.inputHandler.GetMode() == input.ModeText {
+		pane += m.gradientManager.ApplyToText("> "+m.inputHandler.GetText()+"_") + "\n"
+		pane += lipgloss.NewStyle().Faint(true).Render("Enter send • esc cancel") + "\n"
+	} else {
+		pane += lipgloss.NewStyle().Faint(true).Render("/ chat • F1-F4 emotes") + "\n"
+	}
+
+	return pane
+}
+
+// sendNetworkMove submits the local player's move to the lobby server
+func (m *Model) sendNetworkMove(row, col int) tea.Cmd {
+	if m.lobbyClient == nil || m.netGame == nil {
+		m.errorMessage = "Not connected to the lobby"
+		return nil
+	}
+	if m.game.GetCurrentPlayer() != m.netGame.You {
+		m.statusMessage = "Waiting for " + m.netGame.Opponent + "..."
+		return nil
+	}
+	if !m.game.IsValidMove(row, col) {
+		m.errorMessage = fmt.Sprintf("position (%d, %d) is already occupied", row, col)
+		m.audioManager.PlaySound(audio.SoundError)
+		return nil
+	}
+
+	if err := m.lobbyClient.Move(m.netGame.GameID, row, col); err != nil {
+		m.errorMessage = err.Error()
+	}
+	return nil
+}
+
+// handleLobbyInput handles keys on the lobby screen
+func (m *Model) handleLobbyInput(action input.KeybindingAction) tea.Cmd {
+	switch action {
+	case input.ActionSelect:
+		if m.lobbyClient == nil {
+			return m.connectLobby()
+		}
+		if m.lobbyQueued {
+			m.lobbyQueued = false
+			if err := m.lobbyClient.LeaveQueue(); err != nil {
+				m.errorMessage = err.Error()
+			}
+			m.statusMessage = "Left the queue"
+			return nil
+		}
+		if err := m.lobbyClient.JoinQueue(lobby.DefaultVariant, m.config.GetTimeControl().Name); err != nil {
+			m.errorMessage = err.Error()
+		}
+	case input.ActionReset:
+		return m.requestLobby()
+	case input.ActionBack:
+		if m.lobbyClient != nil && m.lobbyQueued {
+			m.lobbyClient.LeaveQueue()
+			m.lobbyQueued = false
+		}
+		m.state = StateMainMenu
+		m.cursorPosition = [2]int{1, 0}
+	}
+	return nil
+}
+
+// renderLobbyScreen shows online users and active games
+func (m *Model) renderLobbyScreen() string {
+	content := m.gradientManager.ApplyToText("🌐 ONLINE LOBBY") + "\n\n"
+
+	if m.lobbyClient == nil {
+		content += "Not connected\n"
+	} else {
+		content += fmt.Sprintf("Connected as %s (%d)\n", m.lobbyClient.Name(), m.lobbyClient.Rating())
+		if m.lobbyServer != nil {
+			content += "Hosting on " + m.lobbyServer.Addr() + "\n"
+		}
+		if m.lobbyQueued {
+			content += m.gradientManager.ApplyToText("Searching for an opponent...") + "\n"
+		}
+	}
+	content += "\n"
+
+	content += m.gradientManager.ApplyToText("👥 ONLINE USERS") + "\n"
+	content += "───────────────\n"
+	if len(m.lobbyUsers) == 0 {
+		content += "Nobody online\n"
+	}
+	for _, user := range m.lobbyUsers {
+		line := fmt.Sprintf("%-16s %4d  %s", user.Name, user.Rating, user.Status)
+		if user.Queue != "" {
+			line += " (" + user.Queue + ")"
+		}
+		content += line + "\n"
+	}
+	content += "\n"
+
+	content += m.gradientManager.ApplyToText("🎲 ACTIVE GAMES") + "\n"
+	content += "───────────────\n"
+	if len(m.lobbyGames) == 0 {
+		content += "No games in progress\n"
+	}
+	for _, info := range m.lobbyGames {
+		content += fmt.Sprintf("%s  %s vs %s  [%s/%s]\n", info.ID, info.PlayerX, info.PlayerO, info.Variant, info.TimeControl)
+	}
+
+	if m.statusMessage != "" {
+		content += "\n" + m.statusMessage + "\n"
+	}
+	if m.errorMessage != "" {
+		content += "\n" + lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Render(m.errorMessage) + "\n"
+	}
+
+	content += "\n" + lipgloss.NewStyle().Faint(true).Render("Enter - Join/leave queue • r - Refresh • esc - Back to menu")
+
+	style := lipgloss.NewStyle().
+		Align(lipgloss.Center).
+		Width(m.width).
+		Height(m.height).
+		Padding(2)
+
+	return style.Render(content)
+}
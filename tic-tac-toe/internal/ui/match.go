@@ -0,0 +1,223 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"tic-tac-toe/internal/game"
+	"tic-tac-toe/internal/input"
+	"tic-tac-toe/internal/match"
+	"tic-tac-toe/internal/persistence"
+)
+
+// startMatch begins a local game from the menu: the first game of a match
+// under the configured format, or a lone game when no format is set
+func (m *Model) startMatch(mode game.GameMode) tea.Cmd {
+	m.match = nil
+	m.tournamentPlay = nil
+	if format := m.config.GetMatchFormat(); !format.IsSingle() {
+		first, second := "Player A", "Player B"
+		if mode == game.PlayerVsAI {
+			first, second = "You", m.aiName()
+			if m.aiHomeSeat == game.FirstSeat {
+				first, second = second, first
+			}
+		}
+		m.beginMatch(match.New(format, first, second))
+	}
+	return m.startLocalGame(mode)
+}
+
+// beginMatch makes the match the one the next local games belong to
+func (m *Model) beginMatch(mt *match.Match) {
+	m.match = mt
+	m.matchID = fmt.Sprintf("%d", time.Now().UnixNano())
+}
+
+// inMatch reports whether the local game is part of a match
+func (m *Model) inMatch() bool {
+	mode := m.game.GetMode()
+	return m.match != nil && (mode == game.PlayerVsPlayer || mode == game.PlayerVsAI)
+}
+
+// seatMatchPlayers puts the match's starter for the next game in the first
+// seat, moving the AI with its player, so the first move alternates
+func (m *Model) seatMatchPlayers() {
+	m.aiSeat = m.aiHomeSeat
+	if m.match == nil {
+		return
+	}
+	starter := m.match.Starter()
+	m.matchSeats = [2]int{starter, 1 - starter}
+	if starter == 1 {
+		m.aiSeat = m.aiHomeSeat.Other()
+	}
+}
+
+// matchPlayerOf names the match player who plays the mark in the current game
+func (m *Model) matchPlayerOf(player game.Player) string {
+	return m.match.Players[m.matchSeats[m.game.Opening.SeatOf(player)]]
+}
+
+// recordMatchGame adds a finished game to its match, and records the match
+// once it is decided
+func (m *Model) recordMatchGame(event game.Event) {
+	if (event.Type != game.EventGameWon && event.Type != game.EventGameDrawn) || !m.inMatch() {
+		return
+	}
+	winner := match.Draw
+	if event.Type == game.EventGameWon {
+		winner = m.matchSeats[m.game.Opening.SeatOf(event.Player)]
+	}
+	if err := m.match.Record(winner); err != nil {
+		m.errorMessage = "Failed to record the match game: " + err.Error()
+		return
+	}
+	if m.match.Over() {
+		m.finishMatch()
+	}
+}
+
+// finishMatch saves the decided match to the stats and the match archive,
+// and moves its winner on in the tournament it belongs to
+func (m *Model) finishMatch() {
+	record := persistence.MatchRecord{
+		ID:    m.matchID,
+		Mode:  int(m.game.GetMode()),
+		Match: *m.match,
+	}
+	if m.tournamentPlay != nil {
+		record.Tournament = m.tournamentID
+	}
+	if err := m.persistManager.RecordMatch(record); err != nil {
+		m.errorMessage = "Failed to save match: " + err.Error()
+	}
+	if m.tournamentPlay != nil {
+		m.reportTournamentMatch()
+	}
+}
+
+// leaveMatch leaves the local game and the match it belongs to, going back
+// to the tournament bracket for tournament matches
+func (m *Model) leaveMatch() {
+	m.cancelAIMove()
+	if m.tournamentPlay != nil {
+		m.state = StateTournament
+	} else {
+		m.state = StateMainMenu
+		m.cursorPosition = [2]int{1, 0}
+	}
+	m.match = nil
+	m.tournamentPlay = nil
+}
+
+// nextMatchGame starts the match's next game, or shows the summary once the
+// match is decided
+func (m *Model) nextMatchGame() tea.Cmd {
+	if m.match.Over() {
+		m.state = StateMatchSummary
+		return nil
+	}
+	return m.startLocalGame(m.game.GetMode())
+}
+
+// renderMatchStatus shows the match's format, score and who plays which mark
+func (m *Model) renderMatchStatus() string {
+	if !m.inMatch() {
+		return ""
+	}
+	games := len(m.match.Results)
+	if m.game.GetStatus() == game.StatusPlaying {
+		games++
+	}
+	status := fmt.Sprintf("Match: %s • Game %d\n", m.match.Format, games)
+	status += "Score: " + m.gradientManager.ApplyToText(m.match.Summary()) + "\n"
+	status += fmt.Sprintf("%s plays X, %s plays O\n", m.matchPlayerOf(game.PlayerX), m.matchPlayerOf(game.PlayerO))
+	return status
+}
+
+// renderMatchProgress tells the game-over screen where the match stands
+func (m *Model) renderMatchProgress() string {
+	progress := m.match.Format.String() + ": " + m.match.Summary() + "\n\n"
+	if m.match.Over() {
+		progress += "Press Enter to see the match result\n"
+	} else {
+		progress += fmt.Sprintf("Press Enter for game %d\n", len(m.match.Results)+1)
+	}
+	if m.tournamentPlay != nil {
+		progress += "Press 'esc' to leave the match for the bracket\n"
+	} else {
+		progress += "Press 'esc' to leave the match\n"
+	}
+	return progress
+}
+
+// renderMatchSummary shows the result of a decided match and its games
+func (m *Model) renderMatchSummary() string {
+	content := m.gradientManager.ApplyToText("🏆 MATCH OVER 🏆") + "\n\n"
+	if winner, ok := m.match.Winner(); ok {
+		content += m.gradientManager.ApplyToText(m.match.Players[winner]+" wins the match!") + "\n"
+	} else {
+		content += m.gradientManager.ApplyToText("The match is drawn") + "\n"
+	}
+	content += m.match.Format.String() + ": " + m.match.Summary() + "\n\n"
+
+	for i, winner := range m.match.Results {
+		result := "Draw"
+		if winner != match.Draw {
+			result = m.match.Players[winner] + " won"
+		}
+		content += fmt.Sprintf("Game %d: %s moved first • %s\n", i+1, m.match.Players[i%2], result)
+	}
+
+	if m.tournamentPlay != nil {
+		content += "\nPress Enter to go back to the bracket\n"
+	} else {
+		content += "\nPress 'r' for a new match\n"
+		content += "Press 'esc' for main menu\n"
+	}
+
+	if m.errorMessage != "" {
+		content += "\n" + lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Render(m.errorMessage)
+	}
+
+	style := lipgloss.NewStyle().
+		Align(lipgloss.Center).
+		Width(m.width).
+		Height(m.height).
+		Padding(2)
+
+	return style.Render(content)
+}
+
+// handleMatchSummaryInput handles keys on the match summary screen
+func (m *Model) handleMatchSummaryInput(action input.KeybindingAction) tea.Cmd {
+	switch action {
+	case input.ActionSelect, input.ActionBack:
+		m.leaveMatch()
+	case input.ActionReset:
+		if m.tournamentPlay == nil {
+			return m.startMatch(m.game.GetMode())
+		}
+	}
+	return nil
+}
+
+// rankedNames orders the players of a tally by count, most first, then by name
+func rankedNames(tally map[string]int) []string {
+	names := make([]string, 0, len(tally))
+	for name := range tally {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if tally[names[i]] != tally[names[j]] {
+			return tally[names[i]] > tally[names[j]]
+		}
+		return names[i] < names[j]
+	})
+	return names
+}
@@ -0,0 +1,287 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"tic-tac-toe/internal/audio"
+	"tic-tac-toe/internal/game"
+	"tic-tac-toe/internal/input"
+	"tic-tac-toe/internal/multi"
+)
+
+// multiMoveMsg carries the result of a background multi-player AI search
+type multiMoveMsg struct {
+	search int // The search that produced it; older searches were cancelled
+	player game.Player
+	move   game.Position
+	err    error
+}
+
+// playerColors tell the players of a multi-player game apart on the board
+var playerColors = map[game.Player]lipgloss.Color{
+	game.PlayerX:        lipgloss.Color("203"),
+	game.PlayerO:        lipgloss.Color("75"),
+	game.PlayerTriangle: lipgloss.Color("114"),
+	game.PlayerSquare:   lipgloss.Color("221"),
+}
+
+// multiHuman is the mark the human plays in multi-player games; the AIs
+// play the others
+const multiHuman = game.PlayerX
+
+// multiHistoryLength is how many of the latest moves the panel lists
+const multiHistoryLength = 6
+
+// startMulti opens a new multi-player game of the configured shape, with an
+// AI at the configured strength and search for every player but the human
+func (m *Model) startMulti() {
+	m.cancelAIMove()
+	shape := m.config.GetMultiShape()
+	m.multiGame = multi.New(shape)
+	m.multiAIs = map[game.Player]*multi.AI{}
+	for i, player := range m.multiGame.Players() {
+		if player == multiHuman {
+			continue
+		}
+		m.multiAIs[player] = multi.NewAI(player, shape.Players, m.config.GetAIStrength(), m.config.GetMultiSearch())
+		if m.seed != 0 {
+			m.multiAIs[player].SetSeed(m.seed + int64(i))
+		}
+	}
+	m.multiCursor = game.Position{Row: shape.Rows / 2, Col: shape.Cols / 2}
+	m.winLine = nil
+	m.state = StateMulti
+}
+
+// handleMultiInput handles keys in a multi-player game
+func (m *Model) handleMultiInput(action input.KeybindingAction) tea.Cmd {
+	shape := m.multiGame.Shape
+	cursor := &m.multiCursor
+	switch action {
+	case input.ActionMoveUp:
+		cursor.Row = max(cursor.Row-1, 0)
+	case input.ActionMoveDown:
+		cursor.Row = min(cursor.Row+1, shape.Rows-1)
+	case input.ActionMoveLeft:
+		cursor.Col = max(cursor.Col-1, 0)
+	case input.ActionMoveRight:
+		cursor.Col = min(cursor.Col+1, shape.Cols-1)
+	case input.ActionSelect:
+		if m.multiGame.Status != game.StatusPlaying {
+			m.startMulti()
+			return nil
+		}
+		return m.makeMultiMove()
+	case input.ActionCycleBoard:
+		if err := m.config.NextMultiShape(); err != nil {
+			m.errorMessage = "Failed to change game: " + err.Error()
+			return nil
+		}
+		m.startMulti()
+		m.statusMessage = "Game changed to " + m.multiGame.Shape.String()
+	case input.ActionCycleSearch:
+		if err := m.config.NextMultiSearch(); err != nil {
+			m.errorMessage = "Failed to change AI search: " + err.Error()
+			return nil
+		}
+		m.startMulti()
+		m.statusMessage = "AI search changed to " + m.config.GetMultiSearch().Name()
+	case input.ActionReset:
+		m.startMulti()
+	case input.ActionBack:
+		m.cancelAIMove()
+		m.state = StateMainMenu
+		m.cursorPosition = [2]int{1, 0}
+	}
+	return nil
+}
+
+// makeMultiMove places the human's mark under the cursor and starts the
+// next player's AI
+func (m *Model) makeMultiMove() tea.Cmd {
+	if m.aiThinking() || m.multiGame.CurrentPlayer != multiHuman {
+		m.statusMessage = "Wait for the AIs to move"
+		return nil
+	}
+	if err := m.multiGame.Place(m.multiCursor.Row, m.multiCursor.Col); err != nil {
+		m.errorMessage = err.Error()
+		m.audioManager.PlaySound(audio.SoundError)
+		return nil
+	}
+	return m.afterMultiMove()
+}
+
+// afterMultiMove scores a finished game, or starts the AI whose turn it is
+func (m *Model) afterMultiMove() tea.Cmd {
+	m.audioManager.PlaySound(audio.SoundMove)
+	if m.multiGame.Status != game.StatusPlaying {
+		m.finishMulti()
+		return nil
+	}
+	if m.multiGame.CurrentPlayer != multiHuman {
+		return m.startMultiAIMove()
+	}
+	return nil
+}
+
+// startMultiAIMove searches for the current AI's move on a copy of the board in a tea.Cmd
+func (m *Model) startMultiAIMove() tea.Cmd {
+	m.cancelAIMove()
+	ctx, cancel := context.WithTimeout(context.Background(), aiMoveBudget)
+	m.aiCancel = cancel
+	m.aiSearch++
+	m.aiThinkingSince = time.Now()
+
+	search, position := m.aiSearch, m.multiGame.Clone()
+	player := m.multiAIs[position.CurrentPlayer]
+	return func() tea.Msg {
+		move, err := player.Move(ctx, position)
+		return multiMoveMsg{search: search, player: player.GetPlayer(), move: move, err: err}
+	}
+}
+
+// applyMultiMove plays the current AI's move and hands over to the next
+// AI, ignoring results of searches cancelled by a new game or by leaving
+func (m *Model) applyMultiMove(msg multiMoveMsg) tea.Cmd {
+	if msg.search != m.aiSearch || !m.aiThinking() {
+		return nil
+	}
+	m.cancelAIMove()
+	if m.multiGame == nil || m.multiGame.Status != game.StatusPlaying {
+		return nil
+	}
+	if msg.err != nil {
+		m.errorMessage = "AI move failed: " + msg.err.Error()
+		return nil
+	}
+	if err := m.multiGame.Place(msg.move.Row, msg.move.Col); err != nil {
+		m.errorMessage = "AI move error: " + err.Error()
+		m.audioManager.PlaySound(audio.SoundError)
+		return nil
+	}
+	m.statusMessage = fmt.Sprintf("%s played (%d,%d)", msg.player, msg.move.Row, msg.move.Col)
+	return m.afterMultiMove()
+}
+
+// finishMulti plays the result, pulses the winning line and records the
+// result under the game's name
+func (m *Model) finishMulti() {
+	if m.multiGame.Status == game.StatusWon {
+		m.audioManager.PlaySound(audio.SoundWin)
+		m.pulseWinLine()
+	} else {
+		m.audioManager.PlaySound(audio.SoundDraw)
+	}
+	if err := m.persistManager.UpdateMultiScore(m.multiGame.Shape.Name(), m.multiGame.Winner); err != nil {
+		m.errorMessage = "Failed to save score: " + err.Error()
+	}
+}
+
+// multiPlayerName names the player of a mark from the human's side
+func multiPlayerName(player game.Player) string {
+	if player == multiHuman {
+		return "You"
+	}
+	return "AI " + string(player)
+}
+
+// renderMark draws a player's mark in its color, or a dot for an empty cell
+func renderMark(player game.Player) string {
+	if player == game.Empty {
+		return "·"
+	}
+	return lipgloss.NewStyle().Bold(true).Foreground(playerColors[player]).Render(string(player))
+}
+
+// renderMultiScreen draws the board with the players, the latest moves and
+// the game status beside it
+func (m *Model) renderMultiScreen() string {
+	g := m.multiGame
+	winning := map[game.Position]bool{}
+	for _, p := range g.WinningLine() {
+		winning[p] = true
+	}
+
+	rows := make([]string, 0, g.Shape.Rows)
+	for row := 0; row < g.Shape.Rows; row++ {
+		var line strings.Builder
+		for col := 0; col < g.Shape.Cols; col++ {
+			p := game.Position{Row: row, Col: col}
+			mark := g.At(row, col)
+			switch {
+			case winning[p]:
+				line.WriteString(m.renderLineCell(" " + string(mark) + " "))
+			case p == m.multiCursor && g.Status == game.StatusPlaying:
+				line.WriteString("[" + renderMark(mark) + "]")
+			default:
+				line.WriteString(" " + renderMark(mark) + " ")
+			}
+		}
+		rows = append(rows, line.String())
+	}
+	board := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("39")).
+		Padding(0, 1).
+		Render(strings.Join(rows, "\n"))
+
+	players := ""
+	for _, player := range g.Players() {
+		marker := "  "
+		if player == g.CurrentPlayer && g.Status == game.StatusPlaying {
+			marker = "▶ "
+		}
+		players += marker + renderMark(player) + " " + multiPlayerName(player) + "\n"
+	}
+	history := "Moves:\n"
+	for i := max(len(g.MoveHistory)-multiHistoryLength, 0); i < len(g.MoveHistory); i++ {
+		move := g.MoveHistory[i]
+		history += fmt.Sprintf("%d. %s -> (%d,%d)\n", i+1, renderMark(g.MoverAt(i)), move.Row, move.Col)
+	}
+	side := lipgloss.NewStyle().PaddingLeft(3).Render(players + "\n" + history)
+
+	panel := m.gradientManager.ApplyToText("👥 MULTI-PLAYER") + " — " + g.Shape.String() + "\n"
+	switch {
+	case g.Status == game.StatusWon && g.Winner == multiHuman:
+		panel += m.gradientManager.ApplyToText("You win!") + "\n"
+	case g.Status == game.StatusWon:
+		panel += m.gradientManager.ApplyToText(multiPlayerName(g.Winner)+" wins!") + "\n"
+	case g.Status == game.StatusDraw:
+		panel += m.gradientManager.ApplyToText("Draw!") + "\n"
+	case m.aiThinking():
+		elapsed := time.Since(m.aiThinkingSince).Seconds()
+		panel += m.gradientManager.ApplyToText(fmt.Sprintf("🤔 AI %s thinking… %.1fs", g.CurrentPlayer, elapsed)) + "\n"
+	default:
+		panel += fmt.Sprintf("You play %s • Cursor (%d,%d) • Move %d\n", multiHuman, m.multiCursor.Row, m.multiCursor.Col, len(g.MoveHistory)+1)
+	}
+	opponent := m.multiAIs[game.PlayerO]
+	panel += fmt.Sprintf("AI search: %s, %d moves ahead\n", opponent.GetStrategy().Name(), opponent.GetDepth())
+
+	if m.statusMessage != "" {
+		panel += "\n" + m.statusMessage + "\n"
+	}
+	if m.errorMessage != "" {
+		panel += "\n" + lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Render(m.errorMessage) + "\n"
+	}
+
+	help := "↑↓←→ Move • Enter Place • b Game • i AI search • r New game • esc Back"
+	if g.Status != game.StatusPlaying {
+		help = "Enter/r New game • b Game • i AI search • esc Back"
+	}
+	panel += "\n" + lipgloss.NewStyle().Faint(true).Render(help)
+
+	style := lipgloss.NewStyle().
+		Align(lipgloss.Center).
+		PaddingTop(1).
+		Width(m.width).
+		Height(m.height)
+
+	top := lipgloss.JoinHorizontal(lipgloss.Top, board, side)
+	return style.Render(lipgloss.JoinVertical(lipgloss.Center, top, "", panel))
+}
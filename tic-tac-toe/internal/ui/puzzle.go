@@ -0,0 +1,172 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"tic-tac-toe/internal/ai"
+	"tic-tac-toe/internal/audio"
+	"tic-tac-toe/internal/game"
+	"tic-tac-toe/internal/input"
+	"tic-tac-toe/internal/persistence"
+	"tic-tac-toe/internal/puzzle"
+)
+
+// startPuzzles opens puzzle training, with the human's own mistakes ahead of
+// the solver's puzzles
+func (m *Model) startPuzzles() {
+	stats, err := m.persistManager.LoadPuzzleStats()
+	if err != nil {
+		m.errorMessage = "Failed to load puzzle stats: " + err.Error()
+		return
+	}
+	mistakes, err := m.persistManager.MistakePuzzles()
+	if err != nil {
+		m.errorMessage = "Failed to load your games: " + err.Error()
+	}
+
+	m.puzzleStats = stats
+	m.puzzles = append(mistakes, puzzle.Generate()...)
+	m.state = StatePuzzle
+	m.nextPuzzle()
+}
+
+// nextPuzzle sets up the next puzzle for the current streak
+func (m *Model) nextPuzzle() {
+	p, ok := puzzle.Choose(m.puzzles, m.puzzleStats.Streak, m.puzzleStats.IsSolved, m.puzzleRand)
+	if !ok {
+		m.errorMessage = "No puzzles available"
+		return
+	}
+	g, err := p.Game()
+	if err != nil {
+		m.errorMessage = err.Error()
+		return
+	}
+
+	m.currentPuzzle = &p
+	m.puzzleGame = g
+	m.puzzleAnswered = false
+	m.cursorPosition = [2]int{1, 1}
+	m.inputHandler.SetCursorPosition(1, 1)
+}
+
+// answerPuzzle plays the move under the cursor as the answer, or moves on
+// once the puzzle has been answered
+func (m *Model) answerPuzzle() tea.Cmd {
+	if m.currentPuzzle == nil {
+		return nil
+	}
+	if m.puzzleAnswered {
+		m.nextPuzzle()
+		return nil
+	}
+
+	p := *m.currentPuzzle
+	move := game.Position{Row: m.cursorPosition[0], Col: m.cursorPosition[1]}
+	explanation := ai.Explain(m.puzzleGame, p.Solution)
+	if err := m.puzzleGame.MakeMove(move.Row, move.Col); err != nil {
+		m.errorMessage = err.Error()
+		m.audioManager.PlaySound(audio.SoundError)
+		return nil
+	}
+
+	solved := p.Solves(move)
+	stats, err := m.persistManager.RecordPuzzleAttempt(p.ID, solved)
+	if err != nil {
+		m.errorMessage = "Failed to save puzzle stats: " + err.Error()
+	} else {
+		m.puzzleStats = stats
+	}
+	m.puzzleAnswered = true
+
+	if solved {
+		m.statusMessage = fmt.Sprintf("✓ Solved: %s", explanation)
+		m.audioManager.PlaySound(audio.SoundWin)
+	} else {
+		m.statusMessage = fmt.Sprintf("✗ Not quite. The answer was (%d,%d): %s",
+			p.Solution.Row, p.Solution.Col, explanation)
+		m.audioManager.PlaySound(audio.SoundError)
+	}
+	return nil
+}
+
+// handlePuzzleInput handles keys while solving a puzzle
+func (m *Model) handlePuzzleInput(action input.KeybindingAction) tea.Cmd {
+	switch action {
+	case input.ActionMoveUp, input.ActionMoveDown, input.ActionMoveLeft, input.ActionMoveRight:
+		x, y := m.inputHandler.MoveCursor(action)
+		m.cursorPosition = [2]int{y, x} // Store as [row, col] for consistent rendering
+	case input.ActionSelect:
+		return m.answerPuzzle()
+	case input.ActionNewGame:
+		m.nextPuzzle()
+	case input.ActionBack:
+		m.state = StateMainMenu
+		m.cursorPosition = [2]int{1, 0}
+	}
+	return nil
+}
+
+// renderPuzzleScreen shows the puzzle board with the task and streak
+func (m *Model) renderPuzzleScreen() string {
+	panel := m.gradientManager.ApplyToText("🧩 PUZZLE") + "\n"
+	panel += "─────────\n"
+
+	if p := m.currentPuzzle; p != nil {
+		panel += "Goal: " + m.gradientManager.ApplyToText(p.Goal()) + "\n"
+		panel += "Difficulty: " + strings.Repeat("★", p.Rating) + strings.Repeat("☆", puzzle.MaxRating-p.Rating) + "\n"
+		if p.Source == puzzle.SourceMistake {
+			panel += "From: one of your games\n"
+		}
+		if m.puzzleAnswered {
+			panel += "Theme: " + string(p.Theme) + "\n"
+		}
+	}
+
+	if stats := m.puzzleStats; stats != nil {
+		panel += fmt.Sprintf("\nStreak: %d (best %d)\n", stats.Streak, stats.BestStreak)
+		panel += fmt.Sprintf("Solved: %d of %d tries\n", stats.Solved, stats.Attempts)
+	}
+
+	if m.statusMessage != "" {
+		panel += "\n" + lipgloss.NewStyle().Width(46).Render(m.statusMessage) + "\n"
+	}
+	if m.errorMessage != "" {
+		panel += "\n" + lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Render(m.errorMessage) + "\n"
+	}
+
+	help := "↑↓←→ Move • Enter Play • n Skip • esc Back"
+	if m.puzzleAnswered {
+		help = "Enter Next puzzle • esc Back"
+	}
+	panel += "\n" + lipgloss.NewStyle().Faint(true).Render(help)
+
+	board := ""
+	if m.puzzleGame != nil {
+		board = m.renderBoard(m.puzzleGame.GetBoard())
+	}
+	main := lipgloss.JoinHorizontal(lipgloss.Top, board, "    ", panel)
+
+	style := lipgloss.NewStyle().
+		Align(lipgloss.Center).
+		PaddingTop(1).
+		Width(m.width).
+		Height(m.height)
+
+	return style.Render(main)
+}
+
+// solvedPuzzles counts the different puzzles solved at least once
+func solvedPuzzles(stats *persistence.PuzzleStats) int {
+	solved := 0
+	for id := range stats.Puzzles {
+		if stats.IsSolved(id) {
+			solved++
+		}
+	}
+	return solved
+}
@@ -0,0 +1,253 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"tic-tac-toe/internal/audio"
+	"tic-tac-toe/internal/game"
+	"tic-tac-toe/internal/input"
+	"tic-tac-toe/internal/quantum"
+)
+
+// quantumCellWidth is the width of a cell of the quantum board, which fits
+// three spooky marks to a line
+const quantumCellWidth = 10
+
+// startQuantum opens a new game of quantum tic-tac-toe for two players at one keyboard
+func (m *Model) startQuantum() {
+	m.cancelAIMove()
+	m.quantumGame = quantum.New()
+	m.inputHandler.ClearSource()
+	m.inputHandler.SetCursorPosition(1, 1)
+	m.cursorPosition = [2]int{1, 1}
+	m.state = StateQuantum
+}
+
+// handleQuantumInput handles keys in a quantum game; each spooky mark is
+// placed by picking two cells, and a measurement by picking one
+func (m *Model) handleQuantumInput(action input.KeybindingAction) tea.Cmd {
+	switch action {
+	case input.ActionMoveUp, input.ActionMoveDown, input.ActionMoveLeft, input.ActionMoveRight:
+		x, y := m.inputHandler.MoveCursor(action)
+		m.cursorPosition = [2]int{y, x}
+	case input.ActionSelect:
+		if m.quantumGame.Status != game.StatusPlaying {
+			m.startQuantum()
+			return nil
+		}
+		m.pickQuantumCell()
+	case input.ActionReset:
+		m.startQuantum()
+	case input.ActionBack:
+		if _, _, picked := m.inputHandler.GetSource(); picked {
+			m.inputHandler.ClearSource()
+			m.statusMessage = "Move cancelled"
+			return nil
+		}
+		m.state = StateMainMenu
+		m.cursorPosition = [2]int{1, 0}
+	}
+	return nil
+}
+
+// pickQuantumCell uses the cell under the cursor to measure the pending
+// cycle, or as the first or second cell of the next spooky mark
+func (m *Model) pickQuantumCell() {
+	g := m.quantumGame
+	cell := game.Position{Row: m.cursorPosition[0], Col: m.cursorPosition[1]}
+
+	if mark, ok := g.Measurement(); ok {
+		if err := g.Collapse(cell); err != nil {
+			m.errorMessage = err.Error()
+			m.audioManager.PlaySound(audio.SoundError)
+			return
+		}
+		m.statusMessage = fmt.Sprintf("%s collapsed into (%d,%d)", mark, cell.Row, cell.Col)
+		m.playQuantumResult()
+		return
+	}
+
+	first := cell
+	if len(g.FreeCells()) > 1 {
+		col, row, picked := m.inputHandler.GetSource()
+		switch {
+		case !picked:
+			if _, classical := g.Classical(cell); classical {
+				m.errorMessage = fmt.Sprintf("position (%d, %d) holds a classical mark", cell.Row, cell.Col)
+				m.audioManager.PlaySound(audio.SoundError)
+				return
+			}
+			m.inputHandler.SelectSource(cell.Col, cell.Row)
+			m.statusMessage = "Pick a second cell for the mark"
+			return
+		case row == cell.Row && col == cell.Col:
+			m.inputHandler.ClearSource()
+			m.statusMessage = "Move cancelled"
+			return
+		}
+		first = game.Position{Row: row, Col: col}
+	}
+
+	if err := g.Place(first, cell); err != nil {
+		m.errorMessage = err.Error()
+		m.audioManager.PlaySound(audio.SoundError)
+		return
+	}
+	m.inputHandler.ClearSource()
+	if mark, ok := g.Measurement(); ok {
+		m.statusMessage = fmt.Sprintf("%s closed a cycle: %s chooses where it collapses", mark, g.CurrentPlayer)
+	}
+	m.playQuantumResult()
+}
+
+// playQuantumResult plays the sound for a move or for the end of the game
+func (m *Model) playQuantumResult() {
+	switch m.quantumGame.Status {
+	case game.StatusWon:
+		m.audioManager.PlaySound(audio.SoundWin)
+	case game.StatusDraw:
+		m.audioManager.PlaySound(audio.SoundDraw)
+	default:
+		m.audioManager.PlaySound(audio.SoundMove)
+	}
+}
+
+// quantumCellLines writes the contents of a cell: a classical mark alone in
+// the middle, or the spooky marks in superposition three to a line, or a dot
+// when it is empty
+func (m *Model) quantumCellLines(cell game.Position) [3]string {
+	var lines [3]string
+	if mark, ok := m.quantumGame.Classical(cell); ok {
+		lines[1] = mark.String()
+		return lines
+	}
+	for i, mark := range m.quantumGame.Spooky(cell) {
+		if i%3 != 0 {
+			lines[i/3] += " "
+		}
+		lines[i/3] += strings.ToLower(mark.String())
+	}
+	if lines[0] == "" {
+		lines[1] = "·"
+	}
+	return lines
+}
+
+// renderQuantumScreen draws the board with the marks in each cell and the
+// game status below
+func (m *Model) renderQuantumScreen() string {
+	g := m.quantumGame
+	highlighted := map[game.Position]bool{}
+	if col, row, picked := m.inputHandler.GetSource(); picked {
+		highlighted[game.Position{Row: row, Col: col}] = true
+	}
+	measurement, measuring := g.Measurement()
+	if measuring {
+		for _, cell := range measurement.Cells {
+			highlighted[cell] = true
+		}
+	}
+	winning := map[game.Position]bool{}
+	for _, line := range g.Lines() {
+		for _, mark := range line {
+			for _, cell := range mark.Cells {
+				if classical, ok := g.Classical(cell); ok && classical == mark {
+					winning[cell] = true
+				}
+			}
+		}
+	}
+
+	separator := strings.Repeat("─", quantumCellWidth)
+	var lines []string
+	for row := 0; row < 3; row++ {
+		if row > 0 {
+			lines = append(lines, separator+"┼"+separator+"┼"+separator)
+		}
+		var cells [3][3]string
+		for col := 0; col < 3; col++ {
+			cell := game.Position{Row: row, Col: col}
+			_, classical := g.Classical(cell)
+			for i, text := range m.quantumCellLines(cell) {
+				text = lipgloss.PlaceHorizontal(quantumCellWidth, lipgloss.Center, text)
+				style := lipgloss.NewStyle()
+				if classical {
+					style = style.Bold(true)
+				}
+				if row == m.cursorPosition[0] && col == m.cursorPosition[1] && g.Status == game.StatusPlaying {
+					style = style.Reverse(true)
+				}
+				switch {
+				case winning[cell], highlighted[cell]:
+					text = m.gradientManager.ApplyToText(text)
+				case !classical:
+					style = style.Faint(true)
+				}
+				cells[col][i] = style.Render(text)
+			}
+		}
+		for i := 0; i < 3; i++ {
+			lines = append(lines, cells[0][i]+"│"+cells[1][i]+"│"+cells[2][i])
+		}
+	}
+	board := strings.Join(lines, "\n")
+
+	panel := m.gradientManager.ApplyToText("⚛️  QUANTUM TIC-TAC-TOE") + "\n"
+	switch {
+	case g.Status == game.StatusWon:
+		result := fmt.Sprintf("%s wins!", g.Winner)
+		if loser := opponentOf(g.Winner); g.Points[loser] > 0 {
+			result += fmt.Sprintf(" %s 1 • %s ½", g.Winner, loser)
+		}
+		panel += m.gradientManager.ApplyToText(result) + "\n"
+	case g.Status == game.StatusDraw:
+		panel += m.gradientManager.ApplyToText("Draw!") + "\n"
+	case measuring:
+		a, b := measurement.Cells[0], measurement.Cells[1]
+		panel += fmt.Sprintf("%s measures: collapse %s into (%d,%d) or (%d,%d)\n",
+			g.CurrentPlayer, measurement, a.Row, a.Col, b.Row, b.Col)
+	default:
+		turn := fmt.Sprintf("%s%d", g.CurrentPlayer, len(g.Marks)+1)
+		if col, row, picked := m.inputHandler.GetSource(); picked {
+			panel += fmt.Sprintf("%s to move • %s in (%d,%d) and…\n", g.CurrentPlayer, turn, row, col)
+		} else {
+			panel += fmt.Sprintf("%s to move • %s • Cursor (%d,%d)\n", g.CurrentPlayer, turn, m.cursorPosition[0], m.cursorPosition[1])
+		}
+	}
+
+	if m.statusMessage != "" {
+		panel += "\n" + m.statusMessage + "\n"
+	}
+	if m.errorMessage != "" {
+		panel += "\n" + lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Render(m.errorMessage) + "\n"
+	}
+
+	help := "↑↓←→ Move • Enter Pick two cells • esc Cancel/Back • r New game"
+	switch {
+	case g.Status != game.StatusPlaying:
+		help = "Enter/r New game • esc Back"
+	case measuring:
+		help = "↑↓←→ Move • Enter Collapse into cell • r New game • esc Back"
+	}
+	panel += "\n" + lipgloss.NewStyle().Faint(true).Render(help)
+
+	style := lipgloss.NewStyle().
+		Align(lipgloss.Center).
+		PaddingTop(1).
+		Width(m.width).
+		Height(m.height)
+
+	return style.Render(lipgloss.JoinVertical(lipgloss.Center, board, "", panel))
+}
+
+// opponentOf returns the other player
+func opponentOf(player game.Player) game.Player {
+	if player == game.PlayerX {
+		return game.PlayerO
+	}
+	return game.PlayerX
+}
@@ -0,0 +1,254 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"tic-tac-toe/internal/audio"
+	"tic-tac-toe/internal/game"
+	"tic-tac-toe/internal/input"
+	"tic-tac-toe/internal/qubic"
+)
+
+// qubicMoveMsg carries the result of a background Qubic AI search
+type qubicMoveMsg struct {
+	search int // The search that produced it; older searches were cancelled
+	move   qubic.Point
+	err    error
+}
+
+// startQubic opens a new 4×4×4 game against the AI, which plays O at the
+// configured strength
+func (m *Model) startQubic() {
+	m.cancelAIMove()
+	m.qubicGame = qubic.New()
+	m.qubicAI = qubic.NewAI(game.PlayerO, m.config.GetAIStrength())
+	if m.seed != 0 {
+		m.qubicAI.SetSeed(m.seed)
+	}
+	m.qubicCursor = qubic.Point{Layer: 0, Row: 1, Col: 1}
+	m.winLine = nil
+	m.state = StateQubic
+}
+
+// handleQubicInput handles keys in a Qubic game; the cursor moves within a
+// layer and the layer keys move it between layers
+func (m *Model) handleQubicInput(action input.KeybindingAction) tea.Cmd {
+	cursor := &m.qubicCursor
+	switch action {
+	case input.ActionMoveUp:
+		cursor.Row = max(cursor.Row-1, 0)
+	case input.ActionMoveDown:
+		cursor.Row = min(cursor.Row+1, qubic.Size-1)
+	case input.ActionMoveLeft:
+		cursor.Col = max(cursor.Col-1, 0)
+	case input.ActionMoveRight:
+		cursor.Col = min(cursor.Col+1, qubic.Size-1)
+	case input.ActionLayerUp:
+		cursor.Layer = (cursor.Layer + 1) % qubic.Size
+	case input.ActionLayerDown:
+		cursor.Layer = (cursor.Layer + qubic.Size - 1) % qubic.Size
+	case input.ActionSelect:
+		if m.qubicGame.Status != game.StatusPlaying {
+			m.startQubic()
+			return nil
+		}
+		return m.makeQubicMove()
+	case input.ActionReset:
+		m.startQubic()
+	case input.ActionBack:
+		m.cancelAIMove()
+		m.state = StateMainMenu
+		m.cursorPosition = [2]int{1, 0}
+	}
+	return nil
+}
+
+// makeQubicMove places the human's mark under the cursor and starts the AI's reply
+func (m *Model) makeQubicMove() tea.Cmd {
+	if m.aiThinking() {
+		m.statusMessage = "Wait for the AI to move"
+		return nil
+	}
+	if err := m.qubicGame.MakeMove(m.qubicCursor); err != nil {
+		m.errorMessage = err.Error()
+		m.audioManager.PlaySound(audio.SoundError)
+		return nil
+	}
+	m.audioManager.PlaySound(audio.SoundMove)
+
+	if m.qubicGame.Status != game.StatusPlaying {
+		m.playQubicResult()
+		return nil
+	}
+	return m.startQubicAIMove()
+}
+
+// startQubicAIMove searches for the AI's move on a copy of the cube in a tea.Cmd
+func (m *Model) startQubicAIMove() tea.Cmd {
+	m.cancelAIMove()
+	ctx, cancel := context.WithTimeout(context.Background(), aiMoveBudget)
+	m.aiCancel = cancel
+	m.aiSearch++
+	m.aiThinkingSince = time.Now()
+
+	search, player, position := m.aiSearch, m.qubicAI, m.qubicGame.Clone()
+	return func() tea.Msg {
+		move, err := player.Move(ctx, position)
+		return qubicMoveMsg{search: search, move: move, err: err}
+	}
+}
+
+// applyQubicMove plays the result of the current Qubic search, ignoring
+// results of searches cancelled by a new game or by leaving
+func (m *Model) applyQubicMove(msg qubicMoveMsg) {
+	if msg.search != m.aiSearch || !m.aiThinking() {
+		return
+	}
+	m.cancelAIMove()
+	if m.qubicGame == nil || m.qubicGame.Status != game.StatusPlaying {
+		return
+	}
+	if msg.err != nil {
+		m.errorMessage = "AI move failed: " + msg.err.Error()
+		return
+	}
+	if err := m.qubicGame.MakeMove(msg.move); err != nil {
+		m.errorMessage = "AI move error: " + err.Error()
+		m.audioManager.PlaySound(audio.SoundError)
+		return
+	}
+	m.statusMessage = "AI played " + msg.move.String()
+	m.audioManager.PlaySound(audio.SoundMove)
+	if m.qubicGame.Status != game.StatusPlaying {
+		m.playQubicResult()
+	}
+}
+
+// playQubicResult plays the sound for a finished Qubic game and pulses the
+// winning line
+func (m *Model) playQubicResult() {
+	if m.qubicGame.Status == game.StatusWon {
+		m.audioManager.PlaySound(audio.SoundWin)
+		m.pulseWinLine()
+	} else {
+		m.audioManager.PlaySound(audio.SoundDraw)
+	}
+}
+
+// renderQubicScreen draws the four layers side by side with the game status below
+func (m *Model) renderQubicScreen() string {
+	g := m.qubicGame
+	winning := map[qubic.Point]bool{}
+	if line, ok := g.WinningLine(); ok {
+		for _, p := range line {
+			winning[p] = true
+		}
+	}
+	var last qubic.Point
+	hasLast := len(g.MoveHistory) > 0
+	if hasLast {
+		last = g.MoveHistory[len(g.MoveHistory)-1]
+	}
+
+	layers := make([]string, qubic.Size)
+	for l := range layers {
+		title := fmt.Sprintf("Layer %d", l+1)
+		if l == m.qubicCursor.Layer {
+			title = m.gradientManager.ApplyToText("▼ " + title)
+		}
+		rows := []string{title}
+		for r := 0; r < qubic.Size; r++ {
+			var cells []string
+			for c := 0; c < qubic.Size; c++ {
+				p := qubic.Point{Layer: l, Row: r, Col: c}
+				mark := string(g.At(p))
+				if mark == string(game.Empty) {
+					mark = "·"
+				}
+				cell := " " + mark + " "
+				if p == m.qubicCursor {
+					cell = "[" + mark + "]"
+				}
+				switch {
+				case winning[p]:
+					cell = m.renderLineCell(cell)
+				case hasLast && p == last:
+					cell = lipgloss.NewStyle().Bold(true).Underline(true).Render(cell)
+				}
+				cells = append(cells, cell)
+			}
+			rows = append(rows, strings.Join(cells, ""))
+		}
+		border := lipgloss.NormalBorder()
+		color := lipgloss.Color("240")
+		if l == m.qubicCursor.Layer {
+			border = lipgloss.ThickBorder()
+			color = lipgloss.Color("39")
+		}
+		layers[l] = lipgloss.NewStyle().
+			Border(border).
+			BorderForeground(color).
+			Padding(0, 1).
+			Render(strings.Join(rows, "\n"))
+	}
+	cube := lipgloss.JoinHorizontal(lipgloss.Top, joinWithGap(layers, "  ")...)
+
+	panel := m.gradientManager.ApplyToText("🧊 3D QUBIC") + " — four in a row anywhere in the cube\n"
+	switch g.Status {
+	case game.StatusWon:
+		if g.Winner == m.qubicAI.GetPlayer() {
+			panel += m.gradientManager.ApplyToText("The AI wins!") + "\n"
+		} else {
+			panel += m.gradientManager.ApplyToText("You win!") + "\n"
+		}
+	case game.StatusDraw:
+		panel += m.gradientManager.ApplyToText("Draw!") + "\n"
+	default:
+		if m.aiThinking() {
+			elapsed := time.Since(m.aiThinkingSince).Seconds()
+			panel += m.gradientManager.ApplyToText(fmt.Sprintf("🤔 Thinking… %.1fs", elapsed)) + "\n"
+		} else {
+			panel += fmt.Sprintf("You play X • Cursor %s • Move %d\n", m.qubicCursor, len(g.MoveHistory)+1)
+		}
+	}
+	panel += fmt.Sprintf("AI looks %d threats ahead\n", m.qubicAI.GetThreatDepth())
+
+	if m.statusMessage != "" {
+		panel += "\n" + m.statusMessage + "\n"
+	}
+	if m.errorMessage != "" {
+		panel += "\n" + lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Render(m.errorMessage) + "\n"
+	}
+
+	help := "↑↓←→ Move • , . Change layer • Enter Place • r New game • esc Back"
+	if g.Status != game.StatusPlaying {
+		help = "Enter/r New game • esc Back"
+	}
+	panel += "\n" + lipgloss.NewStyle().Faint(true).Render(help)
+
+	style := lipgloss.NewStyle().
+		Align(lipgloss.Center).
+		PaddingTop(1).
+		Width(m.width).
+		Height(m.height)
+
+	return style.Render(lipgloss.JoinVertical(lipgloss.Center, cube, "", panel))
+}
+
+// joinWithGap puts gap between blocks for lipgloss.JoinHorizontal
+func joinWithGap(blocks []string, gap string) []string {
+	joined := make([]string, 0, 2*len(blocks))
+	for i, block := range blocks {
+		if i > 0 {
+			joined = append(joined, gap)
+		}
+		joined = append(joined, block)
+	}
+	return joined
+}
@@ -0,0 +1,165 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"tic-tac-toe/internal/game"
+	"tic-tac-toe/internal/input"
+	"tic-tac-toe/internal/persistence"
+	"tic-tac-toe/internal/solve"
+)
+
+// setupChoices are the entries of the setup menu: the empty board, the named
+// setups, then random obstacles
+func setupChoices() []*game.Setup {
+	choices := []*game.Setup{nil}
+	for i := range game.Setups {
+		choices = append(choices, &game.Setups[i])
+	}
+	return append(choices, &game.Setup{Name: game.RandomObstacles})
+}
+
+// setupName returns the name shown for a setup, "Standard" for the empty board
+func setupName(setup *game.Setup) string {
+	if setup == nil {
+		return "Standard"
+	}
+	return setup.Name
+}
+
+// setupStatsOrder lists the setups with statistics: the offered ones in menu
+// order, then custom positions alphabetically
+func setupStatsOrder(stats map[string]*persistence.VariantStats) []string {
+	var names, custom []string
+	offered := map[string]bool{}
+	for _, setup := range setupChoices()[1:] {
+		offered[setup.Name] = true
+		if stats[setup.Name] != nil {
+			names = append(names, setup.Name)
+		}
+	}
+	for name := range stats {
+		if !offered[name] && stats[name] != nil {
+			custom = append(custom, name)
+		}
+	}
+	sort.Strings(custom)
+	return append(names, custom...)
+}
+
+// openSetupMenu shows the game setups with the configured one highlighted
+func (m *Model) openSetupMenu() {
+	m.state = StateSetupMenu
+	m.setupCursor = 0
+	current := m.config.GetSetupName()
+	for i, setup := range setupChoices() {
+		if setupName(setup) == current {
+			m.setupCursor = i
+		}
+	}
+}
+
+// handleSetupMenuInput handles keys in the setup menu; Enter picks the
+// starting position for new local games
+func (m *Model) handleSetupMenuInput(action input.KeybindingAction) tea.Cmd {
+	choices := setupChoices()
+	switch action {
+	case input.ActionMoveUp:
+		if m.setupCursor > 0 {
+			m.setupCursor--
+		}
+	case input.ActionMoveDown:
+		if m.setupCursor < len(choices)-1 {
+			m.setupCursor++
+		}
+	case input.ActionSelect:
+		name := setupName(choices[m.setupCursor])
+		if err := m.config.SetSetup(name); err != nil {
+			m.errorMessage = "Failed to save setup: " + err.Error()
+			return nil
+		}
+		m.statusMessage = "Setup set to " + name
+		m.state = StateMainMenu
+	case input.ActionBack:
+		m.state = StateMainMenu
+	}
+	return nil
+}
+
+// renderSetupMenu lists the setups with the board and a description of the
+// highlighted one
+func (m *Model) renderSetupMenu() string {
+	choices := setupChoices()
+	current := m.config.GetSetupName()
+	content := m.gradientManager.ApplyToText("🧱 GAME SETUP") + "\n\n"
+	for i, setup := range choices {
+		name := setupName(setup)
+		if name == current {
+			name += " (current)"
+		}
+		if i == m.setupCursor {
+			content += m.gradientManager.ApplyToText("▶ "+name+" ◀") + "\n"
+		} else {
+			content += "  " + name + "\n"
+		}
+	}
+
+	setup := choices[m.setupCursor]
+	if setup != nil && setup.Position != "" {
+		content += "\n" + renderSetupPosition(setup.Position) + "\n"
+	}
+	content += "\n" + lipgloss.NewStyle().Width(50).Render(setup.Describe()) + "\n"
+	if setup != nil {
+		content += lipgloss.NewStyle().Faint(true).Render("Games from a setup are scored separately") + "\n"
+	}
+
+	if m.errorMessage != "" {
+		content += "\n" + lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Render(m.errorMessage) + "\n"
+	}
+	content += "\n" + lipgloss.NewStyle().Faint(true).Render("↑↓ Navigate • Enter Choose • esc Back")
+
+	style := lipgloss.NewStyle().
+		Align(lipgloss.Center).
+		Width(m.width).
+		Height(m.height).
+		Padding(2)
+
+	return style.Render(content)
+}
+
+// renderSetupPosition draws a setup's position as a small grid
+func renderSetupPosition(position string) string {
+	grid := ""
+	for i, cell := range position {
+		switch {
+		case cell == '/':
+			grid += "\n"
+		case cell == '.':
+			grid += "·"
+		default:
+			grid += string(cell)
+		}
+		if cell != '/' && i+1 < len(position) && position[i+1] != '/' {
+			grid += " "
+		}
+	}
+	return lipgloss.NewStyle().Faint(true).Render(grid)
+}
+
+// resolveSetup returns the setup for a new local game, drawing a fair layout
+// when the configured setup is random obstacles
+func (m *Model) resolveSetup(rules game.Rules) (*game.Setup, error) {
+	setup := m.config.GetSetup()
+	if setup == nil || setup.Position != "" {
+		return setup, nil
+	}
+	drawn, err := solve.RandomObstacles(m.setupRand, rules)
+	if err != nil {
+		return nil, fmt.Errorf("failed to draw obstacles: %w", err)
+	}
+	return drawn, nil
+}
@@ -0,0 +1,126 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"tic-tac-toe/internal/audio"
+	"tic-tac-toe/internal/game"
+	"tic-tac-toe/internal/input"
+)
+
+// swapKeys are the keys that make each swap decision, as shown in prompts
+var swapKeys = map[game.SwapChoice]string{
+	game.KeepSides: "Enter Keep sides",
+	game.SwapSides: "y Swap sides",
+	game.PlaceTwo:  "e Place two more",
+}
+
+// swapChoiceFor maps a key to the swap decision it makes
+func swapChoiceFor(action input.KeybindingAction) (game.SwapChoice, bool) {
+	switch action {
+	case input.ActionSelect:
+		return game.KeepSides, true
+	case input.ActionSwapSides:
+		return game.SwapSides, true
+	case input.ActionPlaceTwo:
+		return game.PlaceTwo, true
+	}
+	return game.KeepSides, false
+}
+
+// swapPrompt asks seat to make one of the choices
+func swapPrompt(seat game.Seat, choices []game.SwapChoice) string {
+	keys := make([]string, len(choices))
+	for i, choice := range choices {
+		keys[i] = swapKeys[choice]
+	}
+	return fmt.Sprintf("%s: swap sides? %s", seat, strings.Join(keys, " • "))
+}
+
+// aiToMove reports whether the AI makes the next move or swap decision of
+// a local game against it
+func (m *Model) aiToMove() bool {
+	if m.game.GetMode() != game.PlayerVsAI || m.game.GetStatus() != game.StatusPlaying {
+		return false
+	}
+	seat, _, pending := m.game.SwapPending()
+	if !pending {
+		seat = m.game.SeatToMove()
+	}
+	return seat == m.aiSeat
+}
+
+// handleSwapInput makes the human's pending swap decision for a key; it
+// reports false for keys that make no decision
+func (m *Model) handleSwapInput(action input.KeybindingAction) (tea.Cmd, bool) {
+	choice, ok := swapChoiceFor(action)
+	if !ok || m.game.GetMode() == game.PlayerVsNetwork || m.game.GetMode() == game.PlayerVsCorrespondence {
+		return nil, false
+	}
+	if m.aiThinking() {
+		m.statusMessage = "Wait for the AI to decide"
+		return nil, true
+	}
+	return m.chooseSwap(choice), true
+}
+
+// chooseSwap makes the pending swap decision, gives the AI the color of its
+// seat, and lets the AI go on if the next move is its own
+func (m *Model) chooseSwap(choice game.SwapChoice) tea.Cmd {
+	seat, _, _ := m.game.SwapPending()
+	if err := m.game.ChooseSwap(choice); err != nil {
+		m.errorMessage = err.Error()
+		m.audioManager.PlaySound(audio.SoundError)
+		return nil
+	}
+	m.ai.SetPlayer(m.game.Opening.ColorOf(m.aiSeat))
+
+	name := seat.String()
+	if m.game.GetMode() == game.PlayerVsAI {
+		name = "You"
+		if seat == m.aiSeat {
+			name = "The AI"
+		}
+	}
+	m.statusMessage = fmt.Sprintf("%s chose to %s", name, choice)
+	if choice == game.SwapSides {
+		m.statusMessage += fmt.Sprintf(": %s now plays %s", seat, m.game.Opening.ColorOf(seat))
+	}
+
+	save := func() tea.Msg {
+		return gameUpdateMsg{saveRequired: true}
+	}
+	if m.aiToMove() {
+		return tea.Batch(m.startAIMove(), save)
+	}
+	return save
+}
+
+// swapGame is a game that can be played under a swap rule
+type swapGame interface {
+	SwapPending() (game.Seat, []game.SwapChoice, bool)
+	SeatToMove() game.Seat
+}
+
+// renderSeats shows which color each seat plays under a swap rule and the
+// decision that is due; aiSeat is the AI's seat when playing against it
+func (m *Model) renderSeats(g swapGame, opening game.Opening, playing bool, aiSeat *game.Seat) string {
+	if opening.Rule == game.NoSwap {
+		return ""
+	}
+	seats := fmt.Sprintf("Swap rule: %s • %s %s, %s %s\n", opening.Rule.Name(),
+		game.FirstSeat, opening.ColorOf(game.FirstSeat), game.SecondSeat, opening.ColorOf(game.SecondSeat))
+	seat, choices, pending := g.SwapPending()
+	switch {
+	case pending && aiSeat != nil && seat == *aiSeat:
+		seats += "The AI is deciding whether to swap sides\n"
+	case pending:
+		seats += m.gradientManager.ApplyToText(swapPrompt(seat, choices)) + "\n"
+	case !opening.Decided && playing:
+		seats += fmt.Sprintf("%s places the opening marks\n", g.SeatToMove())
+	}
+	return seats
+}
@@ -0,0 +1,303 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"tic-tac-toe/internal/game"
+	"tic-tac-toe/internal/input"
+	"tic-tac-toe/internal/match"
+)
+
+// bracketSlot is a match of the tournament bracket
+type bracketSlot struct {
+	round int
+	index int
+}
+
+// maxPlayerName limits the length of tournament player names
+const maxPlayerName = 16
+
+// defaultTournamentPlayers is how many players a new tournament starts with
+const defaultTournamentPlayers = 4
+
+// openTournament shows the tournament: its bracket when one is running,
+// else the list of players to enter
+func (m *Model) openTournament() {
+	m.state = StateTournament
+	if len(m.tournamentNames) == 0 {
+		for i := 1; i <= defaultTournamentPlayers; i++ {
+			m.tournamentNames = append(m.tournamentNames, fmt.Sprintf("Player %d", i))
+		}
+	}
+	m.tournamentCursor = 0
+}
+
+// handleTournamentInput handles keys on the tournament setup and bracket
+func (m *Model) handleTournamentInput(action input.KeybindingAction) tea.Cmd {
+	if m.tournament != nil {
+		return m.handleBracketInput(action)
+	}
+
+	switch action {
+	case input.ActionMoveUp:
+		if m.tournamentCursor > 0 {
+			m.tournamentCursor--
+		}
+	case input.ActionMoveDown:
+		if m.tournamentCursor < len(m.tournamentNames) {
+			m.tournamentCursor++
+		}
+	case input.ActionSpeedUp:
+		m.addTournamentPlayer()
+	case input.ActionSpeedDown:
+		m.removeTournamentPlayer()
+	case input.ActionSelect:
+		if m.tournamentCursor == len(m.tournamentNames) {
+			m.startTournament()
+		} else {
+			m.inputHandler.BeginTextEntry(maxPlayerName)
+		}
+	case input.ActionBack:
+		m.state = StateMainMenu
+		m.cursorPosition = [2]int{1, 0}
+	}
+	return nil
+}
+
+// addTournamentPlayer enters another player under the first free default name
+func (m *Model) addTournamentPlayer() {
+	if len(m.tournamentNames) >= match.MaxPlayers {
+		m.statusMessage = fmt.Sprintf("A tournament takes at most %d players", match.MaxPlayers)
+		return
+	}
+	for i := 1; ; i++ {
+		name := fmt.Sprintf("Player %d", i)
+		if !m.tournamentNameTaken(name, -1) {
+			m.tournamentNames = append(m.tournamentNames, name)
+			return
+		}
+	}
+}
+
+// removeTournamentPlayer takes out the highlighted player, or the last one
+// when the start entry is highlighted
+func (m *Model) removeTournamentPlayer() {
+	if len(m.tournamentNames) <= match.MinPlayers {
+		m.statusMessage = fmt.Sprintf("A tournament needs at least %d players", match.MinPlayers)
+		return
+	}
+	index := min(m.tournamentCursor, len(m.tournamentNames)-1)
+	m.tournamentNames = append(m.tournamentNames[:index], m.tournamentNames[index+1:]...)
+	m.tournamentCursor = min(m.tournamentCursor, len(m.tournamentNames))
+}
+
+// tournamentNameTaken reports whether another entered player, other than
+// the one at skip, has the name, ignoring case
+func (m *Model) tournamentNameTaken(name string, skip int) bool {
+	for i, taken := range m.tournamentNames {
+		if i != skip && strings.EqualFold(taken, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// handleTournamentNameInput handles keys while a player's name is typed
+func (m *Model) handleTournamentNameInput(action input.KeybindingAction) tea.Cmd {
+	switch action {
+	case input.ActionTextSubmit:
+		name := strings.TrimSpace(m.inputHandler.EndTextEntry())
+		switch {
+		case name == "":
+			m.errorMessage = "Player names cannot be empty"
+		case m.tournamentNameTaken(name, m.tournamentCursor):
+			m.errorMessage = fmt.Sprintf("%s is already playing", name)
+		default:
+			m.tournamentNames[m.tournamentCursor] = name
+		}
+	case input.ActionTextCancel:
+		m.inputHandler.EndTextEntry()
+	}
+	return nil
+}
+
+// startTournament seeds the entered players into a bracket under the
+// configured match format
+func (m *Model) startTournament() {
+	tournament, err := match.NewTournament(m.config.GetMatchFormat(), m.tournamentNames)
+	if err != nil {
+		m.errorMessage = err.Error()
+		return
+	}
+	m.tournament = tournament
+	m.tournamentID = fmt.Sprintf("%d", time.Now().UnixNano())
+}
+
+// handleBracketInput handles keys on the bracket of a running tournament
+func (m *Model) handleBracketInput(action input.KeybindingAction) tea.Cmd {
+	switch action {
+	case input.ActionSelect:
+		if _, decided := m.tournament.Champion(); decided {
+			m.tournament = nil
+			return nil
+		}
+		return m.playTournamentMatch()
+	case input.ActionReset:
+		m.tournament = nil
+		m.statusMessage = "Tournament abandoned"
+	case input.ActionBack:
+		m.state = StateMainMenu
+		m.cursorPosition = [2]int{1, 0}
+	}
+	return nil
+}
+
+// playTournamentMatch starts the next match of the bracket as a knockout
+// match between two local players
+func (m *Model) playTournamentMatch() tea.Cmd {
+	round, index, ok := m.tournament.Next()
+	if !ok {
+		return nil
+	}
+	players := m.tournament.Rounds[round][index].Players
+	knockout := match.New(m.tournament.Format, players[0], players[1])
+	knockout.Knockout = true
+	m.beginMatch(knockout)
+	m.tournamentPlay = &bracketSlot{round: round, index: index}
+	return m.startLocalGame(game.PlayerVsPlayer)
+}
+
+// reportTournamentMatch moves the winner of the decided tournament match on
+// through the bracket, and counts the tournament once it has a champion
+func (m *Model) reportTournamentMatch() {
+	winner, ok := m.match.Winner()
+	if !ok {
+		return
+	}
+	slot := m.tournamentPlay
+	if err := m.tournament.Report(slot.round, slot.index, m.match.Players[winner], m.match.Summary()); err != nil {
+		m.errorMessage = err.Error()
+		return
+	}
+	if champion, decided := m.tournament.Champion(); decided {
+		if err := m.persistManager.RecordTournament(champion); err != nil {
+			m.errorMessage = "Failed to save tournament: " + err.Error()
+		}
+	}
+}
+
+// renderTournamentScreen shows the tournament setup or its bracket
+func (m *Model) renderTournamentScreen() string {
+	content := m.gradientManager.ApplyToText("🏆 TOURNAMENT") + "\n\n"
+	if m.tournament != nil {
+		content += m.renderBracket()
+	} else {
+		content += m.renderTournamentSetup()
+	}
+
+	if m.statusMessage != "" {
+		content += "\n" + m.gradientManager.ApplyToText(m.statusMessage)
+	}
+	if m.errorMessage != "" {
+		content += "\n" + lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Render(m.errorMessage)
+	}
+
+	style := lipgloss.NewStyle().
+		Align(lipgloss.Center).
+		Width(m.width).
+		Height(m.height).
+		Padding(1)
+
+	return style.Render(content)
+}
+
+// renderTournamentSetup lists the players entered for the next tournament
+func (m *Model) renderTournamentSetup() string {
+	setup := "Knockout, " + m.config.GetMatchFormat().String() + " per match (f in Settings to change)\n\n"
+	typing := m.inputHandler.GetMode() == input.ModeText
+	for i, name := range m.tournamentNames {
+		line := fmt.Sprintf("%d. %s", i+1, name)
+		if i == m.tournamentCursor && typing {
+			line = fmt.Sprintf("%d. %s▌", i+1, m.inputHandler.GetText())
+		}
+		if i == m.tournamentCursor {
+			setup += m.gradientManager.ApplyToText("▶ "+line+" ◀") + "\n"
+		} else {
+			setup += "  " + line + "\n"
+		}
+	}
+	start := "Start the tournament"
+	if m.tournamentCursor == len(m.tournamentNames) {
+		setup += "\n" + m.gradientManager.ApplyToText("▶ "+start+" ◀") + "\n"
+	} else {
+		setup += "\n  " + start + "\n"
+	}
+
+	help := "↑↓ Navigate • Enter Rename / Start • + Add player • - Remove player • esc Back"
+	if typing {
+		help = "Type a name • Enter Save • esc Cancel"
+	}
+	return setup + "\n" + lipgloss.NewStyle().Faint(true).Render(help) + "\n"
+}
+
+// renderBracket draws the rounds side by side, each match centered against
+// the two matches that feed it
+func (m *Model) renderBracket() string {
+	next := bracketSlot{round: -1}
+	if round, index, ok := m.tournament.Next(); ok {
+		next = bracketSlot{round: round, index: index}
+	}
+
+	columns := make([]string, 0, 2*len(m.tournament.Rounds))
+	for round, pairings := range m.tournament.Rounds {
+		slotHeight := 4 << round
+		column := m.gradientManager.ApplyToText(m.tournament.RoundName(round)) + "\n"
+		for index, pairing := range pairings {
+			lines := m.renderPairing(pairing, round == 0, next == bracketSlot{round: round, index: index})
+			top := (slotHeight - len(lines)) / 2
+			column += strings.Repeat("\n", top) + strings.Join(lines, "\n") + strings.Repeat("\n", slotHeight-top-len(lines)+1)
+		}
+		columns = append(columns, column, "   ")
+	}
+	bracket := lipgloss.JoinHorizontal(lipgloss.Top, columns...) + "\n"
+
+	if champion, decided := m.tournament.Champion(); decided {
+		bracket += m.gradientManager.ApplyToText("🏆 Champion: "+champion+" 🏆") + "\n\n"
+		bracket += lipgloss.NewStyle().Faint(true).Render("Enter New tournament • esc Back") + "\n"
+		return bracket
+	}
+	players := m.tournament.Rounds[next.round][next.index].Players
+	bracket += fmt.Sprintf("Next: %s vs %s (%s, %s)\n\n", players[0], players[1],
+		m.tournament.RoundName(next.round), m.tournament.Format)
+	bracket += lipgloss.NewStyle().Faint(true).Render("Enter Play the next match • r Abandon the tournament • esc Back") + "\n"
+	return bracket
+}
+
+// renderPairing draws one match of the bracket: both players, the winner
+// marked, then the result
+func (m *Model) renderPairing(pairing match.Pairing, firstRound, next bool) []string {
+	lines := make([]string, 0, 3)
+	for _, player := range pairing.Players {
+		switch {
+		case player == match.Bye && firstRound:
+			lines = append(lines, lipgloss.NewStyle().Faint(true).Render("  (bye)"))
+		case player == "":
+			lines = append(lines, lipgloss.NewStyle().Faint(true).Render("  ?"))
+		case player == pairing.Winner:
+			lines = append(lines, m.gradientManager.ApplyToText("✓ "+player))
+		case next:
+			lines = append(lines, "▶ "+player)
+		default:
+			lines = append(lines, "  "+player)
+		}
+	}
+	if pairing.Result != "" {
+		lines = append(lines, lipgloss.NewStyle().Faint(true).Render("  "+pairing.Result))
+	}
+	return lines
+}
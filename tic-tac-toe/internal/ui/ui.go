@@ -1,7 +1,10 @@
 package ui
 
 import (
+	"context"
 	"fmt"
+	"math/rand"
+	"strings"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
@@ -10,11 +13,19 @@ import (
 	"tic-tac-toe/internal/ai"
 	"tic-tac-toe/internal/audio"
 	"tic-tac-toe/internal/config"
+	"tic-tac-toe/internal/correspondence"
 	"tic-tac-toe/internal/game"
 	"tic-tac-toe/internal/gradient"
+	"tic-tac-toe/internal/gravity"
 	"tic-tac-toe/internal/graphics"
 	"tic-tac-toe/internal/input"
+	"tic-tac-toe/internal/lobby"
+	"tic-tac-toe/internal/match"
+	"tic-tac-toe/internal/multi"
 	"tic-tac-toe/internal/persistence"
+	"tic-tac-toe/internal/puzzle"
+	"tic-tac-toe/internal/quantum"
+	"tic-tac-toe/internal/qubic"
 )
 
 type GameState int
@@ -28,8 +39,39 @@ const (
 	StateHelp
 	StateStatistics
 	StateQuitConfirm
+	StateLobby
+	StateCorrespondence
+	StatePuzzle
+	StateVariantMenu
+	StateQubic
+	StateGravity
+	StateQuantum
+	StateSetupMenu
+	StateTournament
+	StateMatchSummary
+	StateMulti
 )
 
+// mainMenuOptions are the entries of the main menu, in display order
+var mainMenuOptions = []string{
+	"🎮 Player vs Player",
+	"🤖 Player vs AI",
+	"🧩 Puzzles",
+	"🎲 Rule Variants",
+	"🧊 3D Qubic",
+	"🔻 Gravity",
+	"⚛️  Quantum",
+	"🧱 Game Setup",
+	"🏆 Tournament",
+	"👥 Multi-player",
+	"🌐 Online Lobby",
+	"📮 Correspondence",
+	"⚙️  Settings",
+	"📊 Statistics",
+	"❓ Help",
+	"🚪 Quit",
+}
+
 type Model struct {
 	state            GameState
 	game             *game.Game
@@ -40,10 +82,37 @@ type Model struct {
 	config           *config.Config
 	persistManager   *persistence.Manager
 	audioManager     *audio.Manager
+	events           *game.Bus // Carries the local game's events to the model's subscribers
+	saveQueued       bool      // A game event asked for a save after the current message
+	
+	// Online lobby
+	lobbyServer      *lobby.Server // Set when this instance hosts the lobby
+	lobbyClient      *lobby.Client
+	lobbyUsers       []lobby.User
+	lobbyGames       []lobby.GameInfo
+	lobbyQueued      bool
+	netGame          *lobby.Pairing // Current networked game, if any
+	chatLog          []persistence.ChatMessage
+	
+	// Correspondence play
+	corrManager      *correspondence.Manager
+	corrGames        []correspondence.Summary
+	corrSelected     int
+	corrGameID       string
+	
+	// Puzzle training
+	puzzles          []puzzle.Puzzle
+	currentPuzzle    *puzzle.Puzzle
+	puzzleGame       *game.Game // The puzzle position, kept apart from the saved game
+	puzzleStats      *persistence.PuzzleStats
+	puzzleAnswered   bool // The current puzzle has been tried; the next move loads another
+	puzzleRand       *rand.Rand
+	setupRand        *rand.Rand // Draws random obstacle layouts
 	
 	width            int
 	height           int
 	cursorPosition   [2]int
+	cursorBoard      int  // The board the cursor is on, in games on several boards
 	cursorSymbols    []string
 	cursorIndex      int
 	cellSize         int  // Dynamic cell size based on terminal
@@ -54,13 +123,66 @@ type Model struct {
 	
 	statusMessage    string
 	errorMessage     string
+	aiExplanation    *ai.Explanation // Why the AI played its last move
+	aiCancel         context.CancelFunc // Stops the running AI search; nil while the AI is not thinking
+	aiSearch         int                // Numbers AI searches so stale results can be told apart
+	aiThinkingSince  time.Time
+	markChoice       game.Player // The mark the human places next when the rules allow a choice; Empty for the usual one
+	winLine          *winAnimation // The winning line still pulsing, if any
+	variantCursor    int         // Highlighted entry of the variant menu
+	setupCursor      int         // Highlighted entry of the setup menu
+	aiSeat           game.Seat   // The seat the AI takes in the current local game; its color follows the swap rule
+	aiHomeSeat       game.Seat   // The AI's seat in lone games and in the first game of a match
+	match            *match.Match // The match the local game belongs to; nil for a lone game
+	matchID          string
+	matchSeats       [2]int // The match player in each seat of the current game
+	tournament       *match.Tournament // The running tournament, if any
+	tournamentID     string
+	tournamentPlay   *bracketSlot // The tournament match being played; nil for other matches
+	tournamentNames  []string     // The players entered for the next tournament
+	tournamentCursor int          // Highlighted entry of the tournament setup
+	qubicGame        *qubic.Game
+	qubicAI          *qubic.AI
+	qubicCursor      qubic.Point
+	gravityGame      *gravity.Game
+	gravityAI        *gravity.AI
+	gravityCursor    int            // Column the next mark drops into
+	gravityDrop      *dropAnimation // The mark still falling, if any
+	multiGame        *multi.Game
+	multiAIs         map[game.Player]*multi.AI // The AI playing each mark but the human's
+	multiCursor      game.Position
+	quantumGame      *quantum.Game
+	seed             int64 // Seeds the AIs' random choices; zero picks a random seed
 	showStartupAnim  bool
 	startupAnimPhase int
+	startCmd         tea.Cmd // Begins the game started from the command line, e.g. the AI's opening
+	variant          *game.Variant // Overrides the configured rules until a variant is picked in the menu
+}
+
+// Options configures how the UI starts
+type Options struct {
+	SaveDir     string         // Save directory; empty uses the default
+	StartGame   bool           // Skip the menu and start a game in Mode
+	Mode        game.GameMode  // PlayerVsPlayer or PlayerVsAI
+	Difficulty  *ai.Difficulty // Overrides the configured AI difficulty
+	Strength    *float64       // Overrides the AI strength from 0 to 1
+	Personality string         // Overrides the configured AI personality by ID
+	HumanPlayer game.Player    // The side the human plays against the AI; X by default
+	Variant     *game.Variant  // Overrides the configured rule variant
+	Seed        int64          // Seeds the AI's and the puzzle picker's random choices; zero picks a random seed
 }
 
 func New() (*Model, error) {
+	return NewWithOptions(Options{})
+}
+
+// NewWithOptions creates the UI model with command-line overrides
+func NewWithOptions(opts Options) (*Model, error) {
 	// Initialize persistence manager
 	persistManager := persistence.New()
+	if opts.SaveDir != "" {
+		persistManager = persistence.NewWithDirectory(opts.SaveDir)
+	}
 	
 	// Initialize configuration
 	cfg := config.New(persistManager)
@@ -77,8 +199,34 @@ func New() (*Model, error) {
 	// Initialize input handler
 	inputHandler := input.New()
 	
-	// Initialize AI with current difficulty
-	aiPlayer := ai.New(cfg.GetAIDifficulty(), "O")
+	// Initialize AI with current difficulty, opposite the human player
+	difficulty := cfg.GetAIDifficulty()
+	if opts.Difficulty != nil {
+		difficulty = *opts.Difficulty
+	}
+	aiSide := game.PlayerO
+	if opts.HumanPlayer == game.PlayerO {
+		aiSide = game.PlayerX
+	}
+	aiPlayer := ai.New(difficulty, aiSide)
+	switch {
+	case opts.Strength != nil:
+		aiPlayer.SetStrength(*opts.Strength)
+	case opts.Difficulty == nil:
+		aiPlayer.SetStrength(cfg.GetAIStrength())
+	}
+	aiPlayer.SetPersonality(cfg.GetAIPersonality())
+	if personality, err := ai.PersonalityByID(opts.Personality); err == nil {
+		aiPlayer.SetPersonality(personality)
+	}
+	puzzleSeed := time.Now().UnixNano()
+	if opts.Seed != 0 {
+		aiPlayer.SetSeed(opts.Seed)
+		puzzleSeed = opts.Seed
+	}
+	if book, err := persistManager.LoadOpeningBook(); err == nil {
+		aiPlayer.SetBook(book)
+	}
 	
 	// Initialize audio manager
 	audioManager := audio.New()
@@ -113,7 +261,19 @@ func New() (*Model, error) {
 		lastUpdateTime:   time.Now(),
 		showStartupAnim:  true,
 		startupAnimPhase: 0,
+		puzzleRand:       rand.New(rand.NewSource(puzzleSeed)),
+		setupRand:        rand.New(rand.NewSource(puzzleSeed)),
+		seed:             opts.Seed,
+		variant:          opts.Variant,
+		aiSeat:           game.SecondSeat,
+		events:           &game.Bus{},
 	}
+	if aiSide == game.PlayerX {
+		model.aiSeat = game.FirstSeat
+	}
+	model.aiHomeSeat = model.aiSeat
+	model.subscribeGameEvents()
+	model.watchGame()
 	
 	// Start animation ticker
 	model.animationTicker = time.NewTicker(time.Duration(1000.0/cfg.GetAnimationSpeed()) * time.Millisecond)
@@ -121,6 +281,11 @@ func New() (*Model, error) {
 	// Initialize board dimensions (will be updated when window size is received)
 	model.updateBoardDimensions()
 	
+	if opts.StartGame {
+		model.showStartupAnim = false
+		model.startCmd = model.startMatch(opts.Mode)
+	}
+	
 	return model, nil
 }
 
@@ -128,6 +293,7 @@ func (m *Model) Init() tea.Cmd {
 	return tea.Batch(
 		m.tickAnimation(),
 		tea.EnableMouseCellMotion,
+		m.startCmd,
 	)
 }
 
@@ -157,8 +323,34 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		
 	case animationTickMsg:
 		m.updateAnimation()
+		if cmd := m.advanceDrop(); cmd != nil {
+			cmds = append(cmds, cmd)
+		}
 		cmds = append(cmds, m.tickAnimation())
 		
+	case lobbyEventMsg:
+		if cmd := m.handleLobbyEvent(msg); cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+		
+	case aiMoveMsg:
+		if cmd := m.applyAIMove(msg); cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+		
+	case qubicMoveMsg:
+		m.applyQubicMove(msg)
+		
+	case gravityMoveMsg:
+		if cmd := m.applyGravityMove(msg); cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+		
+	case multiMoveMsg:
+		if cmd := m.applyMultiMove(msg); cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+		
 	case gameUpdateMsg:
 		// Handle game state updates (AI moves, etc.)
 		if msg.saveRequired {
@@ -168,6 +360,9 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 	}
 	
+	if cmd := m.saveQueuedGame(); cmd != nil {
+		cmds = append(cmds, cmd)
+	}
 	return m, tea.Batch(cmds...)
 }
 
@@ -193,6 +388,28 @@ func (m *Model) View() string {
 		return m.renderStatisticsScreen()
 	case StateQuitConfirm:
 		return m.renderQuitConfirmScreen()
+	case StateLobby:
+		return m.renderLobbyScreen()
+	case StateCorrespondence:
+		return m.renderCorrespondenceScreen()
+	case StatePuzzle:
+		return m.renderPuzzleScreen()
+	case StateVariantMenu:
+		return m.renderVariantMenu()
+	case StateQubic:
+		return m.renderQubicScreen()
+	case StateGravity:
+		return m.renderGravityScreen()
+	case StateQuantum:
+		return m.renderQuantumScreen()
+	case StateSetupMenu:
+		return m.renderSetupMenu()
+	case StateTournament:
+		return m.renderTournamentScreen()
+	case StateMatchSummary:
+		return m.renderMatchSummary()
+	case StateMulti:
+		return m.renderMultiScreen()
 	default:
 		return "Unknown state"
 	}
@@ -221,17 +438,8 @@ func (m *Model) renderStartupScreen() string {
 func (m *Model) renderMainMenu() string {
 	title := m.graphics.GetStartupScreen()
 	
-	menuOptions := []string{
-		"🎮 Player vs Player",
-		"🤖 Player vs AI",
-		"⚙️  Settings",
-		"📊 Statistics",
-		"❓ Help",
-		"🚪 Quit",
-	}
-	
 	menu := ""
-	for i, option := range menuOptions {
+	for i, option := range mainMenuOptions {
 		if i == m.cursorPosition[1] {
 			// Highlight selected option
 			highlighted := "▶ " + option + " ◀"
@@ -270,6 +478,10 @@ func (m *Model) renderGameScreen() string {
 	
 	leftPanel := lipgloss.JoinVertical(lipgloss.Left, status, controls)
 	
+	if m.game.GetMode() == game.PlayerVsNetwork {
+		leftPanel = lipgloss.JoinVertical(lipgloss.Left, m.renderChatPane(), leftPanel)
+	}
+	
 	if m.showHelp {
 		helpPanel := m.renderHelpPanel()
 		leftPanel = lipgloss.JoinVertical(lipgloss.Left, leftPanel, helpPanel)
@@ -302,7 +514,14 @@ func (m *Model) renderGameScreen() string {
 }
 
 func (m *Model) renderGameBoard() string {
-	board := m.game.GetBoard()
+	if m.multiBoard() {
+		return m.renderBoards()
+	}
+	return m.renderBoard(m.game.GetBoard())
+}
+
+// renderBoard draws a board with the cursor over it
+func (m *Model) renderBoard(board [3][3]game.Player) string {
 	boardStr := ""
 	
 	// Create dynamic cell template based on cellSize
@@ -331,12 +550,16 @@ func (m *Model) renderGameBoard() string {
 						cell = xCellTemplate
 					} else if board[row][col] == game.PlayerO {
 						cell = oCellTemplate
+					} else if board[row][col] == game.Blocked {
+						cell = m.createCellString("#")
 					} else {
 						cell = emptyCellTemplate
 					}
 					
-					// Highlight cursor position
-					if row == m.cursorPosition[0] && col == m.cursorPosition[1] {
+					// The winning line pulses over everything else
+					if m.onWinLine(0, row, col) {
+						cell = m.renderWinningCell(cell)
+					} else if row == m.cursorPosition[0] && col == m.cursorPosition[1] {
 						currentCursor := m.cursorSymbols[m.cursorIndex]
 						if board[row][col] == game.Empty {
 							// Show hover effect on empty squares with current cursor symbol
@@ -347,6 +570,14 @@ func (m *Model) renderGameBoard() string {
 							symbol := string(cell[centerPos])
 							cell = m.gradientManager.ApplyToText("▶"+m.createCellString(symbol)[1:len(cell)-1]+"◀")
 						}
+					} else if from, picked := m.slideSource(); picked && from == (game.Position{Row: row, Col: col}) {
+						// Mark the piece picked to move
+						centerPos := m.cellSize / 2
+						symbol := string(cell[centerPos])
+						cell = m.gradientManager.ApplyToText("["+m.createCellString(symbol)[1:len(cell)-1]+"]")
+					} else if board[row][col] == game.Blocked {
+						// Blocked cells stay in the background
+						cell = lipgloss.NewStyle().Faint(true).Render(strings.ReplaceAll(cell, "#", "▓"))
 					} else if board[row][col] != game.Empty {
 						// Apply gradient to played pieces
 						cell = m.gradientManager.ApplyToText(cell)
@@ -397,11 +628,24 @@ func (m *Model) renderGameStatus() string {
 	status := "GAME STATUS\n"
 	status += "───────────\n"
 	
+	rules := m.game.GetRules()
 	currentPlayer := m.game.GetCurrentPlayer()
-	if currentPlayer == game.PlayerX {
-		status += "Current: " + m.gradientManager.ApplyToText("Player X") + "\n"
-	} else {
-		status += "Current: " + m.gradientManager.ApplyToText("Player O") + "\n"
+	status += "Current: " + m.gradientManager.ApplyToText(rules.SeatName(currentPlayer)) + "\n"
+	if !rules.IsClassic() {
+		status += "Rules: " + rules.Variant.Name() + "\n"
+	}
+	if m.game.Setup != nil {
+		status += "Setup: " + m.game.Setup.Name + "\n"
+	}
+	if rules.ChoosesMarks() && m.game.GetStatus() == game.StatusPlaying {
+		status += "Placing: " + m.gradientManager.ApplyToText(string(m.markToPlace())) + " (v to switch)\n"
+	}
+	if m.game.MustSlide() && m.game.GetStatus() == game.StatusPlaying {
+		if from, picked := m.slideSource(); picked {
+			status += fmt.Sprintf("Moving: (%d,%d), pick an empty cell\n", from.Row, from.Col)
+		} else {
+			status += "Moving: pick one of your marks\n"
+		}
 	}
 	
 	gameStatus := m.game.GetStatus()
@@ -409,12 +653,9 @@ func (m *Model) renderGameStatus() string {
 	case game.StatusPlaying:
 		status += "Status: In Progress\n"
 	case game.StatusWon:
-		winner := m.game.GetWinner()
-		if winner == game.PlayerX {
-			status += "Status: " + m.gradientManager.ApplyToText("X Wins!") + "\n"
-		} else {
-			status += "Status: " + m.gradientManager.ApplyToText("O Wins!") + "\n"
-		}
+		// Named as on the game-over screen, e.g. "Order Wins!"
+		winner := rules.SeatName(m.game.GetWinner())
+		status += "Status: " + m.gradientManager.ApplyToText(winner+" Wins!") + "\n"
 	case game.StatusDraw:
 		status += "Status: " + m.gradientManager.ApplyToText("Draw!") + "\n"
 	}
@@ -422,11 +663,33 @@ func (m *Model) renderGameStatus() string {
 	mode := m.game.GetMode()
 	if mode == game.PlayerVsPlayer {
 		status += "Mode: Player vs Player\n"
+	} else if mode == game.PlayerVsCorrespondence {
+		status += "Mode: Correspondence (" + m.corrGameID + ")\n"
+	} else if mode == game.PlayerVsNetwork && m.netGame != nil {
+		status += "Mode: Online vs " + m.netGame.Opponent + "\n"
+		status += "You: " + m.gradientManager.ApplyToText("Player "+string(m.netGame.You)) + "\n"
 	} else {
 		status += "Mode: Player vs AI\n"
-		status += "AI: " + m.config.GetAIDifficultyName() + "\n"
+		personality := m.ai.GetPersonality()
+		status += "AI: " + personality.Name + ", " + m.ai.GetDifficultyName() + " (" + string(m.ai.GetPlayer()) + ")\n"
+		status += lipgloss.NewStyle().Italic(true).Render("\""+personality.Taunt+"\"") + "\n"
+		if m.aiThinking() {
+			elapsed := time.Since(m.aiThinkingSince).Seconds()
+			status += m.gradientManager.ApplyToText(fmt.Sprintf("🤔 Thinking… %.1fs", elapsed)) + "\n"
+		} else if m.aiExplanation != nil {
+			move := m.aiExplanation.Move
+			status += fmt.Sprintf("AI played (%d,%d): %s\n", move.Row, move.Col, m.aiExplanation)
+		}
 	}
 	
+	var aiSeat *game.Seat
+	if mode == game.PlayerVsAI {
+		aiSeat = &m.aiSeat
+	}
+	status += m.renderSeats(m.game, m.game.Opening, m.game.GetStatus() == game.StatusPlaying, aiSeat)
+	status += m.renderMatchStatus()
+	status += m.renderClocks()
+	
 	if m.statusMessage != "" {
 		status += "\n" + m.statusMessage + "\n"
 	}
@@ -444,11 +707,19 @@ func (m *Model) renderGameStatus() string {
 		status += "\nMOVE HISTORY\n"
 		status += "────────────\n"
 		for i, move := range moveHistory {
-			player := "X"
-			if i%2 == 1 {
-				player = "O"
+			player := m.game.MoverAt(i)
+			mark := ""
+			if rules.ChoosesMarks() {
+				mark = " [" + string(m.game.MarkAt(i)) + "]"
+			}
+			if m.multiBoard() {
+				mark = fmt.Sprintf(" on board %d", m.game.MoveAt(i).Board+1)
 			}
-			status += fmt.Sprintf("%d. %s -> (%d,%d)\n", i+1, player, move.Row, move.Col)
+			if from, ok := m.game.Slides[i]; ok {
+				status += fmt.Sprintf("%d. %s (%d,%d) -> (%d,%d)\n", i+1, player, from.Row, from.Col, move.Row, move.Col)
+				continue
+			}
+			status += fmt.Sprintf("%d. %s -> (%d,%d)%s\n", i+1, player, move.Row, move.Col, mark)
 		}
 	}
 	
@@ -459,8 +730,18 @@ func (m *Model) renderGameControls() string {
 	controls := "\nCONTROLS\n"
 	controls += "────────\n"
 	controls += "↑↓←→ Move cursor\n"
+	if m.multiBoard() {
+		controls += "←→ Past the edge: next board\n"
+	}
 	controls += "Enter/Space Place mark\n"
 	controls += "r Reset game\n"
+	controls += "u Undo move\n"
+	if m.game.GetRules().ChoosesMarks() {
+		controls += "v Switch mark\n"
+	}
+	if pieces := m.game.GetRules().Pieces(); pieces > 0 {
+		controls += fmt.Sprintf("After %d marks: pick one, then its cell\n", pieces)
+	}
 	controls += "t Settings\n"
 	controls += "? Toggle help\n"
 	controls += "g Cycle gradient\n"
@@ -494,6 +775,12 @@ func (m *Model) renderSettingsScreen() string {
 	content += "Controls:\n"
 	content += "g - Cycle gradient type\n"
 	content += "d - Cycle AI difficulty\n"
+	content += "m - Cycle time control\n"
+	content += "[/] - Adjust AI strength\n"
+	content += "p - Cycle AI personality\n"
+	content += "o - Cycle swap rule\n"
+	content += "f - Cycle match format\n"
+	content += "i - Cycle multi-player AI search\n"
 	content += "+/- - Adjust animation speed\n"
 	content += "r - Reset to defaults\n"
 	content += "esc - Back to menu\n"
@@ -513,18 +800,25 @@ func (m *Model) renderGameOverScreen() string {
 	
 	var message string
 	if status == game.StatusWon {
-		if winner == game.PlayerX {
-			message = "🎉 PLAYER X WINS! 🎉"
-		} else {
-			message = "🎉 PLAYER O WINS! 🎉"
-		}
+		message = "🎉 " + strings.ToUpper(m.game.GetRules().SeatName(winner)) + " WINS! 🎉"
 	} else {
 		message = "🤝 IT'S A DRAW! 🤝"
 	}
 	
 	content := m.gradientManager.ApplyToText(message) + "\n\n"
-	content += "Press 'r' to play again\n"
-	content += "Press 'esc' for main menu\n"
+	if m.game.TimeLoss {
+		loser := game.PlayerX
+		if winner == game.PlayerX {
+			loser = game.PlayerO
+		}
+		content += fmt.Sprintf("Player %s ran out of time\n\n", loser)
+	}
+	if m.inMatch() {
+		content += m.renderMatchProgress()
+	} else {
+		content += "Press 'r' to play again\n"
+		content += "Press 'esc' for main menu\n"
+	}
 	content += "Press 'q' to quit\n"
 	
 	style := lipgloss.NewStyle().
@@ -552,12 +846,30 @@ func (m *Model) renderHelpScreen() string {
 
 // Animation and update functions
 type animationTickMsg struct{}
+
+// aiMoveMsg carries the result of a background AI search
+type aiMoveMsg struct {
+	search int // The search that produced it; older searches were cancelled
+	move   game.Move
+	swap   *game.SwapChoice // The AI's swap decision, made instead of a move
+	err    error
+}
+
+// aiMoveBudget caps how long the AI may think in untimed games
+const aiMoveBudget = 5 * time.Second
 type gameUpdateMsg struct {
 	saveRequired bool
 }
 
 func (m *Model) tickAnimation() tea.Cmd {
-	return tea.Tick(time.Duration(1000.0/m.config.GetAnimationSpeed())*time.Millisecond, func(t time.Time) tea.Msg {
+	interval := time.Duration(1000.0/m.config.GetAnimationSpeed()) * time.Millisecond
+	if m.gravityDrop != nil {
+		interval /= dropTickDivisor
+	}
+	if m.winLine != nil {
+		interval /= winTickDivisor
+	}
+	return tea.Tick(interval, func(t time.Time) tea.Msg {
 		return animationTickMsg{}
 	})
 }
@@ -565,6 +877,10 @@ func (m *Model) tickAnimation() tea.Cmd {
 func (m *Model) updateAnimation() {
 	m.lastUpdateTime = time.Now()
 	
+	m.pollCorrespondence()
+	m.checkLocalClock(m.lastUpdateTime)
+	m.advanceWinAnimation()
+	
 	if m.state == StateStartup {
 		m.startupAnimPhase++
 		if m.startupAnimPhase > 180 { // 3 seconds at 60fps
@@ -583,6 +899,14 @@ func (m *Model) handleKeyAction(action input.KeybindingAction, keyMsg tea.KeyMsg
 	m.statusMessage = ""
 	m.errorMessage = ""
 	
+	// Typed text goes to the chat box or a player's name rather than the keybindings
+	if m.inputHandler.GetMode() == input.ModeText {
+		if m.state == StateTournament {
+			return m.handleTournamentNameInput(action)
+		}
+		return m.handleChatInput(action)
+	}
+	
 	switch m.state {
 	case StateStartup:
 		if action != input.ActionUnknown {
@@ -612,10 +936,44 @@ func (m *Model) handleKeyAction(action input.KeybindingAction, keyMsg tea.KeyMsg
 		
 	case StateQuitConfirm:
 		return m.handleQuitConfirmInput(action, keyMsg)
+		
+	case StateLobby:
+		return m.handleLobbyInput(action)
+		
+	case StateCorrespondence:
+		return m.handleCorrespondenceInput(action)
+		
+	case StatePuzzle:
+		return m.handlePuzzleInput(action)
+		
+	case StateVariantMenu:
+		return m.handleVariantMenuInput(action)
+		
+	case StateQubic:
+		return m.handleQubicInput(action)
+		
+	case StateGravity:
+		return m.handleGravityInput(action)
+		
+	case StateQuantum:
+		return m.handleQuantumInput(action)
+		
+	case StateSetupMenu:
+		return m.handleSetupMenuInput(action)
+		
+	case StateTournament:
+		return m.handleTournamentInput(action)
+		
+	case StateMatchSummary:
+		return m.handleMatchSummaryInput(action)
+		
+	case StateMulti:
+		return m.handleMultiInput(action)
 	}
 	
 	// Global actions
 	if action == input.ActionQuit {
+		m.cancelAIMove()
 		m.state = StateQuitConfirm
 		return nil
 	}
@@ -630,7 +988,7 @@ func (m *Model) handleMainMenuInput(action input.KeybindingAction) tea.Cmd {
 			m.cursorPosition[1]--
 		}
 	case input.ActionMoveDown:
-		if m.cursorPosition[1] < 5 { // 0-5 for 6 menu options
+		if m.cursorPosition[1] < len(mainMenuOptions)-1 {
 			m.cursorPosition[1]++
 		}
 	case input.ActionSelect:
@@ -653,6 +1011,15 @@ func (m *Model) handleMainMenuInput(action input.KeybindingAction) tea.Cmd {
 	case input.ActionMenu6:
 		m.cursorPosition[1] = 5
 		return m.selectMainMenuItem()
+	case input.ActionMenu7:
+		m.cursorPosition[1] = 6
+		return m.selectMainMenuItem()
+	case input.ActionMenu8:
+		m.cursorPosition[1] = 7
+		return m.selectMainMenuItem()
+	case input.ActionMenu9:
+		m.cursorPosition[1] = 8
+		return m.selectMainMenuItem()
 	case input.ActionBack:
 		return tea.Quit
 	}
@@ -662,34 +1029,56 @@ func (m *Model) handleMainMenuInput(action input.KeybindingAction) tea.Cmd {
 func (m *Model) selectMainMenuItem() tea.Cmd {
 	switch m.cursorPosition[1] {
 	case 0: // Player vs Player
-		m.game.Reset()
-		m.game.SetMode(game.PlayerVsPlayer)
-		m.state = StateGame
-		m.cursorPosition = [2]int{1, 1}
-		return func() tea.Msg {
-			return gameUpdateMsg{saveRequired: true}
-		}
+		return m.startMatch(game.PlayerVsPlayer)
 	case 1: // Player vs AI
-		m.game.Reset()
-		m.game.SetMode(game.PlayerVsAI)
-		m.state = StateGame
-		m.cursorPosition = [2]int{1, 1}
-		return func() tea.Msg {
-			return gameUpdateMsg{saveRequired: true}
-		}
-	case 2: // Settings
+		return m.startMatch(game.PlayerVsAI)
+	case 2: // Puzzles
+		m.startPuzzles()
+	case 3: // Rule Variants
+		m.openVariantMenu()
+	case 4: // 3D Qubic
+		m.startQubic()
+	case 5: // Gravity
+		m.startGravity()
+	case 6: // Quantum
+		m.startQuantum()
+	case 7: // Game Setup
+		m.openSetupMenu()
+	case 8: // Tournament
+		m.openTournament()
+	case 9: // Multi-player
+		m.startMulti()
+	case 10: // Online Lobby
+		m.state = StateLobby
+		return m.connectLobby()
+	case 11: // Correspondence
+		m.state = StateCorrespondence
+		m.refreshCorrespondence()
+	case 12: // Settings
 		m.state = StateSettings
-	case 3: // Statistics
+	case 13: // Statistics
 		m.state = StateStatistics
-	case 4: // Help
+	case 14: // Help
 		m.state = StateHelp
-	case 5: // Quit
+	case 15: // Quit
 		return tea.Quit
 	}
 	return nil
 }
 
 func (m *Model) handleGameInput(action input.KeybindingAction) tea.Cmd {
+	// Any key skips the rest of the winning line's animation
+	if m.winLine != nil {
+		m.endWinAnimation()
+		return nil
+	}
+	
+	if _, _, pending := m.game.SwapPending(); pending {
+		if cmd, handled := m.handleSwapInput(action); handled {
+			return cmd
+		}
+	}
+	
 	switch action {
 	case input.ActionMoveUp:
 		x, y := m.inputHandler.MoveCursor(action)
@@ -698,20 +1087,31 @@ func (m *Model) handleGameInput(action input.KeybindingAction) tea.Cmd {
 		x, y := m.inputHandler.MoveCursor(action)
 		m.cursorPosition = [2]int{y, x} // Store as [row, col] for consistent rendering
 	case input.ActionMoveLeft:
+		if m.crossBoard(action) {
+			return nil
+		}
 		x, y := m.inputHandler.MoveCursor(action)
 		m.cursorPosition = [2]int{y, x} // Store as [row, col] for consistent rendering
 	case input.ActionMoveRight:
+		if m.crossBoard(action) {
+			return nil
+		}
 		x, y := m.inputHandler.MoveCursor(action)
 		m.cursorPosition = [2]int{y, x} // Store as [row, col] for consistent rendering
 	case input.ActionSelect:
 		return m.makeMove()
+	case input.ActionUndo:
+		return m.undoMove()
+	case input.ActionToggleMark:
+		m.toggleMark()
 	case input.ActionReset:
-		m.game.Reset()
-		m.cursorPosition = [2]int{1, 1}
-		return func() tea.Msg {
-			return gameUpdateMsg{saveRequired: true}
+		if m.game.GetMode() == game.PlayerVsNetwork || m.game.GetMode() == game.PlayerVsCorrespondence {
+			m.statusMessage = "Shared games cannot be reset"
+			return nil
 		}
+		return m.startLocalGame(m.game.GetMode())
 	case input.ActionSettings:
+		m.cancelAIMove()
 		m.state = StateSettings
 	case input.ActionHelp:
 		m.showHelp = !m.showHelp
@@ -725,9 +1125,66 @@ func (m *Model) handleGameInput(action input.KeybindingAction) tea.Cmd {
 	case input.ActionCycleCursor:
 		m.cursorIndex = (m.cursorIndex + 1) % len(m.cursorSymbols)
 		m.statusMessage = fmt.Sprintf("Cursor changed to: %s", m.cursorSymbols[m.cursorIndex])
+	case input.ActionChat:
+		m.openChat()
+	case input.ActionEmote1, input.ActionEmote2, input.ActionEmote3, input.ActionEmote4:
+		m.sendEmote(int(action - input.ActionEmote1))
 	case input.ActionBack:
-		m.state = StateMainMenu
-		m.cursorPosition = [2]int{1, 0}
+		if _, picked := m.slideSource(); picked {
+			m.inputHandler.ClearSource()
+			m.statusMessage = "Move cancelled"
+			return nil
+		}
+		m.cancelAIMove()
+		if m.game.GetMode() == game.PlayerVsCorrespondence {
+			m.state = StateCorrespondence
+			m.refreshCorrespondence()
+			return nil
+		}
+		m.leaveMatch()
+	case input.ActionQuit:
+		// Stop a search in progress so its result cannot land after quitting
+		m.cancelAIMove()
+		m.state = StateQuitConfirm
+	}
+	return nil
+}
+
+// undoMove takes back the last move, or against the AI the human's last move
+// and the AI's reply, stopping the AI if it is still thinking
+func (m *Model) undoMove() tea.Cmd {
+	mode := m.game.GetMode()
+	switch {
+	case mode == game.PlayerVsNetwork || mode == game.PlayerVsCorrespondence:
+		m.statusMessage = "Shared games cannot be undone"
+		return nil
+	case m.game.Clock != nil:
+		m.statusMessage = "Timed games cannot be undone"
+		return nil
+	case m.game.Opening.Rule != game.NoSwap:
+		m.statusMessage = "Games with a swap rule cannot be undone"
+		return nil
+	}
+	
+	// Keep the moves before the last one made by a human
+	moves := m.game.GetMoveHistory()
+	keep := len(moves) - 1
+	for mode == game.PlayerVsAI && keep >= 0 && m.game.MoverAt(keep) == m.ai.GetPlayer() {
+		keep--
+	}
+	if keep < 0 {
+		m.statusMessage = "Nothing to undo"
+		return nil
+	}
+	
+	m.cancelAIMove()
+	if err := m.game.Undo(len(moves) - keep); err != nil {
+		m.errorMessage = err.Error()
+		return nil
+	}
+	m.statusMessage = "Took back your last move"
+	if len(moves)-keep > 1 {
+		m.statusMessage = "Took back your last move and the AI's reply"
 	}
 	return nil
 }
@@ -740,75 +1197,149 @@ func (m *Model) makeMove() tea.Cmd {
 	
 	// Use the cursor position directly (already stored as [row, col])
 	row, col := m.cursorPosition[0], m.cursorPosition[1]
-	if err := m.game.MakeMove(row, col); err != nil {
+	
+	// Online moves are applied when the server echoes the new state
+	if m.game.GetMode() == game.PlayerVsNetwork {
+		return m.sendNetworkMove(row, col)
+	}
+	if m.game.GetMode() == game.PlayerVsCorrespondence {
+		return m.playCorrespondenceMove(row, col)
+	}
+	
+	if m.aiThinking() {
+		m.statusMessage = "Wait for the AI to move"
+		return nil
+	}
+	
+	var err error
+	if m.game.MustSlide() {
+		from, ok := m.pickSlide(row, col)
+		if !ok {
+			return nil
+		}
+		err = m.game.MakeSlide(from, game.Position{Row: row, Col: col})
+	} else if m.multiBoard() {
+		err = m.game.MakeMoveOn(m.cursorBoard, row, col)
+	} else {
+		err = m.game.MakeMoveMark(row, col, m.markToPlace())
+	}
+	if err != nil {
 		m.errorMessage = err.Error()
 		m.audioManager.PlaySound(audio.SoundError)
 		return nil
 	}
+	m.markChoice = game.Empty
+	m.inputHandler.ClearSource()
 	
-	// Play move sound
-	m.audioManager.PlaySound(audio.SoundMove)
-	
-	// Check if game is over
-	if m.game.GetStatus() != game.StatusPlaying {
-		m.recordGameScore()
-		
-		// Play appropriate end game sound
-		if m.game.GetStatus() == game.StatusWon {
-			m.audioManager.PlaySound(audio.SoundWin)
-		} else if m.game.GetStatus() == game.StatusDraw {
-			m.audioManager.PlaySound(audio.SoundDraw)
-		}
-		
-		m.state = StateGameOver
+	// Sounds, the score and the save follow from the game's events
+	if m.aiToMove() {
+		return m.startAIMove()
+	}
+	return nil
+}
+
+// pickSlide handles a pick once the player's marks are all placed: the first
+// pick chooses one of their marks and the second where it moves. It returns
+// the mark to move when (row, col) is its destination.
+func (m *Model) pickSlide(row, col int) (game.Position, bool) {
+	from, picked := m.slideSource()
+	to := game.Position{Row: row, Col: col}
+	switch {
+	case m.game.GetBoard()[row][col] == m.game.GetCurrentPlayer() && (!picked || from != to):
+		m.inputHandler.SelectSource(col, row)
+		m.statusMessage = "Choose where to move it"
+		return game.Position{}, false
+	case !picked:
+		m.errorMessage = fmt.Sprintf("Pick one of your %s marks to move", m.game.GetCurrentPlayer())
+		m.audioManager.PlaySound(audio.SoundError)
+		return game.Position{}, false
+	case from == to:
+		m.inputHandler.ClearSource()
+		m.statusMessage = "Move cancelled"
+		return game.Position{}, false
+	}
+	return from, true
+}
+
+// slideSource returns the mark picked to move, if any
+func (m *Model) slideSource() (game.Position, bool) {
+	col, row, ok := m.inputHandler.GetSource()
+	return game.Position{Row: row, Col: col}, ok
+}
+
+// startAIMove searches for the AI's move on a copy of the game in a tea.Cmd,
+// so the UI keeps running while the AI thinks
+func (m *Model) startAIMove() tea.Cmd {
+	m.cancelAIMove()
+	ctx, cancel := context.WithCancel(context.Background())
+	m.aiCancel = cancel
+	m.aiSearch++
+	m.aiThinkingSince = time.Now()
+	
+	search, player, position := m.aiSearch, m.ai, m.game.Clone()
+	if _, _, pending := position.SwapPending(); pending {
 		return func() tea.Msg {
-			return gameUpdateMsg{saveRequired: true}
+			choice, err := player.DecideSwap(ctx, position)
+			return aiMoveMsg{search: search, swap: &choice, err: err}
 		}
 	}
-	
-	// Handle AI move if in AI mode
-	if m.game.GetMode() == game.PlayerVsAI && m.game.GetCurrentPlayer() == game.PlayerO {
-		return m.makeAIMove()
-	}
-	
 	return func() tea.Msg {
-		return gameUpdateMsg{saveRequired: true}
+		move, err := player.GetMoveMark(ctx, position, aiMoveBudget)
+		return aiMoveMsg{search: search, move: move, err: err}
+	}
+}
+
+// cancelAIMove stops the running search, if any; its result will be dropped
+func (m *Model) cancelAIMove() {
+	if m.aiCancel != nil {
+		m.aiCancel()
+		m.aiCancel = nil
 	}
 }
 
-func (m *Model) makeAIMove() tea.Cmd {
-	aiX, aiY, err := m.ai.GetMove(m.game)
+// aiThinking reports whether a search for the AI's move is running
+func (m *Model) aiThinking() bool {
+	return m.aiCancel != nil
+}
+
+// applyAIMove plays the result of the current search; results of searches
+// cancelled by an undo, reset or quit are stale and ignored
+func (m *Model) applyAIMove(msg aiMoveMsg) tea.Cmd {
+	if msg.search != m.aiSearch || !m.aiThinking() {
+		return nil
+	}
+	m.cancelAIMove()
+	if !m.aiToMove() {
+		return nil
+	}
+	
+	move, err := msg.move, msg.err
 	if err != nil {
 		m.errorMessage = "AI move failed: " + err.Error()
 		return nil
 	}
+	if msg.swap != nil {
+		return m.chooseSwap(*msg.swap)
+	}
 	
-	if err := m.game.MakeMove(aiX, aiY); err != nil {
+	// Explanations read the position by classic rules from the empty board
+	var explanation *ai.Explanation
+	if m.game.IsStandard() {
+		why := ai.Explain(m.game, game.Position{Row: move.Row, Col: move.Col})
+		explanation = &why
+	}
+	if err := m.game.Play(move); err != nil {
 		m.errorMessage = "AI move error: " + err.Error()
 		m.audioManager.PlaySound(audio.SoundError)
 		return nil
 	}
+	m.aiExplanation = explanation
 	
-	// Play AI move sound
-	m.audioManager.PlaySound(audio.SoundMove)
-	
-	// Check if game is over after AI move
-	if m.game.GetStatus() != game.StatusPlaying {
-		m.recordGameScore()
-		
-		// Play appropriate end game sound
-		if m.game.GetStatus() == game.StatusWon {
-			m.audioManager.PlaySound(audio.SoundWin)
-		} else if m.game.GetStatus() == game.StatusDraw {
-			m.audioManager.PlaySound(audio.SoundDraw)
-		}
-		
-		m.state = StateGameOver
-	}
-	
-	return func() tea.Msg {
-		return gameUpdateMsg{saveRequired: true}
+	// A swap rule can give the AI several opening marks or a decision in a row
+	if m.aiToMove() {
+		return m.startAIMove()
 	}
+	return nil
 }
 
 func (m *Model) handleSettingsInput(action input.KeybindingAction) tea.Cmd {
@@ -824,9 +1355,52 @@ func (m *Model) handleSettingsInput(action input.KeybindingAction) tea.Cmd {
 		if err := m.config.NextAIDifficulty(); err != nil {
 			m.errorMessage = "Failed to change AI difficulty: " + err.Error()
 		} else {
-			m.ai = ai.New(m.config.GetAIDifficulty(), "O")
+			m.ai = m.config.NewAI(m.ai.GetPlayer())
+			m.loadOpeningBook()
 			m.statusMessage = "AI difficulty changed to " + m.config.GetAIDifficultyName()
 		}
+	case input.ActionCyclePersonality:
+		if err := m.config.NextAIPersonality(); err != nil {
+			m.errorMessage = "Failed to change AI personality: " + err.Error()
+		} else {
+			m.ai.SetPersonality(m.config.GetAIPersonality())
+			m.statusMessage = "AI personality changed to " + m.config.GetAIPersonality().Name
+		}
+	case input.ActionStrengthUp, input.ActionStrengthDown:
+		step := 0.05
+		if action == input.ActionStrengthDown {
+			step = -step
+		}
+		if err := m.config.SetAIStrength(m.config.GetAIStrength() + step); err != nil {
+			m.errorMessage = "Failed to change AI strength: " + err.Error()
+		} else {
+			m.ai.SetStrength(m.config.GetAIStrength())
+			m.statusMessage = fmt.Sprintf("AI strength set to %.2f", m.config.GetAIStrength())
+		}
+	case input.ActionCycleTimeControl:
+		if err := m.config.NextTimeControl(); err != nil {
+			m.errorMessage = "Failed to change time control: " + err.Error()
+		} else {
+			m.statusMessage = "Time control changed to " + m.config.GetTimeControl().String()
+		}
+	case input.ActionCycleSwapRule:
+		if err := m.config.NextSwapRule(); err != nil {
+			m.errorMessage = "Failed to change swap rule: " + err.Error()
+		} else {
+			m.statusMessage = "Swap rule changed to " + m.config.GetSwapRule().Name()
+		}
+	case input.ActionCycleMatch:
+		if err := m.config.NextMatchFormat(); err != nil {
+			m.errorMessage = "Failed to change match format: " + err.Error()
+		} else {
+			m.statusMessage = "Match format changed to " + m.config.GetMatchFormat().String()
+		}
+	case input.ActionCycleSearch:
+		if err := m.config.NextMultiSearch(); err != nil {
+			m.errorMessage = "Failed to change AI search: " + err.Error()
+		} else {
+			m.statusMessage = "Multi-player AI search changed to " + m.config.GetMultiSearch().Name()
+		}
 	case input.ActionSpeedUp:
 		if err := m.config.IncreaseAnimationSpeed(); err != nil {
 			m.errorMessage = "Failed to increase speed: " + err.Error()
@@ -855,23 +1429,119 @@ func (m *Model) handleSettingsInput(action input.KeybindingAction) tea.Cmd {
 }
 
 func (m *Model) handleGameOverInput(action input.KeybindingAction) tea.Cmd {
-	switch action {
-	case input.ActionReset:
+	// Online games return to the lobby instead of restarting locally
+	if m.game.GetMode() == game.PlayerVsNetwork && (action == input.ActionReset || action == input.ActionBack) {
+		m.netGame = nil
 		m.game.Reset()
-		m.state = StateGame
-		m.cursorPosition = [2]int{1, 1}
-		return func() tea.Msg {
-			return gameUpdateMsg{saveRequired: true}
+		m.game.SetMode(game.PlayerVsPlayer)
+		m.state = StateLobby
+		return m.requestLobby()
+	}
+	
+	// Correspondence games return to the game list
+	if m.game.GetMode() == game.PlayerVsCorrespondence && (action == input.ActionReset || action == input.ActionBack) {
+		m.state = StateCorrespondence
+		m.refreshCorrespondence()
+		return nil
+	}
+	
+	switch action {
+	case input.ActionReset, input.ActionSelect:
+		if m.inMatch() {
+			return m.nextMatchGame()
+		}
+		if action == input.ActionReset {
+			return m.startLocalGame(m.game.GetMode())
 		}
 	case input.ActionBack:
-		m.state = StateMainMenu
-		m.cursorPosition = [2]int{1, 0}
+		m.leaveMatch()
+	}
+	return nil
+}
+
+// startLocalGame begins a fresh local game under the configured time control
+func (m *Model) startLocalGame(mode game.GameMode) tea.Cmd {
+	m.cancelAIMove()
+	m.watchGame()
+	m.game.Reset()
+	m.game.SetMode(mode)
+	m.game.SetRules(m.localRules())
+	m.game.SetSwapRule(m.config.GetSwapRule())
+	m.seatMatchPlayers()
+	m.ai.SetPlayer(m.game.Opening.ColorOf(m.aiSeat))
+	setup, err := m.resolveSetup(m.game.GetRules())
+	if err == nil {
+		err = m.game.ApplySetup(setup)
+	}
+	if err != nil {
+		m.errorMessage = err.Error()
+	}
+	m.game.SetTimeControl(m.config.GetTimeControl())
+	m.game.StartClock(time.Now())
+	m.state = StateGame
+	m.cursorPosition = [2]int{1, 1}
+	m.cursorBoard = 0
+	
+	// The AI opens when the human plays O
+	if m.aiToMove() {
+		return m.startAIMove()
 	}
 	return nil
 }
 
+// localRules returns the rules for new local games: the command line's
+// variant, or the configured one
+func (m *Model) localRules() game.Rules {
+	if m.variant != nil {
+		return m.config.RulesFor(*m.variant)
+	}
+	return m.config.GetRules()
+}
+
+// checkLocalClock ends a local game when the player to move runs out of time;
+// online games are flagged by the server
+func (m *Model) checkLocalClock(now time.Time) {
+	if m.state != StateGame || m.game.GetMode() == game.PlayerVsNetwork {
+		return
+	}
+	// The loss on time is recorded and saved through the game's events
+	m.game.CheckTime(now)
+}
+
+// renderClocks shows both players' remaining time, marking whose clock is running
+func (m *Model) renderClocks() string {
+	if m.game.Clock == nil {
+		return ""
+	}
+
+	now := time.Now()
+	clocks := "Clock: " + m.game.Clock.Control.String() + "\n"
+	for _, player := range []game.Player{game.PlayerX, game.PlayerO} {
+		marker := " "
+		if m.game.GetStatus() == game.StatusPlaying && m.game.GetCurrentPlayer() == player {
+			marker = "▶"
+		}
+		clocks += fmt.Sprintf("⏱ %s %s %s\n", marker, player, game.FormatClock(m.game.GetRemainingTime(player, now)))
+	}
+	return clocks
+}
+
 func (m *Model) handleMouseClick(mouseClick *input.MouseClickMsg) tea.Cmd {
-	if m.state != StateGame {
+	if m.state == StateGravity {
+		// Any click on a column drops into it
+		if col, ok := m.gravityColumnAt(mouseClick.X, mouseClick.Y); ok {
+			m.gravityCursor = col
+			return m.dropGravityMark()
+		}
+		return nil
+	}
+	
+	if m.state != StateGame && m.state != StatePuzzle {
+		return nil
+	}
+	
+	// Clicks map onto a single board; several are played from the keyboard
+	if m.state == StateGame && m.multiBoard() {
 		return nil
 	}
 	
@@ -885,6 +1555,9 @@ func (m *Model) handleMouseClick(mouseClick *input.MouseClickMsg) tea.Cmd {
 	m.inputHandler.SetCursorPosition(col, row)
 	m.cursorPosition = [2]int{row, col} // Store as [row, col] for consistent rendering
 	
+	if m.state == StatePuzzle {
+		return m.answerPuzzle()
+	}
 	return m.makeMove()
 }
 
@@ -954,6 +1627,121 @@ func (m *Model) renderStatisticsScreen() string {
 		}
 	}
 	
+	// Most common openings against the AI
+	content += "\n" + m.gradientManager.ApplyToText("📖 YOUR OPENINGS") + "\n"
+	content += "─────────────────\n"
+	openings, err := m.persistManager.HumanOpenings(5)
+	if err != nil {
+		content += "Error loading openings: " + err.Error() + "\n"
+	} else if len(openings) == 0 {
+		content += "No games against the AI yet\n"
+	}
+	for _, opening := range openings {
+		content += fmt.Sprintf("%s (as %s): %d games, %.1f%% won\n",
+			opening.Name, opening.Side, opening.Games, opening.WinRate()*100)
+	}
+	
+	// Puzzle training
+	content += "\n" + m.gradientManager.ApplyToText("🧩 PUZZLES") + "\n"
+	content += "──────────\n"
+	if puzzleStats, err := m.persistManager.LoadPuzzleStats(); err != nil {
+		content += "Error loading puzzles: " + err.Error() + "\n"
+	} else if puzzleStats.Attempts == 0 {
+		content += "No puzzles tried yet\n"
+	} else {
+		content += fmt.Sprintf("Solved: %d of %d tries (%d different puzzles)\n",
+			puzzleStats.Solved, puzzleStats.Attempts, solvedPuzzles(puzzleStats))
+		content += fmt.Sprintf("Streak: %d (best %d)\n", puzzleStats.Streak, puzzleStats.BestStreak)
+	}
+	
+	// Games under rule variants
+	content += "\n" + m.gradientManager.ApplyToText("🎲 RULE VARIANTS") + "\n"
+	content += "────────────────\n"
+	played := false
+	for _, variant := range game.Variants {
+		stats := scores.Variants[string(variant)]
+		if stats == nil || stats.Games == 0 {
+			continue
+		}
+		played = true
+		content += fmt.Sprintf("%s: %d games, %s %d, %s %d, draws %d\n", variant.Name(), stats.Games,
+			game.Rules{Variant: variant}.SeatName(game.PlayerX), stats.XWins,
+			game.Rules{Variant: variant}.SeatName(game.PlayerO), stats.OWins, stats.Draws)
+	}
+	if !played {
+		content += "No variant games yet\n"
+	}
+	
+	// Gravity games by board
+	content += "\n" + m.gradientManager.ApplyToText("🔻 GRAVITY") + "\n"
+	content += "──────────\n"
+	played = false
+	for _, shape := range gravity.Shapes {
+		stats := scores.Gravity[shape.Name()]
+		if stats == nil || stats.Games == 0 {
+			continue
+		}
+		played = true
+		content += fmt.Sprintf("%s: %d games, you %d, AI %d, draws %d\n", shape, stats.Games, stats.XWins, stats.OWins, stats.Draws)
+	}
+	if !played {
+		content += "No gravity games yet\n"
+	}
+	
+	// Multi-player games by players and board
+	content += "\n" + m.gradientManager.ApplyToText("👥 MULTI-PLAYER") + "\n"
+	content += "───────────────\n"
+	played = false
+	for _, shape := range multi.Shapes {
+		stats := scores.Multi[shape.Name()]
+		if stats == nil || stats.Games == 0 {
+			continue
+		}
+		played = true
+		wins := make([]string, 0, shape.Players)
+		for _, player := range game.Rotation(shape.Players) {
+			wins = append(wins, fmt.Sprintf("%s %d", multiPlayerName(player), stats.Wins[string(player)]))
+		}
+		content += fmt.Sprintf("%s: %d games, %s, draws %d\n", shape, stats.Games, strings.Join(wins, ", "), stats.Draws)
+	}
+	if !played {
+		content += "No multi-player games yet\n"
+	}
+	
+	// Games from setups other than the empty board
+	content += "\n" + m.gradientManager.ApplyToText("🧱 SETUPS") + "\n"
+	content += "─────────\n"
+	played = false
+	for _, name := range setupStatsOrder(scores.Setups) {
+		stats := scores.Setups[name]
+		if stats.Games == 0 {
+			continue
+		}
+		played = true
+		content += fmt.Sprintf("%s: %d games, X %d, O %d, draws %d\n", name, stats.Games, stats.XWins, stats.OWins, stats.Draws)
+	}
+	if !played {
+		content += "No setup games yet\n"
+	}
+	
+	// Matches and tournaments
+	content += "\n" + m.gradientManager.ApplyToText("🏆 MATCHES") + "\n"
+	content += "──────────\n"
+	if matches := scores.Matches; matches.Played == 0 {
+		content += "No matches played yet\n"
+	} else {
+		content += fmt.Sprintf("Matches: %d (%d drawn)\n", matches.Played, matches.Drawn)
+		for _, name := range rankedNames(matches.Wins) {
+			content += fmt.Sprintf("  %s: %d won\n", name, matches.Wins[name])
+		}
+		if matches.Tournaments > 0 {
+			content += fmt.Sprintf("Tournaments: %d\n", matches.Tournaments)
+			for _, name := range rankedNames(matches.Champions) {
+				content += fmt.Sprintf("  🏆 %s: %d won\n", name, matches.Champions[name])
+			}
+		}
+	}
+	
 	content += "\n" + lipgloss.NewStyle().Faint(true).Render("r - Reset all statistics • esc - Back to menu")
 	
 	style := lipgloss.NewStyle().
@@ -991,18 +1779,98 @@ func (m *Model) recordGameScore() {
 	mode := m.game.GetMode()
 	winner := m.game.GetWinner()
 	
-	if mode == game.PlayerVsPlayer {
+	if setup := m.game.Setup; setup != nil {
+		// Games from a setup keep their own record, whoever played them
+		if err := m.persistManager.UpdateSetupScore(setup.Name, winner); err != nil {
+			m.errorMessage = "Failed to save score: " + err.Error()
+		}
+	} else if rules := m.game.GetRules(); !rules.IsClassic() {
+		// Variant games keep their own record, whoever played them
+		if err := m.persistManager.UpdateVariantScore(rules.Variant, winner); err != nil {
+			m.errorMessage = "Failed to save score: " + err.Error()
+		}
+	} else if mode == game.PlayerVsPlayer {
 		// Record Player vs Player score
 		if err := m.persistManager.UpdatePlayerVsPlayerScore(winner); err != nil {
 			m.errorMessage = "Failed to save score: " + err.Error()
 		}
 	} else if mode == game.PlayerVsAI {
 		// Record Player vs AI score
-		difficulty := m.config.GetAIDifficulty()
-		if err := m.persistManager.UpdatePlayerVsAIScore(difficulty, winner, game.PlayerO); err != nil {
+		if err := m.persistManager.UpdatePlayerVsAIScore(m.ai.GetDifficulty(), winner, m.ai.GetPlayer()); err != nil {
 			m.errorMessage = "Failed to save score: " + err.Error()
 		}
 	}
+	
+	m.archiveLocalGame()
+}
+
+// archiveLocalGame stores a finished local game so it can be exported or replayed
+func (m *Model) archiveLocalGame() {
+	record := persistence.ArchivedGame{
+		Mode:    int(m.game.GetMode()),
+		PlayerX: "Player X",
+		PlayerO: "Player O",
+	}
+	if m.game.GetMode() == game.PlayerVsAI {
+		if m.ai.GetPlayer() == game.PlayerX {
+			record.PlayerX = m.aiName()
+		} else {
+			record.PlayerO = m.aiName()
+		}
+	}
+	if m.inMatch() {
+		// The match records the game once it is archived, so it is the next one
+		record.PlayerX = m.matchPlayerOf(game.PlayerX)
+		record.PlayerO = m.matchPlayerOf(game.PlayerO)
+		record.Match = &persistence.MatchGame{ID: m.matchID, Game: len(m.match.Results) + 1}
+	}
+	m.archiveGame(record)
+}
+
+// aiName names the AI in archived games and matches, e.g. "AI (Normal)"
+func (m *Model) aiName() string {
+	return "AI (" + m.ai.GetDifficultyName() + ")"
+}
+
+// archiveGame fills in the winner and moves of the current game and archives it
+func (m *Model) archiveGame(record persistence.ArchivedGame) {
+	record.Winner = string(m.game.GetWinner())
+	rules := m.game.GetRules()
+	if !rules.IsClassic() {
+		record.Variant = string(rules.Variant)
+	}
+	record.Setup = m.game.Setup
+	record.Swap = string(m.game.Opening.Rule)
+	if boards := rules.BoardCount(); boards > 1 {
+		record.Boards = boards
+	}
+	for i, move := range m.game.GetMoveHistory() {
+		position := persistence.Position{Row: move.Row, Col: move.Col}
+		if rules.ChoosesMarks() {
+			position.Mark = string(m.game.MarkAt(i))
+		}
+		if from, ok := m.game.Slides[i]; ok {
+			position.From = &persistence.Position{Row: from.Row, Col: from.Col}
+		}
+		position.Board = m.game.OnBoard[i]
+		record.Moves = append(record.Moves, position)
+	}
+
+	if err := m.persistManager.ArchiveGame(record); err != nil {
+		m.errorMessage = "Failed to archive game: " + err.Error()
+		return
+	}
+	m.loadOpeningBook()
+}
+
+// loadOpeningBook gives the AI the opening book, including the latest archived games
+func (m *Model) loadOpeningBook() {
+	book, err := m.persistManager.LoadOpeningBook()
+	if err != nil {
+		m.errorMessage = "Failed to load opening book: " + err.Error()
+		return
+	}
+	m.ai.SetBook(book)
 }
 
 func (m *Model) renderQuitConfirmScreen() string {
@@ -1186,6 +2054,12 @@ func (m *Model) calculateCellHeight() int {
 
 // estimateBoardWidth calculates the approximate width of the rendered board
 func (m *Model) estimateBoardWidth() int {
+	if m.multiBoard() {
+		// Compact boards two columns apart
+		boards := len(m.game.Extra) + 1
+		return boards*compactBoardWidth + (boards-1)*2
+	}
+	
 	// 3 cells + 2 separators + borders + padding
 	cellWidth := m.cellSize * 3
 	separatorWidth := 6 // 2 * " │ " 
@@ -1287,8 +2161,8 @@ func (m *Model) renderCurrentScore() string {
 }
 
 // Start function to begin the application
-func Start(g *game.Game) error {
-	m, err := New()
+func Start(opts Options) error {
+	m, err := NewWithOptions(opts)
 	if err != nil {
 		return err
 	}
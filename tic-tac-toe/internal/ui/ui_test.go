@@ -1,11 +1,16 @@
 package ui_test
 
 import (
+	"strings"
+	"time"
+
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"tic-tac-toe/internal/config"
 	"tic-tac-toe/internal/game"
+	"tic-tac-toe/internal/persistence"
 	"tic-tac-toe/internal/ui"
 )
 
@@ -143,6 +148,759 @@ var _ = Describe("UI", func() {
 	})
 })
 
+var _ = Describe("NewWithOptions", func() {
+	It("should start straight into a game with the AI opening", func() {
+		model, err := ui.NewWithOptions(ui.Options{
+			SaveDir:     GinkgoT().TempDir(),
+			StartGame:   true,
+			Mode:        game.PlayerVsAI,
+			HumanPlayer: game.PlayerO,
+			Seed:        1,
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		model.Update(tea.WindowSizeMsg{Width: 160, Height: 60})
+		Expect(model.View()).To(ContainSubstring("Thinking…"))
+
+		run(model, model.Init())
+		view := model.View()
+		Expect(view).To(ContainSubstring("Mode: Player vs AI"))
+		Expect(view).To(ContainSubstring("(X)"))
+		Expect(view).To(ContainSubstring("1. X ->"))
+		Expect(view).To(MatchRegexp(`AI played \(\d,\d\): takes`))
+	})
+
+	It("should start the command line's variant without saving it", func() {
+		saveDir := GinkgoT().TempDir()
+		variant := game.Misere
+		model, err := ui.NewWithOptions(ui.Options{
+			SaveDir:   saveDir,
+			StartGame: true,
+			Mode:      game.PlayerVsPlayer,
+			Variant:   &variant,
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		model.Update(tea.WindowSizeMsg{Width: 160, Height: 60})
+		Expect(model.View()).To(ContainSubstring("Rules: Misère"))
+
+		cfg := config.New(persistence.NewWithDirectory(saveDir))
+		Expect(cfg.Load()).To(Succeed())
+		Expect(cfg.GetVariant()).To(Equal(game.Classic))
+	})
+
+	It("should show the AI personality and its taunt", func() {
+		model, err := ui.NewWithOptions(ui.Options{
+			SaveDir:     GinkgoT().TempDir(),
+			StartGame:   true,
+			Mode:        game.PlayerVsAI,
+			Personality: "trickster",
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		model.Update(tea.WindowSizeMsg{Width: 160, Height: 60})
+		view := model.View()
+		Expect(view).To(ContainSubstring("AI: Loki"))
+		Expect(view).To(ContainSubstring("looks safe"))
+	})
+})
+
+var _ = Describe("AI moves", func() {
+	var model *ui.Model
+
+	BeforeEach(func() {
+		var err error
+		model, err = ui.NewWithOptions(ui.Options{
+			SaveDir:   GinkgoT().TempDir(),
+			StartGame: true,
+			Mode:      game.PlayerVsAI,
+			Seed:      1,
+		})
+		Expect(err).ToNot(HaveOccurred())
+		model.Update(tea.WindowSizeMsg{Width: 160, Height: 60})
+	})
+
+	It("should think in the background and then reply", func() {
+		_, cmd := model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		Expect(model.View()).To(ContainSubstring("Thinking…"))
+
+		// The board is locked while the AI thinks
+		model.Update(tea.KeyMsg{Type: tea.KeyRight})
+		model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		Expect(model.View()).To(ContainSubstring("Wait for the AI"))
+
+		run(model, cmd)
+		view := model.View()
+		Expect(view).ToNot(ContainSubstring("Thinking…"))
+		Expect(view).To(ContainSubstring("2. O ->"))
+		Expect(view).To(ContainSubstring("AI played"))
+	})
+
+	It("should drop the result of a search cancelled by undo", func() {
+		_, cmd := model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("u")})
+		Expect(model.View()).To(ContainSubstring("Took back your last move"))
+
+		run(model, cmd)
+		view := model.View()
+		Expect(view).ToNot(ContainSubstring("1. X ->"))
+		Expect(view).ToNot(ContainSubstring("Thinking…"))
+	})
+
+	It("should take back the AI's reply with the human's move", func() {
+		_, cmd := model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		run(model, cmd)
+		model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("u")})
+
+		view := model.View()
+		Expect(view).To(ContainSubstring("and the AI's reply"))
+		Expect(view).ToNot(ContainSubstring("1. X ->"))
+	})
+
+	It("should drop the result of a search cancelled by a reset", func() {
+		_, cmd := model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("r")})
+
+		run(model, cmd)
+		Expect(model.View()).ToNot(ContainSubstring("1. X ->"))
+	})
+
+	It("should stop the search when quitting mid-search", func() {
+		_, cmd := model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("q")})
+		Expect(model.View()).To(ContainSubstring("Are you sure you want to quit?"))
+
+		run(model, cmd)
+		Expect(model.View()).To(ContainSubstring("Are you sure you want to quit?"))
+		Expect(model.View()).ToNot(ContainSubstring("Thinking…"))
+	})
+
+	It("should stop the search when leaving mid-search", func() {
+		_, cmd := model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		model.Update(tea.KeyMsg{Type: tea.KeyEsc})
+		Expect(model.View()).To(ContainSubstring("Player vs Player"))
+
+		run(model, cmd)
+		view := model.View()
+		Expect(view).To(ContainSubstring("Player vs Player"))
+		Expect(view).ToNot(ContainSubstring("AI played"))
+	})
+})
+
+// run executes cmd and feeds the messages it produces back into the model.
+// Batched commands run one level deep; ones that do not finish promptly,
+// such as animation ticks, are skipped.
+func run(model *ui.Model, cmd tea.Cmd) {
+	if cmd == nil {
+		return
+	}
+	msg := cmd()
+	batch, ok := msg.(tea.BatchMsg)
+	if !ok {
+		model.Update(msg)
+		return
+	}
+	for _, cmd := range batch {
+		if cmd == nil {
+			continue
+		}
+		done := make(chan tea.Msg, 1)
+		go func() { done <- cmd() }()
+		select {
+		case msg := <-done:
+			model.Update(msg)
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+}
+
+var _ = Describe("Puzzles", func() {
+	It("should serve a puzzle from the menu and record the answer", func() {
+		saveDir := GinkgoT().TempDir()
+		model, err := ui.NewWithOptions(ui.Options{SaveDir: saveDir, Seed: 1})
+		Expect(err).ToNot(HaveOccurred())
+
+		model.Update(tea.WindowSizeMsg{Width: 160, Height: 60})
+		model.Update(tea.KeyMsg{Type: tea.KeySpace})
+		model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("3")})
+		view := model.View()
+		Expect(view).To(ContainSubstring("PUZZLE"))
+		Expect(view).To(MatchRegexp(`Goal: .*[XO] to move and`))
+		Expect(view).To(ContainSubstring("Streak: 0"))
+
+		// Try cells until one is empty, since the puzzle is random
+		for i := 0; i < 9; i++ {
+			model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+			if strings.Contains(model.View(), "Theme:") {
+				break
+			}
+			model.Update(tea.KeyMsg{Type: tea.KeyRight})
+			if i%3 == 2 {
+				model.Update(tea.KeyMsg{Type: tea.KeyDown})
+			}
+		}
+		Expect(model.View()).To(MatchRegexp(`✓ Solved|✗ Not quite`))
+
+		stats, err := persistence.NewWithDirectory(saveDir).LoadPuzzleStats()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(stats.Attempts).To(Equal(1))
+	})
+})
+
+var _ = Describe("Rule variants", func() {
+	It("should pick a variant from the menu and play it with a choice of mark", func() {
+		saveDir := GinkgoT().TempDir()
+		model, err := ui.NewWithOptions(ui.Options{SaveDir: saveDir, Seed: 1})
+		Expect(err).ToNot(HaveOccurred())
+
+		model.Update(tea.WindowSizeMsg{Width: 180, Height: 80})
+		model.Update(tea.KeyMsg{Type: tea.KeySpace})
+		model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("4")})
+		Expect(model.View()).To(ContainSubstring("RULE VARIANTS"))
+		Expect(model.View()).To(ContainSubstring("Classic (current)"))
+
+		model.Update(tea.KeyMsg{Type: tea.KeyDown})
+		model.Update(tea.KeyMsg{Type: tea.KeyDown})
+		Expect(model.View()).To(ContainSubstring("Place an X or an O"))
+		model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		Expect(model.View()).To(ContainSubstring("Rules set to Wild"))
+
+		settings, err := persistence.NewWithDirectory(saveDir).LoadSettings()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(settings.Variant).To(Equal("wild"))
+
+		model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("2")})
+		view := model.View()
+		Expect(view).To(ContainSubstring("Rules: Wild"))
+		Expect(view).To(ContainSubstring("Placing: X"))
+
+		model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("v")})
+		Expect(model.View()).To(ContainSubstring("Placing: O"))
+		_, cmd := model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		// Wait for the AI however long it searches
+		for _, cmd := range cmd().(tea.BatchMsg) {
+			model.Update(cmd())
+		}
+
+		view = model.View()
+		Expect(view).To(ContainSubstring("1. X -> (1,1) [O]"))
+		Expect(view).To(MatchRegexp(`2\. O -> \(\d,\d\) \[[XO]\]`))
+		Expect(view).ToNot(ContainSubstring("AI played"))
+	})
+})
+
+var _ = Describe("Notakto on several boards", func() {
+	It("should pick the boards in the variant menu and cross between them", func() {
+		saveDir := GinkgoT().TempDir()
+		model, err := ui.NewWithOptions(ui.Options{SaveDir: saveDir, Seed: 1})
+		Expect(err).ToNot(HaveOccurred())
+
+		model.Update(tea.WindowSizeMsg{Width: 180, Height: 80})
+		model.Update(tea.KeyMsg{Type: tea.KeySpace})
+		model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("4")})
+		for i := 0; i < 4; i++ {
+			model.Update(tea.KeyMsg{Type: tea.KeyDown})
+		}
+		Expect(model.View()).To(ContainSubstring("Boards: 1"))
+		model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("b")})
+		Expect(model.View()).To(ContainSubstring("Boards: 2"))
+		model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+
+		settings, err := persistence.NewWithDirectory(saveDir).LoadSettings()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(settings.NotaktoBoards).To(Equal(2))
+
+		// Two players, so no AI move interrupts
+		model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("1")})
+		view := model.View()
+		Expect(view).To(ContainSubstring("Board 1"))
+		Expect(view).To(ContainSubstring("Board 2"))
+
+		model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		model.Update(tea.KeyMsg{Type: tea.KeyRight})
+		model.Update(tea.KeyMsg{Type: tea.KeyRight})
+		model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		view = model.View()
+		Expect(view).To(ContainSubstring("1. X -> (1,1) on board 1"))
+		Expect(view).To(ContainSubstring("2. O -> (1,0) on board 2"))
+	})
+})
+
+var _ = Describe("Game setups", func() {
+	It("should pick a setup from the menu and keep its blocked cells out of play", func() {
+		saveDir := GinkgoT().TempDir()
+		model, err := ui.NewWithOptions(ui.Options{SaveDir: saveDir, Seed: 1})
+		Expect(err).ToNot(HaveOccurred())
+
+		model.Update(tea.WindowSizeMsg{Width: 180, Height: 80})
+		model.Update(tea.KeyMsg{Type: tea.KeySpace})
+		model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("8")})
+		Expect(model.View()).To(ContainSubstring("GAME SETUP"))
+		Expect(model.View()).To(ContainSubstring("Standard (current)"))
+
+		model.Update(tea.KeyMsg{Type: tea.KeyDown})
+		Expect(model.View()).To(ContainSubstring("1 blocked cell."))
+		model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		Expect(model.View()).To(ContainSubstring("Setup set to Blocked center"))
+
+		settings, err := persistence.NewWithDirectory(saveDir).LoadSettings()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(settings.Setup).To(Equal("Blocked center"))
+
+		model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("1")})
+		Expect(model.View()).To(ContainSubstring("Setup: Blocked center"))
+		model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		Expect(model.View()).To(ContainSubstring("position (1, 1) is blocked"))
+
+		model.Update(tea.KeyMsg{Type: tea.KeyLeft})
+		model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		view := model.View()
+		Expect(view).To(ContainSubstring("1. X -> (1,0)"))
+		Expect(view).To(ContainSubstring("▓"))
+	})
+
+	It("should draw random obstacles for each new game", func() {
+		model, err := ui.NewWithOptions(ui.Options{SaveDir: GinkgoT().TempDir(), Seed: 1})
+		Expect(err).ToNot(HaveOccurred())
+
+		model.Update(tea.WindowSizeMsg{Width: 180, Height: 80})
+		model.Update(tea.KeyMsg{Type: tea.KeySpace})
+		model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("8")})
+		for i := 0; i < 5; i++ {
+			model.Update(tea.KeyMsg{Type: tea.KeyDown})
+		}
+		Expect(model.View()).To(ContainSubstring("neither side can force a win"))
+		model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+
+		model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("1")})
+		view := model.View()
+		Expect(view).To(ContainSubstring("Setup: Random obstacles"))
+		Expect(view).To(ContainSubstring("▓"))
+	})
+})
+
+var _ = Describe("Finished games", func() {
+	It("should score, archive and save a local game once it is won", func() {
+		saveDir := GinkgoT().TempDir()
+		model, err := ui.NewWithOptions(ui.Options{SaveDir: saveDir, Seed: 1})
+		Expect(err).ToNot(HaveOccurred())
+
+		model.Update(tea.WindowSizeMsg{Width: 180, Height: 80})
+		model.Update(tea.KeyMsg{Type: tea.KeySpace})
+		model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("1")})
+
+		// X takes the center, (0,2) and (2,0); O answers beside them
+		var cmd tea.Cmd
+		for _, keys := range [][]tea.KeyType{
+			{}, {tea.KeyRight}, {tea.KeyUp}, {tea.KeyLeft}, {tea.KeyDown, tea.KeyDown, tea.KeyLeft},
+		} {
+			for _, key := range keys {
+				model.Update(tea.KeyMsg{Type: key})
+			}
+			_, cmd = model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		}
+		run(model, cmd)
+
+		// The winning diagonal pulses on the board before the game-over screen
+		view := model.View()
+		Expect(view).To(ContainSubstring("━━X━━"))
+		Expect(view).ToNot(ContainSubstring("WINS!"))
+		tick := model.Init()().(tea.BatchMsg)[0]()
+		for i := 0; i < 10 && !strings.Contains(model.View(), "WINS!"); i++ {
+			model.Update(tick)
+		}
+		Expect(model.View()).To(ContainSubstring("PLAYER X WINS!"))
+
+		manager := persistence.NewWithDirectory(saveDir)
+		scores, err := manager.LoadScores()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(scores.PlayerVsPlayer.XWins).To(Equal(1))
+
+		archive, err := manager.LoadArchive()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(archive).To(HaveLen(1))
+		Expect(archive[0].Moves).To(HaveLen(5))
+
+		saved, err := manager.LoadGameState()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(saved.GetWinner()).To(Equal(game.PlayerX))
+	})
+})
+
+var _ = Describe("Winning line", func() {
+	It("should skip to the game-over screen on a key", func() {
+		model, err := ui.NewWithOptions(ui.Options{SaveDir: GinkgoT().TempDir(), Seed: 1})
+		Expect(err).ToNot(HaveOccurred())
+
+		model.Update(tea.WindowSizeMsg{Width: 180, Height: 80})
+		model.Update(tea.KeyMsg{Type: tea.KeySpace})
+		model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("1")})
+		// X takes the middle row while O plays along the top
+		for _, key := range []tea.KeyType{tea.KeyEnter, tea.KeyUp, tea.KeyEnter, tea.KeyDown, tea.KeyLeft, tea.KeyEnter, tea.KeyUp, tea.KeyEnter, tea.KeyDown, tea.KeyRight, tea.KeyRight, tea.KeyEnter} {
+			model.Update(tea.KeyMsg{Type: key})
+		}
+		Expect(model.View()).To(ContainSubstring("━━X━━"))
+
+		model.Update(tea.KeyMsg{Type: tea.KeyLeft})
+		Expect(model.View()).To(ContainSubstring("PLAYER X WINS!"))
+	})
+
+	It("should name the winner in the status panel as on the game-over screen", func() {
+		variant := game.Notakto
+		model, err := ui.NewWithOptions(ui.Options{SaveDir: GinkgoT().TempDir(), StartGame: true, Mode: game.PlayerVsPlayer, Variant: &variant})
+		Expect(err).ToNot(HaveOccurred())
+
+		model.Update(tea.WindowSizeMsg{Width: 180, Height: 80})
+		// Both place X; the first player completes the middle row and loses
+		for _, key := range []tea.KeyType{tea.KeyEnter, tea.KeyLeft, tea.KeyEnter, tea.KeyRight, tea.KeyRight, tea.KeyEnter} {
+			model.Update(tea.KeyMsg{Type: key})
+		}
+		Expect(model.View()).To(ContainSubstring("Status: Player O Wins!"))
+
+		model.Update(tea.KeyMsg{Type: tea.KeyLeft})
+		Expect(model.View()).To(ContainSubstring("PLAYER O WINS!"))
+	})
+
+	// expectPulse checks that the winning line pulses, then stays lit on the
+	// game's own screen once the pulse is over
+	expectPulse := func(model *ui.Model, result string) {
+		Expect(model.View()).To(MatchRegexp(`━[XOΔ□]━`))
+		tick := model.Init()().(tea.BatchMsg)[0]()
+		for i := 0; i < 10; i++ {
+			model.Update(tick)
+		}
+		view := model.View()
+		Expect(view).ToNot(MatchRegexp(`━[XOΔ□]━`))
+		Expect(view).To(ContainSubstring(result))
+	}
+
+	It("should pulse on the Gravity board", func() {
+		model, err := ui.NewWithOptions(ui.Options{SaveDir: GinkgoT().TempDir(), Seed: 1})
+		Expect(err).ToNot(HaveOccurred())
+
+		model.Update(tea.WindowSizeMsg{Width: 160, Height: 60})
+		model.Update(tea.KeyMsg{Type: tea.KeySpace})
+		model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("6")})
+		tick := model.Init()().(tea.BatchMsg)[0]()
+		land := func() tea.Cmd {
+			for i := 0; i < 10; i++ {
+				_, cmd := model.Update(tick)
+				if view := model.View(); !strings.Contains(view, "Wait for the mark") &&
+					(strings.Contains(view, "Move ") || strings.Contains(view, "Thinking…") || strings.Contains(view, "wins!")) {
+					return cmd
+				}
+			}
+			Fail("the mark never landed")
+			return nil
+		}
+		// X keeps to the side columns while the AI builds along the bottom
+		for turn := 0; turn < 10 && !strings.Contains(model.View(), "wins!"); turn++ {
+			model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'1' + rune(turn%2*6)}})
+			cmd := land()
+			if strings.Contains(model.View(), "Thinking…") {
+				for _, cmd := range cmd().(tea.BatchMsg) {
+					if cmd != nil {
+						model.Update(cmd())
+					}
+				}
+				land()
+			}
+		}
+		expectPulse(model, "The AI wins!")
+	})
+
+	// playAlong places the human's marks around the board until someone wins
+	playAlong := func(model *ui.Model) {
+		keys := []tea.KeyType{tea.KeyDown, tea.KeyLeft, tea.KeyDown, tea.KeyLeft, tea.KeyUp, tea.KeyUp, tea.KeyUp, tea.KeyRight}
+		for turn := 0; turn < 30 && !strings.Contains(model.View(), "win"); turn++ {
+			_, cmd := model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+			for cmd != nil {
+				_, cmd = model.Update(cmd())
+			}
+			model.Update(tea.KeyMsg{Type: keys[turn%len(keys)]})
+		}
+	}
+
+	It("should pulse on the Qubic cube", func() {
+		model, err := ui.NewWithOptions(ui.Options{SaveDir: GinkgoT().TempDir(), Seed: 1})
+		Expect(err).ToNot(HaveOccurred())
+
+		model.Update(tea.WindowSizeMsg{Width: 180, Height: 80})
+		model.Update(tea.KeyMsg{Type: tea.KeySpace})
+		model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("5")})
+		playAlong(model)
+		expectPulse(model, "The AI wins!")
+	})
+
+	It("should pulse on the multi-player board", func() {
+		model, err := ui.NewWithOptions(ui.Options{SaveDir: GinkgoT().TempDir(), Seed: 1})
+		Expect(err).ToNot(HaveOccurred())
+
+		model.Update(tea.WindowSizeMsg{Width: 180, Height: 80})
+		model.Update(tea.KeyMsg{Type: tea.KeySpace})
+		for i := 0; i < 9; i++ {
+			model.Update(tea.KeyMsg{Type: tea.KeyDown})
+		}
+		model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		playAlong(model)
+		expectPulse(model, "AI Δ wins!")
+	})
+})
+
+var _ = Describe("Swap rules", func() {
+	It("should let the second player take over the first mark under the pie rule", func() {
+		saveDir := GinkgoT().TempDir()
+		model, err := ui.NewWithOptions(ui.Options{SaveDir: saveDir, Seed: 1})
+		Expect(err).ToNot(HaveOccurred())
+
+		model.Update(tea.WindowSizeMsg{Width: 180, Height: 80})
+		model.Update(tea.KeyMsg{Type: tea.KeySpace})
+		for i := 0; i < 12; i++ {
+			model.Update(tea.KeyMsg{Type: tea.KeyDown})
+		}
+		model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("o")})
+		Expect(model.View()).To(ContainSubstring("Swap Rule: Pie rule"))
+
+		settings, err := persistence.NewWithDirectory(saveDir).LoadSettings()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(settings.SwapRule).To(Equal("pie"))
+
+		model.Update(tea.KeyMsg{Type: tea.KeyEsc})
+		model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("1")})
+		Expect(model.View()).To(ContainSubstring("Swap rule: Pie rule • Player 1 X, Player 2 O"))
+		model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		Expect(model.View()).To(ContainSubstring("Player 2: swap sides?"))
+
+		model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+		view := model.View()
+		Expect(view).To(ContainSubstring("Player 2 chose to swap sides: Player 2 now plays X"))
+		Expect(view).To(ContainSubstring("Player 1 O, Player 2 X"))
+
+		model.Update(tea.KeyMsg{Type: tea.KeyRight})
+		model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		Expect(model.View()).To(ContainSubstring("2. O -> (1,2)"))
+	})
+})
+
+var _ = Describe("Moving marks", func() {
+	It("should move a placed mark in two steps once all three are down", func() {
+		model, err := ui.NewWithOptions(ui.Options{SaveDir: GinkgoT().TempDir(), Seed: 1})
+		Expect(err).ToNot(HaveOccurred())
+
+		model.Update(tea.WindowSizeMsg{Width: 180, Height: 80})
+		model.Update(tea.KeyMsg{Type: tea.KeySpace})
+		model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("4")})
+		for i := 0; i < 5; i++ {
+			model.Update(tea.KeyMsg{Type: tea.KeyDown})
+		}
+		model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		Expect(model.View()).To(ContainSubstring("Rules set to Achi"))
+		model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("1")})
+
+		pick := func(row, col int) {
+			for i := 0; i < 2; i++ {
+				model.Update(tea.KeyMsg{Type: tea.KeyUp})
+				model.Update(tea.KeyMsg{Type: tea.KeyLeft})
+			}
+			for i := 0; i < row; i++ {
+				model.Update(tea.KeyMsg{Type: tea.KeyDown})
+			}
+			for i := 0; i < col; i++ {
+				model.Update(tea.KeyMsg{Type: tea.KeyRight})
+			}
+			model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		}
+		for _, cell := range [][2]int{{0, 0}, {1, 1}, {0, 2}, {0, 1}, {2, 1}, {1, 0}} {
+			pick(cell[0], cell[1])
+		}
+		Expect(model.View()).To(ContainSubstring("Moving: pick one of your marks"))
+
+		pick(2, 1)
+		Expect(model.View()).To(ContainSubstring("Moving: (2,1), pick an empty cell"))
+		pick(1, 2)
+		Expect(model.View()).To(ContainSubstring("cannot move from (2, 1) to (1, 2) under Achi rules"))
+		pick(2, 2)
+		Expect(model.View()).To(ContainSubstring("7. X (2,1) -> (2,2)"))
+
+		pick(2, 0)
+		Expect(model.View()).To(ContainSubstring("Pick one of your O marks to move"))
+		pick(1, 0)
+		model.Update(tea.KeyMsg{Type: tea.KeyEsc})
+		view := model.View()
+		Expect(view).To(ContainSubstring("Move cancelled"))
+		Expect(view).To(ContainSubstring("Moving: pick one of your marks"))
+	})
+})
+
+var _ = Describe("Quantum", func() {
+	It("should place spooky marks in two cells and let the opponent measure a cycle", func() {
+		model, err := ui.NewWithOptions(ui.Options{SaveDir: GinkgoT().TempDir(), Seed: 1})
+		Expect(err).ToNot(HaveOccurred())
+
+		model.Update(tea.WindowSizeMsg{Width: 160, Height: 60})
+		model.Update(tea.KeyMsg{Type: tea.KeySpace})
+		model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("7")})
+		view := model.View()
+		Expect(view).To(ContainSubstring("QUANTUM TIC-TAC-TOE"))
+		Expect(view).To(ContainSubstring("X to move • X1 • Cursor (1,1)"))
+
+		model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		Expect(model.View()).To(ContainSubstring("X1 in (1,1) and…"))
+		model.Update(tea.KeyMsg{Type: tea.KeyRight})
+		model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		view = model.View()
+		Expect(strings.Count(view, "x1")).To(Equal(2))
+		Expect(view).To(ContainSubstring("O to move • O2"))
+
+		model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		model.Update(tea.KeyMsg{Type: tea.KeyEsc})
+		Expect(model.View()).To(ContainSubstring("Move cancelled"))
+
+		model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		model.Update(tea.KeyMsg{Type: tea.KeyLeft})
+		model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		view = model.View()
+		Expect(view).To(ContainSubstring("O2 closed a cycle: X chooses where it collapses"))
+		Expect(view).To(ContainSubstring("X measures: collapse O2 into (1,2) or (1,1)"))
+
+		model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		view = model.View()
+		Expect(view).To(ContainSubstring("O2 collapsed into (1,1)"))
+		Expect(view).To(ContainSubstring("X1"))
+		Expect(view).ToNot(ContainSubstring("x1"))
+		Expect(view).To(ContainSubstring("X to move • X3"))
+
+		model.Update(tea.KeyMsg{Type: tea.KeyEsc})
+		Expect(model.View()).To(ContainSubstring("Quantum"))
+	})
+})
+
+var _ = Describe("3D Qubic", func() {
+	It("should render four layers and play against the AI", func() {
+		model, err := ui.NewWithOptions(ui.Options{SaveDir: GinkgoT().TempDir(), Seed: 1})
+		Expect(err).ToNot(HaveOccurred())
+
+		model.Update(tea.WindowSizeMsg{Width: 160, Height: 60})
+		model.Update(tea.KeyMsg{Type: tea.KeySpace})
+		model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("5")})
+		view := model.View()
+		Expect(view).To(ContainSubstring("3D QUBIC"))
+		Expect(view).To(ContainSubstring("Layer 4"))
+		Expect(view).To(ContainSubstring("Cursor (0,1,1)"))
+
+		model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(".")})
+		model.Update(tea.KeyMsg{Type: tea.KeyRight})
+		Expect(model.View()).To(ContainSubstring("Cursor (1,1,2)"))
+		model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(",")})
+		model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(",")})
+		Expect(model.View()).To(ContainSubstring("Cursor (3,1,2)"))
+
+		_, cmd := model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		Expect(model.View()).To(ContainSubstring("Thinking…"))
+		model.Update(cmd())
+		view = model.View()
+		Expect(view).To(MatchRegexp(`AI played \(\d,\d,\d\)`))
+		Expect(view).To(ContainSubstring("[X]"))
+	})
+})
+
+var _ = Describe("Gravity", func() {
+	It("should drop marks down columns and let the AI reply once they land", func() {
+		model, err := ui.NewWithOptions(ui.Options{SaveDir: GinkgoT().TempDir(), Seed: 1})
+		Expect(err).ToNot(HaveOccurred())
+
+		model.Update(tea.WindowSizeMsg{Width: 160, Height: 60})
+		model.Update(tea.KeyMsg{Type: tea.KeySpace})
+		model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("6")})
+		view := model.View()
+		Expect(view).To(ContainSubstring("GRAVITY"))
+		Expect(view).To(ContainSubstring("7×6, 4 in a row"))
+		Expect(view).To(ContainSubstring("Column 4 • Move 1"))
+
+		model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("2")})
+		model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("3")})
+		Expect(model.View()).To(ContainSubstring("Wait for the mark to land"))
+
+		// The animation tick moves the mark down a row at a time
+		tick := model.Init()().(tea.BatchMsg)[0]()
+		land := func() tea.Cmd {
+			for i := 0; i < 10; i++ {
+				_, cmd := model.Update(tick)
+				if view := model.View(); strings.Contains(view, "Move ") || strings.Contains(view, "Thinking…") {
+					return cmd
+				}
+			}
+			Fail("the mark never landed")
+			return nil
+		}
+		cmd := land()
+		Expect(model.View()).To(ContainSubstring("Thinking…"))
+		for _, cmd := range cmd().(tea.BatchMsg) {
+			if cmd != nil {
+				model.Update(cmd())
+			}
+		}
+		Expect(model.View()).To(MatchRegexp(`AI dropped in column \d`))
+		land()
+		Expect(model.View()).To(ContainSubstring("Move 3"))
+
+		// Clicking anywhere in the first column drops into it
+		model.Update(tea.MouseMsg{X: 66, Y: 3, Type: tea.MouseLeft})
+		model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("3")})
+		Expect(model.View()).To(ContainSubstring("Wait for the mark to land"))
+
+		model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("b")})
+		Expect(model.View()).To(ContainSubstring("Board changed to 6×5, 4 in a row"))
+	})
+})
+
+var _ = Describe("Multi-player", func() {
+	It("should let the AIs reply in turn and record the game's players", func() {
+		saveDir := GinkgoT().TempDir()
+		model, err := ui.NewWithOptions(ui.Options{SaveDir: saveDir, Seed: 1})
+		Expect(err).ToNot(HaveOccurred())
+
+		model.Update(tea.WindowSizeMsg{Width: 180, Height: 80})
+		model.Update(tea.KeyMsg{Type: tea.KeySpace})
+		for i := 0; i < 9; i++ {
+			model.Update(tea.KeyMsg{Type: tea.KeyDown})
+		}
+		model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		view := model.View()
+		Expect(view).To(ContainSubstring("MULTI-PLAYER"))
+		Expect(view).To(ContainSubstring("3 players, 6×6, 4 in a row"))
+		Expect(view).To(ContainSubstring("Cursor (3,3) • Move 1"))
+		Expect(view).To(ContainSubstring("AI search: Max-n"))
+
+		// Each AI's move starts the next AI until it is the human's turn again
+		_, cmd := model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		Expect(model.View()).To(ContainSubstring("AI O thinking…"))
+		for cmd != nil {
+			_, cmd = model.Update(cmd())
+		}
+		view = model.View()
+		Expect(view).To(MatchRegexp(`Δ played \(\d,\d\)`))
+		Expect(view).To(ContainSubstring("Move 4"))
+		Expect(view).To(ContainSubstring("1. X -> (3,3)"))
+		Expect(view).To(MatchRegexp(`3\. Δ -> \(\d,\d\)`))
+
+		model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		Expect(model.View()).To(ContainSubstring("position (3, 3) is already occupied"))
+
+		model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("i")})
+		Expect(model.View()).To(ContainSubstring("AI search changed to Paranoid"))
+		model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("b")})
+		Expect(model.View()).To(ContainSubstring("Game changed to 4 players, 8×8, 4 in a row"))
+		Expect(model.View()).To(ContainSubstring("□ AI □"))
+
+		settings, err := persistence.NewWithDirectory(saveDir).LoadSettings()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(settings.MultiBoard).To(Equal("4p-8x8x4"))
+		Expect(settings.MultiSearch).To(Equal("paranoid"))
+	})
+})
+
 var _ = Describe("Start function", func() {
 	It("should accept a game instance", func() {
 		g := game.New()
@@ -152,4 +910,133 @@ var _ = Describe("Start function", func() {
 		// But we can verify it doesn't panic with a nil game
 		// Note: This test is limited because Start() blocks with the UI
 	})
-})
\ No newline at end of file
+})
+
+// placeAt moves the cursor from the top-left corner to the cell and places a mark
+func placeAt(model *ui.Model, row, col int) {
+	for _, key := range []tea.KeyType{tea.KeyUp, tea.KeyUp, tea.KeyLeft, tea.KeyLeft} {
+		model.Update(tea.KeyMsg{Type: key})
+	}
+	for range row {
+		model.Update(tea.KeyMsg{Type: tea.KeyDown})
+	}
+	for range col {
+		model.Update(tea.KeyMsg{Type: tea.KeyRight})
+	}
+	model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+}
+
+// winTopRow lets the first player take the top row, then skips the winning line
+func winTopRow(model *ui.Model) {
+	for _, cell := range [][2]int{{0, 0}, {1, 0}, {0, 1}, {1, 1}, {0, 2}} {
+		placeAt(model, cell[0], cell[1])
+	}
+	model.Update(tea.KeyMsg{Type: tea.KeyLeft})
+}
+
+var _ = Describe("Matches", func() {
+	It("should alternate the first move through a best-of-3 match and record it", func() {
+		saveDir := GinkgoT().TempDir()
+		manager := persistence.NewWithDirectory(saveDir)
+		Expect(manager.UpdateSettings(func(settings *persistence.Settings) {
+			settings.Match = "bo3"
+		})).To(Succeed())
+		model, err := ui.NewWithOptions(ui.Options{SaveDir: saveDir, Seed: 1})
+		Expect(err).ToNot(HaveOccurred())
+
+		model.Update(tea.WindowSizeMsg{Width: 180, Height: 80})
+		model.Update(tea.KeyMsg{Type: tea.KeySpace})
+		model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("1")})
+		Expect(model.View()).To(ContainSubstring("Match: Best of 3 • Game 1"))
+		Expect(model.View()).To(ContainSubstring("Player A plays X, Player B plays O"))
+
+		winTopRow(model)
+		view := model.View()
+		Expect(view).To(ContainSubstring("Best of 3: Player A 1 – 0 Player B"))
+		Expect(view).To(ContainSubstring("Press Enter for game 2"))
+
+		model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		Expect(model.View()).To(ContainSubstring("Player B plays X, Player A plays O"))
+		winTopRow(model)
+		Expect(model.View()).To(ContainSubstring("Player A 1 – 1 Player B"))
+
+		model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		Expect(model.View()).To(ContainSubstring("Player A plays X, Player B plays O"))
+		winTopRow(model)
+		Expect(model.View()).To(ContainSubstring("Press Enter to see the match result"))
+
+		model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		view = model.View()
+		Expect(view).To(ContainSubstring("Player A wins the match!"))
+		Expect(view).To(ContainSubstring("Game 2: Player B moved first • Player B won"))
+
+		matches, err := manager.LoadMatches()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(matches).To(HaveLen(1))
+		Expect(matches[0].Match.Summary()).To(Equal("Player A 2 – 1 Player B"))
+
+		archive, err := manager.LoadArchive()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(archive).To(HaveLen(3))
+		Expect(archive[1].PlayerX).To(Equal("Player B"))
+		Expect(archive[1].Match).To(Equal(&persistence.MatchGame{ID: matches[0].ID, Game: 2}))
+
+		scores, err := manager.LoadScores()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(scores.Matches.Wins).To(Equal(map[string]int{"Player A": 1}))
+
+		model.Update(tea.KeyMsg{Type: tea.KeyEsc})
+		Expect(model.View()).To(ContainSubstring("🏆 Tournament"))
+	})
+
+	It("should play a knockout tournament through its bracket to a champion", func() {
+		saveDir := GinkgoT().TempDir()
+		model, err := ui.NewWithOptions(ui.Options{SaveDir: saveDir, Seed: 1})
+		Expect(err).ToNot(HaveOccurred())
+
+		model.Update(tea.WindowSizeMsg{Width: 180, Height: 80})
+		model.Update(tea.KeyMsg{Type: tea.KeySpace})
+		model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("9")})
+		Expect(model.View()).To(ContainSubstring("4. Player 4"))
+
+		// Rename the first two players and drop the other two
+		for _, name := range []string{"Ann", "Ben"} {
+			model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+			model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(name)})
+			model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+			model.Update(tea.KeyMsg{Type: tea.KeyDown})
+		}
+		model.Update(tea.KeyMsg{Type: tea.KeyDown})
+		model.Update(tea.KeyMsg{Type: tea.KeyDown})
+		model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("-")})
+		model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("-")})
+		model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		view := model.View()
+		Expect(view).To(ContainSubstring("Final"))
+		Expect(view).To(ContainSubstring("Next: Ann vs Ben"))
+
+		// A drawn knockout game is replayed with the other player moving first
+		model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		Expect(model.View()).To(ContainSubstring("Ann plays X, Ben plays O"))
+		for _, cell := range [][2]int{{0, 0}, {1, 1}, {2, 2}, {0, 1}, {2, 1}, {2, 0}, {0, 2}, {1, 2}, {1, 0}} {
+			placeAt(model, cell[0], cell[1])
+		}
+		Expect(model.View()).To(ContainSubstring("Press Enter for game 2"))
+
+		model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		Expect(model.View()).To(ContainSubstring("Ben plays X, Ann plays O"))
+		winTopRow(model)
+		model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		Expect(model.View()).To(ContainSubstring("Ben wins the match!"))
+
+		model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		view = model.View()
+		Expect(view).To(ContainSubstring("Champion: Ben"))
+		Expect(view).To(ContainSubstring("Ann 0 – 1 Ben (1 draw)"))
+
+		scores, err := persistence.NewWithDirectory(saveDir).LoadScores()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(scores.Matches.Tournaments).To(Equal(1))
+		Expect(scores.Matches.Champions).To(Equal(map[string]int{"Ben": 1}))
+	})
+})
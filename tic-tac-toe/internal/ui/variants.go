@@ -0,0 +1,119 @@
+package ui
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"tic-tac-toe/internal/game"
+	"tic-tac-toe/internal/input"
+)
+
+// openVariantMenu shows the rule variants with the configured one highlighted
+func (m *Model) openVariantMenu() {
+	m.state = StateVariantMenu
+	m.variantCursor = 0
+	for i, variant := range game.Variants {
+		if variant == m.localRules().Variant {
+			m.variantCursor = i
+		}
+	}
+}
+
+// handleVariantMenuInput handles keys in the variant menu; Enter picks the
+// rules for new local games
+func (m *Model) handleVariantMenuInput(action input.KeybindingAction) tea.Cmd {
+	switch action {
+	case input.ActionMoveUp:
+		if m.variantCursor > 0 {
+			m.variantCursor--
+		}
+	case input.ActionMoveDown:
+		if m.variantCursor < len(game.Variants)-1 {
+			m.variantCursor++
+		}
+	case input.ActionSelect:
+		variant := game.Variants[m.variantCursor]
+		if err := m.config.SetVariant(string(variant)); err != nil {
+			m.errorMessage = "Failed to save rules: " + err.Error()
+			return nil
+		}
+		m.variant = nil
+		m.statusMessage = "Rules set to " + variant.Name()
+		m.state = StateMainMenu
+	case input.ActionCycleBoard:
+		if game.Variants[m.variantCursor] != game.Notakto {
+			return nil
+		}
+		if err := m.config.NextNotaktoBoards(); err != nil {
+			m.errorMessage = "Failed to save boards: " + err.Error()
+		}
+	case input.ActionBack:
+		m.state = StateMainMenu
+	}
+	return nil
+}
+
+// renderVariantMenu lists the rule variants with a description of each
+func (m *Model) renderVariantMenu() string {
+	content := m.gradientManager.ApplyToText("🎲 RULE VARIANTS") + "\n\n"
+	for i, variant := range game.Variants {
+		name := variant.Name()
+		if variant == m.localRules().Variant {
+			name += " (current)"
+		}
+		if i == m.variantCursor {
+			content += m.gradientManager.ApplyToText("▶ "+name+" ◀") + "\n"
+		} else {
+			content += "  " + name + "\n"
+		}
+	}
+
+	description := game.Variants[m.variantCursor].Description()
+	content += "\n" + lipgloss.NewStyle().Width(50).Render(description) + "\n"
+	help := "↑↓ Navigate • Enter Choose • esc Back"
+	if game.Variants[m.variantCursor] == game.Notakto {
+		content += fmt.Sprintf("Boards: %d\n", m.config.GetNotaktoBoards())
+		help = "↑↓ Navigate • Enter Choose • b Boards • esc Back"
+	}
+	if game.Variants[m.variantCursor] != game.Classic {
+		content += lipgloss.NewStyle().Faint(true).Render("Variant games are scored separately") + "\n"
+	}
+
+	if m.errorMessage != "" {
+		content += "\n" + lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Render(m.errorMessage) + "\n"
+	}
+	content += "\n" + lipgloss.NewStyle().Faint(true).Render(help)
+
+	style := lipgloss.NewStyle().
+		Align(lipgloss.Center).
+		Width(m.width).
+		Height(m.height).
+		Padding(2)
+
+	return style.Render(content)
+}
+
+// markToPlace returns the mark the human's next move places
+func (m *Model) markToPlace() game.Player {
+	if m.markChoice != game.Empty && m.game.CanPlace(m.markChoice) {
+		return m.markChoice
+	}
+	return m.game.GetRules().Marks(m.game.GetCurrentPlayer())[0]
+}
+
+// toggleMark switches the mark to place, for rules that let players choose
+func (m *Model) toggleMark() {
+	marks := m.game.GetRules().Marks(m.game.GetCurrentPlayer())
+	if len(marks) < 2 {
+		m.statusMessage = fmt.Sprintf("%s rules have no choice of mark", m.game.GetRules().Variant.Name())
+		return
+	}
+	if m.markToPlace() == marks[0] {
+		m.markChoice = marks[1]
+	} else {
+		m.markChoice = marks[0]
+	}
+	m.statusMessage = "Placing " + string(m.markChoice)
+}
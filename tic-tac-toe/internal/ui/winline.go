@@ -0,0 +1,89 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"tic-tac-toe/internal/game"
+)
+
+// winAnimation pulses the winning line on the board before the game-over
+// screen appears. The Gravity, Qubic and multi-player screens find the
+// cells of their line themselves and only use the frame.
+type winAnimation struct {
+	board int        // The board the lines are on, in games on several boards
+	cells [3][3]bool // The cells of every winning line on a 3×3 board
+	frame int
+}
+
+// winAnimationFrames is how many ticks the winning line pulses for
+const winAnimationFrames = 8
+
+// winTickDivisor speeds up the animation tick while the winning line pulses
+const winTickDivisor = 4
+
+// startWinAnimation pulses the lines that won the game; draws and games won
+// without a line, such as on time, go straight to the game-over screen
+func (m *Model) startWinAnimation(board int, lines []game.Line) {
+	if len(lines) == 0 {
+		m.state = StateGameOver
+		return
+	}
+	m.winLine = &winAnimation{board: board}
+	for _, line := range lines {
+		for _, p := range line {
+			m.winLine.cells[p.Row][p.Col] = true
+		}
+	}
+}
+
+// pulseWinLine pulses the winning line of a Gravity, Qubic or multi-player
+// game; its screen stays up and the line stays lit once the pulse is over
+func (m *Model) pulseWinLine() {
+	m.winLine = &winAnimation{}
+}
+
+// advanceWinAnimation moves the pulse on a frame, showing the game-over
+// screen once it has run its course
+func (m *Model) advanceWinAnimation() {
+	if m.winLine == nil {
+		return
+	}
+	m.winLine.frame++
+	if m.winLine.frame >= winAnimationFrames {
+		m.endWinAnimation()
+	}
+}
+
+// endWinAnimation stops the pulse and shows the game-over screen
+func (m *Model) endWinAnimation() {
+	m.winLine = nil
+	if m.state == StateGame {
+		m.state = StateGameOver
+	}
+}
+
+// onWinLine reports whether the cell of the given board is part of a
+// winning line being shown
+func (m *Model) onWinLine(board, row, col int) bool {
+	return m.winLine != nil && m.winLine.board == board && m.winLine.cells[row][col]
+}
+
+// renderLineCell draws a cell of the winning line of a Gravity, Qubic or
+// multi-player game: pulsing while the animation runs, then in the gradient
+func (m *Model) renderLineCell(cell string) string {
+	if m.winLine != nil {
+		return m.renderWinningCell(cell)
+	}
+	return m.gradientManager.ApplyToText(cell)
+}
+
+// renderWinningCell draws a cell of the winning line: struck through in the
+// gradient on even frames and faint on odd ones, so the line pulses
+func (m *Model) renderWinningCell(cell string) string {
+	if m.winLine.frame%2 == 1 {
+		return lipgloss.NewStyle().Faint(true).Render(cell)
+	}
+	return m.gradientManager.ApplyToText(strings.ReplaceAll(cell, " ", "━"))
+}
@@ -1,21 +1,11 @@
 package main
 
 import (
-	"fmt"
-	"log"
+	"os"
 
-	"tic-tac-toe/internal/game"
-	"tic-tac-toe/internal/ui"
+	"tic-tac-toe/internal/cli"
 )
 
 func main() {
-	fmt.Println("🎮 Tic-Tac-Toe Game Starting...")
-	
-	// Initialize game
-	g := game.New()
-	
-	// Start UI
-	if err := ui.Start(g); err != nil {
-		log.Fatal("Failed to start game:", err)
-	}
-}
\ No newline at end of file
+	os.Exit(cli.Run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}